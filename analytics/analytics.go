@@ -8,7 +8,6 @@ import (
 	"github.com/dukex/mixpanel"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
-	cm "github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/google/uuid"
 	"github.com/hibiken/asynq"
 	log "github.com/sirupsen/logrus"
@@ -82,14 +81,7 @@ func newAnalytics(Repo *Repo, cfg config.Configuration) (*Analytics, error) {
 	return a, nil
 }
 
-func TrackDailyAnalytics(store datastore.Store, cfg config.Configuration) func(context.Context, *asynq.Task) error {
-	repo := &Repo{
-		ConfigRepo: cm.NewConfigRepo(store),
-		EventRepo:  cm.NewEventRepository(store),
-		GroupRepo:  cm.NewGroupRepo(store),
-		OrgRepo:    cm.NewOrgRepo(store),
-		UserRepo:   cm.NewUserRepo(store),
-	}
+func TrackDailyAnalytics(repo *Repo, cfg config.Configuration) func(context.Context, *asynq.Task) error {
 	return func(ctx context.Context, t *asynq.Task) error {
 		a, err := newAnalytics(repo, cfg)
 		if err != nil {
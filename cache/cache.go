@@ -11,6 +11,8 @@ import (
 
 type Cache interface {
 	Set(ctx context.Context, key string, data interface{}, expiration time.Duration) error
+	// SetNX sets key only when it isn't set yet, and reports whether it did.
+	SetNX(ctx context.Context, key string, data interface{}, expiration time.Duration) (bool, error)
 	Get(ctx context.Context, key string, data interface{}) error
 	Delete(ctx context.Context, key string) error
 }
@@ -3,6 +3,7 @@ package mcache
 import (
 	"context"
 	"errors"
+	"sync"
 	"time"
 
 	"github.com/go-redis/cache/v8"
@@ -10,6 +11,9 @@ import (
 
 type MemoryCache struct {
 	cache *cache.Cache
+
+	// mu makes SetNX's check and set atomic
+	mu sync.Mutex
 }
 
 const cacheSize = 128000
@@ -31,6 +35,17 @@ func (m *MemoryCache) Set(ctx context.Context, key string, data interface{}, ttl
 	})
 }
 
+func (m *MemoryCache) SetNX(ctx context.Context, key string, data interface{}, ttl time.Duration) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cache.Exists(ctx, key) {
+		return false, nil
+	}
+
+	return true, m.Set(ctx, key, data, ttl)
+}
+
 func (m *MemoryCache) Get(ctx context.Context, key string, data interface{}) error {
 	err := m.cache.Get(ctx, key, &data)
 
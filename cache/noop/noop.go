@@ -15,6 +15,10 @@ func (n *NoopCache) Set(ctx context.Context, key string, data interface{}, ttl t
 	return nil
 }
 
+func (n *NoopCache) SetNX(ctx context.Context, key string, data interface{}, ttl time.Duration) (bool, error) {
+	return true, nil
+}
+
 func (n *NoopCache) Get(ctx context.Context, key string, data interface{}) error {
 	return nil
 }
@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/url"
 	"os"
@@ -19,9 +20,9 @@ import (
 	"github.com/google/uuid"
 	"github.com/newrelic/go-agent/v3/newrelic"
 	"go.mongodb.org/mongo-driver/bson/primitive"
-	"go.mongodb.org/mongo-driver/mongo"
 
 	"github.com/frain-dev/convoy/logger"
+	"github.com/frain-dev/convoy/queue/memqueue"
 	redisqueue "github.com/frain-dev/convoy/queue/redis"
 	"github.com/frain-dev/convoy/tracer"
 	prefixed "github.com/x-cray/logrus-prefixed-formatter"
@@ -31,11 +32,13 @@ import (
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/datastore/bolt"
 	"github.com/frain-dev/convoy/limiter"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/spf13/cobra"
 
 	cm "github.com/frain-dev/convoy/datastore/mongo"
+	"github.com/frain-dev/convoy/datastore/postgres"
 )
 
 func main() {
@@ -55,9 +58,8 @@ func main() {
 	}
 
 	app := &app{}
-	db := &cm.Client{}
 
-	cli := NewCli(app, db)
+	cli := NewCli(app)
 	if err := cli.Execute(); err != nil {
 		log.Fatal(err)
 	}
@@ -66,7 +68,7 @@ func main() {
 func ensureDefaultUser(ctx context.Context, a *app) error {
 	pageable := datastore.Pageable{}
 
-	userRepo := cm.NewUserRepo(a.store)
+	userRepo := a.db.UserRepo()
 	users, _, err := userRepo.LoadUsersPaged(ctx, pageable)
 	if err != nil {
 		return fmt.Errorf("failed to load users - %w", err)
@@ -105,6 +107,7 @@ func ensureDefaultUser(ctx context.Context, a *app) error {
 }
 
 type app struct {
+	db       datastore.Database
 	store    datastore.Store
 	queue    queue.Queuer
 	logger   logger.Logger
@@ -114,7 +117,7 @@ type app struct {
 	searcher searcher.Searcher
 }
 
-func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) error {
+func preRun(app *app) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
 		cfgPath, err := cmd.Flags().GetString("config")
 		if err != nil {
@@ -126,11 +129,6 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 			return err
 		}
 
-		cfg, err := config.Get()
-		if err != nil {
-			return err
-		}
-
 		// Override with CLI Flags
 		cliConfig, err := buildCliConfiguration(cmd)
 		if err != nil {
@@ -141,6 +139,11 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 			return err
 		}
 
+		cfg, err := config.Get()
+		if err != nil {
+			return err
+		}
+
 		nwCfg := cfg.Tracer.NewRelic
 		nRApp, err := newrelic.NewApplication(
 			newrelic.ConfigAppName(nwCfg.AppName),
@@ -154,12 +157,17 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 
 		apm.SetApplication(nRApp)
 
-		database, err := cm.New(cfg)
+		db, err := newDatabase(cfg)
 		if err != nil {
 			return err
 		}
 
-		*db = *database
+		app.db = db
+
+		// the store is only available when running on mongodb
+		if mdb, ok := db.(*cm.Client); ok {
+			app.store = mdb.Store()
+		}
 
 		// Check Pending Migrations
 		if len(cmd.Aliases) > 0 {
@@ -178,18 +186,21 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 		var li limiter.RateLimiter
 		var q queue.Queuer
 
-		if cfg.Queue.Type == config.RedisQueueProvider {
+		queueNames := map[string]int{
+			string(convoy.PriorityQueue):    5,
+			string(convoy.EventQueue):       2,
+			string(convoy.CreateEventQueue): 2,
+			string(convoy.ScheduleQueue):    1,
+			string(convoy.DefaultQueue):     1,
+			string(convoy.BroadcastQueue):   1,
+		}
+
+		switch cfg.Queue.Type {
+		case config.RedisQueueProvider:
 			rdb, err := rdb.NewClient(cfg.Queue.Redis.Dsn)
 			if err != nil {
 				return err
 			}
-			queueNames := map[string]int{
-				string(convoy.PriorityQueue):    5,
-				string(convoy.EventQueue):       2,
-				string(convoy.CreateEventQueue): 2,
-				string(convoy.ScheduleQueue):    1,
-				string(convoy.DefaultQueue):     1,
-			}
 			opts := queue.QueueOptions{
 				Names:             queueNames,
 				RedisClient:       rdb,
@@ -198,6 +209,12 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 				PrometheusAddress: cfg.Prometheus.Dsn,
 			}
 			q = redisqueue.NewQueue(opts)
+		case config.InMemoryQueueProvider:
+			opts := queue.QueueOptions{
+				Names: queueNames,
+				Type:  string(config.InMemoryQueueProvider),
+			}
+			q = memqueue.NewQueue(opts)
 		}
 
 		lo, err := logger.NewLogger(cfg.Logger)
@@ -227,9 +244,6 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 			return err
 		}
 
-		s := datastore.New(db.Database())
-
-		app.store = s
 		app.queue = q
 		app.logger = lo
 		app.tracer = tr
@@ -241,9 +255,9 @@ func preRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) err
 	}
 }
 
-func postRun(app *app, db *cm.Client) func(cmd *cobra.Command, args []string) error {
+func postRun(app *app) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		err := db.Disconnect(context.Background())
+		err := app.db.Disconnect(context.Background())
 		if err == nil {
 			os.Exit(0)
 		}
@@ -258,8 +272,8 @@ func parsePersistentArgs(app *app, cmd *cobra.Command) {
 	var configFile string
 
 	cmd.PersistentFlags().StringVar(&configFile, "config", "./convoy.json", "Configuration file for convoy")
-	cmd.PersistentFlags().StringVar(&queue, "queue", "", "Queue provider (\"redis\")")
-	cmd.PersistentFlags().StringVar(&dbDsn, "db", "", "Database dsn or path to in-memory file")
+	cmd.PersistentFlags().StringVar(&queue, "queue", "", "Queue provider (\"redis\" or \"in-memory\")")
+	cmd.PersistentFlags().StringVar(&dbDsn, "db", "", "Database dsn, or bolt:// followed by the path to an in-memory file")
 	cmd.PersistentFlags().StringVar(&redisDsn, "redis", "", "Redis dsn")
 
 	cmd.AddCommand(addVersionCommand())
@@ -280,15 +294,15 @@ type ConvoyCli struct {
 	cmd *cobra.Command
 }
 
-func NewCli(app *app, db *cm.Client) ConvoyCli {
+func NewCli(app *app) ConvoyCli {
 	cmd := &cobra.Command{
 		Use:     "Convoy",
 		Version: convoy.GetVersion(),
 		Short:   "Fast & reliable webhooks service",
 	}
 
-	cmd.PersistentPreRunE = preRun(app, db)
-	cmd.PersistentPostRunE = postRun(app, db)
+	cmd.PersistentPreRunE = preRun(app)
+	cmd.PersistentPostRunE = postRun(app)
 	parsePersistentArgs(app, cmd)
 
 	return ConvoyCli{cmd: cmd}
@@ -312,8 +326,13 @@ func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 	}
 
 	if !util.IsStringEmpty(dbDsn) {
+		dbType, err := databaseProviderFromDsn(dbDsn)
+		if err != nil {
+			return nil, err
+		}
+
 		c.Database = config.DatabaseConfiguration{
-			Type: config.MongodbDatabaseProvider,
+			Type: dbType,
 			Dsn:  dbDsn,
 		}
 	}
@@ -340,8 +359,57 @@ func buildCliConfiguration(cmd *cobra.Command) (*config.Configuration, error) {
 	return c, nil
 }
 
-func checkPendingMigrations(dbDsn string, db *cm.Client) error {
-	c := db.Client().(*mongo.Database).Client()
+// databaseProviderFromDsn returns the provider of the dsn's scheme, a dsn
+// without a known scheme is rejected rather than taken as a file path.
+func databaseProviderFromDsn(dsn string) (config.DatabaseProvider, error) {
+	switch {
+	case strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://"):
+		return config.PostgresDatabaseProvider, nil
+	case strings.HasPrefix(dsn, "mongodb://") || strings.HasPrefix(dsn, "mongodb+srv://"):
+		return config.MongodbDatabaseProvider, nil
+	case strings.HasPrefix(dsn, bolt.Scheme):
+		return config.InMemoryDatabaseProvider, nil
+	default:
+		return "", errors.New("unsupported database dsn, it must start with postgres://, mongodb:// or bolt://")
+	}
+}
+
+func newDatabase(cfg config.Configuration) (datastore.Database, error) {
+	switch cfg.Database.Type {
+	case config.PostgresDatabaseProvider:
+		return postgres.New(cfg)
+	case config.InMemoryDatabaseProvider:
+		return bolt.New(cfg)
+	default:
+		return cm.New(cfg)
+	}
+}
+
+func checkPendingMigrations(dbDsn string, db datastore.Database) error {
+	// the embedded store has no schema to migrate
+	if _, ok := db.(*bolt.Client); ok {
+		return nil
+	}
+
+	if pdb, ok := db.(*postgres.Client); ok {
+		m, err := postgres.NewMigrator(pdb)
+		if err != nil {
+			return err
+		}
+
+		pm, err := m.HasPendingMigrations(context.Background())
+		if err != nil {
+			return err
+		}
+
+		if pm {
+			return migrate.ErrPendingMigrationsFound
+		}
+
+		return nil
+	}
+
+	c := db.(*cm.Client).Database().Client()
 	u, err := url.Parse(dbDsn)
 	if err != nil {
 		return err
@@ -7,6 +7,7 @@ import (
 
 	"github.com/frain-dev/convoy/config"
 	cm "github.com/frain-dev/convoy/datastore/mongo"
+	"github.com/frain-dev/convoy/datastore/postgres"
 	"github.com/frain-dev/convoy/internal/pkg/migrate"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -36,6 +37,24 @@ func addUpCommand() *cobra.Command {
 				log.WithError(err).Fatalf("Error fetching the config.")
 			}
 
+			if cfg.Database.Type == config.InMemoryDatabaseProvider {
+				log.Info("The in-memory database has no migrations to run")
+				return
+			}
+
+			if cfg.Database.Type == config.PostgresDatabaseProvider {
+				m, err := newPostgresMigrator(cfg)
+				if err != nil {
+					log.WithError(err).Fatalf("Error instantiating a database client")
+				}
+
+				err = m.Migrate(context.Background())
+				if err != nil {
+					log.WithError(err).Fatalf("Error running migrations")
+				}
+				return
+			}
+
 			db, err := cm.New(cfg)
 			if err != nil {
 				log.WithError(err).Fatalf("Error instantiating a database client")
@@ -78,6 +97,24 @@ func addDownCommand() *cobra.Command {
 				log.WithError(err).Fatalf("Error fetching the config.")
 			}
 
+			if cfg.Database.Type == config.InMemoryDatabaseProvider {
+				log.Info("The in-memory database has no migrations to run")
+				return
+			}
+
+			if cfg.Database.Type == config.PostgresDatabaseProvider {
+				m, err := newPostgresMigrator(cfg)
+				if err != nil {
+					log.WithError(err).Fatalf("Error instantiating a database client")
+				}
+
+				err = m.RollbackTo(context.Background(), migrationID)
+				if err != nil {
+					log.WithError(err).Fatalf("Error rolling back migrations")
+				}
+				return
+			}
+
 			db, err := cm.New(cfg)
 			if err != nil {
 				log.WithError(err).Fatalf("Error instantiating a database client")
@@ -108,3 +145,12 @@ func addDownCommand() *cobra.Command {
 
 	return cmd
 }
+
+func newPostgresMigrator(cfg config.Configuration) (*postgres.Migrator, error) {
+	db, err := postgres.New(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return postgres.NewMigrator(db)
+}
@@ -1,8 +1,12 @@
 package main
 
 import (
-	"github.com/frain-dev/convoy/config"
+	"time"
+
+	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/queue/memqueue"
+	"github.com/frain-dev/convoy/worker"
 	"github.com/frain-dev/convoy/worker/task"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -16,16 +20,40 @@ func addRetryCommand(a *app) *cobra.Command {
 		Use:   "retry",
 		Short: "retry event deliveries with a particular status in a timeframe",
 		Run: func(cmd *cobra.Command, args []string) {
-			cfg, err := config.Get()
-			if err != nil {
-				log.Fatalf("Error getting config: %v", err)
+			statuses := []datastore.EventDeliveryStatus{datastore.EventDeliveryStatus(status)}
+			eventDeliveryRepo := a.db.EventDeliveryRepo()
+			groupRepo := a.db.GroupRepo()
+
+			mq, ok := a.queue.(*memqueue.MemQueue)
+			if !ok {
+				task.RetryEventDeliveries(statuses, timeInterval, eventDeliveryRepo, groupRepo, a.queue)
+				return
 			}
-			if cfg.Queue.Type != config.RedisQueueProvider {
-				log.WithError(err).Fatalf("Queue type error: Command is available for redis queue only.")
+
+			// jobs on the in-memory queue can't be picked up by another
+			// process, so the requeued deliveries are sent from here
+			consumer, err := worker.NewConsumer(a.queue)
+			if err != nil {
+				log.WithError(err).Fatal("failed to create worker")
 			}
 
-			statuses := []datastore.EventDeliveryStatus{datastore.EventDeliveryStatus(status)}
-			task.RetryEventDeliveries(statuses, timeInterval, a.store, a.queue)
+			consumer.RegisterHandlers(convoy.EventProcessor, task.ProcessEventDelivery(
+				a.db.AppRepo(),
+				eventDeliveryRepo,
+				groupRepo,
+				a.limiter,
+				a.db.SubRepo(),
+				a.cache,
+				a.queue))
+			consumer.RegisterHandlers(convoy.DeadLetterProcessor, task.ProcessDeadLetters(eventDeliveryRepo, a.db.DeadLetterRepo()))
+			consumer.Start()
+
+			task.RetryEventDeliveries(statuses, timeInterval, eventDeliveryRepo, groupRepo, a.queue)
+
+			for mq.Len() > 0 {
+				time.Sleep(time.Second)
+			}
+			consumer.Stop()
 		},
 	}
 
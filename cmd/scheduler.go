@@ -6,10 +6,15 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/analytics"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/internal/pkg/pubsub"
+	convoyNet "github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/queue/memqueue"
 	redisqueue "github.com/frain-dev/convoy/queue/redis"
 	"github.com/frain-dev/convoy/worker"
+	"github.com/frain-dev/convoy/worker/task"
 	"github.com/go-chi/chi/v5"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	log "github.com/sirupsen/logrus"
@@ -27,24 +32,70 @@ func addSchedulerCommand(a *app) *cobra.Command {
 			if err != nil {
 				log.Fatalf("Error getting config: %v", err)
 			}
-			if cfg.Queue.Type != config.RedisQueueProvider {
-				log.WithError(err).Fatalf("Queue type error: Command is available for redis queue only.")
-			}
 			ctx := context.Background()
 
 			//initialize scheduler
 			s := worker.NewScheduler(a.queue)
 
 			//register tasks
-			s.RegisterTask("30 * * * *", convoy.ScheduleQueue, convoy.MonitorTwitterSources)
-			s.RegisterTask("55 23 * * *", convoy.ScheduleQueue, convoy.DailyAnalytics)
-			s.RegisterTask("@every 24h", convoy.ScheduleQueue, convoy.RetentionPolicies)
+			registerScheduledTasks(s)
 
 			// Start scheduler
 			s.Start()
 
+			egressPolicy, err := convoyNet.NewEgressPolicy(cfg.EgressPolicy)
+			if err != nil {
+				log.WithError(err).Fatal("invalid egress policy")
+			}
+
+			// there is a single scheduler, so it tails the collections of
+			// the change stream sources and polls the rest api sources
+			go pubsub.NewChangeStreamIngest(a.db.SourceRepo(), a.queue, a.cache).Run(ctx)
+			go pubsub.NewRestApiIngest(a.db.SourceRepo(), a.queue, egressPolicy).Run(ctx)
+
+			// jobs on the in-memory queue can't be picked up by another
+			// process, so the scheduled tasks are processed here
+			if _, ok := a.queue.(*memqueue.MemQueue); ok {
+				consumer, err := worker.NewConsumer(a.queue)
+				if err != nil {
+					log.WithError(err).Fatal("failed to create worker")
+				}
+
+				configRepo := a.db.ConfigRepo()
+				eventRepo := a.db.EventRepo()
+				groupRepo := a.db.GroupRepo()
+
+				consumer.RegisterHandlers(convoy.RetentionPolicies, task.RententionPolicies(
+					cfg,
+					configRepo,
+					groupRepo,
+					eventRepo,
+					a.db.EventDeliveryRepo(),
+					a.searcher))
+
+				consumer.RegisterHandlers(convoy.PruneEndpointSecrets, task.PruneEndpointSecrets(groupRepo, a.db.AppRepo()))
+
+				consumer.RegisterHandlers(convoy.MonitorTwitterSources, task.MonitorTwitterSources(
+					a.db.SourceRepo(),
+					a.db.SubRepo(),
+					a.db.AppRepo(),
+					a.queue))
+
+				consumer.RegisterHandlers(convoy.DailyAnalytics, analytics.TrackDailyAnalytics(&analytics.Repo{
+					ConfigRepo: configRepo,
+					EventRepo:  eventRepo,
+					GroupRepo:  groupRepo,
+					OrgRepo:    a.db.OrgRepo(),
+					UserRepo:   a.db.UserRepo(),
+				}, cfg))
+
+				consumer.Start()
+			}
+
 			router := chi.NewRouter()
-			router.Handle("/queue/monitoring/*", a.queue.(*redisqueue.RedisQueue).Monitor())
+			if rq, ok := a.queue.(*redisqueue.RedisQueue); ok {
+				router.Handle("/queue/monitoring/*", rq.Monitor())
+			}
 			router.Handle("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{}))
 
 			srv := &http.Server{
@@ -64,3 +115,10 @@ func addSchedulerCommand(a *app) *cobra.Command {
 	cmd.Flags().Uint32Var(&port, "port", 5007, "port to serve Metrics")
 	return cmd
 }
+
+func registerScheduledTasks(s *worker.Scheduler) {
+	s.RegisterTask("30 * * * *", convoy.ScheduleQueue, convoy.MonitorTwitterSources)
+	s.RegisterTask("55 23 * * *", convoy.ScheduleQueue, convoy.DailyAnalytics)
+	s.RegisterTask("@every 24h", convoy.ScheduleQueue, convoy.RetentionPolicies)
+	s.RegisterTask("@every 1h", convoy.ScheduleQueue, convoy.PruneEndpointSecrets)
+}
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -8,9 +9,12 @@ import (
 	"github.com/frain-dev/convoy/analytics"
 	"github.com/frain-dev/convoy/auth/realm_chain"
 	"github.com/frain-dev/convoy/config"
-	cm "github.com/frain-dev/convoy/datastore/mongo"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/internal/pkg/pubsub"
 	"github.com/frain-dev/convoy/internal/pkg/server"
 	"github.com/frain-dev/convoy/internal/pkg/smtp"
+	convoyNet "github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/queue/memqueue"
 	route "github.com/frain-dev/convoy/server"
 	"github.com/frain-dev/convoy/util"
 	"github.com/frain-dev/convoy/worker"
@@ -145,8 +149,8 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 	start := time.Now()
 	log.Info("Starting Convoy server...")
 
-	apiKeyRepo := cm.NewApiKeyRepo(a.store)
-	userRepo := cm.NewUserRepo(a.store)
+	apiKeyRepo := a.db.APIKeyRepo()
+	userRepo := a.db.UserRepo()
 	err := realm_chain.Init(&cfg.Auth, apiKeyRepo, userRepo, a.cache)
 	if err != nil {
 		log.WithError(err).Fatal("failed to initialize realm chain")
@@ -158,8 +162,14 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 
 	srv := server.NewServer(cfg.Server.HTTP.Port)
 
+	egressPolicy, err := convoyNet.NewEgressPolicy(cfg.EgressPolicy)
+	if err != nil {
+		return err
+	}
+
 	handler := route.NewApplicationHandler(
 		route.App{
+			DB:       a.db,
 			Store:    a.store,
 			Queue:    a.queue,
 			Logger:   a.logger,
@@ -167,6 +177,8 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 			Cache:    a.cache,
 			Limiter:  a.limiter,
 			Searcher: a.searcher,
+
+			EgressPolicy: egressPolicy,
 		})
 
 	if withWorkers {
@@ -182,13 +194,13 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 			log.WithError(err).Error("failed to create worker")
 		}
 
-		appRepo := cm.NewApplicationRepo(a.store)
-		eventRepo := cm.NewEventRepository(a.store)
-		eventDeliveryRepo := cm.NewEventDeliveryRepository(a.store)
-		groupRepo := cm.NewGroupRepo(a.store)
-		subRepo := cm.NewSubscriptionRepo(a.store)
-		deviceRepo := cm.NewDeviceRepository(a.store)
-		configRepo := cm.NewConfigRepo(a.store)
+		appRepo := a.db.AppRepo()
+		eventRepo := a.db.EventRepo()
+		eventDeliveryRepo := a.db.EventDeliveryRepo()
+		groupRepo := a.db.GroupRepo()
+		subRepo := a.db.SubRepo()
+		deviceRepo := a.db.DeviceRepo()
+		configRepo := a.db.ConfigRepo()
 
 		consumer.RegisterHandlers(convoy.EventProcessor, task.ProcessEventDelivery(
 			appRepo,
@@ -196,8 +208,11 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 			groupRepo,
 			a.limiter,
 			subRepo,
+			a.cache,
 			a.queue))
 
+		consumer.RegisterHandlers(convoy.DeadLetterProcessor, task.ProcessDeadLetters(eventDeliveryRepo, a.db.DeadLetterRepo()))
+
 		consumer.RegisterHandlers(convoy.CreateEventProcessor, task.ProcessEventCreation(
 			appRepo,
 			eventRepo,
@@ -209,6 +224,16 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 			a.searcher,
 			deviceRepo))
 
+		consumer.RegisterHandlers(convoy.BroadcastProcessor, task.ProcessBroadcastEvent(
+			appRepo,
+			eventRepo,
+			groupRepo,
+			eventDeliveryRepo,
+			a.cache,
+			a.queue,
+			subRepo,
+			deviceRepo))
+
 		consumer.RegisterHandlers(convoy.RetentionPolicies, task.RententionPolicies(
 			cfg,
 			configRepo,
@@ -217,11 +242,21 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 			eventDeliveryRepo,
 			a.searcher))
 
+		consumer.RegisterHandlers(convoy.PruneEndpointSecrets, task.PruneEndpointSecrets(groupRepo, appRepo))
+
 		consumer.RegisterHandlers(convoy.MonitorTwitterSources, task.MonitorTwitterSources(
-			a.store,
+			a.db.SourceRepo(),
+			subRepo,
+			appRepo,
 			a.queue))
 
-		consumer.RegisterHandlers(convoy.DailyAnalytics, analytics.TrackDailyAnalytics(a.store, cfg))
+		consumer.RegisterHandlers(convoy.DailyAnalytics, analytics.TrackDailyAnalytics(&analytics.Repo{
+			ConfigRepo: configRepo,
+			EventRepo:  eventRepo,
+			GroupRepo:  groupRepo,
+			OrgRepo:    a.db.OrgRepo(),
+			UserRepo:   a.db.UserRepo(),
+		}, cfg))
 		consumer.RegisterHandlers(convoy.EmailProcessor, task.ProcessEmails(sc))
 		consumer.RegisterHandlers(convoy.IndexDocument, task.SearchIndex(a.searcher))
 		consumer.RegisterHandlers(convoy.NotificationProcessor, task.ProcessNotifications(sc))
@@ -229,6 +264,29 @@ func StartConvoyServer(a *app, cfg config.Configuration, withWorkers bool) error
 		//start worker
 		log.Infof("Starting Convoy workers...")
 		consumer.Start()
+
+		// the workers' metrics are served on the server's /metrics route,
+		// including the dead letter queue depth the worker command reports
+		metrics.RegisterDeadLetterMetrics(a.db.DeadLetterRepo())
+
+		// consume the broker topics of pub sub sources
+		go pubsub.NewIngest(a.db.SourceRepo(), a.queue).Run(context.Background())
+
+		// jobs on the in-memory queue only live in this process, so the
+		// periodic tasks are scheduled, the change stream and rest api
+		// sources are read here, and the deliveries that were queued before
+		// a restart are queued again
+		if _, ok := a.queue.(*memqueue.MemQueue); ok {
+			s := worker.NewScheduler(a.queue)
+			registerScheduledTasks(s)
+			s.Start()
+
+			go task.RetryEventDeliveries(nil, "", eventDeliveryRepo, groupRepo, a.queue)
+			go pubsub.NewChangeStreamIngest(a.db.SourceRepo(), a.queue, a.cache).Run(context.Background())
+			go pubsub.NewRestApiIngest(a.db.SourceRepo(), a.queue, egressPolicy).Run(context.Background())
+		}
+	} else if _, ok := a.queue.(*memqueue.MemQueue); ok {
+		log.Warn("the in-memory queue is only processed by the server's workers, jobs will not be processed")
 	}
 
 	srv.SetHandler(handler.BuildRoutes())
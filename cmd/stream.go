@@ -11,8 +11,6 @@ import (
 	convoyMiddleware "github.com/frain-dev/convoy/internal/pkg/middleware"
 	"github.com/frain-dev/convoy/internal/pkg/socket"
 
-	cm "github.com/frain-dev/convoy/datastore/mongo"
-
 	"github.com/frain-dev/convoy/auth/realm_chain"
 	"github.com/frain-dev/convoy/config"
 	log "github.com/sirupsen/logrus"
@@ -31,13 +29,13 @@ func addStreamCommand(a *app) *cobra.Command {
 				log.WithError(err).Fatal("failed to initialize realm chain")
 			}
 
-			appRepo := cm.NewApplicationRepo(a.store)
-			eventDeliveryRepo := cm.NewEventDeliveryRepository(a.store)
-			sourceRepo := cm.NewSourceRepo(a.store)
-			subRepo := cm.NewSubscriptionRepo(a.store)
-			deviceRepo := cm.NewDeviceRepository(a.store)
-			groupRepo := cm.NewGroupRepo(a.store)
-			apiKeyRepo := cm.NewApiKeyRepo(a.store)
+			appRepo := a.db.AppRepo()
+			eventDeliveryRepo := a.db.EventDeliveryRepo()
+			sourceRepo := a.db.SourceRepo()
+			subRepo := a.db.SubRepo()
+			deviceRepo := a.db.DeviceRepo()
+			groupRepo := a.db.GroupRepo()
+			apiKeyRepo := a.db.APIKeyRepo()
 
 			// enable only the native auth realm
 			authCfg := &config.AuthConfiguration{
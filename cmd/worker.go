@@ -2,15 +2,17 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"net/http"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/analytics"
 	"github.com/frain-dev/convoy/config"
-	cm "github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/internal/pkg/pubsub"
 	"github.com/frain-dev/convoy/internal/pkg/smtp"
+	"github.com/frain-dev/convoy/queue/memqueue"
 	"github.com/frain-dev/convoy/worker"
 	"github.com/frain-dev/convoy/worker/task"
 	"github.com/go-chi/chi/v5"
@@ -26,12 +28,23 @@ func addWorkerCommand(a *app) *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "worker",
 		Short: "Start worker instance",
+		Long: `Start worker instance. A separate worker needs MongoDB or PostgreSQL and
+the redis queue, with the in-memory database or queue run the server with
+--with-workers instead.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			cfg, err := config.Get()
 			if err != nil {
 				return err
 			}
 
+			if _, ok := a.queue.(*memqueue.MemQueue); ok {
+				return errors.New("jobs on the in-memory queue can only be processed by the server, start the server with --with-workers instead")
+			}
+
+			if cfg.Database.Type == config.InMemoryDatabaseProvider {
+				return errors.New("the in-memory database can only be opened by one convoy process, start the server with --with-workers instead")
+			}
+
 			sc, err := smtp.NewClient(&cfg.SMTP)
 			if err != nil {
 				log.WithError(err).Error("Failed to create smtp client")
@@ -46,13 +59,13 @@ func addWorkerCommand(a *app) *cobra.Command {
 				log.WithError(err).Error("failed to create worker")
 			}
 
-			appRepo := cm.NewApplicationRepo(a.store)
-			eventRepo := cm.NewEventRepository(a.store)
-			eventDeliveryRepo := cm.NewEventDeliveryRepository(a.store)
-			groupRepo := cm.NewGroupRepo(a.store)
-			subRepo := cm.NewSubscriptionRepo(a.store)
-			deviceRepo := cm.NewDeviceRepository(a.store)
-			configRepo := cm.NewConfigRepo(a.store)
+			appRepo := a.db.AppRepo()
+			eventRepo := a.db.EventRepo()
+			eventDeliveryRepo := a.db.EventDeliveryRepo()
+			groupRepo := a.db.GroupRepo()
+			subRepo := a.db.SubRepo()
+			deviceRepo := a.db.DeviceRepo()
+			configRepo := a.db.ConfigRepo()
 
 			consumer.RegisterHandlers(convoy.EventProcessor, task.ProcessEventDelivery(
 				appRepo,
@@ -60,8 +73,11 @@ func addWorkerCommand(a *app) *cobra.Command {
 				groupRepo,
 				a.limiter,
 				subRepo,
+				a.cache,
 				a.queue))
 
+			consumer.RegisterHandlers(convoy.DeadLetterProcessor, task.ProcessDeadLetters(eventDeliveryRepo, a.db.DeadLetterRepo()))
+
 			consumer.RegisterHandlers(convoy.CreateEventProcessor, task.ProcessEventCreation(
 				appRepo,
 				eventRepo,
@@ -73,6 +89,16 @@ func addWorkerCommand(a *app) *cobra.Command {
 				a.searcher,
 				deviceRepo))
 
+			consumer.RegisterHandlers(convoy.BroadcastProcessor, task.ProcessBroadcastEvent(
+				appRepo,
+				eventRepo,
+				groupRepo,
+				eventDeliveryRepo,
+				a.cache,
+				a.queue,
+				subRepo,
+				deviceRepo))
+
 			consumer.RegisterHandlers(convoy.RetentionPolicies, task.RententionPolicies(
 				cfg,
 				configRepo,
@@ -81,11 +107,21 @@ func addWorkerCommand(a *app) *cobra.Command {
 				eventDeliveryRepo,
 				a.searcher))
 
+			consumer.RegisterHandlers(convoy.PruneEndpointSecrets, task.PruneEndpointSecrets(groupRepo, appRepo))
+
 			consumer.RegisterHandlers(convoy.MonitorTwitterSources, task.MonitorTwitterSources(
-				a.store,
+				a.db.SourceRepo(),
+				subRepo,
+				appRepo,
 				a.queue))
 
-			consumer.RegisterHandlers(convoy.DailyAnalytics, analytics.TrackDailyAnalytics(a.store, cfg))
+			consumer.RegisterHandlers(convoy.DailyAnalytics, analytics.TrackDailyAnalytics(&analytics.Repo{
+				ConfigRepo: configRepo,
+				EventRepo:  eventRepo,
+				GroupRepo:  groupRepo,
+				OrgRepo:    a.db.OrgRepo(),
+				UserRepo:   a.db.UserRepo(),
+			}, cfg))
 			consumer.RegisterHandlers(convoy.EmailProcessor, task.ProcessEmails(sc))
 			consumer.RegisterHandlers(convoy.IndexDocument, task.SearchIndex(a.searcher))
 			consumer.RegisterHandlers(convoy.NotificationProcessor, task.ProcessNotifications(sc))
@@ -94,7 +130,11 @@ func addWorkerCommand(a *app) *cobra.Command {
 			log.Infof("Starting Convoy workers...")
 			consumer.Start()
 
+			// consume the broker topics of pub sub sources
+			go pubsub.NewIngest(a.db.SourceRepo(), a.queue).Run(ctx)
+
 			metrics.RegisterQueueMetrics(a.queue)
+			metrics.RegisterDeadLetterMetrics(a.db.DeadLetterRepo())
 
 			router := chi.NewRouter()
 			router.Handle("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{}))
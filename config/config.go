@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"reflect"
 	"strings"
@@ -159,6 +160,54 @@ type TypesenseConfiguration struct {
 	ApiKey string `json:"api_key" envconfig:"CONVOY_TYPESENSE_API_KEY"`
 }
 
+// EgressPolicyConfiguration restricts the urls deliveries can be sent to.
+// When it is enabled, deliveries can't be sent to an address in one of the
+// blocked CIDR ranges, DefaultEgressBlockedCIDRs when none are set, or with a
+// scheme or port that isn't allowed. AllowedSchemes defaults to http and
+// https and every port is allowed when AllowedPorts is empty. When
+// AllowedHosts is set, deliveries can only be sent to those hosts, a host
+// like *.example.com allows every subdomain of example.com.
+type EgressPolicyConfiguration struct {
+	Enabled        bool     `json:"enabled" envconfig:"CONVOY_EGRESS_POLICY_ENABLED"`
+	BlockedCIDRs   []string `json:"blocked_cidrs" envconfig:"CONVOY_EGRESS_BLOCKED_CIDRS"`
+	AllowedSchemes []string `json:"allowed_schemes" envconfig:"CONVOY_EGRESS_ALLOWED_SCHEMES"`
+	AllowedPorts   []int    `json:"allowed_ports" envconfig:"CONVOY_EGRESS_ALLOWED_PORTS"`
+	AllowedHosts   []string `json:"allowed_hosts" envconfig:"CONVOY_EGRESS_ALLOWED_HOSTS"`
+}
+
+// DispatcherConfiguration tunes the connection pool deliveries are sent
+// through, fields that aren't set take their default. IdleConnTimeout is in
+// seconds.
+type DispatcherConfiguration struct {
+	MaxIdleConns        int  `json:"max_idle_conns" envconfig:"CONVOY_DISPATCHER_MAX_IDLE_CONNS"`
+	MaxIdleConnsPerHost int  `json:"max_idle_conns_per_host" envconfig:"CONVOY_DISPATCHER_MAX_IDLE_CONNS_PER_HOST"`
+	MaxConnsPerHost     int  `json:"max_conns_per_host" envconfig:"CONVOY_DISPATCHER_MAX_CONNS_PER_HOST"`
+	IdleConnTimeout     int  `json:"idle_conn_timeout" envconfig:"CONVOY_DISPATCHER_IDLE_CONN_TIMEOUT"`
+	DisableHTTP2        bool `json:"disable_http2" envconfig:"CONVOY_DISPATCHER_DISABLE_HTTP2"`
+}
+
+// DefaultEgressBlockedCIDRs are the loopback, private, link local and other
+// special purpose ranges, like 169.254.169.254 where cloud providers serve
+// instance metadata.
+var DefaultEgressBlockedCIDRs = []string{
+	"0.0.0.0/8",
+	"10.0.0.0/8",
+	"100.64.0.0/10",
+	"127.0.0.0/8",
+	"169.254.0.0/16",
+	"172.16.0.0/12",
+	"192.0.0.0/24",
+	"192.168.0.0/16",
+	"198.18.0.0/15",
+	"224.0.0.0/4",
+	"240.0.0.0/4",
+	"::/128",
+	"::1/128",
+	"fc00::/7",
+	"fe80::/10",
+	"ff00::/8",
+}
+
 const (
 	envPrefix              string = "convoy"
 	DevelopmentEnvironment string = "development"
@@ -167,6 +216,7 @@ const (
 
 const (
 	RedisQueueProvider                 QueueProvider           = "redis"
+	InMemoryQueueProvider              QueueProvider           = "in-memory"
 	DefaultStrategyProvider            StrategyProvider        = "linear"
 	ExponentialBackoffStrategyProvider StrategyProvider        = "exponential"
 	DefaultSignatureHeader             SignatureHeaderProvider = "X-Convoy-Signature"
@@ -175,6 +225,7 @@ const (
 	RedisCacheProvider                 CacheProvider           = "redis"
 	RedisLimiterProvider               LimiterProvider         = "redis"
 	MongodbDatabaseProvider            DatabaseProvider        = "mongodb"
+	PostgresDatabaseProvider           DatabaseProvider        = "postgres"
 	InMemoryDatabaseProvider           DatabaseProvider        = "in-memory"
 )
 
@@ -194,21 +245,23 @@ func (s SignatureHeaderProvider) String() string {
 }
 
 type Configuration struct {
-	Auth            AuthConfiguration       `json:"auth,omitempty"`
-	Database        DatabaseConfiguration   `json:"database"`
-	Queue           QueueConfiguration      `json:"queue"`
-	Prometheus      PrometheusConfiguration `json:"prometheus"`
-	Server          ServerConfiguration     `json:"server"`
-	MaxResponseSize uint64                  `json:"max_response_size" envconfig:"CONVOY_MAX_RESPONSE_SIZE"`
-	SMTP            SMTPConfiguration       `json:"smtp"`
-	Environment     string                  `json:"env" envconfig:"CONVOY_ENV"`
-	MultipleTenants bool                    `json:"multiple_tenants"`
-	Logger          LoggerConfiguration     `json:"logger"`
-	Tracer          TracerConfiguration     `json:"tracer"`
-	Cache           CacheConfiguration      `json:"cache"`
-	Limiter         LimiterConfiguration    `json:"limiter"`
-	Host            string                  `json:"host" envconfig:"CONVOY_HOST"`
-	Search          SearchConfiguration     `json:"search"`
+	Auth            AuthConfiguration         `json:"auth,omitempty"`
+	Database        DatabaseConfiguration     `json:"database"`
+	Queue           QueueConfiguration        `json:"queue"`
+	Prometheus      PrometheusConfiguration   `json:"prometheus"`
+	Server          ServerConfiguration       `json:"server"`
+	MaxResponseSize uint64                    `json:"max_response_size" envconfig:"CONVOY_MAX_RESPONSE_SIZE"`
+	SMTP            SMTPConfiguration         `json:"smtp"`
+	Environment     string                    `json:"env" envconfig:"CONVOY_ENV"`
+	MultipleTenants bool                      `json:"multiple_tenants"`
+	Logger          LoggerConfiguration       `json:"logger"`
+	Tracer          TracerConfiguration       `json:"tracer"`
+	Cache           CacheConfiguration        `json:"cache"`
+	Limiter         LimiterConfiguration      `json:"limiter"`
+	Host            string                    `json:"host" envconfig:"CONVOY_HOST"`
+	Search          SearchConfiguration       `json:"search"`
+	EgressPolicy    EgressPolicyConfiguration `json:"egress_policy"`
+	Dispatcher      DispatcherConfiguration   `json:"dispatcher"`
 }
 
 // Get fetches the application configuration. LoadConfig must have been called
@@ -304,6 +357,7 @@ func ensureQueueConfig(queueCfg QueueConfiguration) error {
 		if queueCfg.Redis.Dsn == "" {
 			return errors.New("redis queue dsn is empty")
 		}
+	case InMemoryQueueProvider:
 
 	default:
 		return fmt.Errorf("unsupported queue type: %s", queueCfg.Type)
@@ -311,6 +365,23 @@ func ensureQueueConfig(queueCfg QueueConfiguration) error {
 	return nil
 }
 
+func ensureDatabaseConfig(dbCfg DatabaseConfiguration) error {
+	switch dbCfg.Type {
+	case MongodbDatabaseProvider, PostgresDatabaseProvider, InMemoryDatabaseProvider:
+		if dbCfg.Dsn == "" {
+			return errors.New("database dsn is empty")
+		}
+
+		if dbCfg.Type == InMemoryDatabaseProvider && !strings.HasPrefix(dbCfg.Dsn, "bolt://") {
+			return errors.New("in-memory database dsn must be a path prefixed with bolt://")
+		}
+
+	default:
+		return fmt.Errorf("unsupported database type: %s", dbCfg.Type)
+	}
+	return nil
+}
+
 func ensureMaxResponseSize(c *Configuration) {
 	bytes := c.MaxResponseSize * 1024
 
@@ -328,6 +399,10 @@ func validate(c *Configuration) error {
 
 	ensureMaxResponseSize(c)
 
+	if err := ensureDatabaseConfig(c.Database); err != nil {
+		return err
+	}
+
 	if err := ensureQueueConfig(c.Queue); err != nil {
 		return err
 	}
@@ -336,5 +411,56 @@ func validate(c *Configuration) error {
 		return err
 	}
 
+	if err := ensureEgressPolicy(&c.EgressPolicy); err != nil {
+		return err
+	}
+
+	if err := ensureDispatcherConfig(c.Dispatcher); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func ensureDispatcherConfig(d DispatcherConfiguration) error {
+	if d.MaxIdleConns < 0 || d.MaxIdleConnsPerHost < 0 || d.MaxConnsPerHost < 0 || d.IdleConnTimeout < 0 {
+		return errors.New("dispatcher connection limits and idle timeout can't be negative")
+	}
+
+	return nil
+}
+
+func ensureEgressPolicy(p *EgressPolicyConfiguration) error {
+	if !p.Enabled {
+		return nil
+	}
+
+	if len(p.BlockedCIDRs) == 0 {
+		p.BlockedCIDRs = DefaultEgressBlockedCIDRs
+	}
+
+	for _, cidr := range p.BlockedCIDRs {
+		if _, _, err := net.ParseCIDR(cidr); err != nil {
+			return fmt.Errorf("invalid egress policy blocked cidr: %v", err)
+		}
+	}
+
+	if len(p.AllowedSchemes) == 0 {
+		p.AllowedSchemes = []string{"http", "https"}
+	}
+
+	for i, scheme := range p.AllowedSchemes {
+		p.AllowedSchemes[i] = strings.ToLower(scheme)
+		if p.AllowedSchemes[i] != "http" && p.AllowedSchemes[i] != "https" {
+			return fmt.Errorf("unsupported egress policy scheme: %s", scheme)
+		}
+	}
+
+	for _, port := range p.AllowedPorts {
+		if port < 1 || port > 65535 {
+			return fmt.Errorf("invalid egress policy port: %d", port)
+		}
+	}
+
 	return nil
 }
@@ -220,6 +220,14 @@ func TestLoadConfig(t *testing.T) {
 			wantErr:    true,
 			wantErrMsg: "unsupported queue type: abc",
 		},
+		{
+			name: "should_error_for_unsupported_database_type",
+			args: args{
+				path: "./testdata/Config/unsupported-database-type.json",
+			},
+			wantErr:    true,
+			wantErrMsg: "unsupported database type: cassandra",
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -319,3 +327,42 @@ func TestOverride(t *testing.T) {
 		})
 	}
 }
+
+func TestEnsureEgressPolicy(t *testing.T) {
+	p := &EgressPolicyConfiguration{}
+	require.NoError(t, ensureEgressPolicy(p))
+	require.Equal(t, &EgressPolicyConfiguration{}, p)
+
+	p = &EgressPolicyConfiguration{Enabled: true, AllowedSchemes: []string{"HTTPS"}}
+	require.NoError(t, ensureEgressPolicy(p))
+	require.Equal(t, DefaultEgressBlockedCIDRs, p.BlockedCIDRs)
+	require.Equal(t, []string{"https"}, p.AllowedSchemes)
+
+	p = &EgressPolicyConfiguration{Enabled: true}
+	require.NoError(t, ensureEgressPolicy(p))
+	require.Equal(t, []string{"http", "https"}, p.AllowedSchemes)
+
+	err := ensureEgressPolicy(&EgressPolicyConfiguration{Enabled: true, BlockedCIDRs: []string{"10.0.0.0/33"}})
+	require.EqualError(t, err, "invalid egress policy blocked cidr: invalid CIDR address: 10.0.0.0/33")
+
+	err = ensureEgressPolicy(&EgressPolicyConfiguration{Enabled: true, AllowedSchemes: []string{"ftp"}})
+	require.EqualError(t, err, "unsupported egress policy scheme: ftp")
+
+	err = ensureEgressPolicy(&EgressPolicyConfiguration{Enabled: true, AllowedPorts: []int{0}})
+	require.EqualError(t, err, "invalid egress policy port: 0")
+}
+
+func TestEnsureDispatcherConfig(t *testing.T) {
+	require.NoError(t, ensureDispatcherConfig(DispatcherConfiguration{}))
+	require.NoError(t, ensureDispatcherConfig(DispatcherConfiguration{MaxIdleConnsPerHost: 50, IdleConnTimeout: 30}))
+
+	err := ensureDispatcherConfig(DispatcherConfiguration{MaxConnsPerHost: -1})
+	require.EqualError(t, err, "dispatcher connection limits and idle timeout can't be negative")
+}
+
+func TestEnsureDatabaseConfig(t *testing.T) {
+	require.NoError(t, ensureDatabaseConfig(DatabaseConfiguration{Type: InMemoryDatabaseProvider, Dsn: "bolt:///var/convoy/convoy.db"}))
+
+	err := ensureDatabaseConfig(DatabaseConfiguration{Type: InMemoryDatabaseProvider, Dsn: "/var/convoy/convoy.db"})
+	require.EqualError(t, err, "in-memory database dsn must be a path prefixed with bolt://")
+}
@@ -0,0 +1,88 @@
+package bolt
+
+import (
+	"context"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type apiKeyRepo struct {
+	db *bbolt.DB
+}
+
+func NewApiKeyRepo(db *bbolt.DB) datastore.APIKeyRepository {
+	return &apiKeyRepo{
+		db: db,
+	}
+}
+
+func (a *apiKeyRepo) CreateAPIKey(ctx context.Context, apiKey *datastore.APIKey) error {
+	apiKey.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(apiKey.UID) {
+		apiKey.UID = uuid.New().String()
+	}
+
+	return insert(a.db, apiKeysBucket, apiKey)
+}
+
+func (a *apiKeyRepo) UpdateAPIKey(ctx context.Context, apiKey *datastore.APIKey) error {
+	return update(a.db, apiKeysBucket, newFilter().eq("uid", apiKey.UID), apiKey, nil)
+}
+
+func (a *apiKeyRepo) FindAPIKeyByID(ctx context.Context, uid string) (*datastore.APIKey, error) {
+	return a.findAPIKey(newFilter().eq("uid", uid))
+}
+
+func (a *apiKeyRepo) FindAPIKeyByMaskID(ctx context.Context, maskID string) (*datastore.APIKey, error) {
+	return a.findAPIKey(newFilter().eq("mask_id", maskID))
+}
+
+func (a *apiKeyRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*datastore.APIKey, error) {
+	return a.findAPIKey(newFilter().eq("hash", hash))
+}
+
+func (a *apiKeyRepo) RevokeAPIKeys(ctx context.Context, uids []string) error {
+	return softDelete(a.db, apiKeysBucket, newFilter().in("uid", uids))
+}
+
+func (a *apiKeyRepo) LoadAPIKeysPaged(ctx context.Context, f *datastore.ApiKeyFilter, pageable *datastore.Pageable) ([]datastore.APIKey, datastore.PaginationData, error) {
+	filter := newFilter()
+
+	if !util.IsStringEmpty(f.GroupID) {
+		filter.eq("role.group", f.GroupID)
+	}
+
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("role.app", f.AppID)
+	}
+
+	if !util.IsStringEmpty(string(f.KeyType)) {
+		filter.eq("key_type", f.KeyType)
+	}
+
+	var apiKeys []datastore.APIKey
+	pagination, err := findPaged(a.db, apiKeysBucket, filter, *pageable, &apiKeys)
+	if err != nil {
+		return nil, datastore.PaginationData{}, err
+	}
+
+	return apiKeys, pagination, nil
+}
+
+func (a *apiKeyRepo) findAPIKey(filter *filter) (*datastore.APIKey, error) {
+	apiKey := &datastore.APIKey{}
+
+	err := findOne(a.db, apiKeysBucket, filter, apiKey)
+	if err != nil {
+		if err == errNotFound {
+			err = datastore.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
@@ -0,0 +1,129 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_UpdateApplication(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	groupRepo := NewGroupRepo(db)
+	appRepo := NewApplicationRepo(db)
+
+	newGroup := &datastore.Group{
+		Name:           "Random new group",
+		UID:            uuid.NewString(),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	require.NoError(t, groupRepo.CreateGroup(context.Background(), newGroup))
+
+	app := &datastore.Application{
+		Title:          "Next application name",
+		GroupID:        newGroup.UID,
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	require.NoError(t, appRepo.CreateApplication(context.Background(), app, app.GroupID))
+
+	newTitle := "Newer name"
+	app.Title = newTitle
+
+	require.NoError(t, appRepo.UpdateApplication(context.Background(), app, app.GroupID))
+
+	newApp, err := appRepo.FindApplicationByID(context.Background(), app.UID)
+	require.NoError(t, err)
+	require.Equal(t, newTitle, newApp.Title)
+
+	app2 := &datastore.Application{
+		Title:          newTitle,
+		GroupID:        newGroup.UID,
+		UID:            uuid.NewString(),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	err = appRepo.CreateApplication(context.Background(), app2, app2.GroupID)
+	require.Equal(t, datastore.ErrDuplicateAppName, err)
+}
+
+func Test_CreateApplicationEndpoint(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	appRepo := NewApplicationRepo(db)
+
+	app := &datastore.Application{
+		Title:          "Next application name",
+		GroupID:        uuid.NewString(),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	require.NoError(t, appRepo.CreateApplication(context.Background(), app, app.GroupID))
+
+	for i := 0; i < 2; i++ {
+		endpoint := &datastore.Endpoint{
+			UID:            uuid.NewString(),
+			TargetURL:      "https://example.com",
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, appRepo.CreateApplicationEndpoint(context.Background(), app.GroupID, app.UID, endpoint))
+
+		e, err := appRepo.FindApplicationEndpointByID(context.Background(), app.UID, endpoint.UID)
+		require.NoError(t, err)
+		require.Equal(t, endpoint.TargetURL, e.TargetURL)
+	}
+
+	dbApp, err := appRepo.FindApplicationByID(context.Background(), app.UID)
+	require.NoError(t, err)
+	require.Len(t, dbApp.Endpoints, 2)
+}
+
+func Test_LoadApplicationsPaged(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	appRepo := NewApplicationRepo(db)
+	eventRepo := NewEventRepository(db)
+	groupID := uuid.NewString()
+
+	for i := 0; i < 3; i++ {
+		app := &datastore.Application{
+			Title:          uuid.NewString(),
+			GroupID:        groupID,
+			CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, appRepo.CreateApplication(context.Background(), app, app.GroupID))
+
+		event := &datastore.Event{
+			AppID:          app.UID,
+			GroupID:        groupID,
+			CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, eventRepo.CreateEvent(context.Background(), event))
+	}
+
+	apps, pagination, err := appRepo.LoadApplicationsPaged(context.Background(), groupID, "", datastore.Pageable{Page: 1, PerPage: 2})
+	require.NoError(t, err)
+	require.Len(t, apps, 2)
+	require.Equal(t, int64(3), pagination.Total)
+	require.Equal(t, int64(2), pagination.Next)
+
+	for _, app := range apps {
+		require.Equal(t, int64(1), app.Events)
+	}
+
+	require.NoError(t, appRepo.DeleteApplication(context.Background(), &apps[0]))
+
+	_, err = appRepo.FindApplicationByID(context.Background(), apps[0].UID)
+	require.Equal(t, datastore.ErrApplicationNotFound, err)
+}
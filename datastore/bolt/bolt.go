@@ -0,0 +1,142 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"go.etcd.io/bbolt"
+)
+
+var _ datastore.Database = &Client{}
+
+// Scheme prefixes the dsn of the embedded datastore, the path to its file
+// follows it, e.g. bolt:///var/convoy/convoy.db.
+const Scheme = "bolt://"
+
+// lockTimeout is how long opening the file waits for another process to
+// release it.
+var lockTimeout = 5 * time.Second
+
+// Client is an embedded datastore backed by a single bolt file, it lets
+// convoy run without an external database. bolt holds an exclusive lock on
+// the file so only one convoy process can use it at a time, run the server
+// with its workers enabled rather than a separate worker process.
+type Client struct {
+	db *bbolt.DB
+}
+
+func New(cfg config.Configuration) (*Client, error) {
+	path, err := Path(cfg.Database.Dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := bbolt.Open(path, 0600, &bbolt.Options{Timeout: lockTimeout})
+	if err != nil {
+		if errors.Is(err, bbolt.ErrTimeout) {
+			return nil, fmt.Errorf("database file %s is in use by another process, the in-memory database can only be opened by one convoy process at a time, run the server with --with-workers instead of a separate worker", path)
+		}
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Client{db: db}, nil
+}
+
+// Path returns the path to the file of the embedded datastore's dsn.
+func Path(dsn string) (string, error) {
+	if !strings.HasPrefix(dsn, Scheme) || len(dsn) == len(Scheme) {
+		return "", fmt.Errorf("in-memory database dsn must be a path prefixed with %s, e.g. %s/var/convoy/convoy.db", Scheme, Scheme)
+	}
+
+	return strings.TrimPrefix(dsn, Scheme), nil
+}
+
+func (c *Client) Disconnect(ctx context.Context) error {
+	return c.db.Close()
+}
+
+func (c *Client) GetName() string {
+	return "bolt"
+}
+
+func (c *Client) Client() interface{} {
+	return c.db
+}
+
+func (c *Client) Database() *bbolt.DB {
+	return c.db
+}
+
+func (c *Client) APIKeyRepo() datastore.APIKeyRepository {
+	return NewApiKeyRepo(c.db)
+}
+
+func (c *Client) AppRepo() datastore.ApplicationRepository {
+	return NewApplicationRepo(c.db)
+}
+
+func (c *Client) ConfigRepo() datastore.ConfigurationRepository {
+	return NewConfigRepo(c.db)
+}
+
+func (c *Client) DeadLetterRepo() datastore.DeadLetterRepository {
+	return NewDeadLetterRepository(c.db)
+}
+
+func (c *Client) DeviceRepo() datastore.DeviceRepository {
+	return NewDeviceRepository(c.db)
+}
+
+func (c *Client) EventRepo() datastore.EventRepository {
+	return NewEventRepository(c.db)
+}
+
+func (c *Client) EventDeliveryRepo() datastore.EventDeliveryRepository {
+	return NewEventDeliveryRepository(c.db)
+}
+
+func (c *Client) GroupRepo() datastore.GroupRepository {
+	return NewGroupRepo(c.db)
+}
+
+func (c *Client) OrgRepo() datastore.OrganisationRepository {
+	return NewOrgRepo(c.db)
+}
+
+func (c *Client) OrgInviteRepo() datastore.OrganisationInviteRepository {
+	return NewOrgInviteRepo(c.db)
+}
+
+func (c *Client) OrgMemberRepo() datastore.OrganisationMemberRepository {
+	return NewOrgMemberRepo(c.db)
+}
+
+func (c *Client) SourceRepo() datastore.SourceRepository {
+	return NewSourceRepo(c.db)
+}
+
+func (c *Client) SubRepo() datastore.SubscriptionRepository {
+	return NewSubscriptionRepo(c.db)
+}
+
+func (c *Client) UserRepo() datastore.UserRepository {
+	return NewUserRepo(c.db)
+}
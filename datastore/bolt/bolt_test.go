@@ -0,0 +1,88 @@
+package bolt
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func getConfig(t *testing.T) config.Configuration {
+	return config.Configuration{
+		Database: config.DatabaseConfiguration{
+			Type: config.InMemoryDatabaseProvider,
+			Dsn:  Scheme + filepath.Join(t.TempDir(), "convoy.db"),
+		},
+	}
+}
+
+func getDB(t *testing.T) (*bbolt.DB, func()) {
+	db, err := New(getConfig(t))
+	require.NoError(t, err)
+
+	return db.Database(), func() {
+		require.NoError(t, db.Disconnect(context.Background()))
+	}
+}
+
+func Test_New(t *testing.T) {
+	cfg := getConfig(t)
+
+	db, err := New(cfg)
+	require.NoError(t, err)
+	require.Equal(t, "bolt", db.GetName())
+
+	err = db.Database().View(func(tx *bbolt.Tx) error {
+		for _, bucket := range buckets {
+			require.NotNil(t, tx.Bucket(bucket), string(bucket))
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	config := &datastore.Configuration{UID: uuid.NewString(), DocumentStatus: datastore.ActiveDocumentStatus}
+	require.NoError(t, db.ConfigRepo().CreateConfiguration(context.Background(), config))
+	require.NoError(t, db.Disconnect(context.Background()))
+
+	// documents survive reopening the file
+	db, err = New(cfg)
+	require.NoError(t, err)
+	defer db.Disconnect(context.Background())
+
+	dbConfig, err := db.ConfigRepo().LoadConfiguration(context.Background())
+	require.NoError(t, err)
+	require.Equal(t, config.UID, dbConfig.UID)
+}
+
+func Test_New_locked(t *testing.T) {
+	defer func(d time.Duration) { lockTimeout = d }(lockTimeout)
+	lockTimeout = 50 * time.Millisecond
+
+	cfg := getConfig(t)
+
+	db, err := New(cfg)
+	require.NoError(t, err)
+	defer db.Disconnect(context.Background())
+
+	// a second process can't open the file while the first holds it
+	_, err = New(cfg)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "can only be opened by one convoy process")
+}
+
+func Test_Path(t *testing.T) {
+	path, err := Path("bolt:///var/convoy/convoy.db")
+	require.NoError(t, err)
+	require.Equal(t, "/var/convoy/convoy.db", path)
+
+	for _, dsn := range []string{"/var/convoy/convoy.db", "postgres//localhost/convoy", "bolt://"} {
+		_, err = Path(dsn)
+		require.Error(t, err, dsn)
+	}
+}
@@ -0,0 +1,48 @@
+package bolt
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type configRepo struct {
+	db *bbolt.DB
+}
+
+func NewConfigRepo(db *bbolt.DB) datastore.ConfigurationRepository {
+	return &configRepo{
+		db: db,
+	}
+}
+
+func (c *configRepo) CreateConfiguration(ctx context.Context, config *datastore.Configuration) error {
+	config.ID = primitive.NewObjectID()
+	return insert(c.db, configurationsBucket, config)
+}
+
+func (c *configRepo) LoadConfiguration(ctx context.Context) (*datastore.Configuration, error) {
+	config := &datastore.Configuration{}
+
+	err := findOne(c.db, configurationsBucket, newFilter(), config)
+	if err == errNotFound {
+		return nil, datastore.ErrConfigNotFound
+	}
+
+	return config, err
+}
+
+func (c *configRepo) UpdateConfiguration(ctx context.Context, config *datastore.Configuration) error {
+	set := bson.M{
+		"is_analytics_enabled": config.IsAnalyticsEnabled,
+		"is_signup_enabled":    config.IsSignupEnabled,
+		"storage_policy":       config.StoragePolicy,
+		"updated_at":           primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(c.db, configurationsBucket, newFilter().eq("uid", config.UID), set, nil)
+}
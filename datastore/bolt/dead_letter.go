@@ -0,0 +1,130 @@
+package bolt
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type deadLetterRepo struct {
+	db *bbolt.DB
+}
+
+func NewDeadLetterRepository(db *bbolt.DB) datastore.DeadLetterRepository {
+	return &deadLetterRepo{
+		db: db,
+	}
+}
+
+func (d *deadLetterRepo) CreateDeadLetter(ctx context.Context, deadLetter *datastore.DeadLetter) error {
+	deadLetter.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(deadLetter.UID) {
+		deadLetter.UID = uuid.New().String()
+	}
+
+	return insert(d.db, deadLettersBucket, deadLetter)
+}
+
+func (d *deadLetterRepo) FindDeadLetterByID(ctx context.Context, groupID, id string) (*datastore.DeadLetter, error) {
+	deadLetter := &datastore.DeadLetter{}
+
+	err := findOne(d.db, deadLettersBucket, newFilter().eq("uid", id).eq("group_id", groupID), deadLetter)
+	if err != nil {
+		if err == errNotFound {
+			err = datastore.ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+
+	return deadLetter, nil
+}
+
+func (d *deadLetterRepo) FindDeadLettersByIDs(ctx context.Context, groupID string, ids []string) ([]datastore.DeadLetter, error) {
+	var deadLetters []datastore.DeadLetter
+
+	err := findAll(d.db, deadLettersBucket, newFilter().in("uid", ids).eq("group_id", groupID), &deadLetters)
+	if err != nil {
+		return nil, err
+	}
+
+	return deadLetters, nil
+}
+
+func (d *deadLetterRepo) LoadDeadLettersPaged(ctx context.Context, f *datastore.DeadLetterFilter, pageable datastore.Pageable) ([]datastore.DeadLetter, datastore.PaginationData, error) {
+	deadLetters := make([]datastore.DeadLetter, 0)
+	pagination, err := findPaged(d.db, deadLettersBucket, getDeadLetterFilter(f), pageable, &deadLetters)
+	if err != nil {
+		return deadLetters, datastore.PaginationData{}, err
+	}
+
+	return deadLetters, pagination, nil
+}
+
+func (d *deadLetterRepo) CountDeadLetters(ctx context.Context, f *datastore.DeadLetterFilter) (int64, error) {
+	return count(d.db, deadLettersBucket, getDeadLetterFilter(f))
+}
+
+func (d *deadLetterRepo) CountDeadLettersByGroup(ctx context.Context, status datastore.DeadLetterStatus) (map[string]int64, error) {
+	docs, err := scan(d.db, deadLettersBucket, newFilter().eq("status", status).active())
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int64{}
+	for _, doc := range docs {
+		groupID, _ := lookupString(doc, "group_id")
+		counts[groupID]++
+	}
+
+	return counts, nil
+}
+
+func (d *deadLetterRepo) UpdateStatusOfDeadLetters(ctx context.Context, ids []string, status datastore.DeadLetterStatus) error {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{"status": status, "updated_at": now}
+	if status == datastore.RedrivenDeadLetterStatus {
+		set["redriven_at"] = now
+	}
+
+	return update(d.db, deadLettersBucket, newFilter().in("uid", ids).active(), set, nil)
+}
+
+func getDeadLetterFilter(f *datastore.DeadLetterFilter) *filter {
+	filter := newFilter()
+
+	if !util.IsStringEmpty(f.GroupID) {
+		filter.eq("group_id", f.GroupID)
+	}
+
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("app_id", f.AppID)
+	}
+
+	if !util.IsStringEmpty(f.EndpointID) {
+		filter.eq("endpoint_id", f.EndpointID)
+	}
+
+	if !util.IsStringEmpty(f.SubscriptionID) {
+		filter.eq("subscription_id", f.SubscriptionID)
+	}
+
+	if !util.IsStringEmpty(f.EventDeliveryID) {
+		filter.eq("event_delivery_id", f.EventDeliveryID)
+	}
+
+	if len(f.Status) > 0 {
+		filter.in("status", f.Status)
+	}
+
+	if f.SearchParams.CreatedAtEnd > 0 {
+		filter.createdBetween(f.SearchParams)
+	}
+
+	return filter
+}
@@ -0,0 +1,74 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_DeadLetters(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	deadLetterRepo := NewDeadLetterRepository(db)
+	groupID := uuid.NewString()
+
+	create := func(groupID, appID string) *datastore.DeadLetter {
+		deadLetter := &datastore.DeadLetter{
+			GroupID:         groupID,
+			AppID:           appID,
+			EventDeliveryID: uuid.NewString(),
+			Status:          datastore.PendingDeadLetterStatus,
+			CreatedAt:       primitive.NewDateTimeFromTime(time.Now()),
+			DocumentStatus:  datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, deadLetterRepo.CreateDeadLetter(context.Background(), deadLetter))
+		return deadLetter
+	}
+
+	first := create(groupID, "app-1")
+	create(groupID, "app-1")
+	create(groupID, "app-2")
+	create(uuid.NewString(), "app-1")
+
+	deadLetter, err := deadLetterRepo.FindDeadLetterByID(context.Background(), groupID, first.UID)
+	require.NoError(t, err)
+	require.Equal(t, first.EventDeliveryID, deadLetter.EventDeliveryID)
+
+	_, err = deadLetterRepo.FindDeadLetterByID(context.Background(), uuid.NewString(), first.UID)
+	require.ErrorIs(t, err, datastore.ErrDeadLetterNotFound)
+
+	deadLetters, _, err := deadLetterRepo.LoadDeadLettersPaged(context.Background(), &datastore.DeadLetterFilter{
+		GroupID: groupID,
+		AppID:   "app-1",
+		SearchParams: datastore.SearchParams{
+			CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+			CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+		},
+	}, datastore.Pageable{Page: 1, PerPage: 10, Sort: -1})
+	require.NoError(t, err)
+	require.Len(t, deadLetters, 2)
+
+	require.NoError(t, deadLetterRepo.UpdateStatusOfDeadLetters(context.Background(), []string{first.UID}, datastore.RedrivenDeadLetterStatus))
+
+	deadLetter, err = deadLetterRepo.FindDeadLetterByID(context.Background(), groupID, first.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.RedrivenDeadLetterStatus, deadLetter.Status)
+	require.NotZero(t, deadLetter.RedrivenAt)
+
+	n, err := deadLetterRepo.CountDeadLetters(context.Background(), &datastore.DeadLetterFilter{
+		GroupID: groupID,
+		Status:  []datastore.DeadLetterStatus{datastore.PendingDeadLetterStatus},
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(2), n)
+
+	counts, err := deadLetterRepo.CountDeadLettersByGroup(context.Background(), datastore.PendingDeadLetterStatus)
+	require.NoError(t, err)
+	require.Equal(t, int64(2), counts[groupID])
+}
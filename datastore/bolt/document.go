@@ -0,0 +1,505 @@
+package bolt
+
+import (
+	"errors"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Every model is stored as its bson document in a bucket named after the
+// mongo collection, keyed by its object id. bolt has no secondary indexes so
+// queries scan the bucket and match the raw documents, which is plenty for
+// the local and test workloads this store is meant for.
+
+var (
+	apiKeysBucket             = []byte("api_keys")
+	applicationsBucket        = []byte("applications")
+	configurationsBucket      = []byte("configurations")
+	deadLettersBucket         = []byte("dead_letters")
+	devicesBucket             = []byte("devices")
+	eventsBucket              = []byte("events")
+	eventDeliveriesBucket     = []byte("event_deliveries")
+	groupsBucket              = []byte("groups")
+	organisationsBucket       = []byte("organisations")
+	organisationInvitesBucket = []byte("organisation_invites")
+	organisationMembersBucket = []byte("organisation_members")
+	sourcesBucket             = []byte("sources")
+	subscriptionsBucket       = []byte("subscriptions")
+	usersBucket               = []byte("users")
+
+	buckets = [][]byte{
+		apiKeysBucket,
+		applicationsBucket,
+		configurationsBucket,
+		deadLettersBucket,
+		devicesBucket,
+		eventsBucket,
+		eventDeliveriesBucket,
+		groupsBucket,
+		organisationsBucket,
+		organisationInvitesBucket,
+		organisationMembersBucket,
+		sourcesBucket,
+		subscriptionsBucket,
+		usersBucket,
+	}
+)
+
+var errNotFound = errors.New("document not found")
+
+// executor runs read and write transactions, it is implemented by *bbolt.DB
+// and by txExecutor so the helpers below can take part in a larger
+// transaction.
+type executor interface {
+	View(fn func(*bbolt.Tx) error) error
+	Update(fn func(*bbolt.Tx) error) error
+}
+
+type txExecutor struct {
+	tx *bbolt.Tx
+}
+
+func (t txExecutor) View(fn func(*bbolt.Tx) error) error   { return fn(t.tx) }
+func (t txExecutor) Update(fn func(*bbolt.Tx) error) error { return fn(t.tx) }
+
+func withTx(db *bbolt.DB, fn func(e executor) error) error {
+	return db.Update(func(tx *bbolt.Tx) error {
+		return fn(txExecutor{tx: tx})
+	})
+}
+
+// filter holds the conditions a document must satisfy to match a query.
+type filter struct {
+	preds    []func(bson.Raw) bool
+	isActive bool
+}
+
+func newFilter() *filter {
+	return &filter{}
+}
+
+func (f *filter) eq(key string, v interface{}) *filter {
+	want := reflect.ValueOf(v).String()
+	return f.cond(func(raw bson.Raw) bool {
+		s, ok := lookupString(raw, key)
+		return ok && s == want
+	})
+}
+
+func (f *filter) ne(key string, v interface{}) *filter {
+	want := reflect.ValueOf(v).String()
+	return f.cond(func(raw bson.Raw) bool {
+		s, ok := lookupString(raw, key)
+		return !ok || s != want
+	})
+}
+
+func (f *filter) in(key string, v interface{}) *filter {
+	set := map[string]bool{}
+	for _, s := range toStrings(v) {
+		set[s] = true
+	}
+
+	return f.cond(func(raw bson.Raw) bool {
+		s, ok := lookupString(raw, key)
+		return ok && set[s]
+	})
+}
+
+// contains matches documents whose array at key holds v.
+func (f *filter) contains(key string, v interface{}) *filter {
+	want := reflect.ValueOf(v).String()
+	return f.cond(func(raw bson.Raw) bool {
+		val, err := raw.LookupErr(strings.Split(key, ".")...)
+		if err != nil || val.Type != bsontype.Array {
+			return false
+		}
+
+		values, err := val.Array().Values()
+		if err != nil {
+			return false
+		}
+
+		for _, value := range values {
+			if s, ok := value.StringValueOK(); ok && s == want {
+				return true
+			}
+		}
+
+		return false
+	})
+}
+
+func (f *filter) cond(pred func(bson.Raw) bool) *filter {
+	f.preds = append(f.preds, pred)
+	return f
+}
+
+// active restricts f to documents that haven't been soft deleted, it is
+// safe to call more than once.
+func (f *filter) active() *filter {
+	if f.isActive {
+		return f
+	}
+
+	f.isActive = true
+	return f.eq("document_status", datastore.ActiveDocumentStatus)
+}
+
+func (f *filter) createdBetween(searchParams datastore.SearchParams) *filter {
+	start := primitive.NewDateTimeFromTime(time.Unix(searchParams.CreatedAtStart, 0))
+	end := primitive.NewDateTimeFromTime(time.Unix(searchParams.CreatedAtEnd, 0))
+
+	return f.cond(func(raw bson.Raw) bool {
+		createdAt := lookupTime(raw, "created_at")
+		return createdAt >= start && createdAt <= end
+	})
+}
+
+func (f *filter) match(raw bson.Raw) bool {
+	for _, pred := range f.preds {
+		if !pred(raw) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func toStrings(v interface{}) []string {
+	if s, ok := v.([]string); ok {
+		return s
+	}
+
+	rv := reflect.ValueOf(v)
+	s := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		s = append(s, rv.Index(i).String())
+	}
+
+	return s
+}
+
+func lookupString(raw bson.Raw, key string) (string, bool) {
+	val, err := raw.LookupErr(strings.Split(key, ".")...)
+	if err != nil || val.Type != bsontype.String {
+		return "", false
+	}
+
+	return val.StringValue(), true
+}
+
+func lookupTime(raw bson.Raw, key string) primitive.DateTime {
+	val, err := raw.LookupErr(strings.Split(key, ".")...)
+	if err != nil || val.Type != bsontype.DateTime {
+		return 0
+	}
+
+	return primitive.DateTime(val.DateTime())
+}
+
+func insert(e executor, bucket []byte, v interface{}) error {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	id, err := bson.Raw(raw).LookupErr("_id")
+	if err != nil {
+		return err
+	}
+
+	oid, ok := id.ObjectIDOK()
+	if !ok {
+		return errors.New("document has no object id")
+	}
+
+	return e.Update(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).Put([]byte(oid.Hex()), raw)
+	})
+}
+
+// scan returns copies of the documents in bucket that match f, oldest
+// first.
+func scan(e executor, bucket []byte, f *filter) ([]bson.Raw, error) {
+	var docs []bson.Raw
+
+	err := e.View(func(tx *bbolt.Tx) error {
+		return tx.Bucket(bucket).ForEach(func(_, v []byte) error {
+			raw := bson.Raw(v)
+			if f.match(raw) {
+				docs = append(docs, append(bson.Raw(nil), raw...))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(docs, func(i, j int) bool {
+		ti, tj := lookupTime(docs[i], "created_at"), lookupTime(docs[j], "created_at")
+		if ti != tj {
+			return ti < tj
+		}
+
+		ui, _ := lookupString(docs[i], "uid")
+		uj, _ := lookupString(docs[j], "uid")
+		return ui < uj
+	})
+
+	return docs, nil
+}
+
+// findOne decodes the first active document matching f into out, it returns
+// errNotFound when there is none.
+func findOne(e executor, bucket []byte, f *filter, out interface{}) error {
+	docs, err := scan(e, bucket, f.active())
+	if err != nil {
+		return err
+	}
+
+	if len(docs) == 0 {
+		return errNotFound
+	}
+
+	return bson.Unmarshal(docs[0], out)
+}
+
+// findAll decodes every active document matching f into out, which must be
+// a pointer to a slice of models or model pointers.
+func findAll(e executor, bucket []byte, f *filter, out interface{}) error {
+	docs, err := scan(e, bucket, f.active())
+	if err != nil {
+		return err
+	}
+
+	return decodeDocuments(docs, out)
+}
+
+// findPaged returns a page of active documents matching f, newest first, the
+// same way the mongo paginator orders them.
+func findPaged(e executor, bucket []byte, f *filter, pageable datastore.Pageable, out interface{}) (datastore.PaginationData, error) {
+	docs, err := scan(e, bucket, f.active())
+	if err != nil {
+		return datastore.PaginationData{}, err
+	}
+
+	reverse(docs)
+
+	err = decodeDocuments(paginate(docs, pageable), out)
+	if err != nil {
+		return datastore.PaginationData{}, err
+	}
+
+	return datastore.NewPaginationData(int64(len(docs)), pageable), nil
+}
+
+func count(e executor, bucket []byte, f *filter) (int64, error) {
+	docs, err := scan(e, bucket, f.active())
+	return int64(len(docs)), err
+}
+
+func reverse(docs []bson.Raw) {
+	for i, j := 0, len(docs)-1; i < j; i, j = i+1, j-1 {
+		docs[i], docs[j] = docs[j], docs[i]
+	}
+}
+
+func paginate(docs []bson.Raw, pageable datastore.Pageable) []bson.Raw {
+	start := pageable.Offset()
+	if start > len(docs) {
+		start = len(docs)
+	}
+
+	end := start + pageable.Limit()
+	if end > len(docs) {
+		end = len(docs)
+	}
+
+	return docs[start:end]
+}
+
+func decodeDocuments(docs []bson.Raw, out interface{}) error {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return datastore.ErrInvalidPtr
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	for _, doc := range docs {
+		elem := reflect.New(elemType)
+		if err := bson.Unmarshal(doc, elem.Interface()); err != nil {
+			return err
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	return nil
+}
+
+// update applies a mongo style update to every document matching f: set holds
+// the fields to overwrite ($set, keys may be dotted paths) and push the values
+// to append to array fields ($push).
+func update(e executor, bucket []byte, f *filter, set interface{}, push bson.M) error {
+	var setElems, pushElems []bson.RawElement
+
+	if set != nil {
+		elems, err := patchElements(set)
+		if err != nil {
+			return err
+		}
+		setElems = elems
+	}
+
+	if len(push) > 0 {
+		elems, err := patchElements(push)
+		if err != nil {
+			return err
+		}
+		pushElems = elems
+	}
+
+	return e.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucket)
+
+		updated := map[string][]byte{}
+		err := b.ForEach(func(k, v []byte) error {
+			if !f.match(v) {
+				return nil
+			}
+
+			var doc bson.D
+			if err := bson.Unmarshal(v, &doc); err != nil {
+				return err
+			}
+
+			for _, elem := range setElems {
+				doc = setPath(doc, strings.Split(elem.Key(), "."), elem.Value())
+			}
+
+			for _, elem := range pushElems {
+				doc = pushValue(doc, elem.Key(), elem.Value())
+			}
+
+			raw, err := bson.Marshal(doc)
+			if err != nil {
+				return err
+			}
+
+			updated[string(k)] = raw
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// buckets must not be modified while they are being iterated
+		for k, raw := range updated {
+			if err := b.Put([]byte(k), raw); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func softDelete(e executor, bucket []byte, f *filter) error {
+	set := bson.M{
+		"deleted_at":      primitive.NewDateTimeFromTime(time.Now()),
+		"document_status": datastore.DeletedDocumentStatus,
+	}
+
+	return update(e, bucket, f, set, nil)
+}
+
+func hardDelete(e executor, bucket []byte, f *filter) error {
+	return e.Update(func(tx *bbolt.Tx) error {
+		b := tx.Bucket(bucket)
+
+		var keys [][]byte
+		err := b.ForEach(func(k, v []byte) error {
+			if f.match(v) {
+				keys = append(keys, append([]byte(nil), k...))
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func patchElements(v interface{}) ([]bson.RawElement, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return bson.Raw(raw).Elements()
+}
+
+func setPath(doc bson.D, path []string, v interface{}) bson.D {
+	for i := range doc {
+		if doc[i].Key != path[0] {
+			continue
+		}
+
+		if len(path) == 1 {
+			doc[i].Value = v
+			return doc
+		}
+
+		sub, _ := doc[i].Value.(bson.D)
+		doc[i].Value = setPath(sub, path[1:], v)
+		return doc
+	}
+
+	if len(path) == 1 {
+		return append(doc, bson.E{Key: path[0], Value: v})
+	}
+
+	return append(doc, bson.E{Key: path[0], Value: setPath(nil, path[1:], v)})
+}
+
+func pushValue(doc bson.D, key string, v interface{}) bson.D {
+	for i := range doc {
+		if doc[i].Key != key {
+			continue
+		}
+
+		arr, _ := doc[i].Value.(bson.A)
+		doc[i].Value = append(arr, v)
+		return doc
+	}
+
+	return append(doc, bson.E{Key: key, Value: bson.A{v}})
+}
@@ -0,0 +1,73 @@
+package bolt
+
+import (
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_Update(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	sub := &datastore.Subscription{
+		ID:             primitive.NewObjectID(),
+		UID:            "sub-1",
+		GroupID:        "group-1",
+		FilterConfig:   &datastore.FilterConfiguration{EventTypes: []string{"user.created"}},
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.NoError(t, insert(db, subscriptionsBucket, sub))
+
+	set := bson.M{"name": "renamed", "filter_config.event_types": []string{"*"}}
+	require.NoError(t, update(db, subscriptionsBucket, newFilter().eq("uid", sub.UID), set, nil))
+
+	dbSub := &datastore.Subscription{}
+	require.NoError(t, findOne(db, subscriptionsBucket, newFilter().contains("filter_config.event_types", "*"), dbSub))
+	require.Equal(t, "renamed", dbSub.Name)
+	require.Equal(t, "group-1", dbSub.GroupID)
+
+	require.NoError(t, softDelete(db, subscriptionsBucket, newFilter().eq("uid", sub.UID)))
+	require.Equal(t, errNotFound, findOne(db, subscriptionsBucket, newFilter().eq("uid", sub.UID), dbSub))
+}
+
+func Test_UpdatePush(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	delivery := &datastore.EventDelivery{
+		ID:             primitive.NewObjectID(),
+		UID:            "delivery-1",
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.NoError(t, insert(db, eventDeliveriesBucket, delivery))
+
+	for _, uid := range []string{"attempt-1", "attempt-2"} {
+		push := bson.M{"attempts": datastore.DeliveryAttempt{UID: uid}}
+		require.NoError(t, update(db, eventDeliveriesBucket, newFilter().eq("uid", delivery.UID), nil, push))
+	}
+
+	dbDelivery := &datastore.EventDelivery{}
+	require.NoError(t, findOne(db, eventDeliveriesBucket, newFilter().eq("uid", delivery.UID), dbDelivery))
+	require.Len(t, dbDelivery.DeliveryAttempts, 2)
+	require.Equal(t, "attempt-2", dbDelivery.DeliveryAttempts[1].UID)
+}
+
+func Test_HardDelete(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	for _, groupID := range []string{"group-1", "group-1", "group-2"} {
+		event := &datastore.Event{ID: primitive.NewObjectID(), GroupID: groupID, DocumentStatus: datastore.ActiveDocumentStatus}
+		require.NoError(t, insert(db, eventsBucket, event))
+	}
+
+	require.NoError(t, hardDelete(db, eventsBucket, newFilter().eq("group_id", "group-1")))
+
+	n, err := count(db, eventsBucket, newFilter())
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
@@ -0,0 +1,176 @@
+package bolt
+
+import (
+	"context"
+	"errors"
+	"sort"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventRepo struct {
+	db *bbolt.DB
+}
+
+func NewEventRepository(db *bbolt.DB) datastore.EventRepository {
+	return &eventRepo{
+		db: db,
+	}
+}
+
+var (
+	dailyIntervalFormat   = "2006-01-02" // 1 day
+	weeklyIntervalFormat  = "2006-01"    // 1 week
+	monthlyIntervalFormat = "2006-01"    // 1 month
+	yearlyIntervalFormat  = "2006"       // 1 year
+)
+
+func (e *eventRepo) CreateEvent(ctx context.Context, event *datastore.Event) error {
+	event.ID = primitive.NewObjectID()
+
+	if util.IsStringEmpty(event.ProviderID) {
+		event.ProviderID = event.AppID
+	}
+	if util.IsStringEmpty(event.UID) {
+		event.UID = uuid.New().String()
+	}
+
+	return insert(e.db, eventsBucket, event)
+}
+
+func (e *eventRepo) CountGroupMessages(ctx context.Context, groupID string) (int64, error) {
+	return count(e.db, eventsBucket, newFilter().eq("group_id", groupID))
+}
+
+func (e *eventRepo) DeleteGroupEvents(ctx context.Context, f *datastore.EventFilter, hardDeleteEvents bool) error {
+	filter := newFilter().eq("group_id", f.GroupID).active().createdBetween(datastore.SearchParams{
+		CreatedAtStart: f.CreatedAtStart,
+		CreatedAtEnd:   f.CreatedAtEnd,
+	})
+
+	if hardDeleteEvents {
+		return hardDelete(e.db, eventsBucket, filter)
+	}
+
+	return softDelete(e.db, eventsBucket, filter)
+}
+
+func (e *eventRepo) LoadEventIntervals(ctx context.Context, groupID string, searchParams datastore.SearchParams, period datastore.Period, interval int) ([]datastore.EventInterval, error) {
+	if searchParams.CreatedAtEnd == 0 || searchParams.CreatedAtEnd < searchParams.CreatedAtStart {
+		searchParams.CreatedAtEnd = searchParams.CreatedAtStart
+	}
+
+	var format string
+	switch period {
+	case datastore.Daily:
+		format = dailyIntervalFormat
+	case datastore.Weekly:
+		format = weeklyIntervalFormat
+	case datastore.Monthly:
+		format = monthlyIntervalFormat
+	case datastore.Yearly:
+		format = yearlyIntervalFormat
+	default:
+		return nil, errors.New("specified data cannot be generated for period")
+	}
+
+	if interval < 1 {
+		interval = 1
+	}
+
+	filter := newFilter().eq("group_id", groupID).createdBetween(searchParams)
+
+	var events []datastore.Event
+	err := findAll(e.db, eventsBucket, filter, &events)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := map[datastore.EventIntervalData]uint64{}
+	for _, event := range events {
+		t := event.CreatedAt.Time().UTC()
+
+		var component int
+		switch period {
+		case datastore.Daily:
+			component = t.YearDay()
+		case datastore.Weekly:
+			_, component = t.ISOWeek()
+		case datastore.Monthly:
+			component = int(t.Month())
+		case datastore.Yearly:
+			component = t.Year()
+		}
+
+		key := datastore.EventIntervalData{
+			Interval: int64(component / interval),
+			Time:     t.Format(format),
+		}
+		counts[key]++
+	}
+
+	eventsIntervals := make([]datastore.EventInterval, 0, len(counts))
+	for data, n := range counts {
+		eventsIntervals = append(eventsIntervals, datastore.EventInterval{Data: data, Count: n})
+	}
+
+	sort.Slice(eventsIntervals, func(i, j int) bool {
+		a, b := eventsIntervals[i].Data, eventsIntervals[j].Data
+		if a.Time != b.Time {
+			return a.Time < b.Time
+		}
+		return a.Interval < b.Interval
+	})
+
+	return eventsIntervals, nil
+}
+
+func (e *eventRepo) FindEventByID(ctx context.Context, id string) (*datastore.Event, error) {
+	event := &datastore.Event{}
+
+	err := findOne(e.db, eventsBucket, newFilter().eq("uid", id), event)
+	if err == errNotFound {
+		err = datastore.ErrEventNotFound
+	}
+
+	return event, err
+}
+
+func (e *eventRepo) FindEventsByIDs(ctx context.Context, ids []string) ([]datastore.Event, error) {
+	var events []datastore.Event
+
+	err := findAll(e.db, eventsBucket, newFilter().in("uid", ids), &events)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (e *eventRepo) LoadEventsPaged(ctx context.Context, f *datastore.Filter) ([]datastore.Event, datastore.PaginationData, error) {
+	filter := newFilter().createdBetween(f.SearchParams)
+
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("app_id", f.AppID)
+	}
+
+	if f.Group != nil && !util.IsStringEmpty(f.Group.UID) {
+		filter.eq("group_id", f.Group.UID)
+	}
+
+	if !util.IsStringEmpty(f.SourceID) {
+		filter.eq("source_id", f.SourceID)
+	}
+
+	events := make([]datastore.Event, 0)
+	pagination, err := findPaged(e.db, eventsBucket, filter, f.Pageable, &events)
+	if err != nil {
+		return events, datastore.PaginationData{}, err
+	}
+
+	return events, pagination, nil
+}
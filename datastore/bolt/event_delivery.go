@@ -0,0 +1,284 @@
+package bolt
+
+import (
+	"context"
+	"go.etcd.io/bbolt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventDeliveryRepo struct {
+	db *bbolt.DB
+}
+
+func NewEventDeliveryRepository(db *bbolt.DB) datastore.EventDeliveryRepository {
+	return &eventDeliveryRepo{
+		db: db,
+	}
+}
+
+func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery) error {
+	eventDelivery.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(eventDelivery.UID) {
+		eventDelivery.UID = uuid.New().String()
+	}
+
+	return insert(e.db, eventDeliveriesBucket, eventDelivery)
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveryByID(ctx context.Context, uid string) (*datastore.EventDelivery, error) {
+	eventDelivery := &datastore.EventDelivery{}
+
+	err := findOne(e.db, eventDeliveriesBucket, newFilter().eq("uid", uid), eventDelivery)
+	if err != nil {
+		if err == errNotFound {
+			err = datastore.ErrEventDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return eventDelivery, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveriesByIDs(ctx context.Context, ids []string) ([]datastore.EventDelivery, error) {
+	var deliveries []datastore.EventDelivery
+
+	err := findAll(e.db, eventDeliveriesBucket, newFilter().in("uid", ids), &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveriesByEventID(ctx context.Context, eventID string) ([]datastore.EventDelivery, error) {
+	var deliveries []datastore.EventDelivery
+
+	err := findAll(e.db, eventDeliveriesBucket, newFilter().eq("event_id", eventID), &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) CountDeliveriesByStatus(ctx context.Context, status datastore.EventDeliveryStatus, searchParams datastore.SearchParams) (int64, error) {
+	filter := newFilter().eq("status", status).createdBetween(searchParams)
+	return count(e.db, eventDeliveriesBucket, filter)
+}
+
+func (e *eventDeliveryRepo) UpdateStatusOfEventDelivery(ctx context.Context, delivery datastore.EventDelivery, status datastore.EventDeliveryStatus) error {
+	set := bson.M{
+		"status":     status,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(e.db, eventDeliveriesBucket, newFilter().eq("uid", delivery.UID), set, nil)
+}
+
+func (e *eventDeliveryRepo) UpdateExpiryOfEventDelivery(ctx context.Context, delivery datastore.EventDelivery, expiresAt primitive.DateTime) error {
+	set := bson.M{
+		"expires_at": expiresAt,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(e.db, eventDeliveriesBucket, newFilter().eq("uid", delivery.UID), set, nil)
+}
+
+func (e *eventDeliveryRepo) UpdateStatusOfEventDeliveries(ctx context.Context, ids []string, status datastore.EventDeliveryStatus) error {
+	set := bson.M{
+		"status":     status,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(e.db, eventDeliveriesBucket, newFilter().in("uid", ids).active(), set, nil)
+}
+
+func (e *eventDeliveryRepo) UpdateEventDeliveryWithAttempt(ctx context.Context, delivery datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+	set := bson.M{
+		"status":      delivery.Status,
+		"description": delivery.Description,
+		"metadata":    delivery.Metadata,
+		"updated_at":  primitive.NewDateTimeFromTime(time.Now()),
+	}
+	push := bson.M{"attempts": attempt}
+
+	return update(e.db, eventDeliveriesBucket, newFilter().eq("uid", delivery.UID), set, push)
+}
+
+func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, groupID, appID, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams, pageable datastore.Pageable) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+	filter := getFilter(groupID, appID, eventID, status, searchParams)
+
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+	pagination, err := findPaged(e.db, eventDeliveriesBucket, filter, pageable, &eventDeliveries)
+	if err != nil {
+		return eventDeliveries, datastore.PaginationData{}, err
+	}
+
+	return eventDeliveries, pagination, nil
+}
+
+// LoadUpcomingEventDeliveriesPaged returns the scheduled deliveries that are
+// yet to be sent.
+func (e *eventDeliveryRepo) LoadUpcomingEventDeliveriesPaged(ctx context.Context, groupID, appID string, pageable datastore.Pageable) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	filter := newFilter().
+		eq("group_id", groupID).
+		eq("status", datastore.ScheduledEventStatus).
+		cond(func(raw bson.Raw) bool {
+			return lookupTime(raw, "metadata.next_send_time") > now
+		})
+
+	if !util.IsStringEmpty(appID) {
+		filter.eq("app_id", appID)
+	}
+
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+	pagination, err := findPaged(e.db, eventDeliveriesBucket, filter, pageable, &eventDeliveries)
+	if err != nil {
+		return eventDeliveries, datastore.PaginationData{}, err
+	}
+
+	return eventDeliveries, pagination, nil
+}
+
+func (e *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, groupID, appID, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams) (int64, error) {
+	filter := getFilter(groupID, appID, eventID, status, searchParams)
+	return count(e.db, eventDeliveriesBucket, filter)
+}
+
+func (e *eventDeliveryRepo) DeleteGroupEventDeliveries(ctx context.Context, f *datastore.EventDeliveryFilter, hardDeleteDeliveries bool) error {
+	filter := newFilter().eq("group_id", f.GroupID).active().createdBetween(datastore.SearchParams{
+		CreatedAtStart: f.CreatedAtStart,
+		CreatedAtEnd:   f.CreatedAtEnd,
+	})
+
+	if hardDeleteDeliveries {
+		return hardDelete(e.db, eventDeliveriesBucket, filter)
+	}
+
+	return softDelete(e.db, eventDeliveriesBucket, filter)
+}
+
+func (e *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, appId, deviceId string, searchParams datastore.SearchParams) ([]datastore.EventDelivery, error) {
+	filter := newFilter().
+		eq("app_id", appId).
+		eq("device_id", deviceId).
+		eq("status", datastore.DiscardedEventStatus).
+		createdBetween(searchParams)
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	err := findAll(e.db, eventDeliveriesBucket, filter, &deliveries)
+	if err != nil {
+		return deliveries, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) FindFirstPendingEventDelivery(ctx context.Context, subscriptionID, orderingKey string) (*datastore.EventDelivery, error) {
+	filter := newFilter().
+		eq("subscription_id", subscriptionID).
+		in("status", datastore.PendingEventStatuses).
+		cond(func(raw bson.Raw) bool {
+			key, _ := lookupString(raw, "ordering_key")
+			return key == orderingKey
+		})
+
+	delivery := &datastore.EventDelivery{}
+	err := findOne(e.db, eventDeliveriesBucket, filter, delivery)
+	if err != nil {
+		if err == errNotFound {
+			err = datastore.ErrEventDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// FindDueEventDeliveriesBySubscriptionID returns the subscription's deliveries
+// with one of the statuses that are due to be sent, oldest first.
+func (e *eventDeliveryRepo) FindDueEventDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, status []datastore.EventDeliveryStatus, limit int) ([]datastore.EventDelivery, error) {
+	now := primitive.NewDateTimeFromTime(time.Now())
+	filter := newFilter().
+		eq("subscription_id", subscriptionID).
+		in("status", status).
+		cond(func(raw bson.Raw) bool {
+			return lookupTime(raw, "metadata.next_send_time") <= now
+		})
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	err := findAll(e.db, eventDeliveriesBucket, filter, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit > 0 && len(deliveries) > limit {
+		deliveries = deliveries[:limit]
+	}
+
+	return deliveries, nil
+}
+
+// ClaimEventDeliveries sets the deliveries that still have one of the statuses
+// to processing and returns their ids, a delivery is only claimed once when
+// it is claimed concurrently.
+func (e *eventDeliveryRepo) ClaimEventDeliveries(ctx context.Context, ids []string, status []datastore.EventDeliveryStatus) ([]string, error) {
+	var claimed []string
+	err := withTx(e.db, func(ex executor) error {
+		deliveries := make([]datastore.EventDelivery, 0)
+		err := findAll(ex, eventDeliveriesBucket, newFilter().in("uid", ids).in("status", status).active(), &deliveries)
+		if err != nil {
+			return err
+		}
+
+		claimed = make([]string, 0, len(deliveries))
+		for _, d := range deliveries {
+			claimed = append(claimed, d.UID)
+		}
+
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		set := bson.M{
+			"status":     datastore.ProcessingEventStatus,
+			"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+		}
+
+		return update(ex, eventDeliveriesBucket, newFilter().in("uid", claimed), set, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func getFilter(groupID string, appID string, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams) *filter {
+	filter := newFilter().createdBetween(searchParams)
+
+	if !util.IsStringEmpty(appID) {
+		filter.eq("app_id", appID)
+	}
+
+	if !util.IsStringEmpty(groupID) {
+		filter.eq("group_id", groupID)
+	}
+
+	if !util.IsStringEmpty(eventID) {
+		filter.eq("event_id", eventID)
+	}
+
+	if len(status) > 0 {
+		filter.in("status", status)
+	}
+
+	return filter
+}
@@ -0,0 +1,216 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_UpdateEventDeliveryWithAttempt(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventDeliveryRepo := NewEventDeliveryRepository(db)
+
+	delivery := &datastore.EventDelivery{
+		EventID:        uuid.NewString(),
+		GroupID:        uuid.NewString(),
+		Status:         datastore.ScheduledEventStatus,
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.NoError(t, eventDeliveryRepo.CreateEventDelivery(context.Background(), delivery))
+
+	for i := 0; i < 2; i++ {
+		delivery.Status = datastore.RetryEventStatus
+		attempt := datastore.DeliveryAttempt{UID: uuid.NewString(), MsgID: delivery.UID}
+		require.NoError(t, eventDeliveryRepo.UpdateEventDeliveryWithAttempt(context.Background(), *delivery, attempt))
+	}
+
+	deliveries, err := eventDeliveryRepo.FindEventDeliveriesByEventID(context.Background(), delivery.EventID)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Len(t, deliveries[0].DeliveryAttempts, 2)
+	require.Equal(t, datastore.RetryEventStatus, deliveries[0].Status)
+
+	n, err := eventDeliveryRepo.CountEventDeliveries(context.Background(), delivery.GroupID, "", "", []datastore.EventDeliveryStatus{datastore.RetryEventStatus}, datastore.SearchParams{
+		CreatedAtStart: time.Now().Add(-time.Hour).Unix(),
+		CreatedAtEnd:   time.Now().Add(time.Hour).Unix(),
+	})
+	require.NoError(t, err)
+	require.Equal(t, int64(1), n)
+}
+
+func Test_FindFirstPendingEventDelivery(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventDeliveryRepo := NewEventDeliveryRepository(db)
+	subscriptionID := uuid.NewString()
+	now := time.Now()
+
+	create := func(key string, status datastore.EventDeliveryStatus, createdAt time.Time) *datastore.EventDelivery {
+		delivery := &datastore.EventDelivery{
+			SubscriptionID: subscriptionID,
+			OrderingKey:    key,
+			Status:         status,
+			CreatedAt:      primitive.NewDateTimeFromTime(createdAt),
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, eventDeliveryRepo.CreateEventDelivery(context.Background(), delivery))
+		return delivery
+	}
+
+	create("order-1", datastore.SuccessEventStatus, now.Add(-3*time.Second))
+	first := create("order-1", datastore.RetryEventStatus, now.Add(-2*time.Second))
+	create("order-1", datastore.ScheduledEventStatus, now.Add(-time.Second))
+	other := create("order-2", datastore.ScheduledEventStatus, now)
+	unkeyed := create("", datastore.ScheduledEventStatus, now)
+
+	tests := map[string]string{"order-1": first.UID, "order-2": other.UID, "": unkeyed.UID}
+	for key, uid := range tests {
+		delivery, err := eventDeliveryRepo.FindFirstPendingEventDelivery(context.Background(), subscriptionID, key)
+		require.NoError(t, err)
+		require.Equal(t, uid, delivery.UID)
+	}
+
+	_, err := eventDeliveryRepo.FindFirstPendingEventDelivery(context.Background(), subscriptionID, "order-3")
+	require.ErrorIs(t, err, datastore.ErrEventDeliveryNotFound)
+}
+
+func Test_FindDueEventDeliveriesBySubscriptionID(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventDeliveryRepo := NewEventDeliveryRepository(db)
+	subscriptionID := uuid.NewString()
+	now := time.Now()
+
+	create := func(subscriptionID string, status datastore.EventDeliveryStatus, createdAt, sendAt time.Time) *datastore.EventDelivery {
+		delivery := &datastore.EventDelivery{
+			SubscriptionID: subscriptionID,
+			Status:         status,
+			Metadata:       &datastore.Metadata{NextSendTime: primitive.NewDateTimeFromTime(sendAt)},
+			CreatedAt:      primitive.NewDateTimeFromTime(createdAt),
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, eventDeliveryRepo.CreateEventDelivery(context.Background(), delivery))
+		return delivery
+	}
+
+	create(subscriptionID, datastore.SuccessEventStatus, now.Add(-5*time.Second), now)
+	create(subscriptionID, datastore.RetryEventStatus, now.Add(-4*time.Second), now.Add(time.Minute))
+	first := create(subscriptionID, datastore.RetryEventStatus, now.Add(-3*time.Second), now)
+	second := create(subscriptionID, datastore.ScheduledEventStatus, now.Add(-2*time.Second), now)
+	create(subscriptionID, datastore.ScheduledEventStatus, now.Add(-time.Second), now)
+	create(uuid.NewString(), datastore.ScheduledEventStatus, now, now)
+
+	status := []datastore.EventDeliveryStatus{datastore.ScheduledEventStatus, datastore.RetryEventStatus}
+	deliveries, err := eventDeliveryRepo.FindDueEventDeliveriesBySubscriptionID(context.Background(), subscriptionID, status, 2)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+	require.Equal(t, first.UID, deliveries[0].UID)
+	require.Equal(t, second.UID, deliveries[1].UID)
+}
+
+func Test_ClaimEventDeliveries(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventDeliveryRepo := NewEventDeliveryRepository(db)
+
+	create := func(status datastore.EventDeliveryStatus) *datastore.EventDelivery {
+		delivery := &datastore.EventDelivery{
+			Status:         status,
+			CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, eventDeliveryRepo.CreateEventDelivery(context.Background(), delivery))
+		return delivery
+	}
+
+	scheduled := create(datastore.ScheduledEventStatus)
+	retry := create(datastore.RetryEventStatus)
+	processing := create(datastore.ProcessingEventStatus)
+
+	ids := []string{scheduled.UID, retry.UID, processing.UID}
+	status := []datastore.EventDeliveryStatus{datastore.ScheduledEventStatus, datastore.RetryEventStatus}
+
+	claimed, err := eventDeliveryRepo.ClaimEventDeliveries(context.Background(), ids, status)
+	require.NoError(t, err)
+	require.ElementsMatch(t, []string{scheduled.UID, retry.UID}, claimed)
+
+	delivery, err := eventDeliveryRepo.FindEventDeliveryByID(context.Background(), retry.UID)
+	require.NoError(t, err)
+	require.Equal(t, datastore.ProcessingEventStatus, delivery.Status)
+
+	// the deliveries are only claimed once
+	claimed, err = eventDeliveryRepo.ClaimEventDeliveries(context.Background(), ids, status)
+	require.NoError(t, err)
+	require.Empty(t, claimed)
+}
+
+func Test_LoadUpcomingEventDeliveriesPaged(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventDeliveryRepo := NewEventDeliveryRepository(db)
+	groupID := uuid.NewString()
+	now := time.Now()
+
+	create := func(groupID, appID string, status datastore.EventDeliveryStatus, sendAt time.Time) *datastore.EventDelivery {
+		delivery := &datastore.EventDelivery{
+			GroupID:        groupID,
+			AppID:          appID,
+			Status:         status,
+			Metadata:       &datastore.Metadata{NextSendTime: primitive.NewDateTimeFromTime(sendAt)},
+			CreatedAt:      primitive.NewDateTimeFromTime(now),
+			DocumentStatus: datastore.ActiveDocumentStatus,
+		}
+		require.NoError(t, eventDeliveryRepo.CreateEventDelivery(context.Background(), delivery))
+		return delivery
+	}
+
+	upcoming := create(groupID, "app-1", datastore.ScheduledEventStatus, now.Add(time.Hour))
+	create(groupID, "app-2", datastore.ScheduledEventStatus, now.Add(24*time.Hour))
+	create(groupID, "app-1", datastore.ScheduledEventStatus, now.Add(-time.Second))
+	create(groupID, "app-1", datastore.CancelledEventStatus, now.Add(time.Hour))
+	create(uuid.NewString(), "app-1", datastore.ScheduledEventStatus, now.Add(time.Hour))
+
+	pageable := datastore.Pageable{Page: 1, PerPage: 10, Sort: -1}
+
+	deliveries, _, err := eventDeliveryRepo.LoadUpcomingEventDeliveriesPaged(context.Background(), groupID, "", pageable)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 2)
+
+	deliveries, _, err = eventDeliveryRepo.LoadUpcomingEventDeliveriesPaged(context.Background(), groupID, "app-1", pageable)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	require.Equal(t, upcoming.UID, deliveries[0].UID)
+}
+
+func Test_UpdateExpiryOfEventDelivery(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	eventDeliveryRepo := NewEventDeliveryRepository(db)
+
+	delivery := &datastore.EventDelivery{
+		Status:         datastore.ExpiredEventStatus,
+		ExpiresAt:      primitive.NewDateTimeFromTime(time.Now().Add(-time.Minute)),
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.NoError(t, eventDeliveryRepo.CreateEventDelivery(context.Background(), delivery))
+
+	require.NoError(t, eventDeliveryRepo.UpdateExpiryOfEventDelivery(context.Background(), *delivery, 0))
+
+	delivery, err := eventDeliveryRepo.FindEventDeliveryByID(context.Background(), delivery.UID)
+	require.NoError(t, err)
+	require.Zero(t, delivery.ExpiresAt)
+}
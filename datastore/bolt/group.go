@@ -0,0 +1,156 @@
+package bolt
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"go.etcd.io/bbolt"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type groupRepo struct {
+	db *bbolt.DB
+}
+
+func NewGroupRepo(db *bbolt.DB) datastore.GroupRepository {
+	return &groupRepo{
+		db: db,
+	}
+}
+
+func (g *groupRepo) CreateGroup(ctx context.Context, o *datastore.Group) error {
+	o.ID = primitive.NewObjectID()
+
+	return withTx(g.db, func(e executor) error {
+		err := assertUniqueGroupName(e, o)
+		if err != nil {
+			return err
+		}
+
+		return insert(e, groupsBucket, o)
+	})
+}
+
+func (g *groupRepo) LoadGroups(ctx context.Context, f *datastore.GroupFilter) ([]*datastore.Group, error) {
+	filter := newFilter()
+	if f.OrgID != "" {
+		filter.eq("organisation_id", f.OrgID)
+	}
+
+	f = f.WithNamesTrimmed()
+	if len(f.Names) > 0 {
+		filter.in("name", f.Names)
+	}
+
+	groups := make([]*datastore.Group, 0)
+	err := findAll(g.db, groupsBucket, filter, &groups)
+
+	return groups, err
+}
+
+func (g *groupRepo) UpdateGroup(ctx context.Context, o *datastore.Group) error {
+	o.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"name":                o.Name,
+		"logo_url":            o.LogoURL,
+		"updated_at":          o.UpdatedAt,
+		"config":              o.Config,
+		"rate_limit":          o.RateLimit,
+		"metadata":            o.Metadata,
+		"rate_limit_duration": o.RateLimitDuration,
+	}
+
+	return withTx(g.db, func(e executor) error {
+		err := assertUniqueGroupName(e, o)
+		if err != nil {
+			return err
+		}
+
+		return update(e, groupsBucket, newFilter().eq("uid", o.UID), set, nil)
+	})
+}
+
+func (g *groupRepo) FetchGroupByID(ctx context.Context, id string) (*datastore.Group, error) {
+	group := new(datastore.Group)
+
+	err := findOne(g.db, groupsBucket, newFilter().eq("uid", id), group)
+	if err == errNotFound {
+		err = datastore.ErrGroupNotFound
+	}
+
+	return group, err
+}
+
+func (g *groupRepo) FillGroupsStatistics(ctx context.Context, groups []*datastore.Group) error {
+	for _, group := range groups {
+		apps, err := count(g.db, applicationsBucket, newFilter().eq("group_id", group.UID))
+		if err != nil {
+			return err
+		}
+
+		events, err := count(g.db, eventsBucket, newFilter().eq("group_id", group.UID))
+		if err != nil {
+			return err
+		}
+
+		group.Statistics = &datastore.GroupStatistics{
+			GroupID:      group.UID,
+			MessagesSent: events,
+			TotalApps:    apps,
+		}
+	}
+
+	return nil
+}
+
+func (g *groupRepo) DeleteGroup(ctx context.Context, uid string) error {
+	return withTx(g.db, func(e executor) error {
+		err := softDelete(e, groupsBucket, newFilter().eq("uid", uid))
+		if err != nil {
+			return err
+		}
+
+		err = softDelete(e, eventsBucket, newFilter().eq("group_id", uid))
+		if err != nil {
+			return err
+		}
+
+		err = softDelete(e, subscriptionsBucket, newFilter().eq("group_id", uid))
+		if err != nil {
+			return err
+		}
+
+		return softDelete(e, applicationsBucket, newFilter().eq("group_id", uid))
+	})
+}
+
+func (g *groupRepo) FetchGroupsByIDs(ctx context.Context, ids []string) ([]datastore.Group, error) {
+	groups := make([]datastore.Group, 0)
+
+	err := findAll(g.db, groupsBucket, newFilter().in("uid", ids), &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func assertUniqueGroupName(e executor, group *datastore.Group) error {
+	filter := newFilter().
+		ne("uid", group.UID).
+		eq("organisation_id", group.OrganisationID).
+		eq("name", group.Name)
+
+	n, err := count(e, groupsBucket, filter)
+	if err != nil {
+		return err
+	}
+
+	if n != 0 {
+		return datastore.ErrDuplicateGroupName
+	}
+
+	return nil
+}
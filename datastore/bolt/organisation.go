@@ -0,0 +1,62 @@
+package bolt
+
+import (
+	"context"
+	"go.etcd.io/bbolt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type orgRepo struct {
+	db *bbolt.DB
+}
+
+func NewOrgRepo(db *bbolt.DB) datastore.OrganisationRepository {
+	return &orgRepo{
+		db: db,
+	}
+}
+
+func (o *orgRepo) CreateOrganisation(ctx context.Context, org *datastore.Organisation) error {
+	org.ID = primitive.NewObjectID()
+	return insert(o.db, organisationsBucket, org)
+}
+
+func (o *orgRepo) LoadOrganisationsPaged(ctx context.Context, pageable datastore.Pageable) ([]datastore.Organisation, datastore.PaginationData, error) {
+	var organisations []datastore.Organisation
+
+	pagination, err := findPaged(o.db, organisationsBucket, newFilter(), pageable, &organisations)
+	if err != nil {
+		return organisations, datastore.PaginationData{}, err
+	}
+
+	return organisations, pagination, nil
+}
+
+func (o *orgRepo) UpdateOrganisation(ctx context.Context, org *datastore.Organisation) error {
+	org.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"name":       org.Name,
+		"updated_at": org.UpdatedAt,
+	}
+
+	return update(o.db, organisationsBucket, newFilter().eq("uid", org.UID), set, nil)
+}
+
+func (o *orgRepo) DeleteOrganisation(ctx context.Context, uid string) error {
+	return softDelete(o.db, organisationsBucket, newFilter().eq("uid", uid))
+}
+
+func (o *orgRepo) FetchOrganisationByID(ctx context.Context, id string) (*datastore.Organisation, error) {
+	org := new(datastore.Organisation)
+
+	err := findOne(o.db, organisationsBucket, newFilter().eq("uid", id), org)
+	if err == errNotFound {
+		err = datastore.ErrOrgNotFound
+	}
+
+	return org, err
+}
@@ -0,0 +1,165 @@
+package bolt
+
+import (
+	"context"
+	"go.etcd.io/bbolt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type orgMemberRepo struct {
+	db *bbolt.DB
+}
+
+func NewOrgMemberRepo(db *bbolt.DB) datastore.OrganisationMemberRepository {
+	return &orgMemberRepo{
+		db: db,
+	}
+}
+
+func (o *orgMemberRepo) CreateOrganisationMember(ctx context.Context, member *datastore.OrganisationMember) error {
+	member.ID = primitive.NewObjectID()
+	return insert(o.db, organisationMembersBucket, member)
+}
+
+func (o *orgMemberRepo) LoadOrganisationMembersPaged(ctx context.Context, organisationID string, pageable datastore.Pageable) ([]*datastore.OrganisationMember, datastore.PaginationData, error) {
+	filter := newFilter()
+	if !util.IsStringEmpty(organisationID) {
+		filter.eq("organisation_id", organisationID)
+	}
+
+	var members []*datastore.OrganisationMember
+	pagination, err := findPaged(o.db, organisationMembersBucket, filter, pageable, &members)
+	if err != nil {
+		return members, datastore.PaginationData{}, err
+	}
+
+	err = o.fillOrgMemberUserMetadata(members)
+	if err != nil {
+		return members, datastore.PaginationData{}, err
+	}
+
+	return members, pagination, nil
+}
+
+func (o *orgMemberRepo) LoadUserOrganisationsPaged(ctx context.Context, userID string, pageable datastore.Pageable) ([]datastore.Organisation, datastore.PaginationData, error) {
+	var members []datastore.OrganisationMember
+	err := findAll(o.db, organisationMembersBucket, newFilter().eq("user_id", userID), &members)
+	if err != nil {
+		log.WithError(err).Error("failed to load user organisations")
+		return nil, datastore.PaginationData{}, err
+	}
+
+	orgIDs := make([]string, 0, len(members))
+	for i := range members {
+		orgIDs = append(orgIDs, members[i].OrganisationID)
+	}
+
+	var orgs []datastore.Organisation
+	err = findAll(o.db, organisationsBucket, newFilter().in("uid", orgIDs), &orgs)
+	if err != nil {
+		log.WithError(err).Error("failed to load user organisations")
+		return nil, datastore.PaginationData{}, err
+	}
+
+	orgMap := make(map[string]datastore.Organisation, len(orgs))
+	for _, org := range orgs {
+		orgMap[org.UID] = org
+	}
+
+	// newest membership first, the same order the other paged queries use
+	organisations := make([]datastore.Organisation, 0, len(members))
+	for i := len(members) - 1; i >= 0; i-- {
+		if org, ok := orgMap[members[i].OrganisationID]; ok {
+			organisations = append(organisations, org)
+		}
+	}
+
+	total := len(organisations)
+	start := pageable.Offset()
+	if start > total {
+		start = total
+	}
+
+	end := start + pageable.Limit()
+	if end > total {
+		end = total
+	}
+
+	return organisations[start:end], datastore.NewPaginationData(int64(total), pageable), nil
+}
+
+func (o *orgMemberRepo) UpdateOrganisationMember(ctx context.Context, member *datastore.OrganisationMember) error {
+	member.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"role":       member.Role,
+		"updated_at": member.UpdatedAt,
+	}
+
+	return update(o.db, organisationMembersBucket, newFilter().eq("uid", member.UID), set, nil)
+}
+
+func (o *orgMemberRepo) DeleteOrganisationMember(ctx context.Context, uid, orgID string) error {
+	filter := newFilter().eq("uid", uid).eq("organisation_id", orgID)
+	return softDelete(o.db, organisationMembersBucket, filter)
+}
+
+func (o *orgMemberRepo) FetchOrganisationMemberByID(ctx context.Context, uid, orgID string) (*datastore.OrganisationMember, error) {
+	filter := newFilter().eq("uid", uid).eq("organisation_id", orgID)
+	return o.fetchOrganisationMember(filter)
+}
+
+func (o *orgMemberRepo) FetchOrganisationMemberByUserID(ctx context.Context, userID, orgID string) (*datastore.OrganisationMember, error) {
+	filter := newFilter().eq("user_id", userID).eq("organisation_id", orgID)
+	return o.fetchOrganisationMember(filter)
+}
+
+func (o *orgMemberRepo) fetchOrganisationMember(filter *filter) (*datastore.OrganisationMember, error) {
+	member := new(datastore.OrganisationMember)
+
+	err := findOne(o.db, organisationMembersBucket, filter, member)
+	if err != nil {
+		if err == errNotFound {
+			return nil, datastore.ErrOrgMemberNotFound
+		}
+		return nil, err
+	}
+
+	err = o.fillOrgMemberUserMetadata([]*datastore.OrganisationMember{member})
+	return member, err
+}
+
+func (o *orgMemberRepo) fillOrgMemberUserMetadata(members []*datastore.OrganisationMember) error {
+	userIDs := make([]string, 0, len(members))
+	for i := range members {
+		userIDs = append(userIDs, members[i].UserID)
+	}
+
+	var users []datastore.User
+	err := findAll(o.db, usersBucket, newFilter().in("uid", userIDs), &users)
+	if err != nil {
+		log.WithError(err).Error("failed to load user metadata for organisation members")
+		return err
+	}
+
+	metaMap := map[string]*datastore.UserMetadata{}
+	for _, user := range users {
+		metaMap[user.UID] = &datastore.UserMetadata{
+			UserID:    user.UID,
+			FirstName: user.FirstName,
+			LastName:  user.LastName,
+			Email:     user.Email,
+		}
+	}
+
+	for i := range members {
+		members[i].UserMetadata = metaMap[members[i].UserID]
+	}
+
+	return nil
+}
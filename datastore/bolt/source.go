@@ -0,0 +1,109 @@
+package bolt
+
+import (
+	"context"
+	"go.etcd.io/bbolt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type sourceRepo struct {
+	db *bbolt.DB
+}
+
+func NewSourceRepo(db *bbolt.DB) datastore.SourceRepository {
+	return &sourceRepo{
+		db: db,
+	}
+}
+
+func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source) error {
+	source.ID = primitive.NewObjectID()
+	return insert(s.db, sourcesBucket, source)
+}
+
+func (s *sourceRepo) UpdateSource(ctx context.Context, groupID string, source *datastore.Source) error {
+	filter := newFilter().eq("uid", source.UID).eq("group_id", groupID).active()
+
+	set := bson.M{
+		"name":             source.Name,
+		"type":             source.Type,
+		"is_disabled":      source.IsDisabled,
+		"verifier":         source.Verifier,
+		"updated_at":       primitive.NewDateTimeFromTime(time.Now()),
+		"provider_config":  source.ProviderConfig,
+		"pub_sub":          source.PubSub,
+		"db_change_stream": source.DBChangeStream,
+		"rest_api":         source.RestApi,
+	}
+
+	return update(s.db, sourcesBucket, filter, set, nil)
+}
+
+// UpdateSourceRestApiState saves state without touching the source's
+// updated_at, an update restarts the source's poller.
+func (s *sourceRepo) UpdateSourceRestApiState(ctx context.Context, groupID string, id string, state *datastore.RestApiState) error {
+	filter := newFilter().eq("uid", id).eq("group_id", groupID).active()
+	return update(s.db, sourcesBucket, filter, bson.M{"rest_api_state": state}, nil)
+}
+
+func (s *sourceRepo) FindSourceByID(ctx context.Context, groupID string, id string) (*datastore.Source, error) {
+	source := &datastore.Source{}
+
+	err := findOne(s.db, sourcesBucket, newFilter().eq("uid", id).eq("group_id", groupID), source)
+	if err == errNotFound {
+		return source, datastore.ErrSourceNotFound
+	}
+
+	return source, err
+}
+
+func (s *sourceRepo) FindSourceByMaskID(ctx context.Context, maskID string) (*datastore.Source, error) {
+	source := &datastore.Source{}
+
+	err := findOne(s.db, sourcesBucket, newFilter().eq("mask_id", maskID), source)
+	if err == errNotFound {
+		return source, datastore.ErrSourceNotFound
+	}
+
+	return source, err
+}
+
+func (s *sourceRepo) DeleteSourceByID(ctx context.Context, groupID string, id string) error {
+	return withTx(s.db, func(e executor) error {
+		err := softDelete(e, sourcesBucket, newFilter().eq("uid", id).eq("group_id", groupID))
+		if err != nil {
+			return err
+		}
+
+		return softDelete(e, subscriptionsBucket, newFilter().eq("source_id", id))
+	})
+}
+
+func (s *sourceRepo) LoadSourcesPaged(ctx context.Context, groupID string, f *datastore.SourceFilter, pageable datastore.Pageable) ([]datastore.Source, datastore.PaginationData, error) {
+	filter := newFilter()
+
+	if !util.IsStringEmpty(groupID) {
+		filter.eq("group_id", groupID)
+	}
+
+	if !util.IsStringEmpty(f.Type) {
+		filter.eq("type", f.Type)
+	}
+
+	if !util.IsStringEmpty(f.Provider) {
+		filter.eq("provider", f.Provider)
+	}
+
+	sources := make([]datastore.Source, 0)
+	pagination, err := findPaged(s.db, sourcesBucket, filter, pageable, &sources)
+	if err != nil {
+		return sources, datastore.PaginationData{}, err
+	}
+
+	return sources, pagination, nil
+}
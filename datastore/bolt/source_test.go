@@ -0,0 +1,42 @@
+package bolt
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_UpdateSourceRestApiState(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	sourceRepo := NewSourceRepo(db)
+	source := &datastore.Source{
+		UID:            uuid.NewString(),
+		GroupID:        uuid.NewString(),
+		Name:           "Convoy-Prod",
+		Type:           datastore.RestApiSource,
+		RestApi:        &datastore.RestApiConfig{URL: "https://api.example.com/events", IDPath: "id"},
+		UpdatedAt:      primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour)),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.NoError(t, sourceRepo.CreateSource(context.Background(), source))
+
+	state := &datastore.RestApiState{Cursor: "page-2", SeenIDs: []string{"1", "2"}}
+	require.NoError(t, sourceRepo.UpdateSourceRestApiState(context.Background(), source.GroupID, source.UID, state))
+
+	newSource, err := sourceRepo.FindSourceByID(context.Background(), source.GroupID, source.UID)
+	require.NoError(t, err)
+
+	require.Equal(t, state, newSource.RestApiState)
+	require.Equal(t, source.RestApi, newSource.RestApi)
+
+	// the poller is restarted when updated_at changes, saving its state
+	// must not change it
+	require.Equal(t, source.UpdatedAt, newSource.UpdatedAt)
+}
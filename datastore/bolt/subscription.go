@@ -0,0 +1,156 @@
+package bolt
+
+import (
+	"context"
+	"go.etcd.io/bbolt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type subscriptionRepo struct {
+	db *bbolt.DB
+}
+
+func NewSubscriptionRepo(db *bbolt.DB) datastore.SubscriptionRepository {
+	return &subscriptionRepo{
+		db: db,
+	}
+}
+
+func (s *subscriptionRepo) CreateSubscription(ctx context.Context, groupID string, subscription *datastore.Subscription) error {
+	if groupID != subscription.GroupID {
+		return datastore.ErrNotAuthorisedToAccessDocument
+	}
+
+	subscription.ID = primitive.NewObjectID()
+	return insert(s.db, subscriptionsBucket, subscription)
+}
+
+func (s *subscriptionRepo) UpdateSubscription(ctx context.Context, groupID string, subscription *datastore.Subscription) error {
+	if groupID != subscription.GroupID {
+		return datastore.ErrNotAuthorisedToAccessDocument
+	}
+
+	subscription.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	filter := newFilter().eq("uid", subscription.UID).eq("group_id", groupID).active()
+	set := bson.M{
+		"name":              subscription.Name,
+		"source_id":         subscription.SourceID,
+		"endpoint_id":       subscription.EndpointID,
+		"filter_config":     subscription.FilterConfig,
+		"alert_config":      subscription.AlertConfig,
+		"retry_config":      subscription.RetryConfig,
+		"disable_endpoint":  subscription.DisableEndpoint,
+		"rate_limit_config": subscription.RateLimitConfig,
+		"transform_config":  subscription.TransformConfig,
+		"ordering_config":   subscription.OrderingConfig,
+		"batch_config":      subscription.BatchConfig,
+		"failure_policy":    subscription.FailurePolicy,
+		"updated_at":        subscription.UpdatedAt,
+	}
+
+	return update(s.db, subscriptionsBucket, filter, set, nil)
+}
+
+func (s *subscriptionRepo) LoadSubscriptionsPaged(ctx context.Context, groupID string, f *datastore.FilterBy, pageable datastore.Pageable) ([]datastore.Subscription, datastore.PaginationData, error) {
+	filter := newFilter().eq("group_id", groupID)
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("app_id", f.AppID)
+	}
+
+	var subscriptions []datastore.Subscription
+	pagination, err := findPaged(s.db, subscriptionsBucket, filter, pageable, &subscriptions)
+	if err != nil {
+		return nil, datastore.PaginationData{}, err
+	}
+
+	return subscriptions, pagination, nil
+}
+
+func (s *subscriptionRepo) DeleteSubscription(ctx context.Context, groupID string, subscription *datastore.Subscription) error {
+	if groupID != subscription.GroupID {
+		return datastore.ErrNotAuthorisedToAccessDocument
+	}
+
+	filter := newFilter().eq("uid", subscription.UID).eq("group_id", groupID)
+	return softDelete(s.db, subscriptionsBucket, filter)
+}
+
+func (s *subscriptionRepo) FindSubscriptionByID(ctx context.Context, groupID string, uid string) (*datastore.Subscription, error) {
+	subscription := &datastore.Subscription{}
+
+	err := findOne(s.db, subscriptionsBucket, newFilter().eq("uid", uid).eq("group_id", groupID), subscription)
+	if err == errNotFound {
+		err = datastore.ErrSubscriptionNotFound
+	}
+
+	return subscription, err
+}
+
+func (s *subscriptionRepo) FindSubscriptionsByEventType(ctx context.Context, groupID string, appID string, eventType datastore.EventType) ([]datastore.Subscription, error) {
+	filter := newFilter().
+		eq("group_id", groupID).
+		eq("app_id", appID).
+		contains("filter_config.event_types", eventType)
+
+	subscriptions := make([]datastore.Subscription, 0)
+	err := findAll(s.db, subscriptionsBucket, filter, &subscriptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *subscriptionRepo) FindSubscriptionsByAppID(ctx context.Context, groupID string, appID string) ([]datastore.Subscription, error) {
+	filter := newFilter().eq("app_id", appID).eq("group_id", groupID)
+
+	subscriptions := make([]datastore.Subscription, 0)
+	err := findAll(s.db, subscriptionsBucket, filter, &subscriptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *subscriptionRepo) FindSubscriptionByDeviceID(ctx context.Context, groupID, deviceID string) (*datastore.Subscription, error) {
+	subscription := &datastore.Subscription{}
+
+	err := findOne(s.db, subscriptionsBucket, newFilter().eq("device_id", deviceID).eq("group_id", groupID), subscription)
+	if err != nil {
+		if err == errNotFound {
+			return nil, datastore.ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+
+	return subscription, nil
+}
+
+func (s *subscriptionRepo) FindSubscriptionsBySourceIDs(ctx context.Context, groupID string, sourceID string) ([]datastore.Subscription, error) {
+	filter := newFilter().eq("group_id", groupID).eq("source_id", sourceID)
+
+	subscriptions := make([]datastore.Subscription, 0)
+	err := findAll(s.db, subscriptionsBucket, filter, &subscriptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return subscriptions, nil
+}
+
+func (s *subscriptionRepo) UpdateSubscriptionStatus(ctx context.Context, groupID string, subscriptionID string, status datastore.SubscriptionStatus) error {
+	filter := newFilter().eq("uid", subscriptionID).eq("group_id", groupID).active()
+	set := bson.M{
+		"status":     status,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(s.db, subscriptionsBucket, filter, set, nil)
+}
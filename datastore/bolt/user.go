@@ -0,0 +1,104 @@
+package bolt
+
+import (
+	"context"
+	"go.etcd.io/bbolt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type userRepo struct {
+	db *bbolt.DB
+}
+
+func NewUserRepo(db *bbolt.DB) datastore.UserRepository {
+	return &userRepo{
+		db: db,
+	}
+}
+
+func (u *userRepo) CreateUser(ctx context.Context, user *datastore.User) error {
+	user.ID = primitive.NewObjectID()
+	user.ResetPasswordToken = uuid.NewString()
+
+	return withTx(u.db, func(e executor) error {
+		err := assertUniqueEmail(e, user)
+		if err != nil {
+			return err
+		}
+
+		return insert(e, usersBucket, user)
+	})
+}
+
+func (u *userRepo) FindUserByEmail(ctx context.Context, email string) (*datastore.User, error) {
+	return u.findUser(newFilter().eq("email", email))
+}
+
+func (u *userRepo) FindUserByID(ctx context.Context, id string) (*datastore.User, error) {
+	return u.findUser(newFilter().eq("uid", id))
+}
+
+func (u *userRepo) FindUserByToken(ctx context.Context, token string) (*datastore.User, error) {
+	return u.findUser(newFilter().eq("reset_password_token", token))
+}
+
+func (u *userRepo) LoadUsersPaged(ctx context.Context, pageable datastore.Pageable) ([]datastore.User, datastore.PaginationData, error) {
+	users := make([]datastore.User, 0)
+
+	pagination, err := findPaged(u.db, usersBucket, newFilter(), pageable, &users)
+	if err != nil {
+		return users, datastore.PaginationData{}, err
+	}
+
+	return users, pagination, nil
+}
+
+func (u *userRepo) UpdateUser(ctx context.Context, user *datastore.User) error {
+	set := bson.M{
+		"first_name":                user.FirstName,
+		"last_name":                 user.LastName,
+		"email":                     user.Email,
+		"password":                  user.Password,
+		"updated_at":                primitive.NewDateTimeFromTime(time.Now()),
+		"reset_password_token":      user.ResetPasswordToken,
+		"reset_password_expires_at": user.ResetPasswordExpiresAt,
+	}
+
+	return withTx(u.db, func(e executor) error {
+		err := assertUniqueEmail(e, user)
+		if err != nil {
+			return err
+		}
+
+		return update(e, usersBucket, newFilter().eq("uid", user.UID), set, nil)
+	})
+}
+
+func (u *userRepo) findUser(filter *filter) (*datastore.User, error) {
+	user := &datastore.User{}
+
+	err := findOne(u.db, usersBucket, filter, user)
+	if err == errNotFound {
+		return user, datastore.ErrUserNotFound
+	}
+
+	return user, err
+}
+
+func assertUniqueEmail(e executor, user *datastore.User) error {
+	n, err := count(e, usersBucket, newFilter().ne("uid", user.UID).eq("email", user.Email))
+	if err != nil {
+		return err
+	}
+
+	if n != 0 {
+		return datastore.ErrDuplicateEmail
+	}
+
+	return nil
+}
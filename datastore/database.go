@@ -0,0 +1,26 @@
+package datastore
+
+import "context"
+
+// Database is implemented by every storage backend convoy can run on
+// (mongodb, postgres etc.) and hands out the repositories the rest of the
+// application depends on.
+type Database interface {
+	GetName() string
+	Disconnect(context.Context) error
+
+	APIKeyRepo() APIKeyRepository
+	AppRepo() ApplicationRepository
+	ConfigRepo() ConfigurationRepository
+	DeadLetterRepo() DeadLetterRepository
+	DeviceRepo() DeviceRepository
+	EventRepo() EventRepository
+	EventDeliveryRepo() EventDeliveryRepository
+	GroupRepo() GroupRepository
+	OrgRepo() OrganisationRepository
+	OrgInviteRepo() OrganisationInviteRepository
+	OrgMemberRepo() OrganisationMemberRepository
+	SourceRepo() SourceRepository
+	SubRepo() SubscriptionRepository
+	UserRepo() UserRepository
+}
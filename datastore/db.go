@@ -32,6 +32,7 @@ const (
 	EventDeliveryCollection       = "eventdeliveries"
 	APIKeyCollection              = "apiKeys"
 	DeviceCollection              = "devices"
+	DeadLetterCollection          = "deadletters"
 )
 
 const CollectionCtx CollectionKey = "collection"
@@ -537,6 +538,8 @@ func (d *MongoStore) retrieveCollection(ctx context.Context) (string, error) {
 		return UserCollection, nil
 	case "devices":
 		return DeviceCollection, nil
+	case "deadletters":
+		return DeadLetterCollection, nil
 	case "data_migrations", nil:
 		return "data_migrations", nil
 	default:
@@ -14,6 +14,10 @@ type Filter struct {
 	Pageable     Pageable
 	Status       []EventDeliveryStatus
 	SearchParams SearchParams
+
+	// Upcoming restricts event deliveries to the scheduled ones that are yet
+	// to be sent, the event id, status and search params are ignored.
+	Upcoming bool
 }
 
 type SourceFilter struct {
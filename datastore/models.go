@@ -5,14 +5,18 @@ import (
 	"database/sql/driver"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/auth"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/pkg/httpheader"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -81,6 +85,10 @@ type StorageType string
 
 type EndpointAuthenticationType string
 
+type PubSubType string
+
+type SignatureScheme string
+
 const (
 	HTTPSource     SourceType = "http"
 	RestApiSource  SourceType = "rest_api"
@@ -96,6 +104,19 @@ const (
 
 const (
 	APIKeyAuthentication EndpointAuthenticationType = "api_key"
+	OAuth2Authentication EndpointAuthenticationType = "oauth2"
+)
+
+const (
+	DefaultSignatureScheme          SignatureScheme = "default"
+	StandardWebhooksSignatureScheme SignatureScheme = "standard_webhooks"
+	Ed25519SignatureScheme          SignatureScheme = "ed25519"
+)
+
+const (
+	SqsPubSub   PubSubType = "sqs"
+	KafkaPubSub PubSubType = "kafka"
+	AmqpPubSub  PubSubType = "amqp"
 )
 
 func (s SourceProvider) IsValid() bool {
@@ -114,6 +135,14 @@ func (s SourceType) IsValid() bool {
 	return false
 }
 
+func (p PubSubType) IsValid() bool {
+	switch p {
+	case SqsPubSub, KafkaPubSub, AmqpPubSub:
+		return true
+	}
+	return false
+}
+
 const (
 	NoopVerifier      VerifierType = "noop"
 	HMacVerifier      VerifierType = "hmac"
@@ -155,6 +184,7 @@ const (
 	DefaultStrategyProvider     = LinearStrategyProvider
 	LinearStrategyProvider      = "linear"
 	ExponentialStrategyProvider = "exponential"
+	ScheduleStrategyProvider    = "schedule"
 )
 
 var (
@@ -194,6 +224,10 @@ var (
 	DefaultRetentionPolicy = RetentionPolicyConfiguration{
 		Policy: "60d",
 	}
+
+	DefaultDeduplicationConfig = DeduplicationConfiguration{
+		Window: 86400,
+	}
 )
 
 const (
@@ -229,10 +263,19 @@ type Endpoint struct {
 	Description string `json:"description" bson:"description"`
 	Secret      string `json:"secret" bson:"secret"`
 
+	// Secrets are the secrets the endpoint's secret was rotated from,
+	// deliveries are signed with them as well until they expire.
+	Secrets []EndpointSecret `json:"secrets,omitempty" bson:"secrets,omitempty"`
+
 	HttpTimeout       string                  `json:"http_timeout" bson:"http_timeout"`
 	RateLimit         int                     `json:"rate_limit" bson:"rate_limit"`
 	RateLimitDuration string                  `json:"rate_limit_duration" bson:"rate_limit_duration"`
 	Authentication    *EndpointAuthentication `json:"authentication" bson:"authentication"`
+	TLSConfig         *TLSConfiguration       `json:"tls_config,omitempty" bson:"tls_config,omitempty"`
+
+	// CircuitBreaker is the state of the endpoint's circuit breaker, it is
+	// kept in the cache rather than with the endpoint.
+	CircuitBreaker *CircuitBreakerStatus `json:"circuit_breaker,omitempty" bson:"-"`
 
 	CreatedAt primitive.DateTime `json:"created_at,omitempty" bson:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt primitive.DateTime `json:"updated_at,omitempty" bson:"updated_at,omitempty" swaggertype:"string"`
@@ -241,9 +284,69 @@ type Endpoint struct {
 	DocumentStatus DocumentStatus `json:"-" bson:"document_status"`
 }
 
+type TLSVersion string
+
+const (
+	TLSVersion10 TLSVersion = "1.0"
+	TLSVersion11 TLSVersion = "1.1"
+	TLSVersion12 TLSVersion = "1.2"
+	TLSVersion13 TLSVersion = "1.3"
+)
+
+// TLSConfiguration is the TLS an endpoint's deliveries are sent over: a
+// PEM encoded client certificate and key for endpoints that require mutual
+// TLS, a PEM encoded bundle of the CAs the endpoint's certificate is verified
+// against instead of the system's and the minimum TLS version. The client
+// key is never returned by the api.
+type TLSConfiguration struct {
+	ClientCert string     `json:"client_cert,omitempty" bson:"client_cert,omitempty"`
+	ClientKey  string     `json:"-" bson:"client_key,omitempty"`
+	CACert     string     `json:"ca_cert,omitempty" bson:"ca_cert,omitempty"`
+	MinVersion TLSVersion `json:"min_version,omitempty" bson:"min_version,omitempty" valid:"optional,in(1.0|1.1|1.2|1.3)~unsupported tls version"`
+}
+
+// DefaultSecretExpiration is how long a rotated out secret is still used to
+// sign deliveries, so consumers have time to switch to the new secret.
+const DefaultSecretExpiration = 24 * time.Hour
+
+type EndpointSecret struct {
+	UID       string             `json:"uid" bson:"uid"`
+	Value     string             `json:"value" bson:"value"`
+	ExpiresAt primitive.DateTime `json:"expires_at" bson:"expires_at" swaggertype:"string"`
+}
+
+// ActiveSecrets returns the endpoint's secret followed by the rotated out
+// secrets that haven't expired.
+func (e *Endpoint) ActiveSecrets(now time.Time) []string {
+	secrets := []string{e.Secret}
+	for _, s := range e.Secrets {
+		if s.ExpiresAt.Time().After(now) {
+			secrets = append(secrets, s.Value)
+		}
+	}
+
+	return secrets
+}
+
+// PruneSecrets removes the endpoint's expired secrets and reports whether it
+// removed any.
+func (e *Endpoint) PruneSecrets(now time.Time) bool {
+	var secrets []EndpointSecret
+	for _, s := range e.Secrets {
+		if s.ExpiresAt.Time().After(now) {
+			secrets = append(secrets, s)
+		}
+	}
+
+	pruned := len(secrets) != len(e.Secrets)
+	e.Secrets = secrets
+	return pruned
+}
+
 type EndpointAuthentication struct {
-	Type   EndpointAuthenticationType `json:"type,omitempty" bson:"type" valid:"optional,in(api_key)~unsupported authentication type"`
+	Type   EndpointAuthenticationType `json:"type,omitempty" bson:"type" valid:"optional,in(api_key|oauth2)~unsupported authentication type"`
 	ApiKey *ApiKey                    `json:"api_key" bson:"api_key"`
+	OAuth2 *OAuth2                    `json:"oauth2,omitempty" bson:"oauth2,omitempty"`
 }
 
 var (
@@ -284,25 +387,79 @@ type GroupConfig struct {
 	Strategy                 *StrategyConfiguration        `json:"strategy"`
 	Signature                *SignatureConfiguration       `json:"signature"`
 	RetentionPolicy          *RetentionPolicyConfiguration `json:"retention_policy" bson:"retention_policy"`
+	CircuitBreaker           *CircuitBreakerConfiguration  `json:"circuit_breaker,omitempty" bson:"circuit_breaker,omitempty"`
+	Deduplication            *DeduplicationConfiguration   `json:"deduplication,omitempty" bson:"deduplication,omitempty"`
 	DisableEndpoint          bool                          `json:"disable_endpoint" bson:"disable_endpoint"`
 	ReplayAttacks            bool                          `json:"replay_attacks" bson:"replay_attacks"`
 	IsRetentionPolicyEnabled bool                          `json:"is_retention_policy_enabled" bson:"is_retention_policy_enabled"`
 }
 
+// DeduplicationConfiguration sets how many seconds a group remembers the
+// idempotency keys of its events. An event with the key of one created within
+// the Window isn't created again, the original event is returned instead.
+// Keys are remembered for a day when it is unset, a zero Window turns
+// deduplication off.
+type DeduplicationConfiguration struct {
+	Window uint64 `json:"window" bson:"window"`
+}
+
+// CircuitBreakerConfiguration configures the circuit breaker of each of a
+// group's endpoints. A breaker opens after FailureThreshold consecutive
+// failed deliveries, or when FailureRate percent of at least MinimumRequests
+// deliveries in a Window of seconds failed. It stays open for OpenDuration
+// seconds, then lets probe deliveries through and closes after
+// SuccessThreshold of them succeed. Unset fields take the defaults in
+// circuitbreaker.DefaultConfig. The breakers are disabled when it is unset.
+type CircuitBreakerConfiguration struct {
+	FailureThreshold uint64 `json:"failure_threshold" bson:"failure_threshold"`
+	FailureRate      uint64 `json:"failure_rate" bson:"failure_rate" valid:"range(0|100)~failure rate must be a percentage"`
+	MinimumRequests  uint64 `json:"minimum_requests" bson:"minimum_requests"`
+	Window           uint64 `json:"window" bson:"window"`
+	OpenDuration     uint64 `json:"open_duration" bson:"open_duration"`
+	SuccessThreshold uint64 `json:"success_threshold" bson:"success_threshold"`
+}
+
+type CircuitBreakerStatus struct {
+	State               string     `json:"state"`
+	Requests            uint64     `json:"requests"`
+	Failures            uint64     `json:"failures"`
+	ConsecutiveFailures uint64     `json:"consecutive_failures"`
+	OpenedAt            *time.Time `json:"opened_at,omitempty"`
+}
+
 type RateLimitConfiguration struct {
 	Count    int    `json:"count" bson:"count"`
 	Duration uint64 `json:"duration" bson:"duration"`
 }
 
+// StrategyConfiguration is how a group retries failed deliveries. The
+// schedule strategy waits the seconds in Schedule before each retry. With
+// HonorRetryAfter set, the Retry-After header of 429 and 503 responses is
+// followed for up to MaxRetryAfter seconds. A delivery isn't retried once
+// MaxElapsedTime seconds have passed since it was created, when it is set.
 type StrategyConfiguration struct {
-	Type       StrategyProvider `json:"type" valid:"optional~please provide a valid strategy type, in(linear|exponential)~unsupported strategy type"`
-	Duration   uint64           `json:"duration" valid:"optional~please provide a valid duration in seconds,int"`
-	RetryCount uint64           `json:"retry_count" valid:"optional~please provide a valid retry count,int"`
+	Type            StrategyProvider `json:"type" valid:"optional~please provide a valid strategy type, in(linear|exponential|schedule)~unsupported strategy type"`
+	Duration        uint64           `json:"duration" valid:"optional~please provide a valid duration in seconds,int"`
+	RetryCount      uint64           `json:"retry_count" valid:"optional~please provide a valid retry count,int"`
+	Schedule        []uint64         `json:"schedule,omitempty"`
+	HonorRetryAfter bool             `json:"honor_retry_after"`
+	MaxRetryAfter   uint64           `json:"max_retry_after,omitempty"`
+	MaxElapsedTime  uint64           `json:"max_elapsed_time,omitempty"`
 }
 
+// SignatureConfiguration is how a group signs its deliveries. The default
+// scheme sends a hex HMAC of the payload in Header. The standard_webhooks
+// and ed25519 schemes send the webhook-id, webhook-timestamp and
+// webhook-signature headers of the Standard Webhooks spec, signed with the
+// endpoint's secret or with the group's Ed25519 key pair. Only the public
+// key of the pair is ever returned.
 type SignatureConfiguration struct {
+	Scheme SignatureScheme                `json:"scheme,omitempty" bson:"scheme" valid:"optional,in(default|standard_webhooks|ed25519)~unsupported signature scheme"`
 	Header config.SignatureHeaderProvider `json:"header,omitempty" valid:"required~please provide a valid signature header"`
 	Hash   string                         `json:"hash,omitempty" valid:"required~please provide a valid hash,supported_hash~unsupported hash type"`
+
+	PublicKey  string `json:"public_key,omitempty" bson:"public_key,omitempty"`
+	PrivateKey string `json:"-" bson:"private_key,omitempty"`
 }
 
 type SignatureValues struct {
@@ -338,6 +495,16 @@ type EventDeliveryFilter struct {
 	CreatedAtEnd   int64  `json:"created_at_end" bson:"created_at_end"`
 }
 
+type DeadLetterFilter struct {
+	GroupID         string             `json:"group_id" bson:"group_id"`
+	AppID           string             `json:"app_id" bson:"app_id"`
+	EndpointID      string             `json:"endpoint_id" bson:"endpoint_id"`
+	SubscriptionID  string             `json:"subscription_id" bson:"subscription_id"`
+	EventDeliveryID string             `json:"event_delivery_id" bson:"event_delivery_id"`
+	Status          []DeadLetterStatus `json:"status" bson:"status"`
+	SearchParams    SearchParams       `json:"search_params" bson:"search_params"`
+}
+
 func (g *GroupFilter) WithNamesTrimmed() *GroupFilter {
 	f := GroupFilter{OrgID: g.OrgID, Names: []string{}}
 
@@ -372,6 +539,7 @@ var (
 	ErrSubscriptionNotFound          = errors.New("subscription not found")
 	ErrEventDeliveryNotFound         = errors.New("event delivery not found")
 	ErrEventDeliveryAttemptNotFound  = errors.New("event delivery attempt not found")
+	ErrDeadLetterNotFound            = errors.New("dead letter not found")
 	ErrDuplicateAppName              = errors.New("an application with this name exists")
 	ErrNotAuthorisedToAccessDocument = errors.New("your credentials cannot access or modify this resource")
 	ErrConfigNotFound                = errors.New("config not found")
@@ -415,6 +583,18 @@ type Event struct {
 	// webhook to the endpoints
 	Data json.RawMessage `json:"data,omitempty" bson:"data"`
 
+	// DeliverAt is when the event's deliveries are sent, they are sent
+	// right away when it isn't set.
+	DeliverAt primitive.DateTime `json:"deliver_at,omitempty" bson:"deliver_at,omitempty" swaggertype:"string"`
+
+	// TTL is how many seconds after they are due the event's deliveries
+	// expire, they don't expire when it isn't set.
+	TTL uint64 `json:"ttl,omitempty" bson:"ttl,omitempty"`
+
+	// Broadcast is set on events sent to every application in the group
+	// with a subscription to the event type, they don't have an AppID.
+	Broadcast *BroadcastConfiguration `json:"broadcast,omitempty" bson:"broadcast,omitempty"`
+
 	CreatedAt primitive.DateTime `json:"created_at,omitempty" bson:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt primitive.DateTime `json:"updated_at,omitempty" bson:"updated_at,omitempty" swaggertype:"string"`
 	DeletedAt primitive.DateTime `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" swaggertype:"string"`
@@ -422,6 +602,12 @@ type Event struct {
 	DocumentStatus DocumentStatus `json:"-" bson:"document_status"`
 }
 
+// BroadcastConfiguration paces a broadcast event's deliveries, no more than
+// RateLimit of them are sent a second.
+type BroadcastConfiguration struct {
+	RateLimit int `json:"rate_limit" bson:"rate_limit"`
+}
+
 type (
 	SubscriptionType    string
 	EventDeliveryStatus string
@@ -442,8 +628,17 @@ const (
 	FailureEventStatus    EventDeliveryStatus = "Failure"
 	SuccessEventStatus    EventDeliveryStatus = "Success"
 	RetryEventStatus      EventDeliveryStatus = "Retry"
+	// CancelledEventStatus : when a scheduled Event was cancelled before it
+	// was sent
+	CancelledEventStatus EventDeliveryStatus = "Cancelled"
+	// ExpiredEventStatus : when an Event's TTL passed before it was sent
+	ExpiredEventStatus EventDeliveryStatus = "Expired"
 )
 
+// PendingEventStatuses are the statuses of event deliveries that haven't
+// succeeded or failed yet.
+var PendingEventStatuses = []EventDeliveryStatus{ScheduledEventStatus, ProcessingEventStatus, RetryEventStatus}
+
 func (e EventDeliveryStatus) IsValid() bool {
 	switch e {
 	case ScheduledEventStatus,
@@ -451,7 +646,9 @@ func (e EventDeliveryStatus) IsValid() bool {
 		DiscardedEventStatus,
 		FailureEventStatus,
 		SuccessEventStatus,
-		RetryEventStatus:
+		RetryEventStatus,
+		CancelledEventStatus,
+		ExpiredEventStatus:
 		return true
 	default:
 		return false
@@ -478,6 +675,13 @@ type Metadata struct {
 	IntervalSeconds uint64 `json:"interval_seconds" bson:"interval_seconds"`
 
 	RetryLimit uint64 `json:"retry_limit" bson:"retry_limit"`
+
+	// Schedule, HonorRetryAfter, MaxRetryAfter and MaxElapsedTime are
+	// copied from the retry config the delivery was created with
+	Schedule        []uint64 `json:"schedule,omitempty" bson:"schedule,omitempty"`
+	HonorRetryAfter bool     `json:"honor_retry_after,omitempty" bson:"honor_retry_after,omitempty"`
+	MaxRetryAfter   uint64   `json:"max_retry_after,omitempty" bson:"max_retry_after,omitempty"`
+	MaxElapsedTime  uint64   `json:"max_elapsed_time,omitempty" bson:"max_elapsed_time,omitempty"`
 }
 
 func (em Metadata) Value() (driver.Value, error) {
@@ -517,6 +721,10 @@ type DeliveryAttempt struct {
 	Error            string     `json:"error,omitempty" bson:"error,omitempty"`
 	Status           bool       `json:"status,omitempty" bson:"status,omitempty"`
 
+	// Classification is how the subscription's failure policy classified
+	// the attempt's outcome
+	Classification AttemptClassification `json:"classification,omitempty" bson:"classification,omitempty"`
+
 	CreatedAt primitive.DateTime `json:"created_at,omitempty" bson:"created_at,omitempty" swaggertype:"string"`
 	UpdatedAt primitive.DateTime `json:"updated_at,omitempty" bson:"updated_at,omitempty" swaggertype:"string"`
 	DeletedAt primitive.DateTime `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" swaggertype:"string"`
@@ -534,6 +742,14 @@ type EventDelivery struct {
 	SubscriptionID string                `json:"subscription_id,omitempty" bson:"subscription_id"`
 	Headers        httpheader.HTTPHeader `json:"headers" bson:"headers"`
 
+	// OrderingKey is the delivery's ordering key when its subscription
+	// delivers events in order.
+	OrderingKey string `json:"ordering_key,omitempty" bson:"ordering_key,omitempty"`
+
+	// ExpiresAt is when the delivery stops being sent, it is set when the
+	// event or the subscription has a TTL.
+	ExpiresAt primitive.DateTime `json:"expires_at,omitempty" bson:"expires_at,omitempty" swaggertype:"string"`
+
 	Endpoint *Endpoint    `json:"endpoint_metadata,omitempty" bson:"-"`
 	Event    *Event       `json:"event_metadata,omitempty" bson:"-"`
 	App      *Application `json:"app_metadata,omitempty" bson:"-"`
@@ -550,6 +766,43 @@ type EventDelivery struct {
 	DocumentStatus DocumentStatus `json:"-" bson:"document_status"`
 }
 
+type DeadLetterStatus string
+
+const (
+	// PendingDeadLetterStatus is a dead letter that hasn't been redriven
+	PendingDeadLetterStatus DeadLetterStatus = "Pending"
+	// RedrivenDeadLetterStatus is a dead letter whose delivery was requeued
+	RedrivenDeadLetterStatus DeadLetterStatus = "Redriven"
+)
+
+// DeadLetter records an event delivery that failed for good, along with the
+// error and attempts it failed with, so it can be inspected and redriven.
+type DeadLetter struct {
+	ID              primitive.ObjectID `json:"-" bson:"_id"`
+	UID             string             `json:"uid" bson:"uid"`
+	GroupID         string             `json:"group_id" bson:"group_id"`
+	AppID           string             `json:"app_id" bson:"app_id"`
+	EventID         string             `json:"event_id" bson:"event_id"`
+	EventDeliveryID string             `json:"event_delivery_id" bson:"event_delivery_id"`
+	EndpointID      string             `json:"endpoint_id" bson:"endpoint_id"`
+	SubscriptionID  string             `json:"subscription_id" bson:"subscription_id"`
+
+	// Reason is why the delivery stopped being retried, e.g. "Retry limit exceeded"
+	Reason string `json:"reason" bson:"reason"`
+	// LastError is the error or response status of the delivery's last attempt
+	LastError string            `json:"last_error" bson:"last_error"`
+	Attempts  []DeliveryAttempt `json:"attempts" bson:"attempts"`
+
+	Status     DeadLetterStatus   `json:"status" bson:"status"`
+	RedrivenAt primitive.DateTime `json:"redriven_at,omitempty" bson:"redriven_at,omitempty" swaggertype:"string"`
+
+	CreatedAt primitive.DateTime `json:"created_at,omitempty" bson:"created_at,omitempty" swaggertype:"string"`
+	UpdatedAt primitive.DateTime `json:"updated_at,omitempty" bson:"updated_at,omitempty" swaggertype:"string"`
+	DeletedAt primitive.DateTime `json:"deleted_at,omitempty" bson:"deleted_at,omitempty" swaggertype:"string"`
+
+	DocumentStatus DocumentStatus `json:"-" bson:"document_status"`
+}
+
 type CLIMetadata struct {
 	EventType string `json:"event_type" bson:"event_type"`
 	HostName  string `json:"host_name,omitempty" bson:"-"`
@@ -595,8 +848,16 @@ type Subscription struct {
 	RetryConfig     *RetryConfiguration     `json:"retry_config,omitempty" bson:"retry_config,omitempty"`
 	FilterConfig    *FilterConfiguration    `json:"filter_config,omitempty" bson:"filter_config,omitempty"`
 	RateLimitConfig *RateLimitConfiguration `json:"rate_limit_config,omitempty" bson:"rate_limit_config,omitempty"`
+	TransformConfig *TransformConfiguration `json:"transform_config,omitempty" bson:"transform_config,omitempty"`
+	OrderingConfig  *OrderingConfiguration  `json:"ordering_config,omitempty" bson:"ordering_config,omitempty"`
+	BatchConfig     *BatchConfiguration     `json:"batch_config,omitempty" bson:"batch_config,omitempty"`
+	FailurePolicy   *FailurePolicy          `json:"failure_policy,omitempty" bson:"failure_policy,omitempty"`
 	DisableEndpoint *bool                   `json:"disable_endpoint,omitempty" bson:"disable_endpoint"`
 
+	// TTL is how many seconds after they are due the subscription's
+	// deliveries expire, the event's TTL applies when it is shorter.
+	TTL uint64 `json:"ttl,omitempty" bson:"ttl,omitempty"`
+
 	CreatedAt primitive.DateTime `json:"created_at,omitempty" bson:"created_at" swaggertype:"string"`
 	UpdatedAt primitive.DateTime `json:"updated_at,omitempty" bson:"updated_at" swaggertype:"string"`
 	DeletedAt primitive.DateTime `json:"deleted_at,omitempty" bson:"deleted_at" swaggertype:"string"`
@@ -605,17 +866,21 @@ type Subscription struct {
 }
 
 type Source struct {
-	ID             primitive.ObjectID `json:"-" bson:"_id"`
-	UID            string             `json:"uid" bson:"uid"`
-	GroupID        string             `json:"group_id" bson:"group_id"`
-	MaskID         string             `json:"mask_id" bson:"mask_id"`
-	Name           string             `json:"name" bson:"name"`
-	Type           SourceType         `json:"type" bson:"type"`
-	Provider       SourceProvider     `json:"provider" bson:"provider"`
-	IsDisabled     bool               `json:"is_disabled" bson:"is_disabled"`
-	Verifier       *VerifierConfig    `json:"verifier" bson:"verifier"`
-	ProviderConfig *ProviderConfig    `json:"provider_config" bson:"provider_config"`
-	ForwardHeaders []string           `json:"forward_headers" bson:"forward_headers"`
+	ID             primitive.ObjectID    `json:"-" bson:"_id"`
+	UID            string                `json:"uid" bson:"uid"`
+	GroupID        string                `json:"group_id" bson:"group_id"`
+	MaskID         string                `json:"mask_id" bson:"mask_id"`
+	Name           string                `json:"name" bson:"name"`
+	Type           SourceType            `json:"type" bson:"type"`
+	Provider       SourceProvider        `json:"provider" bson:"provider"`
+	IsDisabled     bool                  `json:"is_disabled" bson:"is_disabled"`
+	Verifier       *VerifierConfig       `json:"verifier" bson:"verifier"`
+	ProviderConfig *ProviderConfig       `json:"provider_config" bson:"provider_config"`
+	ForwardHeaders []string              `json:"forward_headers" bson:"forward_headers"`
+	PubSub         *PubSubConfig         `json:"pub_sub" bson:"pub_sub"`
+	DBChangeStream *DBChangeStreamConfig `json:"db_change_stream" bson:"db_change_stream"`
+	RestApi        *RestApiConfig        `json:"rest_api" bson:"rest_api"`
+	RestApiState   *RestApiState         `json:"-" bson:"rest_api_state"`
 
 	CreatedAt primitive.DateTime `json:"created_at,omitempty" bson:"created_at" swaggertype:"string"`
 	UpdatedAt primitive.DateTime `json:"updated_at,omitempty" bson:"updated_at" swaggertype:"string"`
@@ -642,9 +907,13 @@ type User struct {
 }
 
 type RetryConfiguration struct {
-	Type       StrategyProvider `json:"type,omitempty" bson:"type,omitempty" valid:"supported_retry_strategy~please provide a valid retry strategy type"`
-	Duration   uint64           `json:"duration,omitempty" bson:"duration,omitempty" valid:"duration~please provide a valid time duration"`
-	RetryCount uint64           `json:"retry_count" bson:"retry_count" valid:"int~please provide a valid retry count"`
+	Type            StrategyProvider `json:"type,omitempty" bson:"type,omitempty" valid:"supported_retry_strategy~please provide a valid retry strategy type"`
+	Duration        uint64           `json:"duration,omitempty" bson:"duration,omitempty" valid:"duration~please provide a valid time duration"`
+	RetryCount      uint64           `json:"retry_count" bson:"retry_count" valid:"int~please provide a valid retry count"`
+	Schedule        []uint64         `json:"schedule,omitempty" bson:"schedule,omitempty"`
+	HonorRetryAfter bool             `json:"honor_retry_after,omitempty" bson:"honor_retry_after,omitempty"`
+	MaxRetryAfter   uint64           `json:"max_retry_after,omitempty" bson:"max_retry_after,omitempty"`
+	MaxElapsedTime  uint64           `json:"max_elapsed_time,omitempty" bson:"max_elapsed_time,omitempty"`
 }
 
 type AlertConfiguration struct {
@@ -652,8 +921,124 @@ type AlertConfiguration struct {
 	Threshold string `json:"threshold" bson:"threshold,omitempty" valid:"duration~please provide a valid time duration"`
 }
 
+// TransformConfiguration holds the jq program that reshapes a
+// subscription's deliveries before they are sent, see package transform.
+type TransformConfiguration struct {
+	Program string `json:"program" bson:"program"`
+}
+
+// OrderingConfiguration makes a subscription send its event deliveries one at
+// a time, in the order they were created. A delivery isn't sent until the
+// ones before it have succeeded or run out of retries. When the key comes
+// from a payload path, KeyPath, or a header, KeyHeader, only deliveries with
+// the same key are ordered with respect to each other, deliveries without the
+// key share the empty key.
+type OrderingConfiguration struct {
+	Enabled   bool   `json:"enabled" bson:"enabled"`
+	KeyPath   string `json:"key_path,omitempty" bson:"key_path,omitempty"`
+	KeyHeader string `json:"key_header,omitempty" bson:"key_header,omitempty"`
+}
+
+// FailurePolicy classifies the outcome of a subscription's delivery attempts.
+// Responses with one of the PermanentStatusCodes fail the delivery without
+// retrying it, and so do network errors that aren't RetryableNetworkErrors
+// when those are set. FailureStatusCodes are 2xx codes that are retried like
+// any other failure. With DisableEndpoint set, a permanent failure deactivates
+// the subscription.
+type FailurePolicy struct {
+	PermanentStatusCodes   []int          `json:"permanent_status_codes,omitempty" bson:"permanent_status_codes,omitempty"`
+	FailureStatusCodes     []int          `json:"failure_status_codes,omitempty" bson:"failure_status_codes,omitempty"`
+	RetryableNetworkErrors []NetworkError `json:"retryable_network_errors,omitempty" bson:"retryable_network_errors,omitempty"`
+	DisableEndpoint        bool           `json:"disable_endpoint" bson:"disable_endpoint"`
+}
+
+// NetworkError is the kind of network error a delivery attempt failed with.
+type NetworkError string
+
+const (
+	TimeoutNetworkError           NetworkError = "timeout"
+	ConnectionRefusedNetworkError NetworkError = "connection_refused"
+	ConnectionResetNetworkError   NetworkError = "connection_reset"
+	DNSNetworkError               NetworkError = "dns"
+	TLSNetworkError               NetworkError = "tls"
+	UnknownNetworkError           NetworkError = "unknown"
+)
+
+func (n NetworkError) IsValid() bool {
+	switch n {
+	case TimeoutNetworkError, ConnectionRefusedNetworkError, ConnectionResetNetworkError,
+		DNSNetworkError, TLSNetworkError, UnknownNetworkError:
+		return true
+	}
+
+	return false
+}
+
+// AttemptClassification is the outcome of a delivery attempt.
+type AttemptClassification string
+
+const (
+	SuccessAttempt          AttemptClassification = "success"
+	RetryableFailureAttempt AttemptClassification = "retryable_failure"
+	PermanentFailureAttempt AttemptClassification = "permanent_failure"
+)
+
+// BatchConfiguration makes a subscription send its event deliveries in
+// batches, as a json array of their payloads. A batch is sent once it has
+// MaxSize deliveries or its oldest delivery has waited MaxWait seconds, and
+// holds at most MaxBytes bytes of payloads.
+type BatchConfiguration struct {
+	Enabled  bool   `json:"enabled" bson:"enabled"`
+	MaxSize  int    `json:"max_size" bson:"max_size"`
+	MaxWait  uint64 `json:"max_wait" bson:"max_wait"`
+	MaxBytes int    `json:"max_bytes" bson:"max_bytes"`
+}
+
 type FilterConfiguration struct {
-	EventTypes []string `json:"event_types" bson:"event_types,omitempty"`
+	EventTypes []string     `json:"event_types" bson:"event_types,omitempty"`
+	Filter     FilterSchema `json:"filter" bson:"filter"`
+}
+
+// FilterSchema holds the filters an event's headers and body must match,
+// see the filter package for their syntax.
+type FilterSchema struct {
+	Headers FilterDocument `json:"headers" bson:"headers"`
+	Body    FilterDocument `json:"body" bson:"body"`
+}
+
+// FilterDocument is a mongodb style query filter. It is stored as a json
+// string, the operators and dotted paths it is made of aren't valid document
+// keys.
+type FilterDocument map[string]interface{}
+
+func (f FilterDocument) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	if f == nil {
+		return bsontype.Null, nil, nil
+	}
+
+	b, err := json.Marshal(map[string]interface{}(f))
+	if err != nil {
+		return bsontype.Null, nil, err
+	}
+
+	return bsontype.String, bsoncore.AppendString(nil, string(b)), nil
+}
+
+func (f *FilterDocument) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	switch t {
+	case bsontype.Null, bsontype.Undefined:
+		*f = nil
+		return nil
+	case bsontype.String:
+		s, _, ok := bsoncore.ReadString(data)
+		if !ok {
+			return errors.New("invalid filter")
+		}
+
+		return json.Unmarshal([]byte(s), f)
+	}
+
+	return fmt.Errorf("cannot decode a filter from bson type %s", t)
 }
 
 type ProviderConfig struct {
@@ -664,6 +1049,109 @@ type TwitterProviderConfig struct {
 	CrcVerifiedAt primitive.DateTime `json:"crc_verified_at" bson:"crc_verified_at"`
 }
 
+type PubSubConfig struct {
+	Type    PubSubType         `json:"type" bson:"type" valid:"supported_pub_sub~please provide a valid pub sub type,required"`
+	Workers int                `json:"workers" bson:"workers"`
+	Sqs     *SQSPubSubConfig   `json:"sqs" bson:"sqs"`
+	Kafka   *KafkaPubSubConfig `json:"kafka" bson:"kafka"`
+	Amqp    *AmqpPubSubConfig  `json:"amqp" bson:"amqp"`
+}
+
+type SQSPubSubConfig struct {
+	AccessKeyID   string `json:"access_key_id" bson:"access_key_id" valid:"required~please provide an access key id"`
+	SecretKey     string `json:"secret_key" bson:"secret_key" valid:"required~please provide a secret key"`
+	DefaultRegion string `json:"default_region" bson:"default_region" valid:"required~please provide a region"`
+	QueueName     string `json:"queue_name" bson:"queue_name" valid:"required~please provide a queue name"`
+	Endpoint      string `json:"endpoint" bson:"endpoint"`
+}
+
+type KafkaPubSubConfig struct {
+	Brokers         []string   `json:"brokers" bson:"brokers" valid:"required~please provide the kafka brokers"`
+	Topic           string     `json:"topic" bson:"topic" valid:"required~please provide a topic"`
+	ConsumerGroupID string     `json:"consumer_group_id" bson:"consumer_group_id"`
+	TLS             bool       `json:"tls" bson:"tls"`
+	Auth            *KafkaAuth `json:"auth" bson:"auth"`
+}
+
+type KafkaAuth struct {
+	Type     string `json:"type" bson:"type" valid:"required~please provide an auth type,in(plain|scram-sha-256|scram-sha-512)~unsupported kafka auth type"`
+	Username string `json:"username" bson:"username" valid:"required~please provide a username"`
+	Password string `json:"password" bson:"password" valid:"required~please provide a password"`
+}
+
+type AmqpPubSubConfig struct {
+	Dsn   string `json:"dsn" bson:"dsn" valid:"required~please provide the amqp dsn"`
+	Queue string `json:"queue" bson:"queue" valid:"required~please provide a queue name"`
+}
+
+// DBChangeStreamConfig is the mongodb collection a db_change_stream source
+// tails. Database defaults to the database in the dsn.
+type DBChangeStreamConfig struct {
+	Dsn        string `json:"dsn" bson:"dsn" valid:"required~please provide the mongodb dsn"`
+	Database   string `json:"database" bson:"database"`
+	Collection string `json:"collection" bson:"collection" valid:"required~please provide a collection"`
+}
+
+// RestApiConfig is the upstream api a rest_api source polls. Every Interval
+// seconds the records at RecordsPath in the response are read, the ones whose
+// value at IDPath hasn't been seen before become events. Paths are dot
+// separated keys and array indexes, an empty RecordsPath means the response
+// is the array of records.
+type RestApiConfig struct {
+	URL         string             `json:"url" bson:"url" valid:"required~please provide a url,url~please provide a valid url"`
+	Method      string             `json:"method" bson:"method" valid:"optional,in(GET|POST)~unsupported http method"`
+	Headers     map[string]string  `json:"headers" bson:"headers"`
+	Auth        *RestApiAuth       `json:"auth" bson:"auth"`
+	Interval    uint64             `json:"interval" bson:"interval"`
+	RecordsPath string             `json:"records_path" bson:"records_path"`
+	IDPath      string             `json:"id_path" bson:"id_path" valid:"required~please provide the path to the record id"`
+	EventType   string             `json:"event_type" bson:"event_type"`
+	Pagination  *RestApiPagination `json:"pagination" bson:"pagination"`
+}
+
+// RestApiState is how far a rest_api source has polled its upstream api:
+// the cursor of the page the next poll starts from and the ids of the latest
+// records that became events, at most MaxRestApiSeenIDs of them.
+type RestApiState struct {
+	Cursor  string   `json:"cursor" bson:"cursor"`
+	SeenIDs []string `json:"seen_ids" bson:"seen_ids"`
+}
+
+// MaxRestApiSeenIDs caps the record ids kept in a rest_api source's state,
+// the oldest are forgotten first.
+const MaxRestApiSeenIDs = 10000
+
+const (
+	// DefaultRestApiInterval is how often, in seconds, a rest_api source
+	// polls when it has no interval.
+	DefaultRestApiInterval = 60
+	MinRestApiInterval     = 10
+)
+
+type RestApiAuthType string
+
+const (
+	BasicRestApiAuth  RestApiAuthType = "basic_auth"
+	BearerRestApiAuth RestApiAuthType = "bearer"
+	APIKeyRestApiAuth RestApiAuthType = "api_key"
+)
+
+type RestApiAuth struct {
+	Type        RestApiAuthType `json:"type" bson:"type" valid:"required~please provide an auth type,in(basic_auth|bearer|api_key)~unsupported auth type"`
+	BasicAuth   *BasicAuth      `json:"basic_auth" bson:"basic_auth"`
+	BearerToken string          `json:"bearer_token" bson:"bearer_token"`
+	ApiKey      *ApiKey         `json:"api_key" bson:"api_key"`
+}
+
+// RestApiPagination is how a rest_api source pages through the upstream
+// api. The cursor at CursorPath in a response is sent in the CursorParam
+// query parameter to fetch the next page. The source carries on from the
+// last page it fetched on its next poll.
+type RestApiPagination struct {
+	CursorPath  string `json:"cursor_path" bson:"cursor_path" valid:"required~please provide the path to the cursor"`
+	CursorParam string `json:"cursor_param" bson:"cursor_param" valid:"required~please provide the cursor query parameter"`
+}
+
 type VerifierConfig struct {
 	Type      VerifierType `json:"type,omitempty" bson:"type" valid:"supported_verifier~please provide a valid verifier type,required"`
 	HMac      *HMac        `json:"hmac" bson:"hmac"`
@@ -688,6 +1176,16 @@ type ApiKey struct {
 	HeaderName  string `json:"header_name" bson:"header_name" valid:"required"`
 }
 
+// OAuth2 holds the client credentials the worker exchanges for the access
+// token deliveries to an endpoint are sent with.
+type OAuth2 struct {
+	TokenURL     string   `json:"token_url" bson:"token_url" valid:"required~please provide a token url,url~please provide a valid token url"`
+	ClientID     string   `json:"client_id" bson:"client_id" valid:"required~please provide a client id"`
+	ClientSecret string   `json:"-" bson:"client_secret" valid:"required~please provide a client secret"`
+	Scopes       []string `json:"scopes,omitempty" bson:"scopes,omitempty"`
+	Audience     string   `json:"audience,omitempty" bson:"audience,omitempty"`
+}
+
 type Organisation struct {
 	ID             primitive.ObjectID `json:"-" bson:"_id"`
 	UID            string             `json:"uid" bson:"uid"`
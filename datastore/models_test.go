@@ -4,6 +4,7 @@ import (
 	"testing"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 
 	"github.com/stretchr/testify/require"
@@ -70,3 +71,66 @@ func TestGroup_IsOwner(t *testing.T) {
 		})
 	}
 }
+
+func TestFilterDocument_BSON(t *testing.T) {
+	tt := []struct {
+		name   string
+		config FilterConfiguration
+	}{
+		{
+			name: "operators and dotted paths",
+			config: FilterConfiguration{
+				EventTypes: []string{"payment.success"},
+				Filter: FilterSchema{
+					Headers: FilterDocument{"x-tenant": "acme"},
+					Body: FilterDocument{
+						"data.amount": map[string]interface{}{"$gt": float64(1000)},
+						"$or":         []interface{}{map[string]interface{}{"data.region": map[string]interface{}{"$regex": "^eu"}}},
+					},
+				},
+			},
+		},
+		{
+			name:   "no filters",
+			config: FilterConfiguration{EventTypes: []string{"*"}},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			b, err := bson.Marshal(tc.config)
+			require.NoError(t, err)
+
+			// the extended json round trip is how the postgres datastore
+			// stores documents
+			ext, err := bson.MarshalExtJSON(bson.Raw(b), false, false)
+			require.NoError(t, err)
+
+			var config FilterConfiguration
+			require.NoError(t, bson.UnmarshalExtJSON(ext, false, &config))
+			require.Equal(t, tc.config, config)
+		})
+	}
+}
+
+func TestEndpoint_Secrets(t *testing.T) {
+	now := time.Now()
+	e := &Endpoint{
+		Secret: "current",
+		Secrets: []EndpointSecret{
+			{UID: "1", Value: "expired", ExpiresAt: primitive.NewDateTimeFromTime(now.Add(-time.Minute))},
+			{UID: "2", Value: "previous", ExpiresAt: primitive.NewDateTimeFromTime(now.Add(time.Hour))},
+		},
+	}
+
+	require.Equal(t, []string{"current", "previous"}, e.ActiveSecrets(now))
+
+	require.True(t, e.PruneSecrets(now))
+	require.Len(t, e.Secrets, 1)
+	require.Equal(t, "2", e.Secrets[0].UID)
+	require.False(t, e.PruneSecrets(now))
+
+	require.True(t, e.PruneSecrets(now.Add(2*time.Hour)))
+	require.Nil(t, e.Secrets)
+	require.Equal(t, []string{"current"}, e.ActiveSecrets(now))
+}
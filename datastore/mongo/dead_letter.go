@@ -0,0 +1,166 @@
+package mongo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type deadLetterRepo struct {
+	store datastore.Store
+}
+
+func NewDeadLetterRepository(store datastore.Store) datastore.DeadLetterRepository {
+	return &deadLetterRepo{
+		store: store,
+	}
+}
+
+func (db *deadLetterRepo) CreateDeadLetter(ctx context.Context, deadLetter *datastore.DeadLetter) error {
+	ctx = db.setCollectionInContext(ctx)
+
+	deadLetter.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(deadLetter.UID) {
+		deadLetter.UID = uuid.New().String()
+	}
+
+	return db.store.Save(ctx, deadLetter, nil)
+}
+
+func (db *deadLetterRepo) FindDeadLetterByID(ctx context.Context, groupID, id string) (*datastore.DeadLetter, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	deadLetter := &datastore.DeadLetter{}
+
+	filter := bson.M{"uid": id, "group_id": groupID, "document_status": datastore.ActiveDocumentStatus}
+	err := db.store.FindOne(ctx, filter, nil, deadLetter)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			err = datastore.ErrDeadLetterNotFound
+		}
+		return nil, err
+	}
+
+	return deadLetter, nil
+}
+
+func (db *deadLetterRepo) FindDeadLettersByIDs(ctx context.Context, groupID string, ids []string) ([]datastore.DeadLetter, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	filter := bson.M{
+		"uid":             bson.M{"$in": ids},
+		"group_id":        groupID,
+		"document_status": datastore.ActiveDocumentStatus,
+	}
+
+	var deadLetters []datastore.DeadLetter
+	err := db.store.FindAll(ctx, filter, nil, nil, &deadLetters)
+	if err != nil {
+		return nil, err
+	}
+
+	return deadLetters, nil
+}
+
+func (db *deadLetterRepo) LoadDeadLettersPaged(ctx context.Context, f *datastore.DeadLetterFilter, pageable datastore.Pageable) ([]datastore.DeadLetter, datastore.PaginationData, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	var deadLetters []datastore.DeadLetter
+	pagination, err := db.store.FindMany(ctx, getDeadLetterFilter(f), nil, nil,
+		int64(pageable.Page), int64(pageable.PerPage), &deadLetters)
+	if err != nil {
+		return deadLetters, datastore.PaginationData{}, err
+	}
+
+	if deadLetters == nil {
+		deadLetters = make([]datastore.DeadLetter, 0)
+	}
+
+	return deadLetters, pagination, nil
+}
+
+func (db *deadLetterRepo) CountDeadLetters(ctx context.Context, f *datastore.DeadLetterFilter) (int64, error) {
+	ctx = db.setCollectionInContext(ctx)
+	return db.store.Count(ctx, getDeadLetterFilter(f))
+}
+
+func (db *deadLetterRepo) CountDeadLettersByGroup(ctx context.Context, status datastore.DeadLetterStatus) (map[string]int64, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	matchStage := bson.D{{Key: "$match", Value: bson.M{
+		"status":          status,
+		"document_status": datastore.ActiveDocumentStatus,
+	}}}
+	groupStage := bson.D{{Key: "$group", Value: bson.M{
+		"_id":   "$group_id",
+		"count": bson.M{"$sum": 1},
+	}}}
+
+	var groups []struct {
+		GroupID string `bson:"_id"`
+		Count   int64  `bson:"count"`
+	}
+
+	err := db.store.Aggregate(ctx, mongo.Pipeline{matchStage, groupStage}, &groups, false)
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(groups))
+	for _, g := range groups {
+		counts[g.GroupID] = g.Count
+	}
+
+	return counts, nil
+}
+
+func (db *deadLetterRepo) UpdateStatusOfDeadLetters(ctx context.Context, ids []string, status datastore.DeadLetterStatus) error {
+	ctx = db.setCollectionInContext(ctx)
+
+	filter := bson.M{
+		"uid":             bson.M{"$in": ids},
+		"document_status": datastore.ActiveDocumentStatus,
+	}
+
+	now := primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{"status": status, "updated_at": now}
+	if status == datastore.RedrivenDeadLetterStatus {
+		set["redriven_at"] = now
+	}
+
+	return db.store.UpdateMany(ctx, filter, bson.M{"$set": set}, false)
+}
+
+func getDeadLetterFilter(f *datastore.DeadLetterFilter) bson.M {
+	filter := bson.M{
+		"document_status":   datastore.ActiveDocumentStatus,
+		"group_id":          f.GroupID,
+		"app_id":            f.AppID,
+		"endpoint_id":       f.EndpointID,
+		"subscription_id":   f.SubscriptionID,
+		"event_delivery_id": f.EventDeliveryID,
+	}
+
+	removeUnusedFields(filter)
+
+	if len(f.Status) > 0 {
+		filter["status"] = bson.M{"$in": f.Status}
+	}
+
+	if f.SearchParams.CreatedAtEnd > 0 {
+		filter["created_at"] = getCreatedDateFilter(f.SearchParams)
+	}
+
+	return filter
+}
+
+func (db *deadLetterRepo) setCollectionInContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, datastore.CollectionCtx, datastore.DeadLetterCollection)
+}
@@ -120,6 +120,20 @@ func (db *eventDeliveryRepo) UpdateStatusOfEventDelivery(ctx context.Context,
 	return db.store.UpdateOne(ctx, filter, update)
 }
 
+func (db *eventDeliveryRepo) UpdateExpiryOfEventDelivery(ctx context.Context, e datastore.EventDelivery, expiresAt primitive.DateTime) error {
+	ctx = db.setCollectionInContext(ctx)
+
+	filter := bson.M{"uid": e.UID}
+	update := bson.M{
+		"$set": bson.M{
+			"expires_at": expiresAt,
+			"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+		},
+	}
+
+	return db.store.UpdateOne(ctx, filter, update)
+}
+
 func (db *eventDeliveryRepo) UpdateStatusOfEventDeliveries(ctx context.Context, ids []string, status datastore.EventDeliveryStatus) error {
 	ctx = db.setCollectionInContext(ctx)
 
@@ -179,6 +193,35 @@ func (db *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, group
 	return eventDeliveries, pagination, nil
 }
 
+// LoadUpcomingEventDeliveriesPaged returns the scheduled deliveries that are
+// yet to be sent.
+func (db *eventDeliveryRepo) LoadUpcomingEventDeliveriesPaged(ctx context.Context, groupID, appID string, pageable datastore.Pageable) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	filter := bson.M{
+		"group_id":                groupID,
+		"status":                  datastore.ScheduledEventStatus,
+		"metadata.next_send_time": bson.M{"$gt": primitive.NewDateTimeFromTime(time.Now())},
+	}
+
+	if !util.IsStringEmpty(appID) {
+		filter["app_id"] = appID
+	}
+
+	var eventDeliveries []datastore.EventDelivery
+	pagination, err := db.store.FindMany(ctx, filter, nil, nil,
+		int64(pageable.Page), int64(pageable.PerPage), &eventDeliveries)
+	if err != nil {
+		return eventDeliveries, datastore.PaginationData{}, err
+	}
+
+	if eventDeliveries == nil {
+		eventDeliveries = make([]datastore.EventDelivery, 0)
+	}
+
+	return eventDeliveries, pagination, nil
+}
+
 func (db *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, groupID, appID, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams) (int64, error) {
 	filter := getFilter(groupID, appID, eventID, status, searchParams)
 	ctx = db.setCollectionInContext(ctx)
@@ -237,6 +280,107 @@ func (db *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, a
 	return deliveries, nil
 }
 
+func (db *eventDeliveryRepo) FindFirstPendingEventDelivery(ctx context.Context, subscriptionID, orderingKey string) (*datastore.EventDelivery, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	filter := bson.M{
+		"subscription_id": subscriptionID,
+		"ordering_key":    orderingKey,
+		"status":          bson.M{"$in": datastore.PendingEventStatuses},
+		"document_status": datastore.ActiveDocumentStatus,
+	}
+
+	if orderingKey == "" {
+		// deliveries without a key don't store it
+		filter["ordering_key"] = bson.M{"$in": bson.A{"", nil}}
+	}
+
+	sort := bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+
+	var deliveries []datastore.EventDelivery
+	err := db.store.FindManyWithDeletedAt(ctx, filter, nil, sort, 1, 0, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(deliveries) == 0 {
+		return nil, datastore.ErrEventDeliveryNotFound
+	}
+
+	return &deliveries[0], nil
+}
+
+// FindDueEventDeliveriesBySubscriptionID returns the subscription's deliveries
+// with one of the statuses that are due to be sent, oldest first.
+func (db *eventDeliveryRepo) FindDueEventDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, status []datastore.EventDeliveryStatus, limit int) ([]datastore.EventDelivery, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	filter := bson.M{
+		"subscription_id":         subscriptionID,
+		"status":                  bson.M{"$in": status},
+		"metadata.next_send_time": bson.M{"$lte": primitive.NewDateTimeFromTime(time.Now())},
+		"document_status":         datastore.ActiveDocumentStatus,
+	}
+
+	sort := bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	err := db.store.FindManyWithDeletedAt(ctx, filter, nil, sort, int64(limit), 0, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimEventDeliveries sets the deliveries that still have one of the statuses
+// to processing and returns their ids, a delivery is only claimed once when
+// it is claimed concurrently.
+func (db *eventDeliveryRepo) ClaimEventDeliveries(ctx context.Context, ids []string, status []datastore.EventDeliveryStatus) ([]string, error) {
+	ctx = db.setCollectionInContext(ctx)
+
+	var claimed []string
+	err := db.store.WithTransaction(ctx, func(sessCtx mongo.SessionContext) error {
+		filter := bson.M{
+			"uid":             bson.M{"$in": ids},
+			"status":          bson.M{"$in": status},
+			"document_status": datastore.ActiveDocumentStatus,
+		}
+
+		deliveries := make([]datastore.EventDelivery, 0)
+		err := db.store.FindAll(sessCtx, filter, nil, nil, &deliveries)
+		if err != nil {
+			return err
+		}
+
+		claimed = make([]string, 0, len(deliveries))
+		for _, d := range deliveries {
+			claimed = append(claimed, d.UID)
+		}
+
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		// a concurrent claim of the same deliveries conflicts with this
+		// transaction and is retried, it no longer finds them then
+		filter["uid"] = bson.M{"$in": claimed}
+		update := bson.M{
+			"$set": bson.M{
+				"status":     datastore.ProcessingEventStatus,
+				"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+			},
+		}
+
+		return db.store.UpdateMany(sessCtx, filter, update, false)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
 func (db *eventDeliveryRepo) setCollectionInContext(ctx context.Context) context.Context {
 	return context.WithValue(ctx, datastore.CollectionCtx, datastore.EventDeliveryCollection)
 }
@@ -16,6 +16,8 @@ import (
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
+var _ datastore.Database = &Client{}
+
 type Client struct {
 	db *mongo.Database
 }
@@ -74,6 +76,66 @@ func (c *Client) Database() *mongo.Database {
 	return c.db
 }
 
+func (c *Client) Store() datastore.Store {
+	return datastore.New(c.db)
+}
+
+func (c *Client) APIKeyRepo() datastore.APIKeyRepository {
+	return NewApiKeyRepo(c.Store())
+}
+
+func (c *Client) AppRepo() datastore.ApplicationRepository {
+	return NewApplicationRepo(c.Store())
+}
+
+func (c *Client) ConfigRepo() datastore.ConfigurationRepository {
+	return NewConfigRepo(c.Store())
+}
+
+func (c *Client) DeadLetterRepo() datastore.DeadLetterRepository {
+	return NewDeadLetterRepository(c.Store())
+}
+
+func (c *Client) DeviceRepo() datastore.DeviceRepository {
+	return NewDeviceRepository(c.Store())
+}
+
+func (c *Client) EventRepo() datastore.EventRepository {
+	return NewEventRepository(c.Store())
+}
+
+func (c *Client) EventDeliveryRepo() datastore.EventDeliveryRepository {
+	return NewEventDeliveryRepository(c.Store())
+}
+
+func (c *Client) GroupRepo() datastore.GroupRepository {
+	return NewGroupRepo(c.Store())
+}
+
+func (c *Client) OrgRepo() datastore.OrganisationRepository {
+	return NewOrgRepo(c.Store())
+}
+
+func (c *Client) OrgInviteRepo() datastore.OrganisationInviteRepository {
+	return NewOrgInviteRepo(c.Store())
+}
+
+func (c *Client) OrgMemberRepo() datastore.OrganisationMemberRepository {
+	return NewOrgMemberRepo(c.Store())
+}
+
+func (c *Client) SourceRepo() datastore.SourceRepository {
+	return NewSourceRepo(c.Store())
+}
+
+func (c *Client) SubRepo() datastore.SubscriptionRepository {
+	return NewSubscriptionRepo(c.Store())
+}
+
+func (c *Client) UserRepo() datastore.UserRepository {
+	return NewUserRepo(c.Store())
+}
+
 func (c *Client) ensureMongoIndices() {
 	c.ensureIndex(datastore.GroupCollection, "uid", true, nil)
 
@@ -96,6 +158,9 @@ func (c *Client) ensureMongoIndices() {
 	c.ensureIndex(datastore.AppCollection, "group_id", false, nil)
 	c.ensureIndex(datastore.EventDeliveryCollection, "status", false, nil)
 	c.ensureIndex(datastore.SourceCollection, "uid", true, nil)
+	c.ensureIndex(datastore.DeadLetterCollection, "uid", true, nil)
+	c.ensureIndex(datastore.DeadLetterCollection, "group_id", false, nil)
+	c.ensureIndex(datastore.DeadLetterCollection, "event_delivery_id", false, nil)
 	c.ensureIndex(datastore.SourceCollection, "mask_id", true, nil)
 	c.ensureIndex(datastore.SubscriptionCollection, "uid", true, nil)
 	c.ensureIndex(datastore.SubscriptionCollection, "filter_config.event_type", false, nil)
@@ -245,6 +310,15 @@ func compoundIndices() map[string][]mongo.IndexModel {
 		},
 
 		datastore.EventDeliveryCollection: {
+			{
+				Keys: bson.D{
+					{Key: "subscription_id", Value: 1},
+					{Key: "ordering_key", Value: 1},
+					{Key: "status", Value: 1},
+					{Key: "created_at", Value: 1},
+				},
+			},
+
 			{
 				Keys: bson.D{
 					{Key: "event_id", Value: 1},
@@ -36,12 +36,15 @@ func (s *sourceRepo) UpdateSource(ctx context.Context, groupId string, source *d
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":            source.Name,
-			"type":            source.Type,
-			"is_disabled":     source.IsDisabled,
-			"verifier":        source.Verifier,
-			"updated_at":      primitive.NewDateTimeFromTime(time.Now()),
-			"provider_config": source.ProviderConfig,
+			"name":             source.Name,
+			"type":             source.Type,
+			"is_disabled":      source.IsDisabled,
+			"verifier":         source.Verifier,
+			"updated_at":       primitive.NewDateTimeFromTime(time.Now()),
+			"provider_config":  source.ProviderConfig,
+			"pub_sub":          source.PubSub,
+			"db_change_stream": source.DBChangeStream,
+			"rest_api":         source.RestApi,
 		},
 	}
 
@@ -49,6 +52,15 @@ func (s *sourceRepo) UpdateSource(ctx context.Context, groupId string, source *d
 	return err
 }
 
+// UpdateSourceRestApiState saves state without touching the source's
+// updated_at, an update restarts the source's poller.
+func (s *sourceRepo) UpdateSourceRestApiState(ctx context.Context, groupId string, id string, state *datastore.RestApiState) error {
+	ctx = s.setCollectionInContext(ctx)
+	filter := bson.M{"uid": id, "group_id": groupId, "document_status": datastore.ActiveDocumentStatus}
+
+	return s.store.UpdateOne(ctx, filter, bson.M{"$set": bson.M{"rest_api_state": state}})
+}
+
 func (s *sourceRepo) FindSourceByID(ctx context.Context, groupId string, id string) (*datastore.Source, error) {
 	ctx = s.setCollectionInContext(ctx)
 	source := &datastore.Source{}
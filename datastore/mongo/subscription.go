@@ -53,10 +53,15 @@ func (s *subscriptionRepo) UpdateSubscription(ctx context.Context, groupId strin
 			"endpoint_id": subscription.EndpointID,
 
 			"filter_config.event_types": subscription.FilterConfig.EventTypes,
+			"filter_config.filter":      subscription.FilterConfig.Filter,
 			"alert_config":              subscription.AlertConfig,
 			"retry_config":              subscription.RetryConfig,
 			"disable_endpoint":          subscription.DisableEndpoint,
 			"rate_limit_config":         subscription.RateLimitConfig,
+			"transform_config":          subscription.TransformConfig,
+			"ordering_config":           subscription.OrderingConfig,
+			"batch_config":              subscription.BatchConfig,
+			"failure_policy":            subscription.FailurePolicy,
 		},
 	}
 
@@ -0,0 +1,51 @@
+package datastore
+
+import "math"
+
+const defaultPerPage = 10
+
+// Limit returns the number of records a page should hold, falling back to
+// the same default the mongo paginator uses.
+func (p Pageable) Limit() int {
+	if p.PerPage < 1 {
+		return defaultPerPage
+	}
+	return p.PerPage
+}
+
+// Offset returns the number of records to skip to get to the current page.
+func (p Pageable) Offset() int {
+	if p.Page < 1 {
+		return 0
+	}
+	return (p.Page - 1) * p.Limit()
+}
+
+// NewPaginationData computes the pagination metadata for a page of results,
+// for backends that don't get it for free from their driver.
+func NewPaginationData(total int64, p Pageable) PaginationData {
+	page := int64(p.Page)
+	if page < 1 {
+		page = 1
+	}
+
+	perPage := int64(p.Limit())
+	totalPage := int64(math.Ceil(float64(total) / float64(perPage)))
+
+	data := PaginationData{
+		Total:     total,
+		Page:      page,
+		PerPage:   perPage,
+		TotalPage: totalPage,
+	}
+
+	if total > 0 && page > 1 {
+		data.Prev = page - 1
+	}
+
+	if total > 0 && page < totalPage {
+		data.Next = page + 1
+	}
+
+	return data
+}
@@ -0,0 +1,53 @@
+package datastore
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NewPaginationData(t *testing.T) {
+	tests := []struct {
+		name     string
+		total    int64
+		pageable Pageable
+		expected PaginationData
+	}{
+		{
+			name:     "first_page",
+			total:    25,
+			pageable: Pageable{Page: 1, PerPage: 10},
+			expected: PaginationData{Total: 25, Page: 1, PerPage: 10, Prev: 0, Next: 2, TotalPage: 3},
+		},
+		{
+			name:     "middle_page",
+			total:    25,
+			pageable: Pageable{Page: 2, PerPage: 10},
+			expected: PaginationData{Total: 25, Page: 2, PerPage: 10, Prev: 1, Next: 3, TotalPage: 3},
+		},
+		{
+			name:     "last_page",
+			total:    25,
+			pageable: Pageable{Page: 3, PerPage: 10},
+			expected: PaginationData{Total: 25, Page: 3, PerPage: 10, Prev: 2, Next: 0, TotalPage: 3},
+		},
+		{
+			name:     "defaults",
+			total:    0,
+			pageable: Pageable{},
+			expected: PaginationData{Total: 0, Page: 1, PerPage: 10, Prev: 0, Next: 0, TotalPage: 0},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.expected, NewPaginationData(tt.total, tt.pageable))
+		})
+	}
+}
+
+func Test_PageableOffset(t *testing.T) {
+	require.Equal(t, 0, Pageable{Page: 1, PerPage: 20}.Offset())
+	require.Equal(t, 40, Pageable{Page: 3, PerPage: 20}.Offset())
+	require.Equal(t, 10, Pageable{Page: 2}.Offset())
+}
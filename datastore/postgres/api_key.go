@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type apiKeyRepo struct {
+	db *sql.DB
+}
+
+func NewApiKeyRepo(db *sql.DB) datastore.APIKeyRepository {
+	return &apiKeyRepo{
+		db: db,
+	}
+}
+
+func (a *apiKeyRepo) CreateAPIKey(ctx context.Context, apiKey *datastore.APIKey) error {
+	apiKey.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(apiKey.UID) {
+		apiKey.UID = uuid.New().String()
+	}
+
+	return insert(ctx, a.db, apiKeysTable, apiKey)
+}
+
+func (a *apiKeyRepo) UpdateAPIKey(ctx context.Context, apiKey *datastore.APIKey) error {
+	return update(ctx, a.db, apiKeysTable, newWhere().eq("uid", apiKey.UID), apiKey, nil)
+}
+
+func (a *apiKeyRepo) FindAPIKeyByID(ctx context.Context, uid string) (*datastore.APIKey, error) {
+	apiKey := &datastore.APIKey{}
+
+	err := findOne(ctx, a.db, apiKeysTable, newWhere().eq("uid", uid), apiKey)
+	if err != nil {
+		if isNoRows(err) {
+			err = datastore.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (a *apiKeyRepo) FindAPIKeyByMaskID(ctx context.Context, maskID string) (*datastore.APIKey, error) {
+	apiKey := &datastore.APIKey{}
+
+	err := findOne(ctx, a.db, apiKeysTable, newWhere().eq("mask_id", maskID), apiKey)
+	if err != nil {
+		if isNoRows(err) {
+			err = datastore.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (a *apiKeyRepo) FindAPIKeyByHash(ctx context.Context, hash string) (*datastore.APIKey, error) {
+	apiKey := &datastore.APIKey{}
+
+	err := findOne(ctx, a.db, apiKeysTable, newWhere().eq("hash", hash), apiKey)
+	if err != nil {
+		if isNoRows(err) {
+			err = datastore.ErrAPIKeyNotFound
+		}
+		return nil, err
+	}
+
+	return apiKey, nil
+}
+
+func (a *apiKeyRepo) RevokeAPIKeys(ctx context.Context, uids []string) error {
+	return softDelete(ctx, a.db, apiKeysTable, newWhere().in("uid", uids))
+}
+
+func (a *apiKeyRepo) LoadAPIKeysPaged(ctx context.Context, f *datastore.ApiKeyFilter, pageable *datastore.Pageable) ([]datastore.APIKey, datastore.PaginationData, error) {
+	filter := newWhere()
+
+	if !util.IsStringEmpty(f.GroupID) {
+		filter.eq("role_group", f.GroupID)
+	}
+
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("role_app", f.AppID)
+	}
+
+	if !util.IsStringEmpty(string(f.KeyType)) {
+		filter.eq("key_type", f.KeyType)
+	}
+
+	var apiKeys []datastore.APIKey
+	pagination, err := findPaged(ctx, a.db, apiKeysTable, filter, *pageable, &apiKeys)
+	if err != nil {
+		return nil, datastore.PaginationData{}, err
+	}
+
+	return apiKeys, pagination, nil
+}
@@ -0,0 +1,221 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type appRepo struct {
+	db *sql.DB
+}
+
+func NewApplicationRepo(db *sql.DB) datastore.ApplicationRepository {
+	return &appRepo{
+		db: db,
+	}
+}
+
+func (a *appRepo) CreateApplication(ctx context.Context, app *datastore.Application, groupID string) error {
+	err := a.assertUniqueAppTitle(ctx, app, groupID)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDuplicateAppName) {
+			return err
+		}
+
+		return fmt.Errorf("failed to check if application name is unique: %v", err)
+	}
+
+	app.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(app.UID) {
+		app.UID = uuid.New().String()
+	}
+
+	err = insert(ctx, a.db, applicationsTable, app)
+	if isUniqueViolation(err, "") {
+		return datastore.ErrDuplicateAppName
+	}
+
+	return err
+}
+
+func (a *appRepo) LoadApplicationsPaged(ctx context.Context, groupID, q string, pageable datastore.Pageable) ([]datastore.Application, datastore.PaginationData, error) {
+	filter := newWhere()
+
+	if !util.IsStringEmpty(groupID) {
+		filter.eq("group_id", groupID)
+	}
+
+	if !util.IsStringEmpty(q) {
+		filter.cond("title ~* ?", q)
+	}
+
+	apps := make([]datastore.Application, 0)
+	pagination, err := findPaged(ctx, a.db, applicationsTable, filter, pageable, &apps)
+	if err != nil {
+		return nil, datastore.PaginationData{}, err
+	}
+
+	err = a.fillEventsCount(ctx, apps)
+	if err != nil {
+		return apps, datastore.PaginationData{}, err
+	}
+
+	return apps, pagination, nil
+}
+
+func (a *appRepo) LoadApplicationsPagedByGroupId(ctx context.Context, groupID string, pageable datastore.Pageable) ([]datastore.Application, datastore.PaginationData, error) {
+	return a.LoadApplicationsPaged(ctx, groupID, "", pageable)
+}
+
+func (a *appRepo) CountGroupApplications(ctx context.Context, groupID string) (int64, error) {
+	n, err := count(ctx, a.db, applicationsTable, newWhere().eq("group_id", groupID))
+	if err != nil {
+		log.WithError(err).Errorf("failed to count apps in group %s", groupID)
+		return 0, err
+	}
+
+	return n, nil
+}
+
+func (a *appRepo) SearchApplicationsByGroupId(ctx context.Context, groupID string, searchParams datastore.SearchParams) ([]datastore.Application, error) {
+	if searchParams.CreatedAtEnd == 0 || searchParams.CreatedAtEnd < searchParams.CreatedAtStart {
+		searchParams.CreatedAtEnd = searchParams.CreatedAtStart
+	}
+
+	filter := newWhere().eq("group_id", groupID).createdBetween(searchParams)
+
+	var apps []datastore.Application
+	err := findAll(ctx, a.db, applicationsTable, filter, "", &apps)
+	if err != nil {
+		return apps, err
+	}
+
+	return apps, a.fillEventsCount(ctx, apps)
+}
+
+func (a *appRepo) FindApplicationByID(ctx context.Context, id string) (*datastore.Application, error) {
+	app := &datastore.Application{}
+
+	err := findOne(ctx, a.db, applicationsTable, newWhere().eq("uid", id), app)
+	if isNoRows(err) {
+		return app, datastore.ErrApplicationNotFound
+	}
+
+	if err != nil {
+		return app, err
+	}
+
+	app.Events, err = count(ctx, a.db, eventsTable, newWhere().eq("app_id", app.UID))
+	if err != nil {
+		log.WithError(err).Errorf("failed to count events in %s", app.UID)
+		return app, err
+	}
+
+	return app, nil
+}
+
+func (a *appRepo) FindApplicationEndpointByID(ctx context.Context, appID string, endpointID string) (*datastore.Endpoint, error) {
+	app, err := a.FindApplicationByID(ctx, appID)
+	if err != nil {
+		return nil, err
+	}
+
+	return findEndpoint(&app.Endpoints, endpointID)
+}
+
+func (a *appRepo) UpdateApplication(ctx context.Context, app *datastore.Application, groupID string) error {
+	err := a.assertUniqueAppTitle(ctx, app, groupID)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDuplicateAppName) {
+			return err
+		}
+
+		return fmt.Errorf("failed to check if application name is unique: %v", err)
+	}
+
+	app.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	set := bson.M{
+		"endpoints":     app.Endpoints,
+		"updated_at":    app.UpdatedAt,
+		"title":         app.Title,
+		"support_email": app.SupportEmail,
+		"is_disabled":   app.IsDisabled,
+	}
+
+	return update(ctx, a.db, applicationsTable, newWhere().eq("uid", app.UID), set, nil)
+}
+
+func (a *appRepo) CreateApplicationEndpoint(ctx context.Context, groupID string, appID string, endpoint *datastore.Endpoint) error {
+	set := bson.M{"updated_at": primitive.NewDateTimeFromTime(time.Now())}
+	push := bson.M{"endpoints": endpoint}
+
+	return update(ctx, a.db, applicationsTable, newWhere().eq("uid", appID).active(), set, push)
+}
+
+func (a *appRepo) DeleteGroupApps(ctx context.Context, groupID string) error {
+	return softDelete(ctx, a.db, applicationsTable, newWhere().eq("group_id", groupID))
+}
+
+func (a *appRepo) DeleteApplication(ctx context.Context, app *datastore.Application) error {
+	return withTx(ctx, a.db, func(tx *sql.Tx) error {
+		err := softDelete(ctx, tx, eventsTable, newWhere().eq("app_id", app.UID))
+		if err != nil {
+			return err
+		}
+
+		err = softDelete(ctx, tx, subscriptionsTable, newWhere().eq("app_id", app.UID))
+		if err != nil {
+			return err
+		}
+
+		return softDelete(ctx, tx, applicationsTable, newWhere().eq("uid", app.UID))
+	})
+}
+
+func (a *appRepo) assertUniqueAppTitle(ctx context.Context, app *datastore.Application, groupID string) error {
+	filter := newWhere().cond("uid <> ?", app.UID).eq("title", app.Title).eq("group_id", groupID)
+
+	n, err := count(ctx, a.db, applicationsTable, filter)
+	if err != nil {
+		return err
+	}
+
+	if n != 0 {
+		return datastore.ErrDuplicateAppName
+	}
+
+	return nil
+}
+
+func (a *appRepo) fillEventsCount(ctx context.Context, apps []datastore.Application) error {
+	for i, app := range apps {
+		n, err := count(ctx, a.db, eventsTable, newWhere().eq("app_id", app.UID))
+		if err != nil {
+			log.Errorf("failed to count events in %s. Reason: %s", app.UID, err)
+			return err
+		}
+		apps[i].Events = n
+	}
+
+	return nil
+}
+
+func findEndpoint(endpoints *[]datastore.Endpoint, id string) (*datastore.Endpoint, error) {
+	for _, endpoint := range *endpoints {
+		if endpoint.UID == id && endpoint.DeletedAt == 0 {
+			return &endpoint, nil
+		}
+	}
+	return nil, datastore.ErrEndpointNotFound
+}
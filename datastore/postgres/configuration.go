@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type configRepo struct {
+	db *sql.DB
+}
+
+func NewConfigRepo(db *sql.DB) datastore.ConfigurationRepository {
+	return &configRepo{
+		db: db,
+	}
+}
+
+func (c *configRepo) CreateConfiguration(ctx context.Context, config *datastore.Configuration) error {
+	config.ID = primitive.NewObjectID()
+	return insert(ctx, c.db, configurationsTable, config)
+}
+
+func (c *configRepo) LoadConfiguration(ctx context.Context) (*datastore.Configuration, error) {
+	config := &datastore.Configuration{}
+
+	err := findOne(ctx, c.db, configurationsTable, newWhere(), config)
+	if isNoRows(err) {
+		return nil, datastore.ErrConfigNotFound
+	}
+
+	return config, err
+}
+
+func (c *configRepo) UpdateConfiguration(ctx context.Context, config *datastore.Configuration) error {
+	set := bson.M{
+		"is_analytics_enabled": config.IsAnalyticsEnabled,
+		"is_signup_enabled":    config.IsSignupEnabled,
+		"storage_policy":       config.StoragePolicy,
+		"updated_at":           primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(ctx, c.db, configurationsTable, newWhere().eq("uid", config.UID), set, nil)
+}
@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type deviceRepo struct {
+	db *sql.DB
+}
+
+func NewDeviceRepository(db *sql.DB) datastore.DeviceRepository {
+	return &deviceRepo{
+		db: db,
+	}
+}
+
+func (d *deviceRepo) CreateDevice(ctx context.Context, device *datastore.Device) error {
+	device.ID = primitive.NewObjectID()
+	return insert(ctx, d.db, devicesTable, device)
+}
+
+func (d *deviceRepo) UpdateDevice(ctx context.Context, device *datastore.Device, appID, groupID string) error {
+	device.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	set := bson.M{
+		"status":       device.Status,
+		"host_name":    device.HostName,
+		"updated_at":   device.UpdatedAt,
+		"last_seen_at": device.LastSeenAt,
+	}
+
+	return update(ctx, d.db, devicesTable, d.filter(device.UID, appID, groupID), set, nil)
+}
+
+func (d *deviceRepo) UpdateDeviceLastSeen(ctx context.Context, device *datastore.Device, appID, groupID string, status datastore.DeviceStatus) error {
+	device.Status = status
+	device.LastSeenAt = primitive.NewDateTimeFromTime(time.Now())
+	device.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+
+	return update(ctx, d.db, devicesTable, d.filter(device.UID, appID, groupID), device, nil)
+}
+
+func (d *deviceRepo) DeleteDevice(ctx context.Context, uid string, appID, groupID string) error {
+	return softDelete(ctx, d.db, devicesTable, d.filter(uid, appID, groupID))
+}
+
+func (d *deviceRepo) FetchDeviceByID(ctx context.Context, uid string, appID, groupID string) (*datastore.Device, error) {
+	device := &datastore.Device{}
+
+	err := findOne(ctx, d.db, devicesTable, d.filter(uid, appID, groupID), device)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, datastore.ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (d *deviceRepo) FetchDeviceByHostName(ctx context.Context, hostName string, appID, groupID string) (*datastore.Device, error) {
+	filter := newWhere().eq("group_id", groupID).eq("host_name", hostName).active()
+	if !util.IsStringEmpty(appID) {
+		filter.eq("app_id", appID)
+	}
+
+	device := &datastore.Device{}
+	err := findOne(ctx, d.db, devicesTable, filter, device)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, datastore.ErrDeviceNotFound
+		}
+		return nil, err
+	}
+
+	return device, nil
+}
+
+func (d *deviceRepo) LoadDevicesPaged(ctx context.Context, groupID string, f *datastore.ApiKeyFilter, pageable datastore.Pageable) ([]datastore.Device, datastore.PaginationData, error) {
+	filter := newWhere().eq("group_id", groupID)
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("app_id", f.AppID)
+	}
+
+	devices := make([]datastore.Device, 0)
+	pagination, err := findPaged(ctx, d.db, devicesTable, filter, pageable, &devices)
+	if err != nil {
+		return devices, datastore.PaginationData{}, err
+	}
+
+	return devices, pagination, nil
+}
+
+func (d *deviceRepo) filter(uid, appID, groupID string) *where {
+	filter := newWhere().eq("uid", uid).eq("group_id", groupID).active()
+	if !util.IsStringEmpty(appID) {
+		filter.eq("app_id", appID)
+	}
+
+	return filter
+}
@@ -0,0 +1,474 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/lib/pq"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Documents are stored the same way they are in mongodb: the whole model is
+// kept in a jsonb column (as relaxed extended json, so bson types survive the
+// round trip) and the handful of fields the repositories filter on are copied
+// into their own indexed columns every time the document is written.
+
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type column struct {
+	name string
+	// key is the dotted path of the field in the bson document
+	key string
+}
+
+type table struct {
+	name    string
+	columns []column
+}
+
+var (
+	apiKeysTable = table{name: "api_keys", columns: []column{
+		{name: "mask_id", key: "mask_id"},
+		{name: "hash", key: "hash"},
+		{name: "key_type", key: "key_type"},
+		{name: "role_group", key: "role.group"},
+		{name: "role_app", key: "role.app"},
+	}}
+	applicationsTable = table{name: "applications", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "title", key: "title"},
+	}}
+	configurationsTable = table{name: "configurations"}
+	deadLettersTable    = table{name: "dead_letters", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "app_id", key: "app_id"},
+		{name: "endpoint_id", key: "endpoint_id"},
+		{name: "subscription_id", key: "subscription_id"},
+		{name: "event_delivery_id", key: "event_delivery_id"},
+		{name: "status", key: "status"},
+	}}
+	devicesTable = table{name: "devices", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "app_id", key: "app_id"},
+		{name: "host_name", key: "host_name"},
+	}}
+	eventsTable = table{name: "events", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "app_id", key: "app_id"},
+		{name: "source_id", key: "source_id"},
+	}}
+	eventDeliveriesTable = table{name: "event_deliveries", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "app_id", key: "app_id"},
+		{name: "event_id", key: "event_id"},
+		{name: "device_id", key: "device_id"},
+		{name: "status", key: "status"},
+		{name: "subscription_id", key: "subscription_id"},
+		{name: "ordering_key", key: "ordering_key"},
+	}}
+	groupsTable = table{name: "groups", columns: []column{
+		{name: "organisation_id", key: "organisation_id"},
+		{name: "name", key: "name"},
+	}}
+	organisationsTable       = table{name: "organisations"}
+	organisationInvitesTable = table{name: "organisation_invites", columns: []column{
+		{name: "organisation_id", key: "organisation_id"},
+		{name: "invitee_email", key: "invitee_email"},
+		{name: "token", key: "token"},
+		{name: "status", key: "status"},
+	}}
+	organisationMembersTable = table{name: "organisation_members", columns: []column{
+		{name: "organisation_id", key: "organisation_id"},
+		{name: "user_id", key: "user_id"},
+	}}
+	sourcesTable = table{name: "sources", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "mask_id", key: "mask_id"},
+		{name: "type", key: "type"},
+		{name: "provider", key: "provider"},
+	}}
+	subscriptionsTable = table{name: "subscriptions", columns: []column{
+		{name: "group_id", key: "group_id"},
+		{name: "app_id", key: "app_id"},
+		{name: "source_id", key: "source_id"},
+		{name: "endpoint_id", key: "endpoint_id"},
+		{name: "device_id", key: "device_id"},
+	}}
+	usersTable = table{name: "users", columns: []column{
+		{name: "email", key: "email"},
+		{name: "reset_password_token", key: "reset_password_token"},
+	}}
+)
+
+// encodeDocument returns the jsonb representation of v along with the raw
+// bson document the column values are read from.
+func encodeDocument(v interface{}) ([]byte, bson.Raw, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := bson.MarshalExtJSON(bson.Raw(raw), false, false)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return data, raw, nil
+}
+
+func decodeDocument(data []byte, v interface{}) error {
+	return bson.UnmarshalExtJSON(data, false, v)
+}
+
+func lookupString(raw bson.Raw, key string) interface{} {
+	val, err := raw.LookupErr(strings.Split(key, ".")...)
+	if err != nil || val.Type != bsontype.String {
+		return nil
+	}
+
+	return val.StringValue()
+}
+
+func lookupTime(raw bson.Raw, key string) interface{} {
+	val, err := raw.LookupErr(key)
+	if err != nil || val.Type != bsontype.DateTime || val.DateTime() == 0 {
+		return nil
+	}
+
+	return primitive.DateTime(val.DateTime()).Time().UTC()
+}
+
+// where collects the conditions of a query. Conditions use ? as their
+// placeholder, rebind turns them into postgres' positional parameters once the
+// whole statement has been assembled.
+type where struct {
+	conds    []string
+	args     []interface{}
+	isActive bool
+}
+
+func newWhere() *where {
+	return &where{}
+}
+
+func (w *where) eq(col string, v interface{}) *where {
+	return w.cond(col+" = ?", v)
+}
+
+func (w *where) in(col string, v interface{}) *where {
+	return w.cond(col+" = ANY(?)", pq.Array(toStrings(v)))
+}
+
+func (w *where) cond(sql string, args ...interface{}) *where {
+	w.conds = append(w.conds, sql)
+	w.args = append(w.args, args...)
+	return w
+}
+
+// active restricts w to documents that haven't been soft deleted, it is
+// safe to call more than once.
+func (w *where) active() *where {
+	if w.isActive {
+		return w
+	}
+
+	w.isActive = true
+	return w.eq("document_status", datastore.ActiveDocumentStatus)
+}
+
+func (w *where) createdBetween(searchParams datastore.SearchParams) *where {
+	return w.cond("created_at >= ? AND created_at <= ?",
+		time.Unix(searchParams.CreatedAtStart, 0), time.Unix(searchParams.CreatedAtEnd, 0))
+}
+
+func (w *where) String() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+
+	return " WHERE " + strings.Join(w.conds, " AND ")
+}
+
+func toStrings(v interface{}) []string {
+	if s, ok := v.([]string); ok {
+		return s
+	}
+
+	rv := reflect.ValueOf(v)
+	s := make([]string, 0, rv.Len())
+	for i := 0; i < rv.Len(); i++ {
+		s = append(s, rv.Index(i).String())
+	}
+
+	return s
+}
+
+func rebind(query string) string {
+	return rebindFrom(query, 0)
+}
+
+// rebindFrom is rebind for statements whose first n parameters are already
+// numbered.
+func rebindFrom(query string, n int) string {
+	var b strings.Builder
+	for _, r := range query {
+		if r == '?' {
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func insert(ctx context.Context, q querier, t table, v interface{}) error {
+	data, raw, err := encodeDocument(v)
+	if err != nil {
+		return err
+	}
+
+	cols := []string{"uid", "document_status", "created_at", "data"}
+	args := []interface{}{lookupString(raw, "uid"), lookupString(raw, "document_status"), lookupTime(raw, "created_at"), string(data)}
+	for _, c := range t.columns {
+		cols = append(cols, c.name)
+		args = append(args, lookupString(raw, c.key))
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(cols)), ", ")
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", t.name, strings.Join(cols, ", "), placeholders)
+
+	_, err = q.ExecContext(ctx, rebind(query), args...)
+	return err
+}
+
+// findOne decodes the oldest active document matching w into out, it returns
+// sql.ErrNoRows when there is none.
+func findOne(ctx context.Context, q querier, t table, w *where, out interface{}) error {
+	w.active()
+	query := fmt.Sprintf("SELECT data FROM %s%s ORDER BY created_at ASC, uid ASC LIMIT 1", t.name, w)
+
+	var data []byte
+	err := q.QueryRowContext(ctx, rebind(query), w.args...).Scan(&data)
+	if err != nil {
+		return err
+	}
+
+	return decodeDocument(data, out)
+}
+
+// findAll decodes every active document matching w into out, which must be a
+// pointer to a slice of models or model pointers.
+func findAll(ctx context.Context, q querier, t table, w *where, orderBy string, out interface{}) error {
+	w.active()
+	if orderBy == "" {
+		orderBy = "created_at ASC, uid ASC"
+	}
+
+	query := fmt.Sprintf("SELECT data FROM %s%s ORDER BY %s", t.name, w, orderBy)
+	return selectDocuments(ctx, q, rebind(query), w.args, out)
+}
+
+// findPaged returns a page of active documents matching w, newest first, the
+// same way the mongo paginator orders them.
+func findPaged(ctx context.Context, q querier, t table, w *where, pageable datastore.Pageable, out interface{}) (datastore.PaginationData, error) {
+	total, err := count(ctx, q, t, w)
+	if err != nil {
+		return datastore.PaginationData{}, err
+	}
+
+	query := fmt.Sprintf("SELECT data FROM %s%s ORDER BY created_at DESC, uid ASC LIMIT %d OFFSET %d",
+		t.name, w, pageable.Limit(), pageable.Offset())
+
+	err = selectDocuments(ctx, q, rebind(query), w.args, out)
+	if err != nil {
+		return datastore.PaginationData{}, err
+	}
+
+	return datastore.NewPaginationData(total, pageable), nil
+}
+
+func count(ctx context.Context, q querier, t table, w *where) (int64, error) {
+	w.active()
+	query := fmt.Sprintf("SELECT COUNT(*) FROM %s%s", t.name, w)
+
+	var n int64
+	err := q.QueryRowContext(ctx, rebind(query), w.args...).Scan(&n)
+	return n, err
+}
+
+func selectDocuments(ctx context.Context, q querier, query string, args []interface{}, out interface{}) error {
+	slice := reflect.ValueOf(out)
+	if slice.Kind() != reflect.Ptr || slice.Elem().Kind() != reflect.Slice {
+		return datastore.ErrInvalidPtr
+	}
+	slice = slice.Elem()
+
+	elemType := slice.Type().Elem()
+	isPtr := elemType.Kind() == reflect.Ptr
+	if isPtr {
+		elemType = elemType.Elem()
+	}
+
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var data []byte
+		if err := rows.Scan(&data); err != nil {
+			return err
+		}
+
+		elem := reflect.New(elemType)
+		if err := decodeDocument(data, elem.Interface()); err != nil {
+			return err
+		}
+
+		if isPtr {
+			slice.Set(reflect.Append(slice, elem))
+		} else {
+			slice.Set(reflect.Append(slice, elem.Elem()))
+		}
+	}
+
+	return rows.Err()
+}
+
+// update applies a mongo style update to every document matching w: set holds
+// the fields to overwrite ($set, keys may be dotted paths) and push the values
+// to append to array fields ($push). The copied columns are refreshed from the
+// updated document in the same statement.
+func update(ctx context.Context, q querier, t table, w *where, set interface{}, push bson.M) error {
+	// $1 holds the set patch and $2 the push patch, the conditions are
+	// numbered after them
+	expr := "data"
+	args := []interface{}{"{}", "{}"}
+
+	if set != nil {
+		patch, keys, err := encodePatch(set)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			expr = fmt.Sprintf("jsonb_set(%s, %s, $1::jsonb->%s, true)", expr, jsonPath(key), pq.QuoteLiteral(key))
+		}
+		args[0] = patch
+	}
+
+	if len(push) > 0 {
+		patch, keys, err := encodePatch(push)
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			field := pq.QuoteLiteral(key)
+			expr = fmt.Sprintf("jsonb_set(%[1]s, %[2]s, (CASE WHEN jsonb_typeof((%[1]s)->%[3]s) = 'array' THEN (%[1]s)->%[3]s ELSE '[]'::jsonb END) || jsonb_build_array($2::jsonb->%[3]s), true)",
+				expr, jsonPath(key), field)
+		}
+		args[1] = patch
+	}
+
+	sets := []string{"data = " + expr, fmt.Sprintf("document_status = (%s)->>'document_status'", expr)}
+	for _, c := range t.columns {
+		sets = append(sets, fmt.Sprintf("%s = (%s)#>>%s", c.name, expr, jsonPath(c.key)))
+	}
+
+	query := fmt.Sprintf("UPDATE %s SET %s%s", t.name, strings.Join(sets, ", "), w)
+	args = append(args, w.args...)
+
+	_, err := q.ExecContext(ctx, rebindFrom(query, 2), args...)
+	return err
+}
+
+func softDelete(ctx context.Context, q querier, t table, w *where) error {
+	set := bson.M{
+		"deleted_at":      primitive.NewDateTimeFromTime(time.Now()),
+		"document_status": datastore.DeletedDocumentStatus,
+	}
+
+	return update(ctx, q, t, w, set, nil)
+}
+
+func hardDelete(ctx context.Context, q querier, t table, w *where) error {
+	query := fmt.Sprintf("DELETE FROM %s%s", t.name, w)
+	_, err := q.ExecContext(ctx, rebind(query), w.args...)
+	return err
+}
+
+// encodePatch encodes an update document as extended json and returns its
+// top level keys in a stable order.
+func encodePatch(v interface{}) (string, []string, error) {
+	raw, err := bson.Marshal(v)
+	if err != nil {
+		return "", nil, err
+	}
+
+	elems, err := bson.Raw(raw).Elements()
+	if err != nil {
+		return "", nil, err
+	}
+
+	keys := make([]string, 0, len(elems))
+	for _, e := range elems {
+		keys = append(keys, e.Key())
+	}
+	sort.Strings(keys)
+
+	data, err := bson.MarshalExtJSON(bson.Raw(raw), false, false)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return string(data), keys, nil
+}
+
+func jsonPath(key string) string {
+	return pq.QuoteLiteral("{" + strings.Join(strings.Split(key, "."), ",") + "}")
+}
+
+func withTx(ctx context.Context, db *sql.DB, fn func(tx *sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func isUniqueViolation(err error, constraint string) bool {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return false
+	}
+
+	return pqErr.Code == "23505" && (constraint == "" || pqErr.Constraint == constraint)
+}
+
+func isNoRows(err error) bool {
+	return errors.Is(err, sql.ErrNoRows)
+}
@@ -0,0 +1,55 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/auth"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func Test_rebind(t *testing.T) {
+	require.Equal(t, "a = $1 AND b = ANY($2)", rebind("a = ? AND b = ANY(?)"))
+	require.Equal(t, "a = $1 AND b = $3", rebindFrom("a = $1 AND b = ?", 2))
+}
+
+func Test_where(t *testing.T) {
+	w := newWhere().eq("group_id", "g").in("status", []datastore.EventDeliveryStatus{datastore.SuccessEventStatus}).active().active()
+
+	require.Equal(t, " WHERE group_id = ? AND status = ANY(?) AND document_status = ?", w.String())
+	require.Len(t, w.args, 3)
+	require.Equal(t, "", newWhere().String())
+}
+
+func Test_encodeDocument(t *testing.T) {
+	key := &datastore.APIKey{
+		ID:             primitive.NewObjectID(),
+		UID:            "uid",
+		MaskID:         "mask",
+		Role:           auth.Role{Type: auth.RoleAdmin, Group: "group-id"},
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	data, raw, err := encodeDocument(key)
+	require.NoError(t, err)
+
+	require.Equal(t, "group-id", lookupString(raw, "role.group"))
+	require.Nil(t, lookupString(raw, "role.app.missing"))
+	require.Equal(t, key.CreatedAt.Time().UTC(), lookupTime(raw, "created_at"))
+
+	decoded := &datastore.APIKey{}
+	require.NoError(t, decodeDocument(data, decoded))
+	require.Equal(t, key, decoded)
+}
+
+func Test_encodePatch(t *testing.T) {
+	patch, keys, err := encodePatch(bson.M{"status": "Active", "filter_config.event_types": []string{"*"}})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"filter_config.event_types", "status"}, keys)
+	require.JSONEq(t, `{"status":"Active","filter_config.event_types":["*"]}`, patch)
+}
@@ -0,0 +1,162 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventRepo struct {
+	db *sql.DB
+}
+
+func NewEventRepository(db *sql.DB) datastore.EventRepository {
+	return &eventRepo{
+		db: db,
+	}
+}
+
+var (
+	dailyIntervalFormat   = "YYYY-MM-DD" // 1 day
+	weeklyIntervalFormat  = "YYYY-MM"    // 1 week
+	monthlyIntervalFormat = "YYYY-MM"    // 1 month
+	yearlyIntervalFormat  = "YYYY"       // 1 year
+)
+
+func (e *eventRepo) CreateEvent(ctx context.Context, event *datastore.Event) error {
+	event.ID = primitive.NewObjectID()
+
+	if util.IsStringEmpty(event.ProviderID) {
+		event.ProviderID = event.AppID
+	}
+	if util.IsStringEmpty(event.UID) {
+		event.UID = uuid.New().String()
+	}
+
+	return insert(ctx, e.db, eventsTable, event)
+}
+
+func (e *eventRepo) CountGroupMessages(ctx context.Context, groupID string) (int64, error) {
+	return count(ctx, e.db, eventsTable, newWhere().eq("group_id", groupID))
+}
+
+func (e *eventRepo) DeleteGroupEvents(ctx context.Context, f *datastore.EventFilter, hardDeleteEvents bool) error {
+	filter := newWhere().eq("group_id", f.GroupID).active().createdBetween(datastore.SearchParams{
+		CreatedAtStart: f.CreatedAtStart,
+		CreatedAtEnd:   f.CreatedAtEnd,
+	})
+
+	if hardDeleteEvents {
+		return hardDelete(ctx, e.db, eventsTable, filter)
+	}
+
+	return softDelete(ctx, e.db, eventsTable, filter)
+}
+
+func (e *eventRepo) LoadEventIntervals(ctx context.Context, groupID string, searchParams datastore.SearchParams, period datastore.Period, interval int) ([]datastore.EventInterval, error) {
+	if searchParams.CreatedAtEnd == 0 || searchParams.CreatedAtEnd < searchParams.CreatedAtStart {
+		searchParams.CreatedAtEnd = searchParams.CreatedAtStart
+	}
+
+	var timeComponent string
+	var format string
+	switch period {
+	case datastore.Daily:
+		timeComponent = "doy"
+		format = dailyIntervalFormat
+	case datastore.Weekly:
+		timeComponent = "week"
+		format = weeklyIntervalFormat
+	case datastore.Monthly:
+		timeComponent = "month"
+		format = monthlyIntervalFormat
+	case datastore.Yearly:
+		timeComponent = "year"
+		format = yearlyIntervalFormat
+	default:
+		return nil, errors.New("specified data cannot be generated for period")
+	}
+
+	if interval < 1 {
+		interval = 1
+	}
+
+	filter := newWhere().eq("group_id", groupID).active().createdBetween(searchParams)
+	query := fmt.Sprintf(`SELECT to_char(created_at AT TIME ZONE 'UTC', '%s') AS total_time,
+		trunc(extract(%s FROM created_at AT TIME ZONE 'UTC') / %d)::BIGINT AS idx,
+		COUNT(*) FROM %s%s GROUP BY total_time, idx ORDER BY total_time, idx`,
+		format, timeComponent, interval, eventsTable.name, filter)
+
+	rows, err := e.db.QueryContext(ctx, rebind(query), filter.args...)
+	if err != nil {
+		log.WithError(err).Errorln("aggregate error")
+		return nil, err
+	}
+	defer rows.Close()
+
+	eventsIntervals := make([]datastore.EventInterval, 0)
+	for rows.Next() {
+		var i datastore.EventInterval
+		err = rows.Scan(&i.Data.Time, &i.Data.Interval, &i.Count)
+		if err != nil {
+			return nil, err
+		}
+
+		eventsIntervals = append(eventsIntervals, i)
+	}
+
+	return eventsIntervals, rows.Err()
+}
+
+func (e *eventRepo) FindEventByID(ctx context.Context, id string) (*datastore.Event, error) {
+	event := &datastore.Event{}
+
+	err := findOne(ctx, e.db, eventsTable, newWhere().eq("uid", id), event)
+	if isNoRows(err) {
+		err = datastore.ErrEventNotFound
+	}
+
+	return event, err
+}
+
+func (e *eventRepo) FindEventsByIDs(ctx context.Context, ids []string) ([]datastore.Event, error) {
+	var events []datastore.Event
+
+	err := findAll(ctx, e.db, eventsTable, newWhere().in("uid", ids), "", &events)
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+func (e *eventRepo) LoadEventsPaged(ctx context.Context, f *datastore.Filter) ([]datastore.Event, datastore.PaginationData, error) {
+	filter := newWhere().createdBetween(f.SearchParams)
+
+	if !util.IsStringEmpty(f.AppID) {
+		filter.eq("app_id", f.AppID)
+	}
+
+	if f.Group != nil && !util.IsStringEmpty(f.Group.UID) {
+		filter.eq("group_id", f.Group.UID)
+	}
+
+	if !util.IsStringEmpty(f.SourceID) {
+		filter.eq("source_id", f.SourceID)
+	}
+
+	events := make([]datastore.Event, 0)
+	pagination, err := findPaged(ctx, e.db, eventsTable, filter, f.Pageable, &events)
+	if err != nil {
+		return events, datastore.PaginationData{}, err
+	}
+
+	return events, pagination, nil
+}
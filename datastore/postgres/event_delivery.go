@@ -0,0 +1,282 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type eventDeliveryRepo struct {
+	db *sql.DB
+}
+
+func NewEventDeliveryRepository(db *sql.DB) datastore.EventDeliveryRepository {
+	return &eventDeliveryRepo{
+		db: db,
+	}
+}
+
+func (e *eventDeliveryRepo) CreateEventDelivery(ctx context.Context, eventDelivery *datastore.EventDelivery) error {
+	eventDelivery.ID = primitive.NewObjectID()
+	if util.IsStringEmpty(eventDelivery.UID) {
+		eventDelivery.UID = uuid.New().String()
+	}
+
+	return insert(ctx, e.db, eventDeliveriesTable, eventDelivery)
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveryByID(ctx context.Context, uid string) (*datastore.EventDelivery, error) {
+	eventDelivery := &datastore.EventDelivery{}
+
+	err := findOne(ctx, e.db, eventDeliveriesTable, newWhere().eq("uid", uid), eventDelivery)
+	if err != nil {
+		if isNoRows(err) {
+			err = datastore.ErrEventDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return eventDelivery, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveriesByIDs(ctx context.Context, ids []string) ([]datastore.EventDelivery, error) {
+	var deliveries []datastore.EventDelivery
+
+	err := findAll(ctx, e.db, eventDeliveriesTable, newWhere().in("uid", ids), "", &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) FindEventDeliveriesByEventID(ctx context.Context, eventID string) ([]datastore.EventDelivery, error) {
+	var deliveries []datastore.EventDelivery
+
+	err := findAll(ctx, e.db, eventDeliveriesTable, newWhere().eq("event_id", eventID), "", &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) CountDeliveriesByStatus(ctx context.Context, status datastore.EventDeliveryStatus, searchParams datastore.SearchParams) (int64, error) {
+	filter := newWhere().eq("status", status).createdBetween(searchParams)
+	return count(ctx, e.db, eventDeliveriesTable, filter)
+}
+
+func (e *eventDeliveryRepo) UpdateStatusOfEventDelivery(ctx context.Context, delivery datastore.EventDelivery, status datastore.EventDeliveryStatus) error {
+	set := bson.M{
+		"status":     status,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(ctx, e.db, eventDeliveriesTable, newWhere().eq("uid", delivery.UID), set, nil)
+}
+
+func (e *eventDeliveryRepo) UpdateExpiryOfEventDelivery(ctx context.Context, delivery datastore.EventDelivery, expiresAt primitive.DateTime) error {
+	set := bson.M{
+		"expires_at": expiresAt,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(ctx, e.db, eventDeliveriesTable, newWhere().eq("uid", delivery.UID), set, nil)
+}
+
+func (e *eventDeliveryRepo) UpdateStatusOfEventDeliveries(ctx context.Context, ids []string, status datastore.EventDeliveryStatus) error {
+	set := bson.M{
+		"status":     status,
+		"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	return update(ctx, e.db, eventDeliveriesTable, newWhere().in("uid", ids).active(), set, nil)
+}
+
+func (e *eventDeliveryRepo) UpdateEventDeliveryWithAttempt(ctx context.Context, delivery datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+	set := bson.M{
+		"status":      delivery.Status,
+		"description": delivery.Description,
+		"metadata":    delivery.Metadata,
+		"updated_at":  primitive.NewDateTimeFromTime(time.Now()),
+	}
+	push := bson.M{"attempts": attempt}
+
+	return update(ctx, e.db, eventDeliveriesTable, newWhere().eq("uid", delivery.UID), set, push)
+}
+
+func (e *eventDeliveryRepo) LoadEventDeliveriesPaged(ctx context.Context, groupID, appID, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams, pageable datastore.Pageable) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+	filter := getFilter(groupID, appID, eventID, status, searchParams)
+
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+	pagination, err := findPaged(ctx, e.db, eventDeliveriesTable, filter, pageable, &eventDeliveries)
+	if err != nil {
+		return eventDeliveries, datastore.PaginationData{}, err
+	}
+
+	return eventDeliveries, pagination, nil
+}
+
+// LoadUpcomingEventDeliveriesPaged returns the scheduled deliveries that are
+// yet to be sent.
+func (e *eventDeliveryRepo) LoadUpcomingEventDeliveriesPaged(ctx context.Context, groupID, appID string, pageable datastore.Pageable) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+	filter := newWhere().
+		eq("group_id", groupID).
+		eq("status", datastore.ScheduledEventStatus).
+		// the document keeps the time as relaxed extended json
+		cond("(data->'metadata'->'next_send_time'->>'$date')::timestamptz > ?", time.Now())
+
+	if !util.IsStringEmpty(appID) {
+		filter.eq("app_id", appID)
+	}
+
+	eventDeliveries := make([]datastore.EventDelivery, 0)
+	pagination, err := findPaged(ctx, e.db, eventDeliveriesTable, filter, pageable, &eventDeliveries)
+	if err != nil {
+		return eventDeliveries, datastore.PaginationData{}, err
+	}
+
+	return eventDeliveries, pagination, nil
+}
+
+func (e *eventDeliveryRepo) CountEventDeliveries(ctx context.Context, groupID, appID, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams) (int64, error) {
+	filter := getFilter(groupID, appID, eventID, status, searchParams)
+	return count(ctx, e.db, eventDeliveriesTable, filter)
+}
+
+func (e *eventDeliveryRepo) DeleteGroupEventDeliveries(ctx context.Context, f *datastore.EventDeliveryFilter, hardDeleteDeliveries bool) error {
+	filter := newWhere().eq("group_id", f.GroupID).active().createdBetween(datastore.SearchParams{
+		CreatedAtStart: f.CreatedAtStart,
+		CreatedAtEnd:   f.CreatedAtEnd,
+	})
+
+	if hardDeleteDeliveries {
+		return hardDelete(ctx, e.db, eventDeliveriesTable, filter)
+	}
+
+	return softDelete(ctx, e.db, eventDeliveriesTable, filter)
+}
+
+func (e *eventDeliveryRepo) FindDiscardedEventDeliveries(ctx context.Context, appId, deviceId string, searchParams datastore.SearchParams) ([]datastore.EventDelivery, error) {
+	filter := newWhere().
+		eq("app_id", appId).
+		eq("device_id", deviceId).
+		eq("status", datastore.DiscardedEventStatus).
+		createdBetween(searchParams)
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	err := findAll(ctx, e.db, eventDeliveriesTable, filter, "", &deliveries)
+	if err != nil {
+		return deliveries, err
+	}
+
+	return deliveries, nil
+}
+
+func (e *eventDeliveryRepo) FindFirstPendingEventDelivery(ctx context.Context, subscriptionID, orderingKey string) (*datastore.EventDelivery, error) {
+	filter := newWhere().
+		eq("subscription_id", subscriptionID).
+		cond("COALESCE(ordering_key, '') = ?", orderingKey).
+		in("status", datastore.PendingEventStatuses)
+
+	delivery := &datastore.EventDelivery{}
+	err := findOne(ctx, e.db, eventDeliveriesTable, filter, delivery)
+	if err != nil {
+		if isNoRows(err) {
+			err = datastore.ErrEventDeliveryNotFound
+		}
+		return nil, err
+	}
+
+	return delivery, nil
+}
+
+// FindDueEventDeliveriesBySubscriptionID returns the subscription's deliveries
+// with one of the statuses that are due to be sent, oldest first.
+func (e *eventDeliveryRepo) FindDueEventDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, status []datastore.EventDeliveryStatus, limit int) ([]datastore.EventDelivery, error) {
+	filter := newWhere().
+		eq("subscription_id", subscriptionID).
+		in("status", status).
+		cond("(data->'metadata'->'next_send_time'->>'$date')::timestamptz <= ?", time.Now()).
+		active()
+
+	query := fmt.Sprintf("SELECT data FROM %s%s ORDER BY created_at ASC, uid ASC LIMIT %d", eventDeliveriesTable.name, filter, limit)
+
+	deliveries := make([]datastore.EventDelivery, 0)
+	err := selectDocuments(ctx, e.db, rebind(query), filter.args, &deliveries)
+	if err != nil {
+		return nil, err
+	}
+
+	return deliveries, nil
+}
+
+// ClaimEventDeliveries sets the deliveries that still have one of the statuses
+// to processing and returns their ids, a delivery is only claimed once when
+// it is claimed concurrently.
+func (e *eventDeliveryRepo) ClaimEventDeliveries(ctx context.Context, ids []string, status []datastore.EventDeliveryStatus) ([]string, error) {
+	var claimed []string
+	err := withTx(ctx, e.db, func(tx *sql.Tx) error {
+		filter := newWhere().in("uid", ids).in("status", status).active()
+
+		// the rows stay locked until the claim commits, a concurrent claim
+		// waits for it and skips the rows that no longer match
+		query := fmt.Sprintf("SELECT data FROM %s%s FOR UPDATE", eventDeliveriesTable.name, filter)
+
+		deliveries := make([]datastore.EventDelivery, 0)
+		err := selectDocuments(ctx, tx, rebind(query), filter.args, &deliveries)
+		if err != nil {
+			return err
+		}
+
+		claimed = make([]string, 0, len(deliveries))
+		for _, d := range deliveries {
+			claimed = append(claimed, d.UID)
+		}
+
+		if len(claimed) == 0 {
+			return nil
+		}
+
+		set := bson.M{
+			"status":     datastore.ProcessingEventStatus,
+			"updated_at": primitive.NewDateTimeFromTime(time.Now()),
+		}
+
+		return update(ctx, tx, eventDeliveriesTable, newWhere().in("uid", claimed), set, nil)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func getFilter(groupID string, appID string, eventID string, status []datastore.EventDeliveryStatus, searchParams datastore.SearchParams) *where {
+	filter := newWhere().createdBetween(searchParams)
+
+	if !util.IsStringEmpty(appID) {
+		filter.eq("app_id", appID)
+	}
+
+	if !util.IsStringEmpty(groupID) {
+		filter.eq("group_id", groupID)
+	}
+
+	if !util.IsStringEmpty(eventID) {
+		filter.eq("event_id", eventID)
+	}
+
+	if len(status) > 0 {
+		filter.in("status", status)
+	}
+
+	return filter
+}
@@ -0,0 +1,155 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const groupNameIndex = "idx_groups_organisation_id_name"
+
+type groupRepo struct {
+	db *sql.DB
+}
+
+func NewGroupRepo(db *sql.DB) datastore.GroupRepository {
+	return &groupRepo{
+		db: db,
+	}
+}
+
+func (g *groupRepo) CreateGroup(ctx context.Context, o *datastore.Group) error {
+	o.ID = primitive.NewObjectID()
+
+	err := insert(ctx, g.db, groupsTable, o)
+	if isUniqueViolation(err, groupNameIndex) {
+		return datastore.ErrDuplicateGroupName
+	}
+
+	return err
+}
+
+func (g *groupRepo) LoadGroups(ctx context.Context, f *datastore.GroupFilter) ([]*datastore.Group, error) {
+	filter := newWhere()
+	if f.OrgID != "" {
+		filter.eq("organisation_id", f.OrgID)
+	}
+
+	f = f.WithNamesTrimmed()
+	if len(f.Names) > 0 {
+		filter.in("name", f.Names)
+	}
+
+	groups := make([]*datastore.Group, 0)
+	err := findAll(ctx, g.db, groupsTable, filter, "created_at ASC", &groups)
+
+	return groups, err
+}
+
+func (g *groupRepo) UpdateGroup(ctx context.Context, o *datastore.Group) error {
+	o.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"name":                o.Name,
+		"logo_url":            o.LogoURL,
+		"updated_at":          o.UpdatedAt,
+		"config":              o.Config,
+		"rate_limit":          o.RateLimit,
+		"metadata":            o.Metadata,
+		"rate_limit_duration": o.RateLimitDuration,
+	}
+
+	err := update(ctx, g.db, groupsTable, newWhere().eq("uid", o.UID), set, nil)
+	if isUniqueViolation(err, groupNameIndex) {
+		return datastore.ErrDuplicateGroupName
+	}
+
+	return err
+}
+
+func (g *groupRepo) FetchGroupByID(ctx context.Context, id string) (*datastore.Group, error) {
+	group := new(datastore.Group)
+
+	err := findOne(ctx, g.db, groupsTable, newWhere().eq("uid", id), group)
+	if isNoRows(err) {
+		err = datastore.ErrGroupNotFound
+	}
+
+	return group, err
+}
+
+func (g *groupRepo) FillGroupsStatistics(ctx context.Context, groups []*datastore.Group) error {
+	ids := make([]string, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.UID)
+	}
+
+	query := `SELECT g.uid,
+		(SELECT COUNT(*) FROM applications a WHERE a.group_id = g.uid AND a.document_status = $2),
+		(SELECT COUNT(*) FROM events e WHERE e.group_id = g.uid)
+		FROM groups g WHERE g.uid = ANY($1)`
+
+	rows, err := g.db.QueryContext(ctx, query, pq.Array(ids), datastore.ActiveDocumentStatus)
+	if err != nil {
+		log.WithError(err).Error("failed to run group statistics query")
+		return err
+	}
+	defer rows.Close()
+
+	statsMap := map[string]*datastore.GroupStatistics{}
+	for rows.Next() {
+		s := &datastore.GroupStatistics{}
+		err = rows.Scan(&s.GroupID, &s.TotalApps, &s.MessagesSent)
+		if err != nil {
+			return err
+		}
+		statsMap[s.GroupID] = s
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range groups {
+		groups[i].Statistics = statsMap[groups[i].UID]
+	}
+
+	return nil
+}
+
+func (g *groupRepo) DeleteGroup(ctx context.Context, uid string) error {
+	return withTx(ctx, g.db, func(tx *sql.Tx) error {
+		err := softDelete(ctx, tx, groupsTable, newWhere().eq("uid", uid))
+		if err != nil {
+			return err
+		}
+
+		err = softDelete(ctx, tx, eventsTable, newWhere().eq("group_id", uid))
+		if err != nil {
+			return err
+		}
+
+		err = softDelete(ctx, tx, subscriptionsTable, newWhere().eq("group_id", uid))
+		if err != nil {
+			return err
+		}
+
+		return softDelete(ctx, tx, applicationsTable, newWhere().eq("group_id", uid))
+	})
+}
+
+func (g *groupRepo) FetchGroupsByIDs(ctx context.Context, ids []string) ([]datastore.Group, error) {
+	groups := make([]datastore.Group, 0)
+
+	err := findAll(ctx, g.db, groupsTable, newWhere().in("uid", ids), "created_at ASC", &groups)
+	if err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
@@ -0,0 +1,215 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+)
+
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+const migrationsTable = "convoy_migrations"
+
+type migration struct {
+	ID   string
+	Up   string
+	Down string
+}
+
+// Migrator applies the sql migrations embedded in the binary, keeping track
+// of the ones already applied in the convoy_migrations table.
+type Migrator struct {
+	db         *sql.DB
+	migrations []*migration
+}
+
+func NewMigrator(c *Client) (*Migrator, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{db: c.db, migrations: migrations}, nil
+}
+
+func loadMigrations() ([]*migration, error) {
+	entries, err := migrationFiles.ReadDir("migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	byID := map[string]*migration{}
+	for _, entry := range entries {
+		name := entry.Name()
+
+		var id, direction string
+		switch {
+		case strings.HasSuffix(name, ".up.sql"):
+			id, direction = strings.TrimSuffix(name, ".up.sql"), "up"
+		case strings.HasSuffix(name, ".down.sql"):
+			id, direction = strings.TrimSuffix(name, ".down.sql"), "down"
+		default:
+			continue
+		}
+
+		b, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byID[id]
+		if !ok {
+			m = &migration{ID: id}
+			byID[id] = m
+		}
+
+		if direction == "up" {
+			m.Up = string(b)
+		} else {
+			m.Down = string(b)
+		}
+	}
+
+	migrations := make([]*migration, 0, len(byID))
+	for _, m := range byID {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %s has no up script", m.ID)
+		}
+		migrations = append(migrations, m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].ID < migrations[j].ID
+	})
+
+	return migrations, nil
+}
+
+// Migrate applies every migration that hasn't been applied yet.
+func (m *Migrator) Migrate(ctx context.Context) error {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, mig := range m.migrations {
+		if applied[mig.ID] {
+			continue
+		}
+
+		err = withTx(ctx, m.db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mig.Up); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("INSERT INTO %s (id) VALUES ($1)", migrationsTable), mig.ID)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply migration %s: %v", mig.ID, err)
+		}
+
+		log.Infof("applied migration %s", mig.ID)
+	}
+
+	return nil
+}
+
+// RollbackTo rolls back every applied migration that came after id, the
+// migration with the given id is left applied.
+func (m *Migrator) RollbackTo(ctx context.Context, id string) error {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return err
+	}
+
+	found := false
+	for _, mig := range m.migrations {
+		if mig.ID == id {
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("migration %s not found", id)
+	}
+
+	for i := len(m.migrations) - 1; i >= 0; i-- {
+		mig := m.migrations[i]
+		if mig.ID == id {
+			break
+		}
+
+		if !applied[mig.ID] {
+			continue
+		}
+
+		err = withTx(ctx, m.db, func(tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, mig.Down); err != nil {
+				return err
+			}
+
+			_, err := tx.ExecContext(ctx, fmt.Sprintf("DELETE FROM %s WHERE id = $1", migrationsTable), mig.ID)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("failed to roll back migration %s: %v", mig.ID, err)
+		}
+
+		log.Infof("rolled back migration %s", mig.ID)
+	}
+
+	return nil
+}
+
+// HasPendingMigrations reports whether any of the embedded migrations has not
+// been applied yet.
+func (m *Migrator) HasPendingMigrations(ctx context.Context) (bool, error) {
+	applied, err := m.appliedMigrations(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	for _, mig := range m.migrations {
+		if !applied[mig.ID] {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *Migrator) appliedMigrations(ctx context.Context) (map[string]bool, error) {
+	_, err := m.db.ExecContext(ctx, fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+		id TEXT PRIMARY KEY,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+	)`, migrationsTable))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := m.db.QueryContext(ctx, fmt.Sprintf("SELECT id FROM %s", migrationsTable))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[string]bool{}
+	for rows.Next() {
+		var id string
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		applied[id] = true
+	}
+
+	return applied, rows.Err()
+}
@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS event_deliveries;
+DROP TABLE IF EXISTS events;
+DROP TABLE IF EXISTS devices;
+DROP TABLE IF EXISTS subscriptions;
+DROP TABLE IF EXISTS sources;
+DROP TABLE IF EXISTS applications;
+DROP TABLE IF EXISTS api_keys;
+DROP TABLE IF EXISTS groups;
+DROP TABLE IF EXISTS organisation_invites;
+DROP TABLE IF EXISTS organisation_members;
+DROP TABLE IF EXISTS organisations;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS configurations;
@@ -0,0 +1,155 @@
+-- Every table stores the full document in data, plus the handful of fields
+-- the repositories filter on copied into their own indexed columns.
+
+CREATE TABLE IF NOT EXISTS configurations (
+    uid TEXT PRIMARY KEY,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS users (
+    uid TEXT PRIMARY KEY,
+    email TEXT,
+    reset_password_token TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_email ON users (email) WHERE document_status = 'Active';
+CREATE INDEX IF NOT EXISTS idx_users_reset_password_token ON users (reset_password_token);
+
+CREATE TABLE IF NOT EXISTS organisations (
+    uid TEXT PRIMARY KEY,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+
+CREATE TABLE IF NOT EXISTS organisation_members (
+    uid TEXT PRIMARY KEY,
+    organisation_id TEXT,
+    user_id TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organisation_members_organisation_id_user_id ON organisation_members (organisation_id, user_id) WHERE document_status = 'Active';
+CREATE INDEX IF NOT EXISTS idx_organisation_members_user_id ON organisation_members (user_id);
+
+CREATE TABLE IF NOT EXISTS organisation_invites (
+    uid TEXT PRIMARY KEY,
+    organisation_id TEXT,
+    invitee_email TEXT,
+    token TEXT,
+    status TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organisation_invites_token ON organisation_invites (token);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_organisation_invites_organisation_id_invitee_email ON organisation_invites (organisation_id, invitee_email) WHERE document_status = 'Active';
+
+CREATE TABLE IF NOT EXISTS groups (
+    uid TEXT PRIMARY KEY,
+    organisation_id TEXT,
+    name TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_groups_organisation_id_name ON groups (organisation_id, name) WHERE document_status = 'Active';
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    uid TEXT PRIMARY KEY,
+    mask_id TEXT,
+    hash TEXT,
+    key_type TEXT,
+    role_group TEXT,
+    role_app TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_api_keys_mask_id ON api_keys (mask_id);
+CREATE INDEX IF NOT EXISTS idx_api_keys_hash ON api_keys (hash);
+CREATE INDEX IF NOT EXISTS idx_api_keys_role_group ON api_keys (role_group);
+
+CREATE TABLE IF NOT EXISTS applications (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    title TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_applications_group_id_title ON applications (group_id, title) WHERE document_status = 'Active';
+CREATE INDEX IF NOT EXISTS idx_applications_group_id_created_at ON applications (group_id, created_at);
+
+CREATE TABLE IF NOT EXISTS sources (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    mask_id TEXT,
+    type TEXT,
+    provider TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sources_mask_id ON sources (mask_id);
+CREATE INDEX IF NOT EXISTS idx_sources_group_id ON sources (group_id);
+
+CREATE TABLE IF NOT EXISTS subscriptions (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    app_id TEXT,
+    source_id TEXT,
+    endpoint_id TEXT,
+    device_id TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_group_id_app_id ON subscriptions (group_id, app_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_group_id_source_id ON subscriptions (group_id, source_id);
+CREATE INDEX IF NOT EXISTS idx_subscriptions_device_id ON subscriptions (device_id);
+
+CREATE TABLE IF NOT EXISTS devices (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    app_id TEXT,
+    host_name TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_devices_group_id_app_id_host_name ON devices (group_id, app_id, host_name) WHERE document_status = 'Active';
+
+CREATE TABLE IF NOT EXISTS events (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    app_id TEXT,
+    source_id TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_events_group_id_created_at ON events (group_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_app_id_created_at ON events (app_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_events_source_id ON events (source_id);
+
+CREATE TABLE IF NOT EXISTS event_deliveries (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    app_id TEXT,
+    event_id TEXT,
+    device_id TEXT,
+    status TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_group_id_created_at ON event_deliveries (group_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_app_id_created_at ON event_deliveries (app_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_event_id ON event_deliveries (event_id);
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_status ON event_deliveries (status);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_event_deliveries_subscription_id_ordering_key;
+ALTER TABLE event_deliveries DROP COLUMN IF EXISTS ordering_key;
+ALTER TABLE event_deliveries DROP COLUMN IF EXISTS subscription_id;
@@ -0,0 +1,9 @@
+-- Ordered subscriptions look up the oldest pending delivery with the same
+-- ordering key before sending one.
+
+ALTER TABLE event_deliveries ADD COLUMN IF NOT EXISTS subscription_id TEXT;
+ALTER TABLE event_deliveries ADD COLUMN IF NOT EXISTS ordering_key TEXT;
+
+UPDATE event_deliveries SET subscription_id = data->>'subscription_id', ordering_key = data->>'ordering_key';
+
+CREATE INDEX IF NOT EXISTS idx_event_deliveries_subscription_id_ordering_key ON event_deliveries (subscription_id, ordering_key, created_at);
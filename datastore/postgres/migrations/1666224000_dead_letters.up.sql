@@ -0,0 +1,18 @@
+-- Event deliveries that failed for good are recorded here so they can be
+-- inspected and redriven.
+
+CREATE TABLE IF NOT EXISTS dead_letters (
+    uid TEXT PRIMARY KEY,
+    group_id TEXT,
+    app_id TEXT,
+    endpoint_id TEXT,
+    subscription_id TEXT,
+    event_delivery_id TEXT,
+    status TEXT,
+    document_status TEXT NOT NULL,
+    created_at TIMESTAMPTZ,
+    data JSONB NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_group_id_created_at ON dead_letters (group_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_event_delivery_id ON dead_letters (event_delivery_id);
+CREATE INDEX IF NOT EXISTS idx_dead_letters_status ON dead_letters (status);
@@ -0,0 +1,62 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type orgRepo struct {
+	db *sql.DB
+}
+
+func NewOrgRepo(db *sql.DB) datastore.OrganisationRepository {
+	return &orgRepo{
+		db: db,
+	}
+}
+
+func (o *orgRepo) CreateOrganisation(ctx context.Context, org *datastore.Organisation) error {
+	org.ID = primitive.NewObjectID()
+	return insert(ctx, o.db, organisationsTable, org)
+}
+
+func (o *orgRepo) LoadOrganisationsPaged(ctx context.Context, pageable datastore.Pageable) ([]datastore.Organisation, datastore.PaginationData, error) {
+	var organisations []datastore.Organisation
+
+	pagination, err := findPaged(ctx, o.db, organisationsTable, newWhere(), pageable, &organisations)
+	if err != nil {
+		return organisations, datastore.PaginationData{}, err
+	}
+
+	return organisations, pagination, nil
+}
+
+func (o *orgRepo) UpdateOrganisation(ctx context.Context, org *datastore.Organisation) error {
+	org.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"name":       org.Name,
+		"updated_at": org.UpdatedAt,
+	}
+
+	return update(ctx, o.db, organisationsTable, newWhere().eq("uid", org.UID), set, nil)
+}
+
+func (o *orgRepo) DeleteOrganisation(ctx context.Context, uid string) error {
+	return softDelete(ctx, o.db, organisationsTable, newWhere().eq("uid", uid))
+}
+
+func (o *orgRepo) FetchOrganisationByID(ctx context.Context, id string) (*datastore.Organisation, error) {
+	org := new(datastore.Organisation)
+
+	err := findOne(ctx, o.db, organisationsTable, newWhere().eq("uid", id), org)
+	if isNoRows(err) {
+		err = datastore.ErrOrgNotFound
+	}
+
+	return org, err
+}
@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type orgInviteRepo struct {
+	db *sql.DB
+}
+
+func NewOrgInviteRepo(db *sql.DB) datastore.OrganisationInviteRepository {
+	return &orgInviteRepo{
+		db: db,
+	}
+}
+
+func (o *orgInviteRepo) CreateOrganisationInvite(ctx context.Context, iv *datastore.OrganisationInvite) error {
+	iv.ID = primitive.NewObjectID()
+	return insert(ctx, o.db, organisationInvitesTable, iv)
+}
+
+func (o *orgInviteRepo) LoadOrganisationsInvitesPaged(ctx context.Context, orgID string, inviteStatus datastore.InviteStatus, pageable datastore.Pageable) ([]datastore.OrganisationInvite, datastore.PaginationData, error) {
+	filter := newWhere()
+
+	if !util.IsStringEmpty(orgID) {
+		filter.eq("organisation_id", orgID)
+	}
+
+	if !util.IsStringEmpty(inviteStatus.String()) {
+		filter.eq("status", inviteStatus)
+	}
+
+	var invitations []datastore.OrganisationInvite
+	pagination, err := findPaged(ctx, o.db, organisationInvitesTable, filter, pageable, &invitations)
+	if err != nil {
+		return invitations, datastore.PaginationData{}, err
+	}
+
+	return invitations, pagination, nil
+}
+
+func (o *orgInviteRepo) UpdateOrganisationInvite(ctx context.Context, iv *datastore.OrganisationInvite) error {
+	iv.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"role":            iv.Role,
+		"status":          iv.Status,
+		"updated_at":      iv.UpdatedAt,
+		"expires_at":      iv.ExpiresAt,
+		"document_status": iv.DocumentStatus,
+	}
+
+	return update(ctx, o.db, organisationInvitesTable, newWhere().eq("uid", iv.UID), set, nil)
+}
+
+func (o *orgInviteRepo) DeleteOrganisationInvite(ctx context.Context, uid string) error {
+	return softDelete(ctx, o.db, organisationInvitesTable, newWhere().eq("uid", uid))
+}
+
+func (o *orgInviteRepo) FetchOrganisationInviteByID(ctx context.Context, id string) (*datastore.OrganisationInvite, error) {
+	iv := &datastore.OrganisationInvite{}
+
+	err := findOne(ctx, o.db, organisationInvitesTable, newWhere().eq("uid", id), iv)
+	if isNoRows(err) {
+		err = datastore.ErrOrgInviteNotFound
+	}
+
+	return iv, err
+}
+
+func (o *orgInviteRepo) FetchOrganisationInviteByToken(ctx context.Context, token string) (*datastore.OrganisationInvite, error) {
+	iv := &datastore.OrganisationInvite{}
+
+	err := findOne(ctx, o.db, organisationInvitesTable, newWhere().eq("token", token), iv)
+	if isNoRows(err) {
+		err = datastore.ErrOrgInviteNotFound
+	}
+
+	return iv, err
+}
@@ -0,0 +1,153 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type orgMemberRepo struct {
+	db *sql.DB
+}
+
+func NewOrgMemberRepo(db *sql.DB) datastore.OrganisationMemberRepository {
+	return &orgMemberRepo{
+		db: db,
+	}
+}
+
+func (o *orgMemberRepo) CreateOrganisationMember(ctx context.Context, member *datastore.OrganisationMember) error {
+	member.ID = primitive.NewObjectID()
+	return insert(ctx, o.db, organisationMembersTable, member)
+}
+
+func (o *orgMemberRepo) LoadOrganisationMembersPaged(ctx context.Context, organisationID string, pageable datastore.Pageable) ([]*datastore.OrganisationMember, datastore.PaginationData, error) {
+	filter := newWhere()
+	if !util.IsStringEmpty(organisationID) {
+		filter.eq("organisation_id", organisationID)
+	}
+
+	var members []*datastore.OrganisationMember
+	pagination, err := findPaged(ctx, o.db, organisationMembersTable, filter, pageable, &members)
+	if err != nil {
+		return members, datastore.PaginationData{}, err
+	}
+
+	err = o.fillOrgMemberUserMetadata(ctx, members)
+	if err != nil {
+		return members, datastore.PaginationData{}, err
+	}
+
+	return members, pagination, nil
+}
+
+func (o *orgMemberRepo) LoadUserOrganisationsPaged(ctx context.Context, userID string, pageable datastore.Pageable) ([]datastore.Organisation, datastore.PaginationData, error) {
+	from := fmt.Sprintf(`FROM %s m JOIN %s o ON o.uid = m.organisation_id
+		WHERE m.user_id = $1 AND m.document_status = $2 AND o.document_status = $2`,
+		organisationMembersTable.name, organisationsTable.name)
+	args := []interface{}{userID, datastore.ActiveDocumentStatus}
+
+	var total int64
+	err := o.db.QueryRowContext(ctx, "SELECT COUNT(*) "+from, args...).Scan(&total)
+	if err != nil {
+		log.WithError(err).Error("failed to count user organisations")
+		return nil, datastore.PaginationData{}, err
+	}
+
+	query := fmt.Sprintf("SELECT o.data %s ORDER BY m.created_at DESC, m.uid ASC LIMIT %d OFFSET %d",
+		from, pageable.Limit(), pageable.Offset())
+
+	organisations := make([]datastore.Organisation, 0)
+	err = selectDocuments(ctx, o.db, query, args, &organisations)
+	if err != nil {
+		log.WithError(err).Error("failed to load user organisations")
+		return nil, datastore.PaginationData{}, err
+	}
+
+	return organisations, datastore.NewPaginationData(total, pageable), nil
+}
+
+func (o *orgMemberRepo) UpdateOrganisationMember(ctx context.Context, member *datastore.OrganisationMember) error {
+	member.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	set := bson.M{
+		"role":       member.Role,
+		"updated_at": member.UpdatedAt,
+	}
+
+	return update(ctx, o.db, organisationMembersTable, newWhere().eq("uid", member.UID), set, nil)
+}
+
+func (o *orgMemberRepo) DeleteOrganisationMember(ctx context.Context, uid, orgID string) error {
+	filter := newWhere().eq("uid", uid).eq("organisation_id", orgID)
+	return softDelete(ctx, o.db, organisationMembersTable, filter)
+}
+
+func (o *orgMemberRepo) FetchOrganisationMemberByID(ctx context.Context, uid, orgID string) (*datastore.OrganisationMember, error) {
+	filter := newWhere().eq("uid", uid).eq("organisation_id", orgID)
+	return o.fetchOrganisationMember(ctx, filter)
+}
+
+func (o *orgMemberRepo) FetchOrganisationMemberByUserID(ctx context.Context, userID, orgID string) (*datastore.OrganisationMember, error) {
+	filter := newWhere().eq("user_id", userID).eq("organisation_id", orgID)
+	return o.fetchOrganisationMember(ctx, filter)
+}
+
+func (o *orgMemberRepo) fetchOrganisationMember(ctx context.Context, filter *where) (*datastore.OrganisationMember, error) {
+	member := new(datastore.OrganisationMember)
+
+	err := findOne(ctx, o.db, organisationMembersTable, filter, member)
+	if err != nil {
+		if isNoRows(err) {
+			return nil, datastore.ErrOrgMemberNotFound
+		}
+		return nil, err
+	}
+
+	err = o.fillOrgMemberUserMetadata(ctx, []*datastore.OrganisationMember{member})
+	return member, err
+}
+
+func (o *orgMemberRepo) fillOrgMemberUserMetadata(ctx context.Context, members []*datastore.OrganisationMember) error {
+	userIDs := make([]string, 0, len(members))
+	for i := range members {
+		userIDs = append(userIDs, members[i].UserID)
+	}
+
+	query := fmt.Sprintf(`SELECT uid, COALESCE(data->>'first_name', ''), COALESCE(data->>'last_name', ''), COALESCE(email, '')
+		FROM %s WHERE uid = ANY($1)`, usersTable.name)
+
+	rows, err := o.db.QueryContext(ctx, query, pq.Array(userIDs))
+	if err != nil {
+		log.WithError(err).Error("failed to load user metadata for organisation members")
+		return err
+	}
+	defer rows.Close()
+
+	metaMap := map[string]*datastore.UserMetadata{}
+	for rows.Next() {
+		m := &datastore.UserMetadata{}
+		err = rows.Scan(&m.UserID, &m.FirstName, &m.LastName, &m.Email)
+		if err != nil {
+			return err
+		}
+		metaMap[m.UserID] = m
+	}
+
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for i := range members {
+		members[i].UserMetadata = metaMap[members[i].UserID]
+	}
+
+	return nil
+}
@@ -0,0 +1,106 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	_ "github.com/lib/pq"
+)
+
+var _ datastore.Database = &Client{}
+
+type Client struct {
+	db *sql.DB
+}
+
+func New(cfg config.Configuration) (*Client, error) {
+	db, err := sql.Open("postgres", cfg.Database.Dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+
+	return &Client{db: db}, nil
+}
+
+func (c *Client) Disconnect(ctx context.Context) error {
+	return c.db.Close()
+}
+
+func (c *Client) GetName() string {
+	return "postgres"
+}
+
+func (c *Client) Client() interface{} {
+	return c.db
+}
+
+func (c *Client) Database() *sql.DB {
+	return c.db
+}
+
+func (c *Client) APIKeyRepo() datastore.APIKeyRepository {
+	return NewApiKeyRepo(c.db)
+}
+
+func (c *Client) AppRepo() datastore.ApplicationRepository {
+	return NewApplicationRepo(c.db)
+}
+
+func (c *Client) ConfigRepo() datastore.ConfigurationRepository {
+	return NewConfigRepo(c.db)
+}
+
+func (c *Client) DeadLetterRepo() datastore.DeadLetterRepository {
+	return NewDeadLetterRepository(c.db)
+}
+
+func (c *Client) DeviceRepo() datastore.DeviceRepository {
+	return NewDeviceRepository(c.db)
+}
+
+func (c *Client) EventRepo() datastore.EventRepository {
+	return NewEventRepository(c.db)
+}
+
+func (c *Client) EventDeliveryRepo() datastore.EventDeliveryRepository {
+	return NewEventDeliveryRepository(c.db)
+}
+
+func (c *Client) GroupRepo() datastore.GroupRepository {
+	return NewGroupRepo(c.db)
+}
+
+func (c *Client) OrgRepo() datastore.OrganisationRepository {
+	return NewOrgRepo(c.db)
+}
+
+func (c *Client) OrgInviteRepo() datastore.OrganisationInviteRepository {
+	return NewOrgInviteRepo(c.db)
+}
+
+func (c *Client) OrgMemberRepo() datastore.OrganisationMemberRepository {
+	return NewOrgMemberRepo(c.db)
+}
+
+func (c *Client) SourceRepo() datastore.SourceRepository {
+	return NewSourceRepo(c.db)
+}
+
+func (c *Client) SubRepo() datastore.SubscriptionRepository {
+	return NewSubscriptionRepo(c.db)
+}
+
+func (c *Client) UserRepo() datastore.UserRepository {
+	return NewUserRepo(c.db)
+}
@@ -0,0 +1,63 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/stretchr/testify/require"
+)
+
+func getDSN() string {
+	return os.Getenv("TEST_POSTGRES_DSN")
+}
+
+func getConfig() config.Configuration {
+	return config.Configuration{
+		Database: config.DatabaseConfiguration{
+			Type: config.PostgresDatabaseProvider,
+			Dsn:  getDSN(),
+		},
+	}
+}
+
+func getDB(t *testing.T) (*sql.DB, func()) {
+	db, err := New(getConfig())
+	require.NoError(t, err)
+
+	m, err := NewMigrator(db)
+	require.NoError(t, err)
+	require.NoError(t, m.Migrate(context.Background()))
+
+	return db.Database(), func() {
+		for i := len(m.migrations) - 1; i >= 0; i-- {
+			_, err := db.Database().Exec(m.migrations[i].Down)
+			require.NoError(t, err)
+		}
+
+		_, err := db.Database().Exec(fmt.Sprintf("DROP TABLE %s", migrationsTable))
+		require.NoError(t, err)
+		require.NoError(t, db.Disconnect(context.Background()))
+	}
+}
+
+func Test_Migrator(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	m, err := NewMigrator(&Client{db: db})
+	require.NoError(t, err)
+
+	pending, err := m.HasPendingMigrations(context.Background())
+	require.NoError(t, err)
+	require.False(t, pending)
+
+	// migrating again is a no-op
+	require.NoError(t, m.Migrate(context.Background()))
+}
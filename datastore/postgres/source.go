@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type sourceRepo struct {
+	db *sql.DB
+}
+
+func NewSourceRepo(db *sql.DB) datastore.SourceRepository {
+	return &sourceRepo{
+		db: db,
+	}
+}
+
+func (s *sourceRepo) CreateSource(ctx context.Context, source *datastore.Source) error {
+	source.ID = primitive.NewObjectID()
+	return insert(ctx, s.db, sourcesTable, source)
+}
+
+func (s *sourceRepo) UpdateSource(ctx context.Context, groupID string, source *datastore.Source) error {
+	filter := newWhere().eq("uid", source.UID).eq("group_id", groupID).active()
+
+	set := bson.M{
+		"name":             source.Name,
+		"type":             source.Type,
+		"is_disabled":      source.IsDisabled,
+		"verifier":         source.Verifier,
+		"updated_at":       primitive.NewDateTimeFromTime(time.Now()),
+		"provider_config":  source.ProviderConfig,
+		"pub_sub":          source.PubSub,
+		"db_change_stream": source.DBChangeStream,
+		"rest_api":         source.RestApi,
+	}
+
+	return update(ctx, s.db, sourcesTable, filter, set, nil)
+}
+
+// UpdateSourceRestApiState saves state without touching the source's
+// updated_at, an update restarts the source's poller.
+func (s *sourceRepo) UpdateSourceRestApiState(ctx context.Context, groupID string, id string, state *datastore.RestApiState) error {
+	filter := newWhere().eq("uid", id).eq("group_id", groupID).active()
+	return update(ctx, s.db, sourcesTable, filter, bson.M{"rest_api_state": state}, nil)
+}
+
+func (s *sourceRepo) FindSourceByID(ctx context.Context, groupID string, id string) (*datastore.Source, error) {
+	source := &datastore.Source{}
+
+	err := findOne(ctx, s.db, sourcesTable, newWhere().eq("uid", id).eq("group_id", groupID), source)
+	if isNoRows(err) {
+		return source, datastore.ErrSourceNotFound
+	}
+
+	return source, err
+}
+
+func (s *sourceRepo) FindSourceByMaskID(ctx context.Context, maskID string) (*datastore.Source, error) {
+	source := &datastore.Source{}
+
+	err := findOne(ctx, s.db, sourcesTable, newWhere().eq("mask_id", maskID), source)
+	if isNoRows(err) {
+		return source, datastore.ErrSourceNotFound
+	}
+
+	return source, err
+}
+
+func (s *sourceRepo) DeleteSourceByID(ctx context.Context, groupID string, id string) error {
+	return withTx(ctx, s.db, func(tx *sql.Tx) error {
+		err := softDelete(ctx, tx, sourcesTable, newWhere().eq("uid", id).eq("group_id", groupID))
+		if err != nil {
+			return err
+		}
+
+		return softDelete(ctx, tx, subscriptionsTable, newWhere().eq("source_id", id))
+	})
+}
+
+func (s *sourceRepo) LoadSourcesPaged(ctx context.Context, groupID string, f *datastore.SourceFilter, pageable datastore.Pageable) ([]datastore.Source, datastore.PaginationData, error) {
+	filter := newWhere()
+
+	if !util.IsStringEmpty(groupID) {
+		filter.eq("group_id", groupID)
+	}
+
+	if !util.IsStringEmpty(f.Type) {
+		filter.eq("type", f.Type)
+	}
+
+	if !util.IsStringEmpty(f.Provider) {
+		filter.eq("provider", f.Provider)
+	}
+
+	sources := make([]datastore.Source, 0)
+	pagination, err := findPaged(ctx, s.db, sourcesTable, filter, pageable, &sources)
+	if err != nil {
+		return sources, datastore.PaginationData{}, err
+	}
+
+	return sources, pagination, nil
+}
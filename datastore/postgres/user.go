@@ -0,0 +1,89 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const userEmailIndex = "idx_users_email"
+
+type userRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) datastore.UserRepository {
+	return &userRepo{
+		db: db,
+	}
+}
+
+func (u *userRepo) CreateUser(ctx context.Context, user *datastore.User) error {
+	user.ID = primitive.NewObjectID()
+	user.ResetPasswordToken = uuid.NewString()
+
+	err := insert(ctx, u.db, usersTable, user)
+	if isUniqueViolation(err, userEmailIndex) {
+		return datastore.ErrDuplicateEmail
+	}
+
+	return err
+}
+
+func (u *userRepo) FindUserByEmail(ctx context.Context, email string) (*datastore.User, error) {
+	return u.findUser(ctx, newWhere().eq("email", email))
+}
+
+func (u *userRepo) FindUserByID(ctx context.Context, id string) (*datastore.User, error) {
+	return u.findUser(ctx, newWhere().eq("uid", id))
+}
+
+func (u *userRepo) FindUserByToken(ctx context.Context, token string) (*datastore.User, error) {
+	return u.findUser(ctx, newWhere().eq("reset_password_token", token))
+}
+
+func (u *userRepo) LoadUsersPaged(ctx context.Context, pageable datastore.Pageable) ([]datastore.User, datastore.PaginationData, error) {
+	users := make([]datastore.User, 0)
+
+	pagination, err := findPaged(ctx, u.db, usersTable, newWhere(), pageable, &users)
+	if err != nil {
+		return users, datastore.PaginationData{}, err
+	}
+
+	return users, pagination, nil
+}
+
+func (u *userRepo) UpdateUser(ctx context.Context, user *datastore.User) error {
+	set := bson.M{
+		"first_name":                user.FirstName,
+		"last_name":                 user.LastName,
+		"email":                     user.Email,
+		"password":                  user.Password,
+		"updated_at":                primitive.NewDateTimeFromTime(time.Now()),
+		"reset_password_token":      user.ResetPasswordToken,
+		"reset_password_expires_at": user.ResetPasswordExpiresAt,
+	}
+
+	err := update(ctx, u.db, usersTable, newWhere().eq("uid", user.UID), set, nil)
+	if isUniqueViolation(err, userEmailIndex) {
+		return datastore.ErrDuplicateEmail
+	}
+
+	return err
+}
+
+func (u *userRepo) findUser(ctx context.Context, filter *where) (*datastore.User, error) {
+	user := &datastore.User{}
+
+	err := findOne(ctx, u.db, usersTable, filter, user)
+	if isNoRows(err) {
+		return user, datastore.ErrUserNotFound
+	}
+
+	return user, err
+}
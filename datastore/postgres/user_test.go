@@ -0,0 +1,47 @@
+//go:build integration
+// +build integration
+
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_CreateUser(t *testing.T) {
+	db, closeFn := getDB(t)
+	defer closeFn()
+
+	userRepo := NewUserRepo(db)
+
+	user := &datastore.User{
+		UID:            uuid.NewString(),
+		FirstName:      "test",
+		LastName:       "test",
+		Email:          "test@test.com",
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.NoError(t, userRepo.CreateUser(context.Background(), user))
+
+	dbUser, err := userRepo.FindUserByEmail(context.Background(), user.Email)
+	require.NoError(t, err)
+	require.Equal(t, user.UID, dbUser.UID)
+
+	dbUser, err = userRepo.FindUserByToken(context.Background(), user.ResetPasswordToken)
+	require.NoError(t, err)
+	require.Equal(t, user.UID, dbUser.UID)
+
+	duplicate := &datastore.User{
+		UID:            uuid.NewString(),
+		Email:          user.Email,
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+	require.Equal(t, datastore.ErrDuplicateEmail, userRepo.CreateUser(context.Background(), duplicate))
+
+	_, err = userRepo.FindUserByID(context.Background(), uuid.NewString())
+	require.Equal(t, datastore.ErrUserNotFound, err)
+}
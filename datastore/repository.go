@@ -2,6 +2,8 @@ package datastore
 
 import (
 	"context"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type APIKeyRepository interface {
@@ -22,12 +24,27 @@ type EventDeliveryRepository interface {
 	CountDeliveriesByStatus(context.Context, EventDeliveryStatus, SearchParams) (int64, error)
 	UpdateStatusOfEventDelivery(context.Context, EventDelivery, EventDeliveryStatus) error
 	UpdateStatusOfEventDeliveries(context.Context, []string, EventDeliveryStatus) error
+	UpdateExpiryOfEventDelivery(ctx context.Context, delivery EventDelivery, expiresAt primitive.DateTime) error
 	FindDiscardedEventDeliveries(ctx context.Context, appId, deviceId string, searchParams SearchParams) ([]EventDelivery, error)
+	FindFirstPendingEventDelivery(ctx context.Context, subscriptionID, orderingKey string) (*EventDelivery, error)
+	FindDueEventDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, status []EventDeliveryStatus, limit int) ([]EventDelivery, error)
+	ClaimEventDeliveries(ctx context.Context, ids []string, status []EventDeliveryStatus) ([]string, error)
 
 	UpdateEventDeliveryWithAttempt(context.Context, EventDelivery, DeliveryAttempt) error
 	CountEventDeliveries(context.Context, string, string, string, []EventDeliveryStatus, SearchParams) (int64, error)
 	DeleteGroupEventDeliveries(ctx context.Context, filter *EventDeliveryFilter, hardDelete bool) error
 	LoadEventDeliveriesPaged(context.Context, string, string, string, []EventDeliveryStatus, SearchParams, Pageable) ([]EventDelivery, PaginationData, error)
+	LoadUpcomingEventDeliveriesPaged(ctx context.Context, groupID, appID string, pageable Pageable) ([]EventDelivery, PaginationData, error)
+}
+
+type DeadLetterRepository interface {
+	CreateDeadLetter(context.Context, *DeadLetter) error
+	FindDeadLetterByID(ctx context.Context, groupID, id string) (*DeadLetter, error)
+	FindDeadLettersByIDs(ctx context.Context, groupID string, ids []string) ([]DeadLetter, error)
+	LoadDeadLettersPaged(context.Context, *DeadLetterFilter, Pageable) ([]DeadLetter, PaginationData, error)
+	CountDeadLetters(context.Context, *DeadLetterFilter) (int64, error)
+	CountDeadLettersByGroup(ctx context.Context, status DeadLetterStatus) (map[string]int64, error)
+	UpdateStatusOfDeadLetters(ctx context.Context, ids []string, status DeadLetterStatus) error
 }
 
 type EventRepository interface {
@@ -107,6 +124,7 @@ type SubscriptionRepository interface {
 type SourceRepository interface {
 	CreateSource(context.Context, *Source) error
 	UpdateSource(ctx context.Context, groupID string, source *Source) error
+	UpdateSourceRestApiState(ctx context.Context, groupID string, id string, state *RestApiState) error
 	FindSourceByID(ctx context.Context, groupID string, id string) (*Source, error)
 	FindSourceByMaskID(ctx context.Context, maskID string) (*Source, error)
 	DeleteSourceByID(ctx context.Context, groupID string, id string) error
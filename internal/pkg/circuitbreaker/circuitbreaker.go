@@ -0,0 +1,288 @@
+// Package circuitbreaker pauses deliveries to endpoints that keep failing.
+//
+// Each endpoint has a breaker that starts closed. It opens when too many
+// deliveries in a row fail, or when too many of the deliveries in a window
+// fail. While it is open no deliveries are attempted. Once it has been open
+// for a while it becomes half open and lets a single probe delivery through
+// at a time, the breaker closes when enough probes succeed and opens again
+// when one fails.
+//
+// The breakers are kept in the cache so every worker shares them. Updates
+// aren't atomic, so concurrent deliveries to the same endpoint can lose a
+// count, which only makes a breaker a little slower to trip. The probe of a
+// half open breaker is claimed atomically, so only one worker sends it.
+package circuitbreaker
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+)
+
+type State string
+
+const (
+	Closed   State = "closed"
+	Open     State = "open"
+	HalfOpen State = "half-open"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// breakerTTL is how long a breaker is kept after it was last updated, an
+// endpoint that gets no deliveries for this long starts over closed.
+const breakerTTL = 24 * time.Hour
+
+type Config struct {
+	FailureThreshold uint64
+	FailureRate      uint64
+	MinimumRequests  uint64
+	Window           time.Duration
+	OpenDuration     time.Duration
+	SuccessThreshold uint64
+}
+
+var DefaultConfig = Config{
+	FailureThreshold: 10,
+	FailureRate:      50,
+	MinimumRequests:  20,
+	Window:           time.Minute,
+	OpenDuration:     30 * time.Second,
+	SuccessThreshold: 1,
+}
+
+// NewConfig returns the breaker config for a group's circuit breaker
+// configuration, unset fields take their default.
+func NewConfig(c *datastore.CircuitBreakerConfiguration) Config {
+	cfg := DefaultConfig
+	if c.FailureThreshold > 0 {
+		cfg.FailureThreshold = c.FailureThreshold
+	}
+
+	if c.FailureRate > 0 {
+		cfg.FailureRate = c.FailureRate
+	}
+
+	if c.MinimumRequests > 0 {
+		cfg.MinimumRequests = c.MinimumRequests
+	}
+
+	if c.Window > 0 {
+		cfg.Window = time.Duration(c.Window) * time.Second
+	}
+
+	if c.OpenDuration > 0 {
+		cfg.OpenDuration = time.Duration(c.OpenDuration) * time.Second
+	}
+
+	if c.SuccessThreshold > 0 {
+		cfg.SuccessThreshold = c.SuccessThreshold
+	}
+
+	return cfg
+}
+
+// Breaker is an endpoint's circuit breaker.
+type Breaker struct {
+	State               State     `json:"state"`
+	Requests            uint64    `json:"requests"`
+	Failures            uint64    `json:"failures"`
+	ConsecutiveFailures uint64    `json:"consecutive_failures"`
+	Successes           uint64    `json:"successes"`
+	WindowStart         time.Time `json:"window_start"`
+	OpenedAt            time.Time `json:"opened_at"`
+}
+
+// Status returns the breaker's state as it is shown on its endpoint.
+func (b *Breaker) Status() *datastore.CircuitBreakerStatus {
+	s := &datastore.CircuitBreakerStatus{
+		State:               string(b.State),
+		Requests:            b.Requests,
+		Failures:            b.Failures,
+		ConsecutiveFailures: b.ConsecutiveFailures,
+	}
+
+	if b.State != Closed {
+		openedAt := b.OpenedAt
+		s.OpenedAt = &openedAt
+	}
+
+	return s
+}
+
+// Key returns the cache key of an endpoint's breaker.
+func Key(endpointID string) string {
+	return convoy.CircuitBreakerCacheKey.Get(endpointID).String()
+}
+
+// probeKey is the cache key of the probe of the breaker stored under key.
+func probeKey(key string) string {
+	return key + ":probe"
+}
+
+// probe is a claimed probe, it is released when its outcome is recorded or
+// when Until passes.
+type probe struct {
+	Until time.Time `json:"until"`
+}
+
+// Manager loads and updates breakers in the cache.
+type Manager struct {
+	cache cache.Cache
+	cfg   Config
+	now   func() time.Time
+
+	// OnStateChange, when set, is called after a breaker changes state.
+	OnStateChange func(key string, from, to State)
+}
+
+func NewManager(c cache.Cache, cfg Config) *Manager {
+	return &Manager{cache: c, cfg: cfg, now: time.Now}
+}
+
+// Get returns the breaker stored under key, a breaker that isn't stored is
+// closed. An open breaker whose open duration has passed is half open.
+func (m *Manager) Get(ctx context.Context, key string) (*Breaker, error) {
+	b, _, err := m.load(ctx, key)
+	return b, err
+}
+
+// load returns the breaker stored under key and the state it was stored in.
+func (m *Manager) load(ctx context.Context, key string) (*Breaker, State, error) {
+	b := &Breaker{}
+	if err := m.cache.Get(ctx, key, b); err != nil {
+		return nil, "", err
+	}
+
+	if b.State == "" {
+		b.State = Closed
+	}
+
+	stored := b.State
+	if b.State == Open && !m.now().Before(b.OpenedAt.Add(m.cfg.OpenDuration)) {
+		b.State = HalfOpen
+	}
+
+	return b, stored, nil
+}
+
+// Allow reports whether a delivery can be attempted and, when it can't,
+// how long to wait before trying again. A half open breaker allows one probe
+// at a time, the probe has until the open duration passes to be recorded.
+func (m *Manager) Allow(ctx context.Context, key string) (bool, time.Duration, error) {
+	b, stored, err := m.load(ctx, key)
+	if err != nil {
+		return false, 0, err
+	}
+
+	now := m.now()
+	switch b.State {
+	case Open:
+		return false, b.OpenedAt.Add(m.cfg.OpenDuration).Sub(now), nil
+	case HalfOpen:
+		p := &probe{Until: now.Add(m.cfg.OpenDuration)}
+		claimed, err := m.cache.SetNX(ctx, probeKey(key), p, m.cfg.OpenDuration)
+		if err != nil {
+			return false, 0, err
+		}
+
+		if !claimed {
+			claimedProbe := &probe{}
+			if err = m.cache.Get(ctx, probeKey(key), claimedProbe); err != nil {
+				return false, 0, err
+			}
+
+			wait := claimedProbe.Until.Sub(now)
+			if wait < 0 {
+				// the probe was released after it was claimed
+				wait = 0
+			}
+
+			return false, wait, nil
+		}
+
+		// only the worker that claimed the probe stores the state change
+		if stored != HalfOpen {
+			if err = m.save(ctx, key, b, stored); err != nil {
+				return false, 0, err
+			}
+		}
+	}
+
+	return true, 0, nil
+}
+
+// Record records the outcome of a delivery attempt and returns the breaker.
+func (m *Manager) Record(ctx context.Context, key string, success bool) (*Breaker, error) {
+	b, stored, err := m.load(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	if b.State == Open {
+		// the breaker opened while this delivery was in flight
+		return b, nil
+	}
+
+	now := m.now()
+	if b.State == HalfOpen {
+		// the probe is over, the next one can be claimed
+		if err = m.cache.Delete(ctx, probeKey(key)); err != nil {
+			return nil, err
+		}
+
+		if !success {
+			m.open(b, now)
+		} else if b.Successes++; b.Successes >= m.cfg.SuccessThreshold {
+			m.close(b, now)
+		}
+
+		return b, m.save(ctx, key, b, stored)
+	}
+
+	if now.Sub(b.WindowStart) >= m.cfg.Window {
+		b.Requests, b.Failures, b.WindowStart = 0, 0, now
+	}
+
+	b.Requests++
+	if success {
+		b.ConsecutiveFailures = 0
+	} else {
+		b.Failures++
+		b.ConsecutiveFailures++
+	}
+
+	if b.ConsecutiveFailures >= m.cfg.FailureThreshold ||
+		(b.Requests >= m.cfg.MinimumRequests && b.Failures*100 >= m.cfg.FailureRate*b.Requests) {
+		m.open(b, now)
+	}
+
+	return b, m.save(ctx, key, b, stored)
+}
+
+func (m *Manager) open(b *Breaker, now time.Time) {
+	b.State = Open
+	b.OpenedAt = now
+	b.Successes = 0
+}
+
+func (m *Manager) close(b *Breaker, now time.Time) {
+	*b = Breaker{State: Closed, WindowStart: now}
+}
+
+// save stores b, from is the state it was stored in.
+func (m *Manager) save(ctx context.Context, key string, b *Breaker, from State) error {
+	if err := m.cache.Set(ctx, key, b, breakerTTL); err != nil {
+		return err
+	}
+
+	if m.OnStateChange != nil && from != b.State {
+		m.OnStateChange(key, from, b.State)
+	}
+
+	return nil
+}
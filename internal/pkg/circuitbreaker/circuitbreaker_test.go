@@ -0,0 +1,188 @@
+package circuitbreaker
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcache "github.com/frain-dev/convoy/cache/memory"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+type transition struct {
+	from, to State
+}
+
+func newTestManager(cfg Config) (*Manager, *time.Time, *[]transition) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+	transitions := &[]transition{}
+
+	m := NewManager(mcache.NewMemoryCache(), cfg)
+	m.now = func() time.Time { return now }
+	m.OnStateChange = func(_ string, from, to State) {
+		*transitions = append(*transitions, transition{from, to})
+	}
+
+	return m, &now, transitions
+}
+
+func record(t *testing.T, m *Manager, success bool, n int) *Breaker {
+	var b *Breaker
+	var err error
+	for i := 0; i < n; i++ {
+		b, err = m.Record(context.Background(), "endpoint", success)
+		require.NoError(t, err)
+	}
+
+	return b
+}
+
+func TestNewConfig(t *testing.T) {
+	require.Equal(t, DefaultConfig, NewConfig(&datastore.CircuitBreakerConfiguration{}))
+
+	cfg := NewConfig(&datastore.CircuitBreakerConfiguration{FailureRate: 25, OpenDuration: 120})
+	require.Equal(t, uint64(25), cfg.FailureRate)
+	require.Equal(t, 2*time.Minute, cfg.OpenDuration)
+	require.Equal(t, DefaultConfig.FailureThreshold, cfg.FailureThreshold)
+}
+
+func TestManager_consecutiveFailures(t *testing.T) {
+	m, _, transitions := newTestManager(Config{
+		FailureThreshold: 3, FailureRate: 100, MinimumRequests: 100,
+		Window: time.Minute, OpenDuration: 30 * time.Second, SuccessThreshold: 1,
+	})
+	ctx := context.Background()
+
+	b, err := m.Get(ctx, "endpoint")
+	require.NoError(t, err)
+	require.Equal(t, Closed, b.State)
+
+	record(t, m, false, 2)
+	b = record(t, m, true, 1)
+	require.Equal(t, Closed, b.State)
+	require.Equal(t, uint64(0), b.ConsecutiveFailures)
+
+	b = record(t, m, false, 3)
+	require.Equal(t, Open, b.State)
+	require.Equal(t, []transition{{Closed, Open}}, *transitions)
+
+	allowed, wait, err := m.Allow(ctx, "endpoint")
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, 30*time.Second, wait)
+}
+
+func TestManager_failureRate(t *testing.T) {
+	m, now, _ := newTestManager(Config{
+		FailureThreshold: 100, FailureRate: 50, MinimumRequests: 4,
+		Window: time.Minute, OpenDuration: 30 * time.Second, SuccessThreshold: 1,
+	})
+
+	// half of the requests failed, but not enough were made
+	record(t, m, true, 1)
+	b := record(t, m, false, 1)
+	record(t, m, true, 1)
+	require.Equal(t, Closed, b.State)
+
+	// the window is over, so its requests are forgotten
+	*now = now.Add(time.Minute)
+	b = record(t, m, false, 1)
+	require.Equal(t, uint64(1), b.Requests)
+
+	record(t, m, true, 2)
+	b = record(t, m, false, 1)
+	require.Equal(t, Open, b.State)
+}
+
+func TestManager_halfOpen(t *testing.T) {
+	m, now, transitions := newTestManager(Config{
+		FailureThreshold: 1, FailureRate: 100, MinimumRequests: 100,
+		Window: time.Minute, OpenDuration: 30 * time.Second, SuccessThreshold: 2,
+	})
+	ctx := context.Background()
+
+	record(t, m, false, 1)
+	*now = now.Add(30 * time.Second)
+
+	b, err := m.Get(ctx, "endpoint")
+	require.NoError(t, err)
+	require.Equal(t, HalfOpen, b.State)
+
+	// a single probe is let through at a time
+	allowed, _, err := m.Allow(ctx, "endpoint")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	allowed, wait, err := m.Allow(ctx, "endpoint")
+	require.NoError(t, err)
+	require.False(t, allowed)
+	require.Equal(t, 30*time.Second, wait)
+
+	b = record(t, m, true, 1)
+	require.Equal(t, HalfOpen, b.State)
+
+	allowed, _, err = m.Allow(ctx, "endpoint")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	b = record(t, m, true, 1)
+	require.Equal(t, Closed, b.State)
+	require.Equal(t, []transition{{Closed, Open}, {Open, HalfOpen}, {HalfOpen, Closed}}, *transitions)
+}
+
+func TestManager_concurrentProbes(t *testing.T) {
+	m, now, transitions := newTestManager(Config{
+		FailureThreshold: 1, FailureRate: 100, MinimumRequests: 100,
+		Window: time.Minute, OpenDuration: 30 * time.Second, SuccessThreshold: 1,
+	})
+
+	record(t, m, false, 1)
+	*now = now.Add(time.Minute)
+
+	// workers share the cache, only one of them gets to send the probe
+	var wg sync.WaitGroup
+	var allowed int32
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ok, _, err := m.Allow(context.Background(), "endpoint")
+			require.NoError(t, err)
+			if ok {
+				atomic.AddInt32(&allowed, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), allowed)
+	require.Equal(t, []transition{{Closed, Open}, {Open, HalfOpen}}, *transitions)
+}
+
+func TestManager_failedProbe(t *testing.T) {
+	m, now, transitions := newTestManager(Config{
+		FailureThreshold: 1, FailureRate: 100, MinimumRequests: 100,
+		Window: time.Minute, OpenDuration: 30 * time.Second, SuccessThreshold: 1,
+	})
+	ctx := context.Background()
+
+	record(t, m, false, 1)
+	*now = now.Add(time.Minute)
+
+	allowed, _, err := m.Allow(ctx, "endpoint")
+	require.NoError(t, err)
+	require.True(t, allowed)
+
+	b := record(t, m, false, 1)
+	require.Equal(t, Open, b.State)
+	require.Equal(t, *now, b.OpenedAt)
+	require.Equal(t, []transition{{Closed, Open}, {Open, HalfOpen}, {HalfOpen, Open}}, *transitions)
+
+	s := b.Status()
+	require.Equal(t, "open", s.State)
+	require.Equal(t, *now, *s.OpenedAt)
+}
@@ -0,0 +1,109 @@
+// Package idempotency remembers the events created with an idempotency key,
+// so a producer retrying a request doesn't create the same event twice.
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+)
+
+// ErrInProgress is returned for an idempotency key whose event is still
+// being created by another request.
+var ErrInProgress = errors.New("an event with this idempotency key is being created")
+
+// Header is the request header that carries an event's idempotency key.
+const Header = "Idempotency-Key"
+
+// providerHeaders are the headers that carry the delivery id of each source
+// provider, the provider sends the same id when it redelivers an event.
+var providerHeaders = map[datastore.SourceProvider]string{
+	datastore.GithubSourceProvider:  "X-GitHub-Delivery",
+	datastore.ShopifySourceProvider: "X-Shopify-Webhook-Id",
+}
+
+// Store keeps the events created with an idempotency key in the cache for
+// the deduplication window of their group.
+type Store struct {
+	cache cache.Cache
+}
+
+func NewStore(c cache.Cache) *Store {
+	return &Store{cache: c}
+}
+
+// Window is how long g remembers idempotency keys, it is zero when g has
+// deduplication turned off.
+func Window(g *datastore.Group) time.Duration {
+	cfg := &datastore.DefaultDeduplicationConfig
+	if g.Config != nil && g.Config.Deduplication != nil {
+		cfg = g.Config.Deduplication
+	}
+
+	return time.Duration(cfg.Window) * time.Second
+}
+
+// SourceKey returns the idempotency key of a request to a source, the
+// Idempotency-Key header takes precedence over the provider's delivery id.
+func SourceKey(source *datastore.Source, h http.Header) string {
+	if key := h.Get(Header); !util.IsStringEmpty(key) {
+		return key
+	}
+
+	if header, ok := providerHeaders[source.Provider]; ok {
+		return h.Get(header)
+	}
+
+	return ""
+}
+
+func cacheKey(groupID, scopeID, key string) string {
+	return convoy.IdempotencyKeyCacheKey.Get(groupID).Get(scopeID).Get(key).String()
+}
+
+// Reserve claims key for an event that is about to be created in scopeID,
+// the app the event is sent to or the source it is ingested from. Concurrent
+// requests with the same key can't both claim it, the event created with key
+// is returned to the others, or ErrInProgress while it is being created.
+func (s *Store) Reserve(ctx context.Context, groupID, scopeID, key string, window time.Duration) (*datastore.Event, error) {
+	k := cacheKey(groupID, scopeID, key)
+
+	// the reservation is an empty event until Save replaces it
+	reserved, err := s.cache.SetNX(ctx, k, &datastore.Event{}, window)
+	if err != nil {
+		return nil, err
+	}
+
+	if reserved {
+		return nil, nil
+	}
+
+	var event *datastore.Event
+	err = s.cache.Get(ctx, k, &event)
+	if err != nil {
+		return nil, err
+	}
+
+	if event == nil || util.IsStringEmpty(event.UID) {
+		return nil, ErrInProgress
+	}
+
+	return event, nil
+}
+
+// Release drops the reservation of key when its event couldn't be created,
+// so the request can be retried with it.
+func (s *Store) Release(ctx context.Context, groupID, scopeID, key string) error {
+	return s.cache.Delete(ctx, cacheKey(groupID, scopeID, key))
+}
+
+// Save remembers that event was created in scopeID with key for the window.
+func (s *Store) Save(ctx context.Context, scopeID, key string, event *datastore.Event, window time.Duration) error {
+	return s.cache.Set(ctx, cacheKey(event.GroupID, scopeID, key), event, window)
+}
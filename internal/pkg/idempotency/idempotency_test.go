@@ -0,0 +1,126 @@
+package idempotency
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	mcache "github.com/frain-dev/convoy/cache/memory"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWindow(t *testing.T) {
+	tests := []struct {
+		name     string
+		group    *datastore.Group
+		expected time.Duration
+	}{
+		{
+			name:     "should default the window",
+			group:    &datastore.Group{Config: &datastore.GroupConfig{}},
+			expected: 24 * time.Hour,
+		},
+		{
+			name: "should use the group's window",
+			group: &datastore.Group{Config: &datastore.GroupConfig{
+				Deduplication: &datastore.DeduplicationConfiguration{Window: 60},
+			}},
+			expected: time.Minute,
+		},
+		{
+			name: "should turn deduplication off",
+			group: &datastore.Group{Config: &datastore.GroupConfig{
+				Deduplication: &datastore.DeduplicationConfiguration{},
+			}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, Window(tc.group))
+		})
+	}
+}
+
+func TestSourceKey(t *testing.T) {
+	github := &datastore.Source{Provider: datastore.GithubSourceProvider}
+
+	h := http.Header{}
+	h.Set("X-GitHub-Delivery", "delivery-1")
+	require.Equal(t, "delivery-1", SourceKey(github, h))
+	require.Equal(t, "", SourceKey(&datastore.Source{}, h))
+
+	h.Set(Header, "key-1")
+	require.Equal(t, "key-1", SourceKey(github, h))
+	require.Equal(t, "key-1", SourceKey(&datastore.Source{}, h))
+}
+
+func TestStore(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(mcache.NewMemoryCache())
+
+	event, err := s.Reserve(ctx, "group-1", "app-1", "key-1", time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, event)
+
+	// the event is still being created
+	_, err = s.Reserve(ctx, "group-1", "app-1", "key-1", time.Minute)
+	require.ErrorIs(t, err, ErrInProgress)
+
+	require.NoError(t, s.Save(ctx, "app-1", "key-1", &datastore.Event{UID: "event-1", GroupID: "group-1"}, time.Minute))
+
+	event, err = s.Reserve(ctx, "group-1", "app-1", "key-1", time.Minute)
+	require.NoError(t, err)
+	require.Equal(t, "event-1", event.UID)
+
+	// the key is scoped to the group and the app
+	event, err = s.Reserve(ctx, "group-2", "app-1", "key-1", time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, event)
+
+	event, err = s.Reserve(ctx, "group-1", "app-2", "key-1", time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, event)
+}
+
+func TestStore_Release(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(mcache.NewMemoryCache())
+
+	event, err := s.Reserve(ctx, "group-1", "app-1", "key-1", time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, event)
+
+	require.NoError(t, s.Release(ctx, "group-1", "app-1", "key-1"))
+
+	// the key can be reserved again once its event failed to be created
+	event, err = s.Reserve(ctx, "group-1", "app-1", "key-1", time.Minute)
+	require.NoError(t, err)
+	require.Nil(t, event)
+}
+
+func TestStore_concurrentReserve(t *testing.T) {
+	ctx := context.Background()
+	s := NewStore(mcache.NewMemoryCache())
+
+	var wg sync.WaitGroup
+	var reserved int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			event, err := s.Reserve(ctx, "group-1", "app-1", "key-1", time.Minute)
+			if err == nil && event == nil {
+				atomic.AddInt32(&reserved, 1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	require.Equal(t, int32(1), reserved)
+}
@@ -0,0 +1,77 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/prometheus/client_golang/prometheus"
+	log "github.com/sirupsen/logrus"
+)
+
+var deadLetterQueueDepth = prometheus.NewDesc(
+	"dead_letter_queue_depth",
+	"Number of dead letters in a group that haven't been redriven.",
+	[]string{"group_id"}, nil,
+)
+
+// DeadLetters counts the event deliveries of each group that were dead
+// lettered.
+func DeadLetters() *prometheus.CounterVec {
+	registerDeadLetterMetrics()
+	return deadLetters
+}
+
+// DeadLettersRedriven counts the dead letters of each group that were
+// redriven.
+func DeadLettersRedriven() *prometheus.CounterVec {
+	registerDeadLetterMetrics()
+	return deadLettersRedriven
+}
+
+func registerDeadLetterMetrics() {
+	dl.Do(func() {
+		deadLetters = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dead_letters_total",
+			Help: "Number of event deliveries that were dead lettered.",
+		}, []string{"group_id"})
+
+		deadLettersRedriven = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dead_letters_redriven_total",
+			Help: "Number of dead letters that were redriven.",
+		}, []string{"group_id"})
+
+		Reg().MustRegister(deadLetters, deadLettersRedriven)
+	})
+}
+
+// RegisterDeadLetterMetrics registers a collector that reports the depth of
+// each group's dead letter queue, it is counted from repo on every scrape.
+func RegisterDeadLetterMetrics(repo datastore.DeadLetterRepository) {
+	dlq.Do(func() {
+		Reg().MustRegister(&deadLetterCollector{repo: repo})
+	})
+}
+
+type deadLetterCollector struct {
+	repo datastore.DeadLetterRepository
+}
+
+func (c *deadLetterCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- deadLetterQueueDepth
+}
+
+func (c *deadLetterCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	counts, err := c.repo.CountDeadLettersByGroup(ctx, datastore.PendingDeadLetterStatus)
+	if err != nil {
+		log.WithError(err).Error("failed to count dead letters")
+		return
+	}
+
+	for groupID, n := range counts {
+		ch <- prometheus.MustNewConstMetric(deadLetterQueueDepth, prometheus.GaugeValue, float64(n), groupID)
+	}
+}
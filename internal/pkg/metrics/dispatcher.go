@@ -0,0 +1,33 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// DispatcherOpenConnections is the number of connections the dispatcher's
+// transports have open to endpoints.
+func DispatcherOpenConnections() prometheus.Gauge {
+	registerDispatcherMetrics()
+	return dispatcherOpenConnections
+}
+
+// DispatcherConnections counts the connections deliveries were sent on, by
+// whether the connection was reused from the pool.
+func DispatcherConnections() *prometheus.CounterVec {
+	registerDispatcherMetrics()
+	return dispatcherConnections
+}
+
+func registerDispatcherMetrics() {
+	dp.Do(func() {
+		dispatcherOpenConnections = prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "dispatcher_open_connections",
+			Help: "Number of connections the dispatcher has open to endpoints.",
+		})
+
+		dispatcherConnections = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "dispatcher_connections_total",
+			Help: "Number of connections requests to endpoints were sent on, by whether they were reused.",
+		}, []string{"reused"})
+
+		Reg().MustRegister(dispatcherOpenConnections, dispatcherConnections)
+	})
+}
@@ -11,8 +11,13 @@ import (
 
 var reg *prometheus.Registry
 var requestDuration *prometheus.HistogramVec
+var circuitBreakerTransitions *prometheus.CounterVec
+var deadLetters *prometheus.CounterVec
+var deadLettersRedriven *prometheus.CounterVec
+var dispatcherOpenConnections prometheus.Gauge
+var dispatcherConnections *prometheus.CounterVec
 
-var re, rd sync.Once
+var re, rd, cb, dl, dlq, dp sync.Once
 
 func Reg() *prometheus.Registry {
 	re.Do(func() {
@@ -25,7 +30,10 @@ func Reg() *prometheus.Registry {
 // Reset is only intended for use in tests
 func Reset() {
 	requestDuration, reg = nil, nil
-	re, rd = sync.Once{}, sync.Once{}
+	circuitBreakerTransitions = nil
+	deadLetters, deadLettersRedriven = nil, nil
+	dispatcherOpenConnections, dispatcherConnections = nil, nil
+	re, rd, cb, dl, dlq, dp = sync.Once{}, sync.Once{}, sync.Once{}, sync.Once{}, sync.Once{}, sync.Once{}
 	prometheus.DefaultRegisterer = prometheus.NewRegistry()
 }
 
@@ -41,8 +49,31 @@ func RequestDuration() *prometheus.HistogramVec {
 	return requestDuration
 }
 
+// CircuitBreakerTransitions counts the state changes of each group's
+// endpoint circuit breakers, each transition is counted by the worker that
+// stored it.
+func CircuitBreakerTransitions() *prometheus.CounterVec {
+	cb.Do(func() {
+		circuitBreakerTransitions = prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "endpoint_circuit_breaker_transitions_total",
+			Help: "Number of times a group's endpoint circuit breakers changed state.",
+		}, []string{"group", "from", "to"})
+
+		Reg().MustRegister(circuitBreakerTransitions)
+	})
+
+	return circuitBreakerTransitions
+}
+
+// RegisterQueueMetrics registers the queue metrics collector, only the redis
+// queue exposes metrics.
 func RegisterQueueMetrics(q queue.Queuer) {
+	rq, ok := q.(*redisqueue.RedisQueue)
+	if !ok {
+		return
+	}
+
 	Reg().MustRegister(
-		metrics.NewQueueMetricsCollector(q.(*redisqueue.RedisQueue).Inspector()),
+		metrics.NewQueueMetricsCollector(rq.Inspector()),
 	)
 }
@@ -0,0 +1,143 @@
+// Package oauth2 fetches the access tokens that deliveries to endpoints
+// authenticating with the OAuth2 client credentials grant are sent with.
+//
+// Tokens are kept in the cache so every worker shares them, until a little
+// before they expire. A token the endpoint rejects is refreshed.
+package oauth2
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+	log "github.com/sirupsen/logrus"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+)
+
+// expiryDelta is how long before it expires a token is refreshed, so a
+// token doesn't expire while a delivery is in flight.
+const expiryDelta = 30 * time.Second
+
+// defaultTokenTTL is how long a token without an expiry is cached.
+const defaultTokenTTL = 5 * time.Minute
+
+// tokenTimeout is how long a token request can take.
+const tokenTimeout = 10 * time.Second
+
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	Expiry      time.Time `json:"expiry"`
+}
+
+// Authorization returns the value of the Authorization header the token is
+// sent in.
+func (t *Token) Authorization() string {
+	if tt := strings.TrimSpace(t.TokenType); tt != "" && !strings.EqualFold(tt, "bearer") {
+		return tt + " " + t.AccessToken
+	}
+
+	return "Bearer " + t.AccessToken
+}
+
+// Key returns the cache key of an endpoint's token. It changes with the
+// credentials, so updating them doesn't keep the old token around.
+func Key(endpointID string, cfg *datastore.OAuth2) string {
+	h := sha256.New()
+	for _, s := range []string{cfg.TokenURL, cfg.ClientID, cfg.ClientSecret, strings.Join(cfg.Scopes, " "), cfg.Audience} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	return convoy.OAuth2TokenCacheKey.Get(endpointID + ":" + hex.EncodeToString(h.Sum(nil))[:16]).String()
+}
+
+// Manager fetches tokens and keeps them in the cache.
+type Manager struct {
+	cache  cache.Cache
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewManager returns a manager that requests tokens with client.
+func NewManager(c cache.Cache, client *http.Client) *Manager {
+	return &Manager{cache: c, client: client, now: time.Now}
+}
+
+// Token returns the endpoint's cached token, it fetches a new one when there
+// is none or it is about to expire.
+func (m *Manager) Token(ctx context.Context, endpointID string, cfg *datastore.OAuth2) (*Token, error) {
+	key := Key(endpointID, cfg)
+
+	t := &Token{}
+	err := m.cache.Get(ctx, key, t)
+	if err != nil {
+		// deliveries carry on with a new token when the cache is down
+		log.WithError(err).Errorf("failed to load oauth2 token of endpoint %s", endpointID)
+	} else if t.AccessToken != "" && (t.Expiry.IsZero() || m.now().Add(expiryDelta).Before(t.Expiry)) {
+		return t, nil
+	}
+
+	return m.fetch(ctx, key, cfg)
+}
+
+// Refresh drops the endpoint's cached token and fetches a new one.
+func (m *Manager) Refresh(ctx context.Context, endpointID string, cfg *datastore.OAuth2) (*Token, error) {
+	key := Key(endpointID, cfg)
+
+	err := m.cache.Delete(ctx, key)
+	if err != nil {
+		log.WithError(err).Errorf("failed to delete oauth2 token of endpoint %s", endpointID)
+	}
+
+	return m.fetch(ctx, key, cfg)
+}
+
+func (m *Manager) fetch(ctx context.Context, key string, cfg *datastore.OAuth2) (*Token, error) {
+	cc := &clientcredentials.Config{
+		ClientID:     cfg.ClientID,
+		ClientSecret: cfg.ClientSecret,
+		TokenURL:     cfg.TokenURL,
+		Scopes:       cfg.Scopes,
+	}
+
+	if cfg.Audience != "" {
+		cc.EndpointParams = url.Values{"audience": {cfg.Audience}}
+	}
+
+	if m.client != nil {
+		ctx = context.WithValue(ctx, oauth2.HTTPClient, m.client)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, tokenTimeout)
+	defer cancel()
+
+	tok, err := cc.Token(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &Token{AccessToken: tok.AccessToken, TokenType: tok.TokenType, Expiry: tok.Expiry}
+
+	ttl := defaultTokenTTL
+	if !t.Expiry.IsZero() {
+		ttl = t.Expiry.Sub(m.now()) - expiryDelta
+	}
+
+	if ttl > 0 {
+		err = m.cache.Set(ctx, key, t, ttl)
+		if err != nil {
+			log.WithError(err).Error("failed to cache oauth2 token")
+		}
+	}
+
+	return t, nil
+}
@@ -0,0 +1,115 @@
+package oauth2
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	mcache "github.com/frain-dev/convoy/cache/memory"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func newTokenServer(t *testing.T, expiresIn int) (*httptest.Server, *int32) {
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		require.Equal(t, "client_credentials", r.PostForm.Get("grant_type"))
+		require.Equal(t, "read write", r.PostForm.Get("scope"))
+		require.Equal(t, "https://api.example.com", r.PostForm.Get("audience"))
+
+		id, secret, ok := r.BasicAuth()
+		require.True(t, ok)
+		require.Equal(t, "client", id)
+		require.Equal(t, "secret", secret)
+
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"access_token":"token-%d","token_type":"bearer","expires_in":%d}`, n, expiresIn)
+	}))
+
+	return srv, &calls
+}
+
+func newConfig(tokenURL string) *datastore.OAuth2 {
+	return &datastore.OAuth2{
+		TokenURL:     tokenURL,
+		ClientID:     "client",
+		ClientSecret: "secret",
+		Scopes:       []string{"read", "write"},
+		Audience:     "https://api.example.com",
+	}
+}
+
+func TestManager_Token(t *testing.T) {
+	srv, calls := newTokenServer(t, 3600)
+	defer srv.Close()
+
+	m := NewManager(mcache.NewMemoryCache(), srv.Client())
+	cfg := newConfig(srv.URL)
+	ctx := context.Background()
+
+	tok, err := m.Token(ctx, "endpoint-1", cfg)
+	require.NoError(t, err)
+	require.Equal(t, "Bearer token-1", tok.Authorization())
+
+	// the token is cached until it expires
+	tok, err = m.Token(ctx, "endpoint-1", cfg)
+	require.NoError(t, err)
+	require.Equal(t, "token-1", tok.AccessToken)
+	require.Equal(t, int32(1), atomic.LoadInt32(calls))
+
+	// refreshing replaces the cached token
+	tok, err = m.Refresh(ctx, "endpoint-1", cfg)
+	require.NoError(t, err)
+	require.Equal(t, "token-2", tok.AccessToken)
+
+	tok, err = m.Token(ctx, "endpoint-1", cfg)
+	require.NoError(t, err)
+	require.Equal(t, "token-2", tok.AccessToken)
+	require.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestManager_Token_expiring(t *testing.T) {
+	// the token expires within the expiry delta, so it isn't reused
+	srv, calls := newTokenServer(t, 10)
+	defer srv.Close()
+
+	m := NewManager(mcache.NewMemoryCache(), srv.Client())
+	cfg := newConfig(srv.URL)
+
+	for i := 0; i < 2; i++ {
+		_, err := m.Token(context.Background(), "endpoint-1", cfg)
+		require.NoError(t, err)
+	}
+
+	require.Equal(t, int32(2), atomic.LoadInt32(calls))
+}
+
+func TestManager_Token_error(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusUnauthorized)
+		fmt.Fprint(w, `{"error":"invalid_client"}`)
+	}))
+	defer srv.Close()
+
+	m := NewManager(mcache.NewMemoryCache(), srv.Client())
+
+	_, err := m.Token(context.Background(), "endpoint-1", newConfig(srv.URL))
+	require.Error(t, err)
+}
+
+func TestKey(t *testing.T) {
+	cfg := newConfig("https://auth.example.com/token")
+	key := Key("endpoint-1", cfg)
+
+	require.Equal(t, key, Key("endpoint-1", newConfig("https://auth.example.com/token")))
+	require.NotEqual(t, key, Key("endpoint-2", cfg))
+
+	cfg.ClientSecret = "rotated"
+	require.NotEqual(t, key, Key("endpoint-1", cfg))
+}
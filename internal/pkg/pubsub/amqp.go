@@ -0,0 +1,145 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	amqp "github.com/rabbitmq/amqp091-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// prefetchCount is the number of unacknowledged messages the broker sends
+// each amqp worker.
+const prefetchCount = 10
+
+// amqpPubSub consumes an amqp queue, every worker holds its own connection.
+// Messages are acknowledged once they are handled, the ones that fail are
+// requeued.
+type amqpPubSub struct {
+	source  *datastore.Source
+	cfg     *datastore.AmqpPubSubConfig
+	handler HandlerFunc
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewAmqpPubSub(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+	if _, err := amqp.ParseURI(source.PubSub.Amqp.Dsn); err != nil {
+		return nil, err
+	}
+
+	return &amqpPubSub{
+		source:  source,
+		cfg:     source.PubSub.Amqp,
+		handler: handler,
+	}, nil
+}
+
+func (a *amqpPubSub) Start(ctx context.Context) {
+	ctx, a.cancel = context.WithCancel(ctx)
+
+	for n := 0; n < workers(a.source); n++ {
+		a.wg.Add(1)
+		go a.consume(ctx)
+	}
+}
+
+func (a *amqpPubSub) Stop() {
+	a.cancel()
+	a.wg.Wait()
+}
+
+func (a *amqpPubSub) consume(ctx context.Context) {
+	defer a.wg.Done()
+
+	failures := 0
+	for ctx.Err() == nil {
+		connected, err := a.run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if connected {
+			failures = 0
+		}
+
+		log.WithError(err).Errorf("pub sub source %s lost its connection to amqp queue %s", a.source.UID, a.cfg.Queue)
+		sleep(ctx, backoff(failures))
+		failures++
+	}
+}
+
+// run consumes the queue until ctx is done or the connection fails, it
+// reports whether it got as far as consuming the queue.
+func (a *amqpPubSub) run(ctx context.Context) (bool, error) {
+	conn, err := amqp.Dial(a.cfg.Dsn)
+	if err != nil {
+		return false, err
+	}
+	defer conn.Close()
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return false, err
+	}
+	defer ch.Close()
+
+	if err = ch.Qos(prefetchCount, 0, false); err != nil {
+		return false, err
+	}
+
+	deliveries, err := ch.Consume(a.cfg.Queue, "", false, false, false, false, nil)
+	if err != nil {
+		return false, err
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return true, nil
+		case d, ok := <-deliveries:
+			if !ok {
+				return true, errors.New("delivery channel closed")
+			}
+
+			msg := &Message{
+				ID:      d.MessageId,
+				Data:    d.Body,
+				Headers: httpheader.HTTPHeader{},
+			}
+
+			for k, v := range d.Headers {
+				switch v := v.(type) {
+				case string:
+					msg.Headers[k] = []string{v}
+				case []byte:
+					msg.Headers[k] = []string{string(v)}
+				default:
+					msg.Headers[k] = []string{fmt.Sprint(v)}
+				}
+			}
+
+			if err := a.handler(ctx, a.source, msg); err != nil {
+				log.WithError(err).Errorf("pub sub source %s failed to handle amqp message %s", a.source.UID, msg.ID)
+
+				// give whatever failed a moment before the message comes
+				// straight back
+				sleep(ctx, time.Second)
+				if err = d.Nack(false, true); err != nil {
+					return true, err
+				}
+				continue
+			}
+
+			if err := d.Ack(false); err != nil {
+				return true, err
+			}
+		}
+	}
+}
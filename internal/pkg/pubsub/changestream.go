@@ -0,0 +1,227 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
+)
+
+// resumeTokenTTL is how long a change stream's resume token is kept. A
+// stream that has been stopped for longer starts again from the current
+// changes, the oplog has likely moved past the token by then anyway.
+const resumeTokenTTL = 7 * 24 * time.Hour
+
+// changeOperations are the change stream operations that become events.
+var changeOperations = bson.A{"insert", "update", "replace", "delete"}
+
+type changeEvent struct {
+	ID            bson.Raw `bson:"_id"`
+	OperationType string   `bson:"operationType"`
+	DocumentKey   bson.M   `bson:"documentKey"`
+	FullDocument  bson.M   `bson:"fullDocument"`
+}
+
+// changeStreamPubSub tails a mongodb collection and hands every insert,
+// update, replace and delete to the handler as a message typed
+// <collection>.<operation>. The stream's resume token is saved in the cache
+// after each change is handled, so a restarted stream picks up where it
+// stopped. A change that fails holds up the stream until it succeeds.
+type changeStreamPubSub struct {
+	source   *datastore.Source
+	dsn      string
+	database string
+	cfg      *datastore.DBChangeStreamConfig
+	cache    cache.Cache
+	handler  HandlerFunc
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewChangeStreamPubSub(source *datastore.Source, cache cache.Cache, handler HandlerFunc) (PubSub, error) {
+	cfg := source.DBChangeStream
+	if cfg == nil {
+		return nil, fmt.Errorf("source %s has no db change stream config", source.UID)
+	}
+
+	cs, err := connstring.ParseAndValidate(cfg.Dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	database := cfg.Database
+	if database == "" {
+		database = cs.Database
+	}
+
+	return &changeStreamPubSub{
+		source:   source,
+		dsn:      cfg.Dsn,
+		database: database,
+		cfg:      cfg,
+		cache:    cache,
+		handler:  handler,
+	}, nil
+}
+
+func (c *changeStreamPubSub) Start(ctx context.Context) {
+	ctx, c.cancel = context.WithCancel(ctx)
+
+	// a collection's changes are read by a single stream to keep them in
+	// order
+	c.wg.Add(1)
+	go c.consume(ctx)
+}
+
+func (c *changeStreamPubSub) Stop() {
+	c.cancel()
+	c.wg.Wait()
+}
+
+func (c *changeStreamPubSub) consume(ctx context.Context) {
+	defer c.wg.Done()
+
+	failures := 0
+	for ctx.Err() == nil {
+		handled, err := c.watch(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if handled {
+			failures = 0
+		}
+
+		log.WithError(err).Errorf("change stream source %s stopped watching collection %s", c.source.UID, c.cfg.Collection)
+		sleep(ctx, backoff(failures))
+		failures++
+	}
+}
+
+// watch opens a change stream on the collection and handles its changes
+// until ctx is done or the stream fails. It reports whether any change was
+// handled.
+func (c *changeStreamPubSub) watch(ctx context.Context) (bool, error) {
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(c.dsn))
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		_ = client.Disconnect(context.Background())
+	}()
+
+	opts := options.ChangeStream().SetFullDocument(options.UpdateLookup)
+
+	token, err := c.resumeToken(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	if len(token) > 0 {
+		opts.SetResumeAfter(bson.Raw(token))
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: bson.M{"operationType": bson.M{"$in": changeOperations}}}}}
+	stream, err := client.Database(c.database).Collection(c.cfg.Collection).Watch(ctx, pipeline, opts)
+	if err != nil {
+		return false, err
+	}
+	defer stream.Close(context.Background())
+
+	handled := false
+	for stream.Next(ctx) {
+		var change changeEvent
+		if err = stream.Decode(&change); err != nil {
+			return handled, err
+		}
+
+		msg, err := changeMessage(c.cfg.Collection, &change)
+		if err != nil {
+			return handled, err
+		}
+
+		if err = c.handle(ctx, msg); err != nil {
+			return handled, err
+		}
+
+		handled = true
+		err = c.cache.Set(ctx, c.resumeTokenKey(), &resumeToken{Token: stream.ResumeToken()}, resumeTokenTTL)
+		if err != nil {
+			log.WithError(err).Errorf("change stream source %s failed to save its resume token", c.source.UID)
+		}
+	}
+
+	return handled, stream.Err()
+}
+
+// handle calls the handler until it handles msg, backing off between
+// attempts.
+func (c *changeStreamPubSub) handle(ctx context.Context, msg *Message) error {
+	for failures := 0; ; failures++ {
+		err := c.handler(ctx, c.source, msg)
+		if err == nil {
+			return nil
+		}
+
+		log.WithError(err).Errorf("change stream source %s failed to handle change %s", c.source.UID, msg.ID)
+		if !sleep(ctx, backoff(failures)) {
+			return ctx.Err()
+		}
+	}
+}
+
+func (c *changeStreamPubSub) resumeTokenKey() string {
+	return convoy.ChangeStreamCacheKey.Get(c.source.UID).String()
+}
+
+// resumeToken wraps the token, the cache can't read back a bare byte slice.
+type resumeToken struct {
+	Token []byte
+}
+
+func (c *changeStreamPubSub) resumeToken(ctx context.Context) ([]byte, error) {
+	var t resumeToken
+	err := c.cache.Get(ctx, c.resumeTokenKey(), &t)
+	return t.Token, err
+}
+
+// changeMessage turns a change into a message. The message's data is the
+// changed document, or only its key when the document was deleted.
+func changeMessage(collection string, change *changeEvent) (*Message, error) {
+	doc := change.FullDocument
+	if doc == nil {
+		doc = change.DocumentKey
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	id := change.ID.String()
+	if v, err := change.ID.LookupErr("_data"); err == nil {
+		if s, ok := v.StringValueOK(); ok {
+			id = s
+		}
+	}
+
+	return &Message{
+		ID:   id,
+		Data: data,
+		Headers: httpheader.HTTPHeader{
+			EventTypeHeader: []string{collection + "." + change.OperationType},
+		},
+	}, nil
+}
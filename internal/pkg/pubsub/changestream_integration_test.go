@@ -0,0 +1,101 @@
+//go:build integration
+// +build integration
+
+package pubsub
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	mcache "github.com/frain-dev/convoy/cache/memory"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// change streams need the test database to be a replica set
+func getDSN() string {
+	return os.Getenv("TEST_MONGO_DSN")
+}
+
+func TestChangeStreamPubSub(t *testing.T) {
+	ctx := context.Background()
+
+	client, err := mongo.Connect(ctx, options.Client().ApplyURI(getDSN()))
+	require.NoError(t, err)
+	defer client.Disconnect(ctx)
+
+	coll := client.Database("convoy_change_stream_test").Collection("orders")
+	require.NoError(t, coll.Drop(ctx))
+
+	source := &datastore.Source{
+		UID:  "source-1",
+		Type: datastore.DBChangeStream,
+		DBChangeStream: &datastore.DBChangeStreamConfig{
+			Dsn:        getDSN(),
+			Database:   "convoy_change_stream_test",
+			Collection: "orders",
+		},
+	}
+
+	messages := make(chan *Message, 10)
+	handler := func(_ context.Context, _ *datastore.Source, msg *Message) error {
+		messages <- msg
+		return nil
+	}
+
+	next := func() *Message {
+		select {
+		case msg := <-messages:
+			return msg
+		case <-time.After(10 * time.Second):
+			require.FailNow(t, "timed out waiting for a change")
+			return nil
+		}
+	}
+
+	cache := mcache.NewMemoryCache()
+	ps, err := NewChangeStreamPubSub(source, cache, handler)
+	require.NoError(t, err)
+	ps.Start(ctx)
+
+	// the stream is opened in the background
+	time.Sleep(time.Second)
+
+	_, err = coll.InsertOne(ctx, bson.M{"_id": "order-1", "amount": 500})
+	require.NoError(t, err)
+	_, err = coll.UpdateOne(ctx, bson.M{"_id": "order-1"}, bson.M{"$set": bson.M{"amount": 600}})
+	require.NoError(t, err)
+	_, err = coll.DeleteOne(ctx, bson.M{"_id": "order-1"})
+	require.NoError(t, err)
+
+	msg := next()
+	require.Equal(t, []string{"orders.insert"}, msg.Headers[EventTypeHeader])
+	require.JSONEq(t, `{"_id":"order-1","amount":500}`, string(msg.Data))
+
+	msg = next()
+	require.Equal(t, []string{"orders.update"}, msg.Headers[EventTypeHeader])
+
+	msg = next()
+	require.Equal(t, []string{"orders.delete"}, msg.Headers[EventTypeHeader])
+	require.JSONEq(t, `{"_id":"order-1"}`, string(msg.Data))
+
+	ps.Stop()
+
+	// changes made while the stream is stopped are read when it restarts
+	_, err = coll.InsertOne(ctx, bson.M{"_id": "order-2", "amount": 100})
+	require.NoError(t, err)
+
+	ps, err = NewChangeStreamPubSub(source, cache, handler)
+	require.NoError(t, err)
+	ps.Start(ctx)
+	defer ps.Stop()
+
+	msg = next()
+	require.Equal(t, []string{"orders.insert"}, msg.Headers[EventTypeHeader])
+	require.JSONEq(t, `{"_id":"order-2","amount":100}`, string(msg.Data))
+}
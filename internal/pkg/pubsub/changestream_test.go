@@ -0,0 +1,95 @@
+package pubsub
+
+import (
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func decodeChange(t *testing.T, doc bson.M) *changeEvent {
+	b, err := bson.Marshal(doc)
+	require.NoError(t, err)
+
+	var change changeEvent
+	require.NoError(t, bson.Unmarshal(b, &change))
+	return &change
+}
+
+func TestChangeMessage(t *testing.T) {
+	id, err := primitive.ObjectIDFromHex("62d9b6bb3f4b9c2d8c1e4a10")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		change        bson.M
+		wantID        string
+		wantEventType string
+		wantData      string
+	}{
+		{
+			name: "insert",
+			change: bson.M{
+				"_id":           bson.M{"_data": "8262D9B6BB000000012B022C0100296E5A1004"},
+				"operationType": "insert",
+				"documentKey":   bson.M{"_id": id},
+				"fullDocument": bson.M{
+					"_id":      id,
+					"amount":   int32(500),
+					"customer": bson.M{"name": "Daniel"},
+				},
+			},
+			wantID:        "8262D9B6BB000000012B022C0100296E5A1004",
+			wantEventType: "orders.insert",
+			wantData:      `{"_id":"62d9b6bb3f4b9c2d8c1e4a10","amount":500,"customer":{"name":"Daniel"}}`,
+		},
+		{
+			name: "delete",
+			change: bson.M{
+				"_id":           bson.M{"_data": "8262D9B6BC000000012B022C0100296E5A1004"},
+				"operationType": "delete",
+				"documentKey":   bson.M{"_id": id},
+			},
+			wantID:        "8262D9B6BC000000012B022C0100296E5A1004",
+			wantEventType: "orders.delete",
+			wantData:      `{"_id":"62d9b6bb3f4b9c2d8c1e4a10"}`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			msg, err := changeMessage("orders", decodeChange(t, tc.change))
+			require.NoError(t, err)
+
+			require.Equal(t, tc.wantID, msg.ID)
+			require.Equal(t, []string{tc.wantEventType}, msg.Headers[EventTypeHeader])
+			require.JSONEq(t, tc.wantData, string(msg.Data))
+		})
+	}
+}
+
+func TestNewChangeStreamPubSub(t *testing.T) {
+	source := &datastore.Source{
+		UID:  "source-1",
+		Type: datastore.DBChangeStream,
+		DBChangeStream: &datastore.DBChangeStreamConfig{
+			Dsn:        "mongodb://localhost:27017/shop",
+			Collection: "orders",
+		},
+	}
+
+	ps, err := NewChangeStreamPubSub(source, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "shop", ps.(*changeStreamPubSub).database)
+
+	source.DBChangeStream.Database = "inventory"
+	ps, err = NewChangeStreamPubSub(source, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, "inventory", ps.(*changeStreamPubSub).database)
+
+	source.DBChangeStream.Dsn = "localhost:27017"
+	_, err = NewChangeStreamPubSub(source, nil, nil)
+	require.Error(t, err)
+}
@@ -0,0 +1,244 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventTypeHeader is the message header publishers set to choose the event's
+// type, events from messages without it are typed with the source's mask id
+// like the events sent to the source's ingest url.
+const EventTypeHeader = "X-Convoy-Event-Type"
+
+const defaultSyncInterval = 10 * time.Second
+
+type runningSource struct {
+	updatedAt primitive.DateTime
+	pubSub    PubSub
+}
+
+// Ingest runs a consumer for every enabled source of its source type and
+// writes the messages they read to the create event queue, the same way
+// events sent to a source's ingest url are.
+type Ingest struct {
+	sourceType datastore.SourceType
+	sourceRepo datastore.SourceRepository
+	queue      queue.Queuer
+	interval   time.Duration
+	newPubSub  func(*datastore.Source, HandlerFunc) (PubSub, error)
+
+	mu      sync.Mutex
+	sources map[string]*runningSource
+}
+
+// NewIngest returns an Ingest for pub sub sources. Brokers share a topic
+// between the consumers of every convoy process, so it can run in each of
+// them.
+func NewIngest(sourceRepo datastore.SourceRepository, queue queue.Queuer) *Ingest {
+	return &Ingest{
+		sourceType: datastore.PubSubSource,
+		sourceRepo: sourceRepo,
+		queue:      queue,
+		interval:   defaultSyncInterval,
+		newPubSub:  NewPubSub,
+		sources:    map[string]*runningSource{},
+	}
+}
+
+// NewChangeStreamIngest returns an Ingest for db change stream sources. Each
+// process that runs it tails the collections separately, so it must only
+// run in one of them.
+func NewChangeStreamIngest(sourceRepo datastore.SourceRepository, queue queue.Queuer, cache cache.Cache) *Ingest {
+	return &Ingest{
+		sourceType: datastore.DBChangeStream,
+		sourceRepo: sourceRepo,
+		queue:      queue,
+		interval:   defaultSyncInterval,
+		newPubSub: func(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+			return NewChangeStreamPubSub(source, cache, handler)
+		},
+		sources: map[string]*runningSource{},
+	}
+}
+
+// NewRestApiIngest returns an Ingest for rest api sources. Like change
+// streams, the upstream apis must only be polled by one process.
+func NewRestApiIngest(sourceRepo datastore.SourceRepository, queue queue.Queuer, policy *net.EgressPolicy) *Ingest {
+	return &Ingest{
+		sourceType: datastore.RestApiSource,
+		sourceRepo: sourceRepo,
+		queue:      queue,
+		interval:   defaultSyncInterval,
+		newPubSub: func(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+			return NewRestApiPubSub(source, sourceRepo, policy, handler)
+		},
+		sources: map[string]*runningSource{},
+	}
+}
+
+// Run reloads the sources every interval until ctx is done. It starts
+// consumers for new sources, restarts the ones whose source was updated and
+// stops the ones whose source was disabled or deleted.
+func (i *Ingest) Run(ctx context.Context) {
+	ticker := time.NewTicker(i.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := i.sync(ctx); err != nil {
+			log.WithError(err).Errorf("failed to load %s sources", i.sourceType)
+		}
+
+		select {
+		case <-ctx.Done():
+			i.stop()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (i *Ingest) sync(ctx context.Context) error {
+	sources, err := i.loadSources(ctx)
+	if err != nil {
+		return err
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	seen := map[string]bool{}
+	for idx := range sources {
+		source := sources[idx]
+		if source.IsDisabled || !configured(&source) {
+			continue
+		}
+
+		seen[source.UID] = true
+		if rs, ok := i.sources[source.UID]; ok {
+			if rs.updatedAt == source.UpdatedAt {
+				continue
+			}
+
+			log.Infof("restarting consumers for updated %s source %s", i.sourceType, source.UID)
+			rs.pubSub.Stop()
+			delete(i.sources, source.UID)
+		}
+
+		ps, err := i.newPubSub(&source, i.handle)
+		if err != nil {
+			log.WithError(err).Errorf("failed to create consumers for %s source %s", i.sourceType, source.UID)
+			continue
+		}
+
+		ps.Start(ctx)
+		i.sources[source.UID] = &runningSource{updatedAt: source.UpdatedAt, pubSub: ps}
+	}
+
+	for uid, rs := range i.sources {
+		if !seen[uid] {
+			log.Infof("stopping consumers for %s source %s", i.sourceType, uid)
+			rs.pubSub.Stop()
+			delete(i.sources, uid)
+		}
+	}
+
+	return nil
+}
+
+func (i *Ingest) loadSources(ctx context.Context) ([]datastore.Source, error) {
+	var sources []datastore.Source
+	f := &datastore.SourceFilter{Type: string(i.sourceType)}
+
+	for page := 1; ; page++ {
+		s, pagination, err := i.sourceRepo.LoadSourcesPaged(ctx, "", f, datastore.Pageable{Page: page, PerPage: 100})
+		if err != nil {
+			return nil, err
+		}
+
+		sources = append(sources, s...)
+		if int64(page) >= pagination.TotalPage {
+			return sources, nil
+		}
+	}
+}
+
+// configured reports whether source has the config its type needs.
+func configured(source *datastore.Source) bool {
+	switch source.Type {
+	case datastore.PubSubSource:
+		return source.PubSub != nil
+	case datastore.DBChangeStream:
+		return source.DBChangeStream != nil
+	case datastore.RestApiSource:
+		return source.RestApi != nil
+	}
+
+	return false
+}
+
+func (i *Ingest) stop() {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	for uid, rs := range i.sources {
+		rs.pubSub.Stop()
+		delete(i.sources, uid)
+	}
+}
+
+func (i *Ingest) handle(ctx context.Context, source *datastore.Source, msg *Message) error {
+	// acknowledge messages that can never become an event, otherwise the
+	// broker keeps redelivering them
+	if !json.Valid(msg.Data) {
+		log.Errorf("dropping message %s from %s source %s: message body is not valid json", msg.ID, source.Type, source.UID)
+		return nil
+	}
+
+	headers := httpheader.HTTPHeader{}
+	for k, v := range msg.Headers {
+		headers[http.CanonicalHeaderKey(k)] = v
+	}
+
+	eventType := source.MaskID
+	if v := headers[EventTypeHeader]; len(v) > 0 && v[0] != "" {
+		eventType = v[0]
+	}
+
+	event := &datastore.Event{
+		UID:            uuid.New().String(),
+		EventType:      datastore.EventType(eventType),
+		SourceID:       source.UID,
+		GroupID:        source.GroupID,
+		Data:           msg.Data,
+		Headers:        headers,
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		UpdatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	eventByte, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	job := &queue.Job{
+		ID:      event.UID,
+		Payload: eventByte,
+		Delay:   0,
+	}
+
+	return i.queue.Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, job)
+}
@@ -0,0 +1,198 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type fakePubSub struct {
+	mu      sync.Mutex
+	started bool
+	stopped bool
+}
+
+func (f *fakePubSub) Start(ctx context.Context) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.started = true
+}
+
+func (f *fakePubSub) Stop() {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.stopped = true
+}
+
+func pubSubSource(uid string, updatedAt time.Time) datastore.Source {
+	return datastore.Source{
+		UID:       uid,
+		GroupID:   "group-1",
+		MaskID:    "mask-" + uid,
+		Type:      datastore.PubSubSource,
+		UpdatedAt: primitive.NewDateTimeFromTime(updatedAt),
+		PubSub:    &datastore.PubSubConfig{Type: datastore.SqsPubSub},
+	}
+}
+
+func TestIngest_Sync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sourceRepo := mocks.NewMockSourceRepository(ctrl)
+	ingest := NewIngest(sourceRepo, mocks.NewMockQueuer(ctrl))
+
+	pubSubs := map[string][]*fakePubSub{}
+	ingest.newPubSub = func(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+		ps := &fakePubSub{}
+		pubSubs[source.UID] = append(pubSubs[source.UID], ps)
+		return ps, nil
+	}
+
+	now := time.Now()
+	disabled := pubSubSource("disabled", now)
+	disabled.IsDisabled = true
+
+	f := &datastore.SourceFilter{Type: string(datastore.PubSubSource)}
+	sourceRepo.EXPECT().LoadSourcesPaged(gomock.Any(), "", f, datastore.Pageable{Page: 1, PerPage: 100}).
+		Return([]datastore.Source{pubSubSource("updated", now), pubSubSource("deleted", now), disabled}, datastore.PaginationData{TotalPage: 1}, nil)
+
+	ctx := context.Background()
+	require.NoError(t, ingest.sync(ctx))
+
+	require.Len(t, pubSubs["updated"], 1)
+	require.Len(t, pubSubs["deleted"], 1)
+	require.Empty(t, pubSubs["disabled"])
+	require.True(t, pubSubs["updated"][0].started)
+
+	// loading the same sources again leaves the consumers alone
+	sourceRepo.EXPECT().LoadSourcesPaged(gomock.Any(), "", f, gomock.Any()).
+		Return([]datastore.Source{pubSubSource("updated", now), pubSubSource("deleted", now)}, datastore.PaginationData{TotalPage: 1}, nil)
+	require.NoError(t, ingest.sync(ctx))
+	require.Len(t, pubSubs["updated"], 1)
+	require.False(t, pubSubs["updated"][0].stopped)
+
+	sourceRepo.EXPECT().LoadSourcesPaged(gomock.Any(), "", f, gomock.Any()).
+		Return([]datastore.Source{pubSubSource("updated", now.Add(time.Minute))}, datastore.PaginationData{TotalPage: 1}, nil)
+	require.NoError(t, ingest.sync(ctx))
+
+	require.Len(t, pubSubs["updated"], 2)
+	require.True(t, pubSubs["updated"][0].stopped)
+	require.True(t, pubSubs["updated"][1].started)
+	require.True(t, pubSubs["deleted"][0].stopped)
+
+	ingest.stop()
+	require.True(t, pubSubs["updated"][1].stopped)
+}
+
+func TestIngest_LoadSourcesPaged(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	sourceRepo := mocks.NewMockSourceRepository(ctrl)
+	ingest := NewIngest(sourceRepo, mocks.NewMockQueuer(ctrl))
+
+	now := time.Now()
+	sourceRepo.EXPECT().LoadSourcesPaged(gomock.Any(), "", gomock.Any(), datastore.Pageable{Page: 1, PerPage: 100}).
+		Return([]datastore.Source{pubSubSource("1", now)}, datastore.PaginationData{TotalPage: 2}, nil)
+	sourceRepo.EXPECT().LoadSourcesPaged(gomock.Any(), "", gomock.Any(), datastore.Pageable{Page: 2, PerPage: 100}).
+		Return([]datastore.Source{pubSubSource("2", now)}, datastore.PaginationData{TotalPage: 2}, nil)
+
+	sources, err := ingest.loadSources(context.Background())
+	require.NoError(t, err)
+	require.Len(t, sources, 2)
+}
+
+func TestIngest_Handle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	q := mocks.NewMockQueuer(ctrl)
+	ingest := NewIngest(mocks.NewMockSourceRepository(ctrl), q)
+
+	source := pubSubSource("source-1", time.Now())
+	source.PubSub.Workers = 2
+
+	broker := NewMemoryBroker()
+	ps := broker.PubSub(&source, "events", ingest.handle)
+
+	var mu sync.Mutex
+	var events []datastore.Event
+
+	gomock.InOrder(
+		// the first write fails, the message has to be redelivered
+		q.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).Return(errors.New("queue unavailable")),
+		q.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).Times(2).
+			DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+				var event datastore.Event
+				if err := json.Unmarshal(job.Payload, &event); err != nil {
+					return err
+				}
+
+				mu.Lock()
+				defer mu.Unlock()
+				events = append(events, event)
+				return nil
+			}),
+	)
+
+	broker.Publish("events", &Message{
+		ID:      "1",
+		Data:    []byte(`{"id":1}`),
+		Headers: httpheader.HTTPHeader{"x-convoy-event-type": {"user.created"}},
+	})
+	broker.Publish("events", &Message{ID: "2", Data: []byte(`not json`)})
+	broker.Publish("events", &Message{ID: "3", Data: []byte(`{"id":3}`)})
+
+	ps.Start(context.Background())
+	require.Eventually(t, func() bool { return broker.Len("events") == 0 }, 5*time.Second, 5*time.Millisecond)
+	ps.Stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Len(t, events, 2)
+
+	types := map[string]string{}
+	for _, e := range events {
+		require.Equal(t, source.UID, e.SourceID)
+		require.Equal(t, source.GroupID, e.GroupID)
+		types[string(e.Data)] = string(e.EventType)
+	}
+
+	require.Equal(t, map[string]string{`{"id":1}`: "user.created", `{"id":3}`: source.MaskID}, types)
+}
+
+func TestNewPubSub(t *testing.T) {
+	source := pubSubSource("source-1", time.Now())
+	source.PubSub = &datastore.PubSubConfig{
+		Type: datastore.KafkaPubSub,
+		Kafka: &datastore.KafkaPubSubConfig{
+			Brokers: []string{"localhost:9092"},
+			Topic:   "events",
+		},
+	}
+
+	ps, err := NewPubSub(&source, nil)
+	require.NoError(t, err)
+	require.Equal(t, "convoy-source-1", ps.(*kafkaPubSub).cfg.GroupID)
+
+	source.PubSub = &datastore.PubSubConfig{Type: datastore.AmqpPubSub, Amqp: &datastore.AmqpPubSubConfig{Dsn: "http://localhost", Queue: "events"}}
+	_, err = NewPubSub(&source, nil)
+	require.Error(t, err)
+
+	source.PubSub = &datastore.PubSubConfig{Type: "nats"}
+	_, err = NewPubSub(&source, nil)
+	require.EqualError(t, err, "unsupported pub sub type: nats")
+}
@@ -0,0 +1,188 @@
+package pubsub
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxRecordAttempts is how many times a kafka record is handled before it is
+// skipped, a record that can never be handled would otherwise hold up its
+// partition for good.
+const maxRecordAttempts = 5
+
+// recordRetryDelay is how long a worker waits before handling a record that
+// failed again.
+var recordRetryDelay = time.Second
+
+// kafkaPubSub reads a kafka topic, every worker is a separate member of the
+// source's consumer group. A record's offset is only committed once it is
+// handled or skipped after maxRecordAttempts.
+type kafkaPubSub struct {
+	source  *datastore.Source
+	cfg     kafka.ReaderConfig
+	handler HandlerFunc
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewKafkaPubSub(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+	cfg := source.PubSub.Kafka
+
+	groupID := cfg.ConsumerGroupID
+	if groupID == "" {
+		groupID = "convoy-" + source.UID
+	}
+
+	dialer := &kafka.Dialer{
+		ClientID:  "convoy",
+		Timeout:   10 * time.Second,
+		DualStack: true,
+	}
+
+	if cfg.TLS {
+		dialer.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	if cfg.Auth != nil {
+		mechanism, err := saslMechanism(cfg.Auth)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+	}
+
+	rc := kafka.ReaderConfig{
+		Brokers:     cfg.Brokers,
+		Topic:       cfg.Topic,
+		GroupID:     groupID,
+		Dialer:      dialer,
+		StartOffset: kafka.FirstOffset,
+		ErrorLogger: kafka.LoggerFunc(log.Errorf),
+	}
+
+	if err := rc.Validate(); err != nil {
+		return nil, err
+	}
+
+	return &kafkaPubSub{source: source, cfg: rc, handler: handler}, nil
+}
+
+func saslMechanism(auth *datastore.KafkaAuth) (sasl.Mechanism, error) {
+	switch auth.Type {
+	case "plain":
+		return plain.Mechanism{Username: auth.Username, Password: auth.Password}, nil
+	case "scram-sha-256":
+		return scram.Mechanism(scram.SHA256, auth.Username, auth.Password)
+	case "scram-sha-512":
+		return scram.Mechanism(scram.SHA512, auth.Username, auth.Password)
+	}
+
+	return nil, fmt.Errorf("unsupported kafka auth type: %s", auth.Type)
+}
+
+func (k *kafkaPubSub) Start(ctx context.Context) {
+	ctx, k.cancel = context.WithCancel(ctx)
+
+	for n := 0; n < workers(k.source); n++ {
+		k.wg.Add(1)
+		go k.consume(ctx)
+	}
+}
+
+func (k *kafkaPubSub) Stop() {
+	k.cancel()
+	k.wg.Wait()
+}
+
+func (k *kafkaPubSub) consume(ctx context.Context) {
+	defer k.wg.Done()
+
+	failures := 0
+	for ctx.Err() == nil {
+		start := time.Now()
+		err := k.run(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+
+		// the reader ran long enough for whatever failed before to have
+		// recovered
+		if time.Since(start) > time.Minute {
+			failures = 0
+		}
+
+		log.WithError(err).Errorf("pub sub source %s stopped consuming kafka topic %s", k.source.UID, k.cfg.Topic)
+		sleep(ctx, backoff(failures))
+		failures++
+	}
+}
+
+// run reads the topic until ctx is done or the reader fails. The reader
+// reconnects to the brokers and rejoins the group by itself.
+func (k *kafkaPubSub) run(ctx context.Context) error {
+	r := kafka.NewReader(k.cfg)
+	defer r.Close()
+
+	for {
+		m, err := r.FetchMessage(ctx)
+		if err != nil {
+			return err
+		}
+
+		if !k.handle(ctx, m) {
+			return ctx.Err()
+		}
+
+		if err = r.CommitMessages(ctx, m); err != nil {
+			return err
+		}
+	}
+}
+
+// handle passes the record to the source's handler, it reports whether the
+// record's offset can be committed. That is once it has been handled, or
+// skipped after failing maxRecordAttempts times.
+func (k *kafkaPubSub) handle(ctx context.Context, m kafka.Message) bool {
+	msg := &Message{
+		ID:      fmt.Sprintf("%s/%d/%d", m.Topic, m.Partition, m.Offset),
+		Data:    m.Value,
+		Headers: httpheader.HTTPHeader{},
+	}
+
+	for _, h := range m.Headers {
+		msg.Headers[h.Key] = append(msg.Headers[h.Key], string(h.Value))
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := k.handler(ctx, k.source, msg)
+		if err == nil {
+			return true
+		}
+
+		if ctx.Err() != nil {
+			return false
+		}
+
+		if attempt >= maxRecordAttempts {
+			log.WithError(err).Errorf("pub sub source %s skipped kafka record %s after %d attempts", k.source.UID, msg.ID, attempt)
+			return true
+		}
+
+		log.WithError(err).Errorf("pub sub source %s failed to handle kafka record %s", k.source.UID, msg.ID)
+		if !sleep(ctx, recordRetryDelay) {
+			return false
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/segmentio/kafka-go"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKafkaPubSub_handle(t *testing.T) {
+	recordRetryDelay = time.Millisecond
+	defer func() { recordRetryDelay = time.Second }()
+
+	source := &datastore.Source{UID: "source-1", PubSub: &datastore.PubSubConfig{
+		Type:  datastore.KafkaPubSub,
+		Kafka: &datastore.KafkaPubSubConfig{Brokers: []string{"localhost:9092"}, Topic: "events"},
+	}}
+
+	record := kafka.Message{
+		Topic:     "events",
+		Partition: 1,
+		Offset:    42,
+		Value:     []byte(`{"event": "invoice.paid"}`),
+		Headers:   []kafka.Header{{Key: "X-Event-Type", Value: []byte("invoice.paid")}},
+	}
+
+	attempts := 0
+	k := &kafkaPubSub{source: source, handler: func(_ context.Context, _ *datastore.Source, msg *Message) error {
+		attempts++
+		require.Equal(t, "events/1/42", msg.ID)
+		require.Equal(t, []string{"invoice.paid"}, msg.Headers["X-Event-Type"])
+
+		if attempts < 3 {
+			return errors.New("failed")
+		}
+		return nil
+	}}
+
+	// the record is retried until it is handled
+	require.True(t, k.handle(context.Background(), record))
+	require.Equal(t, 3, attempts)
+
+	// a record that keeps failing is skipped so its partition moves on
+	attempts = 0
+	k.handler = func(context.Context, *datastore.Source, *Message) error {
+		attempts++
+		return errors.New("failed")
+	}
+	require.True(t, k.handle(context.Background(), record))
+	require.Equal(t, maxRecordAttempts, attempts)
+
+	// the offset isn't committed when the worker stops
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	require.False(t, k.handle(ctx, record))
+}
+
+func TestNewKafkaPubSub(t *testing.T) {
+	for _, auth := range []string{"plain", "scram-sha-256", "scram-sha-512"} {
+		source := &datastore.Source{UID: "source-1", PubSub: &datastore.PubSubConfig{
+			Type: datastore.KafkaPubSub,
+			Kafka: &datastore.KafkaPubSubConfig{
+				Brokers: []string{"localhost:9092"},
+				Topic:   "events",
+				TLS:     true,
+				Auth:    &datastore.KafkaAuth{Type: auth, Username: "user", Password: "password"},
+			},
+		}}
+
+		ps, err := NewKafkaPubSub(source, nil)
+		require.NoError(t, err)
+
+		cfg := ps.(*kafkaPubSub).cfg
+		require.Equal(t, "convoy-source-1", cfg.GroupID)
+		require.NotNil(t, cfg.Dialer.TLS)
+		require.NotNil(t, cfg.Dialer.SASLMechanism)
+	}
+}
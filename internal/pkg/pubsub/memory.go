@@ -0,0 +1,158 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+// redeliveryDelay is how long a message a handler failed waits before the
+// memory broker delivers it again.
+const redeliveryDelay = 10 * time.Millisecond
+
+// MemoryBroker is an in-process stand-in for a message broker, it lets the
+// ingest pipeline be exercised without running kafka, sqs or rabbitmq.
+// Messages are published to named queues and each one is delivered to a
+// single consumer of the queue, a message the consumer fails to handle is
+// redelivered until it is handled.
+type MemoryBroker struct {
+	mu     sync.Mutex
+	queues map[string]*memoryQueue
+}
+
+type memoryQueue struct {
+	ready    []*Message
+	inFlight int
+	wake     chan struct{}
+}
+
+func NewMemoryBroker() *MemoryBroker {
+	return &MemoryBroker{queues: map[string]*memoryQueue{}}
+}
+
+// queue returns the queue called name, creating it if it doesn't exist.
+// b.mu must be held.
+func (b *MemoryBroker) queue(name string) *memoryQueue {
+	q, ok := b.queues[name]
+	if !ok {
+		q = &memoryQueue{wake: make(chan struct{})}
+		b.queues[name] = q
+	}
+
+	return q
+}
+
+// Publish adds msg to the end of queue.
+func (b *MemoryBroker) Publish(queue string, msg *Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.push(b.queue(queue), msg)
+}
+
+// Len returns the number of messages in queue that haven't been handled yet,
+// including the ones being handled.
+func (b *MemoryBroker) Len(queue string) int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q := b.queue(queue)
+	return len(q.ready) + q.inFlight
+}
+
+// PubSub returns a PubSub that consumes queue for source.
+func (b *MemoryBroker) PubSub(source *datastore.Source, queue string, handler HandlerFunc) PubSub {
+	return &memoryPubSub{broker: b, queue: queue, source: source, handler: handler}
+}
+
+// push adds msg to q and wakes up its waiting consumers. b.mu must be held.
+func (b *MemoryBroker) push(q *memoryQueue, msg *Message) {
+	q.ready = append(q.ready, msg)
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+// receive blocks until a message is published to queue or ctx is done.
+func (b *MemoryBroker) receive(ctx context.Context, queue string) (*Message, bool) {
+	for {
+		b.mu.Lock()
+		q := b.queue(queue)
+		if len(q.ready) > 0 {
+			msg := q.ready[0]
+			q.ready = q.ready[1:]
+			q.inFlight++
+			b.mu.Unlock()
+			return msg, true
+		}
+
+		wake := q.wake
+		b.mu.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-wake:
+		}
+	}
+}
+
+func (b *MemoryBroker) ack(queue string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.queue(queue).inFlight--
+}
+
+func (b *MemoryBroker) nack(queue string, msg *Message) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	q := b.queue(queue)
+	q.inFlight--
+	b.push(q, msg)
+}
+
+type memoryPubSub struct {
+	broker  *MemoryBroker
+	queue   string
+	source  *datastore.Source
+	handler HandlerFunc
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func (m *memoryPubSub) Start(ctx context.Context) {
+	ctx, m.cancel = context.WithCancel(ctx)
+
+	for n := 0; n < workers(m.source); n++ {
+		m.wg.Add(1)
+		go m.consume(ctx)
+	}
+}
+
+func (m *memoryPubSub) Stop() {
+	m.cancel()
+	m.wg.Wait()
+}
+
+func (m *memoryPubSub) consume(ctx context.Context) {
+	defer m.wg.Done()
+
+	for {
+		msg, ok := m.broker.receive(ctx, m.queue)
+		if !ok {
+			return
+		}
+
+		if err := m.handler(ctx, m.source, msg); err != nil {
+			sleep(ctx, redeliveryDelay)
+			m.broker.nack(m.queue, msg)
+			continue
+		}
+
+		m.broker.ack(m.queue)
+	}
+}
@@ -0,0 +1,82 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+)
+
+// Message is a message read off a pub sub source's broker, Headers holds the
+// sqs message attributes, the kafka record headers or the amqp headers.
+type Message struct {
+	ID      string
+	Data    []byte
+	Headers httpheader.HTTPHeader
+}
+
+// HandlerFunc is called with every message read off a source's broker. The
+// message is acknowledged when it returns nil, otherwise the broker
+// redelivers it.
+type HandlerFunc func(ctx context.Context, source *datastore.Source, msg *Message) error
+
+// PubSub consumes the messages published to a pub sub source's broker.
+type PubSub interface {
+	// Start runs the source's workers in the background until ctx is done
+	// or Stop is called.
+	Start(ctx context.Context)
+
+	// Stop waits for the workers to finish the messages they are handling.
+	Stop()
+}
+
+func NewPubSub(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+	if source.PubSub == nil {
+		return nil, fmt.Errorf("source %s has no pub sub config", source.UID)
+	}
+
+	switch source.PubSub.Type {
+	case datastore.SqsPubSub:
+		return NewSqsPubSub(source, handler)
+	case datastore.KafkaPubSub:
+		return NewKafkaPubSub(source, handler)
+	case datastore.AmqpPubSub:
+		return NewAmqpPubSub(source, handler)
+	}
+
+	return nil, fmt.Errorf("unsupported pub sub type: %s", source.PubSub.Type)
+}
+
+func workers(source *datastore.Source) int {
+	if source.PubSub.Workers < 1 {
+		return 1
+	}
+
+	return source.PubSub.Workers
+}
+
+// sleep waits for d, it returns false if ctx is done first.
+func sleep(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
+
+// backoff returns how long a worker waits before reconnecting to a broker
+// after its nth consecutive failure.
+func backoff(n int) time.Duration {
+	d := time.Second << uint(n)
+	if n > 5 || d > 30*time.Second {
+		return 30 * time.Second
+	}
+
+	return d
+}
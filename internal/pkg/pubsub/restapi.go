@@ -0,0 +1,327 @@
+package pubsub
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/util"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	restApiTimeout = 30 * time.Second
+
+	// maxRestApiResponseSize guards against an upstream api that returns
+	// more than a page
+	maxRestApiResponseSize = 10 << 20
+
+	// maxRestApiPages is how many pages a single poll fetches, the rest are
+	// fetched by the next poll
+	maxRestApiPages = 100
+)
+
+// restApiPubSub polls an upstream api and hands every record it hasn't seen
+// before to the handler. A record's id is remembered once it is handled, a
+// record that fails ends the poll and is retried by the next one. The cursor
+// and the ids are saved on the source after every page.
+type restApiPubSub struct {
+	source     *datastore.Source
+	cfg        *datastore.RestApiConfig
+	sourceRepo datastore.SourceRepository
+	policy     *net.EgressPolicy
+	handler    HandlerFunc
+	client     *http.Client
+	interval   time.Duration
+
+	// state is only used by the poll loop, changed is set when it has
+	// changes that aren't saved yet
+	state   *datastore.RestApiState
+	seen    map[string]bool
+	changed bool
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewRestApiPubSub(source *datastore.Source, sourceRepo datastore.SourceRepository, policy *net.EgressPolicy, handler HandlerFunc) (PubSub, error) {
+	cfg := source.RestApi
+	if cfg == nil {
+		return nil, fmt.Errorf("source %s has no rest api config", source.UID)
+	}
+
+	if _, err := url.ParseRequestURI(cfg.URL); err != nil {
+		return nil, err
+	}
+
+	interval := cfg.Interval
+	if interval == 0 {
+		interval = datastore.DefaultRestApiInterval
+	}
+
+	state := &datastore.RestApiState{}
+	if source.RestApiState != nil {
+		*state = *source.RestApiState
+	}
+
+	seen := make(map[string]bool, len(state.SeenIDs))
+	for _, id := range state.SeenIDs {
+		seen[id] = true
+	}
+
+	return &restApiPubSub{
+		source:     source,
+		cfg:        cfg,
+		sourceRepo: sourceRepo,
+		policy:     policy,
+		handler:    handler,
+		client:     net.NewClient(restApiTimeout, policy),
+		interval:   time.Duration(interval) * time.Second,
+		state:      state,
+		seen:       seen,
+	}, nil
+}
+
+func (r *restApiPubSub) Start(ctx context.Context) {
+	ctx, r.cancel = context.WithCancel(ctx)
+
+	r.wg.Add(1)
+	go r.run(ctx)
+}
+
+func (r *restApiPubSub) Stop() {
+	r.cancel()
+	r.wg.Wait()
+}
+
+func (r *restApiPubSub) run(ctx context.Context) {
+	defer r.wg.Done()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.poll(ctx); err != nil && ctx.Err() == nil {
+			log.WithError(err).Errorf("rest api source %s failed to poll %s", r.source.UID, r.cfg.URL)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// poll fetches the upstream api's pages, starting from the last page the
+// previous poll fetched, and handles the records on them.
+func (r *restApiPubSub) poll(ctx context.Context) error {
+	for page := 0; page < maxRestApiPages; page++ {
+		body, err := r.fetch(ctx, r.state.Cursor)
+		if err != nil {
+			return err
+		}
+
+		records, next, err := r.parse(body)
+		if err != nil {
+			return err
+		}
+
+		for _, record := range records {
+			if err = r.handle(ctx, record); err != nil {
+				// the records handled before this one aren't handed over
+				// again by the next poll
+				if serr := r.save(ctx); serr != nil {
+					log.WithError(serr).Errorf("rest api source %s failed to save its state", r.source.UID)
+				}
+				return err
+			}
+		}
+
+		last := next == "" || next == r.state.Cursor
+		if !last {
+			// the page is done, the next poll carries on from the one after it
+			r.state.Cursor = next
+			r.changed = true
+		}
+
+		if err = r.save(ctx); err != nil || last {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// save stores the state on the source, if it changed since it was last saved.
+func (r *restApiPubSub) save(ctx context.Context) error {
+	if !r.changed {
+		return nil
+	}
+
+	err := r.sourceRepo.UpdateSourceRestApiState(ctx, r.source.GroupID, r.source.UID, r.state)
+	if err != nil {
+		return err
+	}
+
+	r.changed = false
+	return nil
+}
+
+func (r *restApiPubSub) fetch(ctx context.Context, cursor string) ([]byte, error) {
+	u, err := url.Parse(r.cfg.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.cfg.Pagination != nil && cursor != "" {
+		q := u.Query()
+		q.Set(r.cfg.Pagination.CursorParam, cursor)
+		u.RawQuery = q.Encode()
+	}
+
+	if err = r.policy.CheckURL(u.String()); err != nil {
+		return nil, err
+	}
+
+	method := r.cfg.Method
+	if method == "" {
+		method = http.MethodGet
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("User-Agent", net.DefaultUserAgent())
+	for k, v := range r.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	if auth := r.cfg.Auth; auth != nil {
+		switch auth.Type {
+		case datastore.BasicRestApiAuth:
+			req.SetBasicAuth(auth.BasicAuth.UserName, auth.BasicAuth.Password)
+		case datastore.BearerRestApiAuth:
+			req.Header.Set("Authorization", "Bearer "+auth.BearerToken)
+		case datastore.APIKeyRestApiAuth:
+			req.Header.Set(auth.ApiKey.HeaderName, auth.ApiKey.HeaderValue)
+		}
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxRestApiResponseSize+1))
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("upstream api responded with %s", resp.Status)
+	}
+
+	if len(body) > maxRestApiResponseSize {
+		return nil, errors.New("upstream api response is too large")
+	}
+
+	return body, nil
+}
+
+// parse returns the records in body and the cursor of the next page, if
+// there is one.
+func (r *restApiPubSub) parse(body []byte) ([]interface{}, string, error) {
+	var v interface{}
+
+	// numbers are kept as they are, so ids don't lose precision and records
+	// are passed on unchanged
+	d := json.NewDecoder(bytes.NewReader(body))
+	d.UseNumber()
+	if err := d.Decode(&v); err != nil {
+		return nil, "", fmt.Errorf("upstream api response is not valid json: %w", err)
+	}
+
+	value, ok := util.JSONPath(v, r.cfg.RecordsPath)
+	if !ok {
+		return nil, "", fmt.Errorf("upstream api response has no records at %q", r.cfg.RecordsPath)
+	}
+
+	var records []interface{}
+	switch t := value.(type) {
+	case nil:
+	case []interface{}:
+		records = t
+	default:
+		return nil, "", fmt.Errorf("the records at %q are not an array", r.cfg.RecordsPath)
+	}
+
+	var next string
+	if r.cfg.Pagination != nil {
+		if c, ok := util.JSONPath(v, r.cfg.Pagination.CursorPath); ok && c != nil {
+			next = fmt.Sprint(c)
+		}
+	}
+
+	return records, next, nil
+}
+
+func (r *restApiPubSub) handle(ctx context.Context, record interface{}) error {
+	v, ok := util.JSONPath(record, r.cfg.IDPath)
+	if !ok || v == nil {
+		log.Errorf("skipping record from rest api source %s: it has no id at %q", r.source.UID, r.cfg.IDPath)
+		return nil
+	}
+
+	id := fmt.Sprint(v)
+	if r.seen[id] {
+		return nil
+	}
+
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	msg := &Message{ID: id, Data: data, Headers: httpheader.HTTPHeader{}}
+	if r.cfg.EventType != "" {
+		msg.Headers[EventTypeHeader] = []string{r.cfg.EventType}
+	}
+
+	if err = r.handler(ctx, r.source, msg); err != nil {
+		return err
+	}
+
+	r.remember(id)
+	return nil
+}
+
+// remember adds id to the ids in the state, the oldest ones are forgotten
+// once there are more than datastore.MaxRestApiSeenIDs.
+func (r *restApiPubSub) remember(id string) {
+	r.seen[id] = true
+	r.state.SeenIDs = append(r.state.SeenIDs, id)
+	r.changed = true
+
+	if n := len(r.state.SeenIDs) - datastore.MaxRestApiSeenIDs; n > 0 {
+		for _, old := range r.state.SeenIDs[:n] {
+			delete(r.seen, old)
+		}
+		r.state.SeenIDs = r.state.SeenIDs[n:]
+	}
+}
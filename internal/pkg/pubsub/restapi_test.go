@@ -0,0 +1,220 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/net"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+// upstream is a paginated api, each page holds two records and the cursor
+// of the next page
+type upstream struct {
+	mu      sync.Mutex
+	records []map[string]interface{}
+	status  int
+}
+
+func (u *upstream) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	if r.Header.Get("Authorization") != "Bearer token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	if u.status != 0 {
+		w.WriteHeader(u.status)
+		return
+	}
+
+	start := 0
+	switch r.URL.Query().Get("after") {
+	case "":
+	case "page-2":
+		start = 2
+	case "page-3":
+		start = 4
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	end := start + 2
+	if end > len(u.records) {
+		end = len(u.records)
+	}
+
+	resp := map[string]interface{}{
+		"data": map[string]interface{}{"items": u.records[start:end]},
+	}
+
+	if end < len(u.records) {
+		resp["next"] = map[int]string{2: "page-2", 4: "page-3"}[end]
+	}
+
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func (u *upstream) add(records ...map[string]interface{}) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.records = append(u.records, records...)
+}
+
+func TestRestApiPubSub_Poll(t *testing.T) {
+	up := &upstream{}
+	up.add(
+		map[string]interface{}{"id": 9007199254740993, "name": "first"},
+		map[string]interface{}{"id": 2, "name": "second"},
+		map[string]interface{}{"id": 3, "name": "third"},
+	)
+
+	srv := httptest.NewServer(up)
+	defer srv.Close()
+
+	source := &datastore.Source{
+		UID:     "source-1",
+		GroupID: "group-1",
+		Type:    datastore.RestApiSource,
+		RestApi: &datastore.RestApiConfig{
+			URL:         srv.URL + "/events",
+			Auth:        &datastore.RestApiAuth{Type: datastore.BearerRestApiAuth, BearerToken: "token"},
+			RecordsPath: "data.items",
+			IDPath:      "id",
+			EventType:   "upstream.event",
+			Pagination:  &datastore.RestApiPagination{CursorPath: "next", CursorParam: "after"},
+		},
+	}
+
+	var messages []*Message
+	fail := false
+	handler := func(_ context.Context, _ *datastore.Source, msg *Message) error {
+		if fail {
+			return errors.New("queue unavailable")
+		}
+
+		messages = append(messages, msg)
+		return nil
+	}
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// the state is saved after every page and after a failed record
+	var saved datastore.RestApiState
+	sourceRepo := mocks.NewMockSourceRepository(ctrl)
+	sourceRepo.EXPECT().UpdateSourceRestApiState(gomock.Any(), "group-1", "source-1", gomock.Any()).AnyTimes().
+		DoAndReturn(func(_ context.Context, _, _ string, state *datastore.RestApiState) error {
+			saved = datastore.RestApiState{Cursor: state.Cursor, SeenIDs: append([]string(nil), state.SeenIDs...)}
+			return nil
+		})
+
+	ps, err := NewRestApiPubSub(source, sourceRepo, nil, handler)
+	require.NoError(t, err)
+	r := ps.(*restApiPubSub)
+
+	ctx := context.Background()
+	require.NoError(t, r.poll(ctx))
+	require.Len(t, messages, 3)
+	require.Equal(t, "9007199254740993", messages[0].ID)
+	require.JSONEq(t, `{"id":9007199254740993,"name":"first"}`, string(messages[0].Data))
+	require.Equal(t, []string{"upstream.event"}, messages[2].Headers[EventTypeHeader])
+	require.Equal(t, datastore.RestApiState{Cursor: "page-2", SeenIDs: []string{"9007199254740993", "2", "3"}}, saved)
+
+	// the next poll starts from the last page and only sees the new records
+	up.add(map[string]interface{}{"id": 4, "name": "fourth"}, map[string]interface{}{"name": "no id"})
+	up.add(map[string]interface{}{"id": 5, "name": "fifth"})
+
+	fail = true
+	require.Error(t, r.poll(ctx))
+	require.Len(t, messages, 3)
+
+	fail = false
+	require.NoError(t, r.poll(ctx))
+	require.Len(t, messages, 5)
+	require.Equal(t, "4", messages[3].ID)
+	require.Equal(t, "5", messages[4].ID)
+
+	require.NoError(t, r.poll(ctx))
+	require.Len(t, messages, 5)
+
+	// a poller started from the saved state carries on where this one stopped
+	source.RestApiState = &saved
+	ps, err = NewRestApiPubSub(source, sourceRepo, nil, handler)
+	require.NoError(t, err)
+	require.NoError(t, ps.(*restApiPubSub).poll(ctx))
+	require.Len(t, messages, 5)
+
+	up.mu.Lock()
+	up.status = http.StatusInternalServerError
+	up.mu.Unlock()
+	require.EqualError(t, r.poll(ctx), "upstream api responded with 500 Internal Server Error")
+}
+
+func TestRestApiPubSub_EgressPolicy(t *testing.T) {
+	policy, err := net.NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true})
+	require.NoError(t, err)
+
+	source := &datastore.Source{
+		UID:     "source-1",
+		Type:    datastore.RestApiSource,
+		RestApi: &datastore.RestApiConfig{URL: "http://169.254.169.254/latest/meta-data", IDPath: "id"},
+	}
+
+	ps, err := NewRestApiPubSub(source, nil, policy, nil)
+	require.NoError(t, err)
+
+	err = ps.(*restApiPubSub).poll(context.Background())
+	require.ErrorIs(t, err, net.ErrEgressDenied)
+}
+
+func TestRestApiPubSub_Remember(t *testing.T) {
+	r := &restApiPubSub{state: &datastore.RestApiState{}, seen: map[string]bool{}}
+
+	for i := 0; i <= datastore.MaxRestApiSeenIDs; i++ {
+		r.remember(strconv.Itoa(i))
+	}
+
+	require.Len(t, r.state.SeenIDs, datastore.MaxRestApiSeenIDs)
+	require.Len(t, r.seen, datastore.MaxRestApiSeenIDs)
+	require.False(t, r.seen["0"])
+	require.True(t, r.seen["1"])
+	require.Equal(t, "1", r.state.SeenIDs[0])
+}
+
+func TestRestApiPubSub_Parse(t *testing.T) {
+	r := &restApiPubSub{cfg: &datastore.RestApiConfig{IDPath: "id"}}
+
+	records, next, err := r.parse([]byte(`[{"id":1},{"id":2}]`))
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	require.Empty(t, next)
+
+	_, _, err = r.parse([]byte(`{"id":1}`))
+	require.EqualError(t, err, `the records at "" are not an array`)
+
+	r.cfg.RecordsPath = "data"
+	_, _, err = r.parse([]byte(`{"items":[]}`))
+	require.EqualError(t, err, `upstream api response has no records at "data"`)
+
+	records, _, err = r.parse([]byte(`{"data":null}`))
+	require.NoError(t, err)
+	require.Empty(t, records)
+
+	_, _, err = r.parse([]byte(`<html></html>`))
+	require.Error(t, err)
+}
@@ -0,0 +1,131 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sqs"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	log "github.com/sirupsen/logrus"
+)
+
+// sqsPubSub long polls an sqs queue. Messages are deleted once they are
+// handled, the ones that fail become visible again when their visibility
+// timeout expires and are redelivered.
+type sqsPubSub struct {
+	source  *datastore.Source
+	cfg     *datastore.SQSPubSubConfig
+	handler HandlerFunc
+	client  *sqs.SQS
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewSqsPubSub(source *datastore.Source, handler HandlerFunc) (PubSub, error) {
+	cfg := source.PubSub.Sqs
+
+	sess, err := session.NewSession(&aws.Config{
+		Region:      aws.String(cfg.DefaultRegion),
+		Endpoint:    aws.String(cfg.Endpoint),
+		Credentials: credentials.NewStaticCredentials(cfg.AccessKeyID, cfg.SecretKey, ""),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &sqsPubSub{
+		source:  source,
+		cfg:     cfg,
+		handler: handler,
+		client:  sqs.New(sess),
+	}, nil
+}
+
+func (s *sqsPubSub) Start(ctx context.Context) {
+	ctx, s.cancel = context.WithCancel(ctx)
+
+	for n := 0; n < workers(s.source); n++ {
+		s.wg.Add(1)
+		go s.consume(ctx)
+	}
+}
+
+func (s *sqsPubSub) Stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+func (s *sqsPubSub) consume(ctx context.Context) {
+	defer s.wg.Done()
+
+	var queueURL string
+	failures := 0
+
+	for ctx.Err() == nil {
+		if queueURL == "" {
+			out, err := s.client.GetQueueUrlWithContext(ctx, &sqs.GetQueueUrlInput{QueueName: aws.String(s.cfg.QueueName)})
+			if err != nil {
+				if ctx.Err() != nil {
+					return
+				}
+
+				log.WithError(err).Errorf("pub sub source %s failed to get the url of sqs queue %s", s.source.UID, s.cfg.QueueName)
+				sleep(ctx, backoff(failures))
+				failures++
+				continue
+			}
+
+			queueURL = aws.StringValue(out.QueueUrl)
+		}
+
+		out, err := s.client.ReceiveMessageWithContext(ctx, &sqs.ReceiveMessageInput{
+			QueueUrl:              aws.String(queueURL),
+			MaxNumberOfMessages:   aws.Int64(10),
+			WaitTimeSeconds:       aws.Int64(20),
+			MessageAttributeNames: aws.StringSlice([]string{sqs.QueueAttributeNameAll}),
+		})
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			log.WithError(err).Errorf("pub sub source %s failed to receive messages from sqs queue %s", s.source.UID, s.cfg.QueueName)
+			sleep(ctx, backoff(failures))
+			failures++
+			continue
+		}
+
+		failures = 0
+		for _, m := range out.Messages {
+			msg := &Message{
+				ID:      aws.StringValue(m.MessageId),
+				Data:    []byte(aws.StringValue(m.Body)),
+				Headers: httpheader.HTTPHeader{},
+			}
+
+			for k, v := range m.MessageAttributes {
+				if v.StringValue != nil {
+					msg.Headers[k] = []string{aws.StringValue(v.StringValue)}
+				}
+			}
+
+			if err := s.handler(ctx, s.source, msg); err != nil {
+				log.WithError(err).Errorf("pub sub source %s failed to handle sqs message %s", s.source.UID, msg.ID)
+				continue
+			}
+
+			_, err = s.client.DeleteMessageWithContext(ctx, &sqs.DeleteMessageInput{
+				QueueUrl:      aws.String(queueURL),
+				ReceiptHandle: m.ReceiptHandle,
+			})
+			if err != nil {
+				log.WithError(err).Errorf("pub sub source %s failed to delete sqs message %s", s.source.UID, msg.ID)
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/filter"
 	"github.com/gorilla/websocket"
 	log "github.com/sirupsen/logrus"
 )
@@ -171,7 +172,7 @@ func (c *Client) IsOnline() bool {
 
 func (c *Client) HasEventType(evType string) bool {
 	for _, eventType := range c.EventTypes {
-		if evType == eventType || eventType == "*" {
+		if filter.MatchEventType(eventType, evType) {
 			return true
 		}
 	}
@@ -214,6 +214,18 @@ func TestPingHandler_FailedToSendPongMessage(t *testing.T) {
 	require.Error(t, ErrFailedToSendPongMessage, err)
 }
 
+func TestHasEventType(t *testing.T) {
+	c := &Client{EventTypes: []string{"invoice.*", "payment.**"}}
+
+	require.True(t, c.HasEventType("invoice.paid"))
+	require.True(t, c.HasEventType("payment.card.failed"))
+	require.False(t, c.HasEventType("invoice.line.added"))
+	require.False(t, c.HasEventType("user.created"))
+
+	c.EventTypes = []string{"*"}
+	require.True(t, c.HasEventType("user.created"))
+}
+
 func TestProcessMessage(t *testing.T) {
 
 	type Args struct {
@@ -14,6 +14,7 @@ import (
 	"github.com/gorilla/websocket"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/filter"
 	"github.com/frain-dev/convoy/util"
 	"github.com/google/uuid"
 	log "github.com/sirupsen/logrus"
@@ -209,6 +210,12 @@ func listen(ctx context.Context, group *datastore.Group, app *datastore.Applicat
 		appID = app.UID
 	}
 
+	for _, eventType := range listenRequest.EventTypes {
+		if err := filter.ValidateEventType(eventType); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
 	device, err := r.DeviceRepo.FetchDeviceByID(ctx, listenRequest.DeviceID, appID, group.UID)
 	if err != nil {
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
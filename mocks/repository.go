@@ -10,6 +10,7 @@ import (
 
 	datastore "github.com/frain-dev/convoy/datastore"
 	gomock "github.com/golang/mock/gomock"
+	primitive "go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 // MockAPIKeyRepository is a mock of APIKeyRepository interface.
@@ -161,6 +162,21 @@ func (m *MockEventDeliveryRepository) EXPECT() *MockEventDeliveryRepositoryMockR
 	return m.recorder
 }
 
+// ClaimEventDeliveries mocks base method.
+func (m *MockEventDeliveryRepository) ClaimEventDeliveries(ctx context.Context, ids []string, status []datastore.EventDeliveryStatus) ([]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimEventDeliveries", ctx, ids, status)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimEventDeliveries indicates an expected call of ClaimEventDeliveries.
+func (mr *MockEventDeliveryRepositoryMockRecorder) ClaimEventDeliveries(ctx, ids, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).ClaimEventDeliveries), ctx, ids, status)
+}
+
 // CountDeliveriesByStatus mocks base method.
 func (m *MockEventDeliveryRepository) CountDeliveriesByStatus(arg0 context.Context, arg1 datastore.EventDeliveryStatus, arg2 datastore.SearchParams) (int64, error) {
 	m.ctrl.T.Helper()
@@ -234,6 +250,36 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) FindDiscardedEventDeliveries(
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDiscardedEventDeliveries", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindDiscardedEventDeliveries), ctx, appId, deviceId, searchParams)
 }
 
+// FindDueEventDeliveriesBySubscriptionID mocks base method.
+func (m *MockEventDeliveryRepository) FindDueEventDeliveriesBySubscriptionID(ctx context.Context, subscriptionID string, status []datastore.EventDeliveryStatus, limit int) ([]datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDueEventDeliveriesBySubscriptionID", ctx, subscriptionID, status, limit)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDueEventDeliveriesBySubscriptionID indicates an expected call of FindDueEventDeliveriesBySubscriptionID.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindDueEventDeliveriesBySubscriptionID(ctx, subscriptionID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDueEventDeliveriesBySubscriptionID", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindDueEventDeliveriesBySubscriptionID), ctx, subscriptionID, status, limit)
+}
+
+// FindFirstPendingEventDelivery mocks base method.
+func (m *MockEventDeliveryRepository) FindFirstPendingEventDelivery(ctx context.Context, subscriptionID, orderingKey string) (*datastore.EventDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindFirstPendingEventDelivery", ctx, subscriptionID, orderingKey)
+	ret0, _ := ret[0].(*datastore.EventDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindFirstPendingEventDelivery indicates an expected call of FindFirstPendingEventDelivery.
+func (mr *MockEventDeliveryRepositoryMockRecorder) FindFirstPendingEventDelivery(ctx, subscriptionID, orderingKey interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindFirstPendingEventDelivery", reflect.TypeOf((*MockEventDeliveryRepository)(nil).FindFirstPendingEventDelivery), ctx, subscriptionID, orderingKey)
+}
+
 // FindEventDeliveriesByEventID mocks base method.
 func (m *MockEventDeliveryRepository) FindEventDeliveriesByEventID(arg0 context.Context, arg1 string) ([]datastore.EventDelivery, error) {
 	m.ctrl.T.Helper()
@@ -295,6 +341,36 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) LoadEventDeliveriesPaged(arg0
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadEventDeliveriesPaged", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadEventDeliveriesPaged), arg0, arg1, arg2, arg3, arg4, arg5, arg6)
 }
 
+// LoadUpcomingEventDeliveriesPaged mocks base method.
+func (m *MockEventDeliveryRepository) LoadUpcomingEventDeliveriesPaged(ctx context.Context, groupID, appID string, pageable datastore.Pageable) ([]datastore.EventDelivery, datastore.PaginationData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadUpcomingEventDeliveriesPaged", ctx, groupID, appID, pageable)
+	ret0, _ := ret[0].([]datastore.EventDelivery)
+	ret1, _ := ret[1].(datastore.PaginationData)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LoadUpcomingEventDeliveriesPaged indicates an expected call of LoadUpcomingEventDeliveriesPaged.
+func (mr *MockEventDeliveryRepositoryMockRecorder) LoadUpcomingEventDeliveriesPaged(ctx, groupID, appID, pageable interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadUpcomingEventDeliveriesPaged", reflect.TypeOf((*MockEventDeliveryRepository)(nil).LoadUpcomingEventDeliveriesPaged), ctx, groupID, appID, pageable)
+}
+
+// UpdateExpiryOfEventDelivery mocks base method.
+func (m *MockEventDeliveryRepository) UpdateExpiryOfEventDelivery(ctx context.Context, delivery datastore.EventDelivery, expiresAt primitive.DateTime) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateExpiryOfEventDelivery", ctx, delivery, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateExpiryOfEventDelivery indicates an expected call of UpdateExpiryOfEventDelivery.
+func (mr *MockEventDeliveryRepositoryMockRecorder) UpdateExpiryOfEventDelivery(ctx, delivery, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateExpiryOfEventDelivery", reflect.TypeOf((*MockEventDeliveryRepository)(nil).UpdateExpiryOfEventDelivery), ctx, delivery, expiresAt)
+}
+
 // UpdateEventDeliveryWithAttempt mocks base method.
 func (m *MockEventDeliveryRepository) UpdateEventDeliveryWithAttempt(arg0 context.Context, arg1 datastore.EventDelivery, arg2 datastore.DeliveryAttempt) error {
 	m.ctrl.T.Helper()
@@ -337,6 +413,133 @@ func (mr *MockEventDeliveryRepositoryMockRecorder) UpdateStatusOfEventDelivery(a
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusOfEventDelivery", reflect.TypeOf((*MockEventDeliveryRepository)(nil).UpdateStatusOfEventDelivery), arg0, arg1, arg2)
 }
 
+// MockDeadLetterRepository is a mock of DeadLetterRepository interface.
+type MockDeadLetterRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockDeadLetterRepositoryMockRecorder
+}
+
+// MockDeadLetterRepositoryMockRecorder is the mock recorder for MockDeadLetterRepository.
+type MockDeadLetterRepositoryMockRecorder struct {
+	mock *MockDeadLetterRepository
+}
+
+// NewMockDeadLetterRepository creates a new mock instance.
+func NewMockDeadLetterRepository(ctrl *gomock.Controller) *MockDeadLetterRepository {
+	mock := &MockDeadLetterRepository{ctrl: ctrl}
+	mock.recorder = &MockDeadLetterRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDeadLetterRepository) EXPECT() *MockDeadLetterRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CountDeadLetters mocks base method.
+func (m *MockDeadLetterRepository) CountDeadLetters(arg0 context.Context, arg1 *datastore.DeadLetterFilter) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeadLetters", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeadLetters indicates an expected call of CountDeadLetters.
+func (mr *MockDeadLetterRepositoryMockRecorder) CountDeadLetters(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeadLetters", reflect.TypeOf((*MockDeadLetterRepository)(nil).CountDeadLetters), arg0, arg1)
+}
+
+// CountDeadLettersByGroup mocks base method.
+func (m *MockDeadLetterRepository) CountDeadLettersByGroup(ctx context.Context, status datastore.DeadLetterStatus) (map[string]int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CountDeadLettersByGroup", ctx, status)
+	ret0, _ := ret[0].(map[string]int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CountDeadLettersByGroup indicates an expected call of CountDeadLettersByGroup.
+func (mr *MockDeadLetterRepositoryMockRecorder) CountDeadLettersByGroup(ctx, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CountDeadLettersByGroup", reflect.TypeOf((*MockDeadLetterRepository)(nil).CountDeadLettersByGroup), ctx, status)
+}
+
+// CreateDeadLetter mocks base method.
+func (m *MockDeadLetterRepository) CreateDeadLetter(arg0 context.Context, arg1 *datastore.DeadLetter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateDeadLetter", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateDeadLetter indicates an expected call of CreateDeadLetter.
+func (mr *MockDeadLetterRepositoryMockRecorder) CreateDeadLetter(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateDeadLetter", reflect.TypeOf((*MockDeadLetterRepository)(nil).CreateDeadLetter), arg0, arg1)
+}
+
+// FindDeadLetterByID mocks base method.
+func (m *MockDeadLetterRepository) FindDeadLetterByID(ctx context.Context, groupID string, id string) (*datastore.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeadLetterByID", ctx, groupID, id)
+	ret0, _ := ret[0].(*datastore.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeadLetterByID indicates an expected call of FindDeadLetterByID.
+func (mr *MockDeadLetterRepositoryMockRecorder) FindDeadLetterByID(ctx, groupID, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeadLetterByID", reflect.TypeOf((*MockDeadLetterRepository)(nil).FindDeadLetterByID), ctx, groupID, id)
+}
+
+// FindDeadLettersByIDs mocks base method.
+func (m *MockDeadLetterRepository) FindDeadLettersByIDs(ctx context.Context, groupID string, ids []string) ([]datastore.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FindDeadLettersByIDs", ctx, groupID, ids)
+	ret0, _ := ret[0].([]datastore.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// FindDeadLettersByIDs indicates an expected call of FindDeadLettersByIDs.
+func (mr *MockDeadLetterRepositoryMockRecorder) FindDeadLettersByIDs(ctx, groupID, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FindDeadLettersByIDs", reflect.TypeOf((*MockDeadLetterRepository)(nil).FindDeadLettersByIDs), ctx, groupID, ids)
+}
+
+// LoadDeadLettersPaged mocks base method.
+func (m *MockDeadLetterRepository) LoadDeadLettersPaged(arg0 context.Context, arg1 *datastore.DeadLetterFilter, arg2 datastore.Pageable) ([]datastore.DeadLetter, datastore.PaginationData, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LoadDeadLettersPaged", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]datastore.DeadLetter)
+	ret1, _ := ret[1].(datastore.PaginationData)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LoadDeadLettersPaged indicates an expected call of LoadDeadLettersPaged.
+func (mr *MockDeadLetterRepositoryMockRecorder) LoadDeadLettersPaged(arg0, arg1, arg2 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LoadDeadLettersPaged", reflect.TypeOf((*MockDeadLetterRepository)(nil).LoadDeadLettersPaged), arg0, arg1, arg2)
+}
+
+// UpdateStatusOfDeadLetters mocks base method.
+func (m *MockDeadLetterRepository) UpdateStatusOfDeadLetters(ctx context.Context, ids []string, status datastore.DeadLetterStatus) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateStatusOfDeadLetters", ctx, ids, status)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateStatusOfDeadLetters indicates an expected call of UpdateStatusOfDeadLetters.
+func (mr *MockDeadLetterRepositoryMockRecorder) UpdateStatusOfDeadLetters(ctx, ids, status interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateStatusOfDeadLetters", reflect.TypeOf((*MockDeadLetterRepository)(nil).UpdateStatusOfDeadLetters), ctx, ids, status)
+}
+
 // MockEventRepository is a mock of EventRepository interface.
 type MockEventRepository struct {
 	ctrl     *gomock.Controller
@@ -1388,6 +1591,20 @@ func (mr *MockSourceRepositoryMockRecorder) UpdateSource(ctx, groupID, source in
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSource", reflect.TypeOf((*MockSourceRepository)(nil).UpdateSource), ctx, groupID, source)
 }
 
+// UpdateSourceRestApiState mocks base method.
+func (m *MockSourceRepository) UpdateSourceRestApiState(ctx context.Context, groupID, id string, state *datastore.RestApiState) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateSourceRestApiState", ctx, groupID, id, state)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateSourceRestApiState indicates an expected call of UpdateSourceRestApiState.
+func (mr *MockSourceRepositoryMockRecorder) UpdateSourceRestApiState(ctx, groupID, id, state interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateSourceRestApiState", reflect.TypeOf((*MockSourceRepository)(nil).UpdateSourceRestApiState), ctx, groupID, id, state)
+}
+
 // MockDeviceRepository is a mock of DeviceRepository interface.
 type MockDeviceRepository struct {
 	ctrl     *gomock.Controller
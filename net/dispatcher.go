@@ -2,6 +2,8 @@ package net
 
 import (
 	"bytes"
+	"context"
+	"crypto/tls"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,19 +11,23 @@ import (
 	"net/http"
 	"net/http/httptrace"
 	"net/url"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/util"
 	log "github.com/sirupsen/logrus"
 )
 
 type Dispatcher struct {
-	client *http.Client
+	client  *http.Client
+	policy  *EgressPolicy
+	timeout time.Duration
 }
 
 func NewDispatcher(timeout time.Duration) *Dispatcher {
@@ -30,6 +36,42 @@ func NewDispatcher(timeout time.Duration) *Dispatcher {
 	}
 }
 
+// NewEndpointDispatcher returns a dispatcher that sends requests with the
+// endpoint's tls configuration and enforces the egress policy. It is a
+// default dispatcher when there is neither. The dispatcher has a transport
+// of its own, deliveries go through a DispatcherPool so connections are
+// reused.
+func NewEndpointDispatcher(timeout time.Duration, cfg *datastore.TLSConfiguration, policy *EgressPolicy) (*Dispatcher, error) {
+	if cfg == nil && policy == nil {
+		return NewDispatcher(timeout), nil
+	}
+
+	var tlsConfig *tls.Config
+	if cfg != nil {
+		var err error
+		tlsConfig, err = NewTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Dispatcher{
+		client:  newClient(newTransport(DefaultTransportConfig, tlsConfig, policy), policy),
+		policy:  policy,
+		timeout: timeout,
+	}, nil
+}
+
+// Client returns the client the dispatcher sends requests with.
+func (d *Dispatcher) Client() *http.Client {
+	return d.client
+}
+
+// CheckURL checks rawURL against the dispatcher's egress policy.
+func (d *Dispatcher) CheckURL(rawURL string) error {
+	return d.policy.CheckURL(rawURL)
+}
+
 func (d *Dispatcher) SendRequest(endpoint, method string, jsonData json.RawMessage, g *datastore.Group, hmac string, timestamp string, maxResponseSize int64, headers httpheader.HTTPHeader) (*Response, error) {
 	r := &Response{}
 	signatureHeader := g.Config.Signature.Header.String()
@@ -40,15 +82,7 @@ func (d *Dispatcher) SendRequest(endpoint, method string, jsonData json.RawMessa
 		return r, err
 	}
 
-	req, err := http.NewRequest(method, endpoint, bytes.NewBuffer(jsonData))
-	if err != nil {
-		log.WithError(err).Error("error occurred while creating request")
-		return r, err
-	}
-
-	req.Header.Set(signatureHeader, hmac)
-	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("User-Agent", defaultUserAgent())
+	signatureHeaders := httpheader.HTTPHeader{signatureHeader: []string{hmac}}
 	if g.Config.ReplayAttacks {
 		if util.IsStringEmpty(timestamp) {
 			err := errors.New("timestamp is required")
@@ -56,9 +90,44 @@ func (d *Dispatcher) SendRequest(endpoint, method string, jsonData json.RawMessa
 			r.Error = err.Error()
 			return r, err
 		}
-		req.Header.Set("Convoy-Timestamp", timestamp)
+		signatureHeaders["Convoy-Timestamp"] = []string{timestamp}
 	}
 
+	return d.SendWebhook(context.Background(), endpoint, method, jsonData, signatureHeaders, maxResponseSize, headers)
+}
+
+// SendWebhook sends a signed payload. The signature headers take precedence
+// over the delivery's own headers. The request is cancelled with ctx, or when
+// the dispatcher's timeout passes.
+func (d *Dispatcher) SendWebhook(ctx context.Context, endpoint, method string, jsonData json.RawMessage, signatureHeaders httpheader.HTTPHeader, maxResponseSize int64, headers httpheader.HTTPHeader) (*Response, error) {
+	r := &Response{}
+	if len(signatureHeaders) == 0 {
+		err := errors.New("signature headers are required")
+		log.WithError(err).Error("Dispatcher invalid arguments")
+		r.Error = err.Error()
+		return r, err
+	}
+
+	if d.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, d.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewBuffer(jsonData))
+	if err != nil {
+		log.WithError(err).Error("error occurred while creating request")
+		return r, err
+	}
+
+	for k, v := range signatureHeaders {
+		for _, value := range v {
+			req.Header.Add(k, value)
+		}
+	}
+	req.Header.Add("Content-Type", "application/json")
+	req.Header.Add("User-Agent", DefaultUserAgent())
+
 	header := httpheader.HTTPHeader(req.Header)
 	header.MergeHeaders(headers)
 
@@ -68,6 +137,12 @@ func (d *Dispatcher) SendRequest(endpoint, method string, jsonData json.RawMessa
 	r.URL = req.URL
 	r.Method = req.Method
 
+	if err = d.policy.CheckURL(endpoint); err != nil {
+		log.WithError(err).Error("endpoint url is not allowed")
+		r.Error = err.Error()
+		return r, err
+	}
+
 	err = d.do(req, r, maxResponseSize)
 
 	return r, err
@@ -83,7 +158,7 @@ func (d *Dispatcher) SendCliRequest(url string, method convoy.HttpMethod, apiKey
 	}
 
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("User-Agent", defaultUserAgent())
+	req.Header.Add("User-Agent", DefaultUserAgent())
 	req.Header.Add("Authorization", fmt.Sprintf(" Bearer %s", apiKey))
 
 	r.RequestHeader = req.Header
@@ -105,7 +180,7 @@ func (d *Dispatcher) ForwardCliEvent(url string, method convoy.HttpMethod, jsonD
 	}
 
 	req.Header.Add("Content-Type", "application/json")
-	req.Header.Add("User-Agent", defaultUserAgent())
+	req.Header.Add("User-Agent", DefaultUserAgent())
 
 	header := httpheader.HTTPHeader(req.Header)
 	header.MergeHeaders(headers)
@@ -139,7 +214,8 @@ func updateDispatchHeaders(r *Response, res *http.Response) {
 	r.ResponseHeader = res.Header
 }
 
-func defaultUserAgent() string {
+// DefaultUserAgent is the User-Agent of the requests convoy sends.
+func DefaultUserAgent() string {
 	f, err := convoy.ReadVersion()
 	if err != nil {
 		return "Convoy/v0.1.0"
@@ -152,6 +228,8 @@ func (d *Dispatcher) do(req *http.Request, res *Response, maxResponseSize int64)
 		GotConn: func(connInfo httptrace.GotConnInfo) {
 			res.IP = connInfo.Conn.RemoteAddr().String()
 			log.Infof("IP address resolved to: %s", connInfo.Conn.RemoteAddr())
+
+			metrics.DispatcherConnections().WithLabelValues(strconv.FormatBool(connInfo.Reused)).Inc()
 		},
 	}
 
@@ -163,6 +241,8 @@ func (d *Dispatcher) do(req *http.Request, res *Response, maxResponseSize int64)
 		res.Error = err.Error()
 		return err
 	}
+	defer response.Body.Close()
+
 	updateDispatchHeaders(res, response)
 
 	// io.LimitReader will attempt to read from response.Body until maxResponseSize is reached.
@@ -178,7 +258,6 @@ func (d *Dispatcher) do(req *http.Request, res *Response, maxResponseSize int64)
 		log.WithError(err).Error("couldn't parse response body")
 		return err
 	}
-	defer response.Body.Close()
 
 	return nil
 }
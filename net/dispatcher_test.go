@@ -69,7 +69,7 @@ func TestDispatcher_SendRequest(t *testing.T) {
 				URL:        nil,
 				RequestHeader: http.Header{
 					"Content-Type":                         []string{"application/json"},
-					"User-Agent":                           []string{defaultUserAgent()},
+					"User-Agent":                           []string{DefaultUserAgent()},
 					config.DefaultSignatureHeader.String(): []string{"12345"}, // should equal hmac field above
 				},
 				ResponseHeader: nil,
@@ -117,7 +117,7 @@ func TestDispatcher_SendRequest(t *testing.T) {
 				URL:        nil,
 				RequestHeader: http.Header{
 					"Content-Type":                         []string{"application/json"},
-					"User-Agent":                           []string{defaultUserAgent()},
+					"User-Agent":                           []string{DefaultUserAgent()},
 					"X-Test-Sig":                           []string{"abcdef"},
 					config.DefaultSignatureHeader.String(): []string{"12345"}, // should equal hmac field above
 				},
@@ -163,7 +163,7 @@ func TestDispatcher_SendRequest(t *testing.T) {
 				URL:        nil,
 				RequestHeader: http.Header{
 					"Content-Type":                         []string{"application/json"},
-					"User-Agent":                           []string{defaultUserAgent()},
+					"User-Agent":                           []string{DefaultUserAgent()},
 					config.DefaultSignatureHeader.String(): []string{"12345"}, // should equal hmac field above
 				},
 				ResponseHeader: nil,
@@ -207,7 +207,7 @@ func TestDispatcher_SendRequest(t *testing.T) {
 				Method:     http.MethodPost,
 				RequestHeader: http.Header{
 					"Content-Type":                         []string{"application/json"},
-					"User-Agent":                           []string{defaultUserAgent()},
+					"User-Agent":                           []string{DefaultUserAgent()},
 					config.DefaultSignatureHeader.String(): []string{"12345"}, // should equal hmac field above
 				},
 				ResponseHeader: nil,
@@ -0,0 +1,206 @@
+package net
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"syscall"
+
+	"github.com/frain-dev/convoy/config"
+)
+
+var ErrEgressDenied = errors.New("blocked by egress policy")
+
+// EgressPolicy enforces the instance's egress policy, see
+// config.EgressPolicyConfiguration. Urls are checked before a request is
+// sent and the addresses they resolve to are checked again when the
+// connection is dialled, so a host can't pass the check and then resolve to
+// a blocked address.
+type EgressPolicy struct {
+	blocked []*net.IPNet
+	schemes map[string]bool
+	ports   map[string]bool
+	hosts   []string
+}
+
+// NewEgressPolicy returns the policy described by cfg, or nil when it isn't
+// enabled. A nil policy allows every url.
+func NewEgressPolicy(cfg config.EgressPolicyConfiguration) (*EgressPolicy, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	p := &EgressPolicy{schemes: map[string]bool{}, ports: map[string]bool{}}
+
+	cidrs := cfg.BlockedCIDRs
+	if len(cidrs) == 0 {
+		cidrs = config.DefaultEgressBlockedCIDRs
+	}
+
+	for _, cidr := range cidrs {
+		_, ipNet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocked cidr: %v", err)
+		}
+		p.blocked = append(p.blocked, ipNet)
+	}
+
+	schemes := cfg.AllowedSchemes
+	if len(schemes) == 0 {
+		schemes = []string{"http", "https"}
+	}
+
+	for _, scheme := range schemes {
+		p.schemes[strings.ToLower(scheme)] = true
+	}
+
+	for _, port := range cfg.AllowedPorts {
+		p.ports[strconv.Itoa(port)] = true
+	}
+
+	for _, host := range cfg.AllowedHosts {
+		p.hosts = append(p.hosts, strings.ToLower(host))
+	}
+
+	return p, nil
+}
+
+// CheckURL checks the url's scheme, port and host and, when the host is an
+// ip address, that it isn't blocked.
+func (p *EgressPolicy) CheckURL(rawURL string) error {
+	if p == nil {
+		return nil
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	scheme := strings.ToLower(u.Scheme)
+	if !p.schemes[scheme] {
+		return fmt.Errorf("%w: scheme %s is not allowed", ErrEgressDenied, u.Scheme)
+	}
+
+	port := u.Port()
+	if port == "" {
+		port = "80"
+		if scheme == "https" {
+			port = "443"
+		}
+	}
+
+	if len(p.ports) > 0 && !p.ports[port] {
+		return fmt.Errorf("%w: port %s is not allowed", ErrEgressDenied, port)
+	}
+
+	host := strings.ToLower(u.Hostname())
+	if !p.allowsHost(host) {
+		return fmt.Errorf("%w: host %s is not allowed", ErrEgressDenied, host)
+	}
+
+	if ip := net.ParseIP(host); ip != nil {
+		return p.checkIP(ip)
+	}
+
+	return nil
+}
+
+// CheckEndpoint checks the url like CheckURL and, when its host is a
+// domain, that none of the addresses it resolves to are blocked. A host
+// that can't be resolved is checked when deliveries are sent to it.
+func (p *EgressPolicy) CheckEndpoint(ctx context.Context, rawURL string) error {
+	if p == nil {
+		return nil
+	}
+
+	if err := p.CheckURL(rawURL); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+
+	if net.ParseIP(u.Hostname()) != nil {
+		return nil
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, u.Hostname())
+	if err != nil {
+		return nil
+	}
+
+	for _, addr := range addrs {
+		if err = p.checkIP(addr.IP); err != nil {
+			return fmt.Errorf("%w: %s resolves to %s, a blocked address", ErrEgressDenied, u.Hostname(), addr.IP)
+		}
+	}
+
+	return nil
+}
+
+func (p *EgressPolicy) allowsHost(host string) bool {
+	if len(p.hosts) == 0 {
+		return true
+	}
+
+	for _, h := range p.hosts {
+		if h == host {
+			return true
+		}
+
+		if strings.HasPrefix(h, "*.") && strings.HasSuffix(host, h[1:]) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *EgressPolicy) checkIP(ip net.IP) error {
+	for _, ipNet := range p.blocked {
+		if ipNet.Contains(ip) {
+			return fmt.Errorf("%w: address %s is in the blocked range %s", ErrEgressDenied, ip, ipNet)
+		}
+	}
+
+	return nil
+}
+
+// maxRedirects is how many redirects a request follows, the same as
+// net/http's default.
+const maxRedirects = 10
+
+// checkRedirect is the CheckRedirect func of the clients that enforce the
+// policy, a url a request is redirected to is checked like the first one.
+// Dialling only checks the addresses, not the scheme, port or host.
+func (p *EgressPolicy) checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	return p.CheckURL(req.URL.String())
+}
+
+// control is the dialer's Control func, it's called with the address that
+// is being connected to after the host was resolved.
+func (p *EgressPolicy) control(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return fmt.Errorf("%w: %s is not an ip address", ErrEgressDenied, host)
+	}
+
+	return p.checkIP(ip)
+}
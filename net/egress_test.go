@@ -0,0 +1,163 @@
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewEgressPolicy(t *testing.T) {
+	p, err := NewEgressPolicy(config.EgressPolicyConfiguration{})
+	require.NoError(t, err)
+	require.Nil(t, p)
+
+	// a nil policy allows every url
+	require.NoError(t, p.CheckURL("http://localhost:27017"))
+
+	_, err = NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true, BlockedCIDRs: []string{"10.0.0.0"}})
+	require.Error(t, err)
+}
+
+func TestEgressPolicy_CheckURL(t *testing.T) {
+	p, err := NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true})
+	require.NoError(t, err)
+
+	restricted, err := NewEgressPolicy(config.EgressPolicyConfiguration{
+		Enabled:        true,
+		AllowedSchemes: []string{"https"},
+		AllowedPorts:   []int{443, 8443},
+		AllowedHosts:   []string{"hooks.example.com", "*.partner.io"},
+	})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		policy  *EgressPolicy
+		url     string
+		wantErr string
+	}{
+		{name: "public host", policy: p, url: "https://example.com/webhook"},
+		{name: "public ip", policy: p, url: "http://93.184.216.34:8080"},
+		{name: "instance metadata", policy: p, url: "http://169.254.169.254/latest/meta-data", wantErr: "blocked by egress policy: address 169.254.169.254 is in the blocked range 169.254.0.0/16"},
+		{name: "loopback", policy: p, url: "http://127.0.0.1:27017", wantErr: "blocked by egress policy: address 127.0.0.1 is in the blocked range 127.0.0.0/8"},
+		{name: "ipv6 loopback", policy: p, url: "http://[::1]:6379", wantErr: "blocked by egress policy: address ::1 is in the blocked range ::1/128"},
+		{name: "ipv4 mapped ipv6", policy: p, url: "http://[::ffff:10.0.0.1]", wantErr: "blocked by egress policy: address 10.0.0.1 is in the blocked range 10.0.0.0/8"},
+		{name: "scheme", policy: p, url: "ftp://example.com", wantErr: "blocked by egress policy: scheme ftp is not allowed"},
+		{name: "allowed host", policy: restricted, url: "https://hooks.example.com"},
+		{name: "allowed subdomain", policy: restricted, url: "https://eu.partner.io:8443/hooks"},
+		{name: "host not allowed", policy: restricted, url: "https://example.com", wantErr: "blocked by egress policy: host example.com is not allowed"},
+		{name: "wildcard doesn't allow the domain", policy: restricted, url: "https://partner.io", wantErr: "blocked by egress policy: host partner.io is not allowed"},
+		{name: "port not allowed", policy: restricted, url: "https://hooks.example.com:9000", wantErr: "blocked by egress policy: port 9000 is not allowed"},
+		{name: "scheme not allowed", policy: restricted, url: "http://hooks.example.com:443", wantErr: "blocked by egress policy: scheme http is not allowed"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.policy.CheckURL(tc.url)
+			if tc.wantErr == "" {
+				require.NoError(t, err)
+				return
+			}
+
+			require.EqualError(t, err, tc.wantErr)
+			require.True(t, errors.Is(err, ErrEgressDenied))
+		})
+	}
+}
+
+func TestEgressPolicy_CheckEndpoint(t *testing.T) {
+	p, err := NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true})
+	require.NoError(t, err)
+
+	err = p.CheckEndpoint(context.Background(), "http://localhost:27017")
+	require.True(t, errors.Is(err, ErrEgressDenied))
+}
+
+func TestDispatcher_SendWebhook_EgressPolicy(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	signatureHeaders := httpheader.HTTPHeader{"X-Convoy-Signature": []string{"signature"}}
+	data := json.RawMessage(`{"event": "invoice.paid"}`)
+
+	blocking, err := NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true})
+	require.NoError(t, err)
+
+	d, err := NewEndpointDispatcher(10*time.Second, nil, blocking)
+	require.NoError(t, err)
+
+	// the ip address is rejected before the request is sent
+	resp, err := d.SendWebhook(context.Background(), srv.URL, http.MethodPost, data, signatureHeaders, 1024, nil)
+	require.True(t, errors.Is(err, ErrEgressDenied))
+	require.Equal(t, err.Error(), resp.Error)
+	require.NotNil(t, resp.URL)
+
+	// a host that resolves to a blocked address is rejected when it's dialled
+	localhost := strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)
+	resp, err = d.SendWebhook(context.Background(), localhost, http.MethodPost, data, signatureHeaders, 1024, nil)
+	require.True(t, errors.Is(err, ErrEgressDenied))
+	require.Contains(t, resp.Error, "blocked by egress policy: address")
+
+	allowing, err := NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true, BlockedCIDRs: []string{"10.0.0.0/8"}})
+	require.NoError(t, err)
+
+	d, err = NewEndpointDispatcher(10*time.Second, nil, allowing)
+	require.NoError(t, err)
+
+	resp, err = d.SendWebhook(context.Background(), localhost, http.MethodPost, data, signatureHeaders, 1024, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestDispatcher_SendWebhook_EgressPolicyRedirect(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/allowed":
+			http.Redirect(w, r, srv.URL+"/ok", http.StatusTemporaryRedirect)
+		case "/denied":
+			http.Redirect(w, r, strings.Replace(srv.URL, "127.0.0.1", "localhost", 1)+"/ok", http.StatusTemporaryRedirect)
+		default:
+			w.WriteHeader(http.StatusOK)
+		}
+	}))
+	defer srv.Close()
+
+	signatureHeaders := httpheader.HTTPHeader{"X-Convoy-Signature": []string{"signature"}}
+	data := json.RawMessage(`{"event": "invoice.paid"}`)
+
+	policy, err := NewEgressPolicy(config.EgressPolicyConfiguration{
+		Enabled:      true,
+		BlockedCIDRs: []string{"10.0.0.0/8"},
+		AllowedHosts: []string{"127.0.0.1"},
+	})
+	require.NoError(t, err)
+
+	d, err := NewEndpointDispatcher(10*time.Second, nil, policy)
+	require.NoError(t, err)
+
+	pooled, err := NewDispatcherPool(DefaultTransportConfig, policy).Dispatcher(10*time.Second, nil)
+	require.NoError(t, err)
+
+	for _, d := range []*Dispatcher{d, pooled} {
+		resp, err := d.SendWebhook(context.Background(), srv.URL+"/allowed", http.MethodPost, data, signatureHeaders, 1024, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		// the host the request is redirected to isn't allowed
+		_, err = d.SendWebhook(context.Background(), srv.URL+"/denied", http.MethodPost, data, signatureHeaders, 1024, nil)
+		require.True(t, errors.Is(err, ErrEgressDenied))
+		require.Contains(t, err.Error(), "host localhost is not allowed")
+	}
+}
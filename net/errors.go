@@ -0,0 +1,44 @@
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/frain-dev/convoy/datastore"
+)
+
+// ClassifyError returns the kind of network error a request failed with.
+func ClassifyError(err error) datastore.NetworkError {
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return datastore.DNSNetworkError
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return datastore.ConnectionRefusedNetworkError
+	}
+
+	if errors.Is(err, syscall.ECONNRESET) {
+		return datastore.ConnectionResetNetworkError
+	}
+
+	var recordErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var certInvalidErr x509.CertificateInvalidError
+	if errors.As(err, &recordErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &certInvalidErr) {
+		return datastore.TLSNetworkError
+	}
+
+	var netErr net.Error
+	if errors.Is(err, os.ErrDeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return datastore.TimeoutNetworkError
+	}
+
+	return datastore.UnknownNetworkError
+}
@@ -0,0 +1,60 @@
+package net
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	stdnet "net"
+	"net/url"
+	"os"
+	"syscall"
+	"testing"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		expected datastore.NetworkError
+	}{
+		{
+			name:     "dns",
+			err:      &url.Error{Op: "Post", URL: "https://example.invalid", Err: &stdnet.DNSError{Err: "no such host", Name: "example.invalid"}},
+			expected: datastore.DNSNetworkError,
+		},
+		{
+			name:     "connection refused",
+			err:      &stdnet.OpError{Op: "dial", Net: "tcp", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)},
+			expected: datastore.ConnectionRefusedNetworkError,
+		},
+		{
+			name:     "connection reset",
+			err:      &stdnet.OpError{Op: "read", Net: "tcp", Err: os.NewSyscallError("read", syscall.ECONNRESET)},
+			expected: datastore.ConnectionResetNetworkError,
+		},
+		{
+			name:     "tls",
+			err:      fmt.Errorf("handshake: %w", x509.UnknownAuthorityError{}),
+			expected: datastore.TLSNetworkError,
+		},
+		{
+			name:     "timeout",
+			err:      &url.Error{Op: "Post", URL: "https://example.com", Err: os.ErrDeadlineExceeded},
+			expected: datastore.TimeoutNetworkError,
+		},
+		{
+			name:     "unknown",
+			err:      errors.New("something went wrong"),
+			expected: datastore.UnknownNetworkError,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, ClassifyError(tc.err))
+		})
+	}
+}
@@ -0,0 +1,53 @@
+package net
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/util"
+)
+
+var tlsVersions = map[datastore.TLSVersion]uint16{
+	datastore.TLSVersion10: tls.VersionTLS10,
+	datastore.TLSVersion11: tls.VersionTLS11,
+	datastore.TLSVersion12: tls.VersionTLS12,
+	datastore.TLSVersion13: tls.VersionTLS13,
+}
+
+// NewTLSConfig builds the client TLS config for an endpoint's tls
+// configuration.
+func NewTLSConfig(cfg *datastore.TLSConfiguration) (*tls.Config, error) {
+	tlsConfig := &tls.Config{}
+
+	if !util.IsStringEmpty(cfg.ClientCert) || !util.IsStringEmpty(cfg.ClientKey) {
+		cert, err := tls.X509KeyPair([]byte(cfg.ClientCert), []byte(cfg.ClientKey))
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %v", err)
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
+	if !util.IsStringEmpty(cfg.CACert) {
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM([]byte(cfg.CACert)) {
+			return nil, errors.New("invalid ca certificate: no pem encoded certificates found")
+		}
+
+		tlsConfig.RootCAs = pool
+	}
+
+	if cfg.MinVersion != "" {
+		v, ok := tlsVersions[cfg.MinVersion]
+		if !ok {
+			return nil, fmt.Errorf("unsupported tls version %s", cfg.MinVersion)
+		}
+
+		tlsConfig.MinVersion = v
+	}
+
+	return tlsConfig, nil
+}
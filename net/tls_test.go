@@ -0,0 +1,173 @@
+package net
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	stdnet "net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert    *x509.Certificate
+	key     *ecdsa.PrivateKey
+	certPEM string
+	keyPEM  string
+}
+
+// newTestCert issues a certificate signed by parent, or a self signed CA
+// certificate when parent is nil.
+func newTestCert(t *testing.T, parent *testCert, usage x509.ExtKeyUsage) *testCert {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "convoy test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+		IPAddresses:  []stdnet.IP{stdnet.ParseIP("127.0.0.1")},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	return &testCert{
+		cert:    cert,
+		key:     key,
+		certPEM: string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+		keyPEM:  string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})),
+	}
+}
+
+func TestDispatcher_SendWebhook_MutualTLS(t *testing.T) {
+	ca := newTestCert(t, nil, x509.ExtKeyUsageAny)
+	serverCert := newTestCert(t, ca, x509.ExtKeyUsageServerAuth)
+	clientCert := newTestCert(t, ca, x509.ExtKeyUsageClientAuth)
+
+	serverKeyPair, err := tls.X509KeyPair([]byte(serverCert.certPEM), []byte(serverCert.keyPEM))
+	require.NoError(t, err)
+
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(ca.cert)
+
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(r.TLS.PeerCertificates[0].Subject.CommonName))
+	}))
+	srv.TLS = &tls.Config{
+		Certificates: []tls.Certificate{serverKeyPair},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	}
+	srv.StartTLS()
+	defer srv.Close()
+
+	signatureHeaders := httpheader.HTTPHeader{"X-Convoy-Signature": []string{"signature"}}
+	data := json.RawMessage(`{"event": "invoice.paid"}`)
+
+	tests := []struct {
+		name    string
+		cfg     *datastore.TLSConfiguration
+		wantErr bool
+	}{
+		{
+			name: "should_send_with_client_certificate",
+			cfg: &datastore.TLSConfiguration{
+				ClientCert: clientCert.certPEM,
+				ClientKey:  clientCert.keyPEM,
+				CACert:     ca.certPEM,
+				MinVersion: datastore.TLSVersion12,
+			},
+		},
+		{
+			name:    "should_fail_without_client_certificate",
+			cfg:     &datastore.TLSConfiguration{CACert: ca.certPEM},
+			wantErr: true,
+		},
+		{
+			name: "should_fail_without_ca_certificate",
+			cfg: &datastore.TLSConfiguration{
+				ClientCert: clientCert.certPEM,
+				ClientKey:  clientCert.keyPEM,
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			d, err := NewEndpointDispatcher(10*time.Second, tc.cfg, nil)
+			require.NoError(t, err)
+
+			resp, err := d.SendWebhook(context.Background(), srv.URL, http.MethodPost, data, signatureHeaders, 1024, nil)
+			if tc.wantErr {
+				require.Error(t, err)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "convoy test", string(resp.Body))
+		})
+	}
+}
+
+func TestNewTLSConfig(t *testing.T) {
+	ca := newTestCert(t, nil, x509.ExtKeyUsageAny)
+	clientCert := newTestCert(t, ca, x509.ExtKeyUsageClientAuth)
+
+	cfg, err := NewTLSConfig(&datastore.TLSConfiguration{
+		ClientCert: clientCert.certPEM,
+		ClientKey:  clientCert.keyPEM,
+		CACert:     ca.certPEM,
+		MinVersion: datastore.TLSVersion13,
+	})
+	require.NoError(t, err)
+	require.Len(t, cfg.Certificates, 1)
+	require.NotNil(t, cfg.RootCAs)
+	require.Equal(t, uint16(tls.VersionTLS13), cfg.MinVersion)
+
+	_, err = NewTLSConfig(&datastore.TLSConfiguration{ClientCert: clientCert.certPEM})
+	require.Error(t, err)
+
+	_, err = NewTLSConfig(&datastore.TLSConfiguration{ClientCert: clientCert.certPEM, ClientKey: ca.keyPEM})
+	require.Error(t, err)
+
+	_, err = NewTLSConfig(&datastore.TLSConfiguration{CACert: "not a certificate"})
+	require.EqualError(t, err, "invalid ca certificate: no pem encoded certificates found")
+
+	_, err = NewTLSConfig(&datastore.TLSConfiguration{MinVersion: "1.4"})
+	require.EqualError(t, err, "unsupported tls version 1.4")
+}
@@ -0,0 +1,265 @@
+package net
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/hex"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TransportConfig tunes the connection pool of a transport.
+type TransportConfig struct {
+	MaxIdleConns        int
+	MaxIdleConnsPerHost int
+	MaxConnsPerHost     int
+	IdleConnTimeout     time.Duration
+	DisableHTTP2        bool
+}
+
+// DefaultTransportConfig keeps more idle connections to each host than
+// net/http does, deliveries tend to go to a few busy endpoints.
+var DefaultTransportConfig = TransportConfig{
+	MaxIdleConns:        1000,
+	MaxIdleConnsPerHost: 100,
+	IdleConnTimeout:     90 * time.Second,
+}
+
+// NewTransportConfig returns the transport config for the dispatcher
+// configuration, unset fields take their default.
+func NewTransportConfig(c config.DispatcherConfiguration) TransportConfig {
+	cfg := DefaultTransportConfig
+	if c.MaxIdleConns > 0 {
+		cfg.MaxIdleConns = c.MaxIdleConns
+	}
+
+	if c.MaxIdleConnsPerHost > 0 {
+		cfg.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+
+	if c.MaxConnsPerHost > 0 {
+		cfg.MaxConnsPerHost = c.MaxConnsPerHost
+	}
+
+	if c.IdleConnTimeout > 0 {
+		cfg.IdleConnTimeout = time.Duration(c.IdleConnTimeout) * time.Second
+	}
+
+	cfg.DisableHTTP2 = c.DisableHTTP2
+	return cfg
+}
+
+// maxPooledTLSTransports caps the transports kept for endpoints with their
+// own tls configuration, the pool starts over when it is reached.
+const maxPooledTLSTransports = 1000
+
+// DispatcherPool hands out dispatchers that share long lived transports, so
+// deliveries to an endpoint reuse its connections. Endpoints without a tls
+// configuration share a transport, each tls configuration gets its own.
+type DispatcherPool struct {
+	cfg    TransportConfig
+	policy *EgressPolicy
+
+	// transport, when set, is used by endpoints without a tls configuration
+	// in place of the shared transport
+	transport http.RoundTripper
+
+	mu         sync.Mutex
+	shared     *http.Client
+	transports map[string]*http.Client
+}
+
+// PoolOption configures a DispatcherPool.
+type PoolOption func(*DispatcherPool)
+
+// WithTransport makes endpoints without a tls configuration send their
+// deliveries through rt, it is meant for tests that stub the endpoints.
+func WithTransport(rt http.RoundTripper) PoolOption {
+	return func(p *DispatcherPool) {
+		p.transport = rt
+	}
+}
+
+func NewDispatcherPool(cfg TransportConfig, policy *EgressPolicy, opts ...PoolOption) *DispatcherPool {
+	p := &DispatcherPool{
+		cfg:        cfg,
+		policy:     policy,
+		transports: map[string]*http.Client{},
+	}
+
+	for _, opt := range opts {
+		opt(p)
+	}
+
+	return p
+}
+
+// Dispatcher returns a dispatcher for an endpoint with the tls configuration
+// cfg, its requests time out after timeout.
+func (p *DispatcherPool) Dispatcher(timeout time.Duration, cfg *datastore.TLSConfiguration) (*Dispatcher, error) {
+	client, err := p.client(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Dispatcher{client: client, policy: p.policy, timeout: timeout}, nil
+}
+
+func (p *DispatcherPool) client(cfg *datastore.TLSConfiguration) (*http.Client, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if cfg == nil {
+		if p.shared == nil {
+			p.shared = newClient(p.sharedTransport(), p.policy)
+		}
+
+		return p.shared, nil
+	}
+
+	key := tlsConfigKey(cfg)
+	if c, ok := p.transports[key]; ok {
+		return c, nil
+	}
+
+	tlsConfig, err := NewTLSConfig(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(p.transports) >= maxPooledTLSTransports {
+		for _, c := range p.transports {
+			c.CloseIdleConnections()
+		}
+		p.transports = map[string]*http.Client{}
+	}
+
+	c := newClient(newTransport(p.cfg, tlsConfig, p.policy), p.policy)
+	p.transports[key] = c
+	return c, nil
+}
+
+// sharedTransport returns the transport of endpoints without a tls
+// configuration.
+func (p *DispatcherPool) sharedTransport() http.RoundTripper {
+	if p.transport != nil {
+		return p.transport
+	}
+
+	return newTransport(p.cfg, nil, p.policy)
+}
+
+// CloseIdleConnections closes the idle connections of every transport in the
+// pool.
+func (p *DispatcherPool) CloseIdleConnections() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.shared != nil {
+		p.shared.CloseIdleConnections()
+	}
+
+	for _, c := range p.transports {
+		c.CloseIdleConnections()
+	}
+}
+
+// newClient returns a client that sends requests through rt, it checks the
+// urls requests are redirected to against policy.
+func newClient(rt http.RoundTripper, policy *EgressPolicy) *http.Client {
+	c := &http.Client{Transport: rt}
+	if policy != nil {
+		c.CheckRedirect = policy.checkRedirect
+	}
+
+	return c
+}
+
+// NewClient returns a client for requests to urls users configure, other
+// than endpoints. Like the dispatchers' clients it checks the addresses it
+// dials and the urls it is redirected to against policy, the urls requests
+// are sent to must be checked with policy.CheckURL.
+func NewClient(timeout time.Duration, policy *EgressPolicy) *http.Client {
+	c := newClient(newTransport(DefaultTransportConfig, nil, policy), policy)
+	c.Timeout = timeout
+	return c
+}
+
+func tlsConfigKey(cfg *datastore.TLSConfiguration) string {
+	h := sha256.New()
+	for _, s := range []string{cfg.ClientCert, cfg.ClientKey, cfg.CACert, string(cfg.MinVersion)} {
+		h.Write([]byte(s))
+		h.Write([]byte{0})
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func newTransport(cfg TransportConfig, tlsConfig *tls.Config, policy *EgressPolicy) *http.Transport {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if policy != nil {
+		// the policy checks the addresses that are dialled, going through a
+		// proxy would hide them
+		proxy = nil
+		dialer.Control = policy.control
+	}
+
+	transport := &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           countConns(dialer.DialContext, metrics.DispatcherOpenConnections()),
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		MaxIdleConns:          cfg.MaxIdleConns,
+		MaxIdleConnsPerHost:   cfg.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       cfg.MaxConnsPerHost,
+		IdleConnTimeout:       cfg.IdleConnTimeout,
+		ForceAttemptHTTP2:     !cfg.DisableHTTP2,
+	}
+
+	if cfg.DisableHTTP2 {
+		// a non nil, empty map turns off http/2
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	return transport
+}
+
+type dialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// countConns wraps dial so the connections it opens are counted in open.
+func countConns(dial dialFunc, open prometheus.Gauge) dialFunc {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		conn, err := dial(ctx, network, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		open.Inc()
+		return &countedConn{Conn: conn, open: open}, nil
+	}
+}
+
+type countedConn struct {
+	net.Conn
+	open prometheus.Gauge
+	once sync.Once
+}
+
+func (c *countedConn) Close() error {
+	c.once.Do(c.open.Dec)
+	return c.Conn.Close()
+}
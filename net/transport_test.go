@@ -0,0 +1,217 @@
+package net
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/pem"
+	stdnet "net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/config"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testPayload          = json.RawMessage(`{"event": "invoice.completed"}`)
+	testSignatureHeaders = httpheader.HTTPHeader{"X-Convoy-Signature": []string{"signature"}}
+)
+
+// newCountingServer returns a server that counts the connections opened to
+// it.
+func newCountingServer(h http.HandlerFunc, tls bool) (*httptest.Server, *int32) {
+	var conns int32
+	srv := httptest.NewUnstartedServer(h)
+	srv.Config.ConnState = func(_ stdnet.Conn, state http.ConnState) {
+		if state == http.StateNew {
+			atomic.AddInt32(&conns, 1)
+		}
+	}
+
+	if tls {
+		srv.StartTLS()
+	} else {
+		srv.Start()
+	}
+
+	return srv, &conns
+}
+
+func caCert(srv *httptest.Server) *datastore.TLSConfiguration {
+	cert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw})
+	return &datastore.TLSConfiguration{CACert: string(cert)}
+}
+
+func TestNewTransportConfig(t *testing.T) {
+	require.Equal(t, DefaultTransportConfig, NewTransportConfig(config.DispatcherConfiguration{}))
+
+	cfg := NewTransportConfig(config.DispatcherConfiguration{MaxIdleConnsPerHost: 10, IdleConnTimeout: 30, DisableHTTP2: true})
+	require.Equal(t, 10, cfg.MaxIdleConnsPerHost)
+	require.Equal(t, 30*time.Second, cfg.IdleConnTimeout)
+	require.True(t, cfg.DisableHTTP2)
+	require.Equal(t, DefaultTransportConfig.MaxIdleConns, cfg.MaxIdleConns)
+}
+
+func TestDispatcherPool_reusesConnections(t *testing.T) {
+	metrics.Reset()
+
+	srv, conns := newCountingServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, true)
+	defer srv.Close()
+
+	pool := NewDispatcherPool(DefaultTransportConfig, nil)
+	defer pool.CloseIdleConnections()
+
+	for i := 0; i < 5; i++ {
+		d, err := pool.Dispatcher(10*time.Second, caCert(srv))
+		require.NoError(t, err)
+
+		resp, err := d.SendWebhook(context.Background(), srv.URL, http.MethodPost, testPayload, testSignatureHeaders, 1024, nil)
+		require.NoError(t, err)
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+	}
+
+	require.Equal(t, int32(1), atomic.LoadInt32(conns))
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.DispatcherConnections().WithLabelValues("false")))
+	require.Equal(t, float64(4), testutil.ToFloat64(metrics.DispatcherConnections().WithLabelValues("true")))
+}
+
+func TestCountConns(t *testing.T) {
+	open := prometheus.NewGauge(prometheus.GaugeOpts{Name: "open_connections"})
+
+	dial := countConns(func(context.Context, string, string) (stdnet.Conn, error) {
+		c, _ := stdnet.Pipe()
+		return c, nil
+	}, open)
+
+	a, err := dial(context.Background(), "tcp", "example.com:443")
+	require.NoError(t, err)
+	b, err := dial(context.Background(), "tcp", "example.com:443")
+	require.NoError(t, err)
+	require.Equal(t, float64(2), testutil.ToFloat64(open))
+
+	// a connection is only counted out once
+	require.NoError(t, a.Close())
+	_ = a.Close()
+	require.Equal(t, float64(1), testutil.ToFloat64(open))
+
+	require.NoError(t, b.Close())
+	require.Equal(t, float64(0), testutil.ToFloat64(open))
+}
+
+func TestDispatcherPool_Dispatcher(t *testing.T) {
+	srv, _ := newCountingServer(func(w http.ResponseWriter, r *http.Request) {}, true)
+	defer srv.Close()
+
+	pool := NewDispatcherPool(DefaultTransportConfig, nil)
+
+	a, err := pool.Dispatcher(time.Second, nil)
+	require.NoError(t, err)
+	b, err := pool.Dispatcher(2*time.Second, nil)
+	require.NoError(t, err)
+	require.Same(t, a.client, b.client)
+
+	// endpoints with the same tls configuration share a transport
+	c, err := pool.Dispatcher(time.Second, caCert(srv))
+	require.NoError(t, err)
+	d, err := pool.Dispatcher(time.Second, caCert(srv))
+	require.NoError(t, err)
+	require.Same(t, c.client, d.client)
+	require.NotSame(t, a.client, c.client)
+
+	_, err = pool.Dispatcher(time.Second, &datastore.TLSConfiguration{CACert: "not a certificate"})
+	require.Error(t, err)
+}
+
+func TestDispatcherPool_WithTransport(t *testing.T) {
+	var sent int32
+	rt := roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		atomic.AddInt32(&sent, 1)
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody, Header: http.Header{}, Request: r}, nil
+	})
+
+	pool := NewDispatcherPool(DefaultTransportConfig, nil, WithTransport(rt))
+	d, err := pool.Dispatcher(time.Second, nil)
+	require.NoError(t, err)
+
+	resp, err := d.SendWebhook(context.Background(), "https://example.com", http.MethodPost, testPayload, testSignatureHeaders, 1024, nil)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.Equal(t, int32(1), atomic.LoadInt32(&sent))
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) {
+	return f(r)
+}
+
+func TestDispatcher_SendWebhook_Timeout(t *testing.T) {
+	srv, _ := newCountingServer(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}, false)
+	defer srv.Close()
+
+	pool := NewDispatcherPool(DefaultTransportConfig, nil)
+	d, err := pool.Dispatcher(50*time.Millisecond, nil)
+	require.NoError(t, err)
+
+	_, err = d.SendWebhook(context.Background(), srv.URL, http.MethodPost, testPayload, testSignatureHeaders, 1024, nil)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// BenchmarkDispatcher_SendWebhook compares sending deliveries to a tls
+// endpoint through the pool with building a transport for every delivery.
+func BenchmarkDispatcher_SendWebhook(b *testing.B) {
+	srv, _ := newCountingServer(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}, true)
+	defer srv.Close()
+
+	send := func(b *testing.B, d *Dispatcher) {
+		_, err := d.SendWebhook(context.Background(), srv.URL, http.MethodPost, testPayload, testSignatureHeaders, 1024, nil)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+
+	b.Run("pooled", func(b *testing.B) {
+		pool := NewDispatcherPool(DefaultTransportConfig, nil)
+		defer pool.CloseIdleConnections()
+
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				d, err := pool.Dispatcher(10*time.Second, caCert(srv))
+				if err != nil {
+					b.Fatal(err)
+				}
+				send(b, d)
+			}
+		})
+	})
+
+	b.Run("transport_per_delivery", func(b *testing.B) {
+		b.RunParallel(func(pb *testing.PB) {
+			for pb.Next() {
+				d, err := NewEndpointDispatcher(10*time.Second, caCert(srv), nil)
+				if err != nil {
+					b.Fatal(err)
+				}
+				send(b, d)
+				d.client.CloseIdleConnections()
+			}
+		})
+	})
+}
@@ -0,0 +1,60 @@
+package filter
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MatchEventType reports whether eventType matches pattern. Event types are
+// dot separated segments. In a pattern, a * segment matches exactly one
+// segment and a ** segment matches zero or more, so invoice.* matches
+// invoice.paid, *.created matches user.created and payment.** matches
+// payment, payment.failed and payment.card.failed. A bare * matches every
+// event type.
+func MatchEventType(pattern, eventType string) bool {
+	if pattern == "*" || pattern == eventType {
+		return true
+	}
+
+	return matchSegments(strings.Split(pattern, "."), strings.Split(eventType, "."))
+}
+
+func matchSegments(pattern, segments []string) bool {
+	for len(pattern) > 0 {
+		switch pattern[0] {
+		case "**":
+			// try every number of segments ** could stand for
+			for i := 0; i <= len(segments); i++ {
+				if matchSegments(pattern[1:], segments[i:]) {
+					return true
+				}
+			}
+
+			return false
+		case "*":
+			if len(segments) == 0 {
+				return false
+			}
+		default:
+			if len(segments) == 0 || pattern[0] != segments[0] {
+				return false
+			}
+		}
+
+		pattern, segments = pattern[1:], segments[1:]
+	}
+
+	return len(segments) == 0
+}
+
+// ValidateEventType checks that pattern only uses * and ** as whole
+// segments.
+func ValidateEventType(pattern string) error {
+	for _, segment := range strings.Split(pattern, ".") {
+		if segment != "*" && segment != "**" && strings.Contains(segment, "*") {
+			return fmt.Errorf("%w: event type %q can only use * and ** as whole segments", ErrInvalidFilter, pattern)
+		}
+	}
+
+	return nil
+}
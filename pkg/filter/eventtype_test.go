@@ -0,0 +1,50 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchEventType(t *testing.T) {
+	tests := []struct {
+		pattern   string
+		eventType string
+		match     bool
+	}{
+		{pattern: "*", eventType: "invoice.paid", match: true},
+		{pattern: "invoice.paid", eventType: "invoice.paid", match: true},
+		{pattern: "invoice.paid", eventType: "invoice.failed", match: false},
+		{pattern: "invoice.*", eventType: "invoice.paid", match: true},
+		{pattern: "invoice.*", eventType: "invoice", match: false},
+		{pattern: "invoice.*", eventType: "invoice.line.added", match: false},
+		{pattern: "*.created", eventType: "user.created", match: true},
+		{pattern: "*.created", eventType: "user.updated", match: false},
+		{pattern: "*.created", eventType: "org.user.created", match: false},
+		{pattern: "payment.**", eventType: "payment", match: true},
+		{pattern: "payment.**", eventType: "payment.failed", match: true},
+		{pattern: "payment.**", eventType: "payment.card.failed", match: true},
+		{pattern: "payment.**", eventType: "payments.failed", match: false},
+		{pattern: "**.failed", eventType: "payment.card.failed", match: true},
+		{pattern: "payment.**.failed", eventType: "payment.failed", match: true},
+		{pattern: "payment.**.failed", eventType: "payment.card.3ds.failed", match: true},
+		{pattern: "payment.**.failed", eventType: "payment.card.succeeded", match: false},
+		{pattern: "**", eventType: "anything.at.all", match: true},
+		{pattern: "invoice*", eventType: "invoices", match: false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.pattern+" "+tc.eventType, func(t *testing.T) {
+			require.Equal(t, tc.match, MatchEventType(tc.pattern, tc.eventType))
+		})
+	}
+}
+
+func TestValidateEventType(t *testing.T) {
+	for _, pattern := range []string{"*", "invoice.paid", "invoice.*", "*.created", "payment.**", "**.failed"} {
+		require.NoError(t, ValidateEventType(pattern))
+	}
+
+	require.EqualError(t, ValidateEventType("invoice*"), `invalid filter: event type "invoice*" can only use * and ** as whole segments`)
+	require.EqualError(t, ValidateEventType("payment.***"), `invalid filter: event type "payment.***" can only use * and ** as whole segments`)
+}
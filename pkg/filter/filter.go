@@ -0,0 +1,349 @@
+// Package filter matches json documents against mongodb style query filters
+// like {"data.amount": {"$gt": 1000}, "data.region": {"$in": ["eu"]}}.
+//
+// A filter's keys are dot separated paths into the document, see
+// util.JSONPath, mapped to either a value the field must equal or to an
+// object of operators the field must satisfy. $or and $and take an array of
+// filters. When a field holds an array, an operator matches if any of its
+// elements does, except $ne and $nin which match if none of them do.
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/frain-dev/convoy/util"
+)
+
+var ErrInvalidFilter = errors.New("invalid filter")
+
+// Validate checks that f only uses supported operators with operands of the
+// right type.
+func Validate(f map[string]interface{}) error {
+	for key, value := range f {
+		switch key {
+		case "$or", "$and":
+			filters, err := subFilters(key, value)
+			if err != nil {
+				return err
+			}
+
+			for _, sf := range filters {
+				if err = Validate(sf); err != nil {
+					return err
+				}
+			}
+
+			continue
+		}
+
+		if strings.HasPrefix(key, "$") {
+			return fmt.Errorf("%w: unsupported operator %s", ErrInvalidFilter, key)
+		}
+
+		ops, ok := operators(value)
+		if !ok {
+			continue
+		}
+
+		for op, operand := range ops {
+			if err := validateOperator(op, operand); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func validateOperator(op string, operand interface{}) error {
+	switch op {
+	case "$eq", "$ne", "$gt", "$gte", "$lt", "$lte":
+	case "$in", "$nin":
+		if _, ok := operand.([]interface{}); !ok {
+			return fmt.Errorf("%w: %s takes an array", ErrInvalidFilter, op)
+		}
+	case "$exists":
+		if _, ok := operand.(bool); !ok {
+			return fmt.Errorf("%w: $exists takes a boolean", ErrInvalidFilter)
+		}
+	case "$regex":
+		pattern, ok := operand.(string)
+		if !ok {
+			return fmt.Errorf("%w: $regex takes a string", ErrInvalidFilter)
+		}
+
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidFilter, err)
+		}
+	default:
+		return fmt.Errorf("%w: unsupported operator %s", ErrInvalidFilter, op)
+	}
+
+	return nil
+}
+
+// Match reports whether doc, a value decoded from json, matches f. An empty
+// filter matches every document.
+func Match(doc interface{}, f map[string]interface{}) (bool, error) {
+	if err := Validate(f); err != nil {
+		return false, err
+	}
+
+	return match(doc, f), nil
+}
+
+// MatchHeaders reports whether headers match f. Header names are case
+// insensitive, so the header names and the paths in f are lower cased before
+// they are compared. A header field holds the array of the header's values.
+func MatchHeaders(headers map[string][]string, f map[string]interface{}) (bool, error) {
+	doc := make(map[string]interface{}, len(headers))
+	for k, v := range headers {
+		values := make([]interface{}, len(v))
+		for i := range v {
+			values[i] = v[i]
+		}
+
+		doc[strings.ToLower(k)] = values
+	}
+
+	return Match(doc, lowerPaths(f))
+}
+
+func lowerPaths(f map[string]interface{}) map[string]interface{} {
+	lowered := make(map[string]interface{}, len(f))
+	for key, value := range f {
+		if key == "$or" || key == "$and" {
+			if filters, err := subFilters(key, value); err == nil {
+				sfs := make([]interface{}, len(filters))
+				for i, sf := range filters {
+					sfs[i] = lowerPaths(sf)
+				}
+				value = sfs
+			}
+
+			lowered[key] = value
+			continue
+		}
+
+		lowered[strings.ToLower(key)] = value
+	}
+
+	return lowered
+}
+
+func match(doc interface{}, f map[string]interface{}) bool {
+	for key, value := range f {
+		switch key {
+		case "$or":
+			filters, _ := subFilters(key, value)
+			matched := false
+			for _, sf := range filters {
+				if match(doc, sf) {
+					matched = true
+					break
+				}
+			}
+
+			if !matched {
+				return false
+			}
+		case "$and":
+			filters, _ := subFilters(key, value)
+			for _, sf := range filters {
+				if !match(doc, sf) {
+					return false
+				}
+			}
+		default:
+			field, found := util.JSONPath(doc, key)
+
+			ops, ok := operators(value)
+			if !ok {
+				ops = map[string]interface{}{"$eq": value}
+			}
+
+			for op, operand := range ops {
+				if !matchOperator(op, field, found, operand) {
+					return false
+				}
+			}
+		}
+	}
+
+	return true
+}
+
+func matchOperator(op string, field interface{}, found bool, operand interface{}) bool {
+	switch op {
+	case "$exists":
+		return found == operand.(bool)
+	case "$ne":
+		return !matchOperator("$eq", field, found, operand)
+	case "$nin":
+		return !matchOperator("$in", field, found, operand)
+	}
+
+	if !found {
+		// a missing field only equals null
+		return op == "$eq" && operand == nil
+	}
+
+	if elems, ok := field.([]interface{}); ok {
+		// an array field equals an array operand as a whole
+		if op == "$eq" && isArray(operand) {
+			return equal(field, operand)
+		}
+
+		for _, elem := range elems {
+			if matchValue(op, elem, operand) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return matchValue(op, field, operand)
+}
+
+func matchValue(op string, v, operand interface{}) bool {
+	switch op {
+	case "$eq":
+		return equal(v, operand)
+	case "$in":
+		for _, o := range operand.([]interface{}) {
+			if equal(v, o) {
+				return true
+			}
+		}
+
+		return false
+	case "$gt":
+		c, ok := compare(v, operand)
+		return ok && c > 0
+	case "$gte":
+		c, ok := compare(v, operand)
+		return ok && c >= 0
+	case "$lt":
+		c, ok := compare(v, operand)
+		return ok && c < 0
+	case "$lte":
+		c, ok := compare(v, operand)
+		return ok && c <= 0
+	case "$regex":
+		s, ok := v.(string)
+		if !ok {
+			return false
+		}
+
+		// the pattern was compiled by Validate
+		return regexp.MustCompile(operand.(string)).MatchString(s)
+	}
+
+	return false
+}
+
+// operators returns value as an object of operators, if it is one.
+func operators(value interface{}) (map[string]interface{}, bool) {
+	m, ok := value.(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil, false
+	}
+
+	for k := range m {
+		if !strings.HasPrefix(k, "$") {
+			return nil, false
+		}
+	}
+
+	return m, true
+}
+
+func subFilters(op string, value interface{}) ([]map[string]interface{}, error) {
+	values, ok := value.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: %s takes an array of filters", ErrInvalidFilter, op)
+	}
+
+	filters := make([]map[string]interface{}, 0, len(values))
+	for _, v := range values {
+		f, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%w: %s takes an array of filters", ErrInvalidFilter, op)
+		}
+
+		filters = append(filters, f)
+	}
+
+	return filters, nil
+}
+
+func isArray(v interface{}) bool {
+	_, ok := v.([]interface{})
+	return ok
+}
+
+func equal(a, b interface{}) bool {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		return ok && x == y
+	}
+
+	return reflect.DeepEqual(a, b)
+}
+
+// compare compares two numbers or two strings.
+func compare(a, b interface{}) (int, bool) {
+	if x, ok := number(a); ok {
+		y, ok := number(b)
+		if !ok {
+			return 0, false
+		}
+
+		switch {
+		case x < y:
+			return -1, true
+		case x > y:
+			return 1, true
+		}
+
+		return 0, true
+	}
+
+	x, ok := a.(string)
+	if !ok {
+		return 0, false
+	}
+
+	y, ok := b.(string)
+	if !ok {
+		return 0, false
+	}
+
+	return strings.Compare(x, y), true
+}
+
+func number(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case float32:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case int32:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	return 0, false
+}
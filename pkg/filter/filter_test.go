@@ -0,0 +1,117 @@
+package filter
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func decode(t *testing.T, s string) map[string]interface{} {
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(s), &m))
+	return m
+}
+
+func TestMatch(t *testing.T) {
+	doc := decode(t, `{
+		"event": "payment.success",
+		"data": {
+			"amount": 1500,
+			"currency": "NGN",
+			"region": "eu",
+			"tags": ["card", "recurring"],
+			"customer": {"email": "daniel@example.com", "phone": null}
+		}
+	}`)
+
+	tests := []struct {
+		name   string
+		filter string
+		match  bool
+	}{
+		{name: "empty filter", filter: `{}`, match: true},
+		{name: "implicit equality", filter: `{"data.currency": "NGN"}`, match: true},
+		{name: "implicit equality fails", filter: `{"data.currency": "USD"}`, match: false},
+		{name: "nested object equality", filter: `{"data.customer": {"email": "daniel@example.com", "phone": null}}`, match: true},
+		{name: "$eq", filter: `{"event": {"$eq": "payment.success"}}`, match: true},
+		{name: "$ne", filter: `{"event": {"$ne": "payment.success"}}`, match: false},
+		{name: "$ne missing field", filter: `{"data.country": {"$ne": "NG"}}`, match: true},
+		{name: "$gt", filter: `{"data.amount": {"$gt": 1000}}`, match: true},
+		{name: "$gt fails", filter: `{"data.amount": {"$gt": 1500}}`, match: false},
+		{name: "$gte", filter: `{"data.amount": {"$gte": 1500}}`, match: true},
+		{name: "$lt and $gt range", filter: `{"data.amount": {"$gt": 1000, "$lt": 2000}}`, match: true},
+		{name: "$lte fails", filter: `{"data.amount": {"$lte": 1000}}`, match: false},
+		{name: "$gt string comparison", filter: `{"data.currency": {"$gt": "EUR"}}`, match: true},
+		{name: "$gt number against string", filter: `{"data.currency": {"$gt": 10}}`, match: false},
+		{name: "$gt missing field", filter: `{"data.fee": {"$gt": 0}}`, match: false},
+		{name: "$in", filter: `{"data.region": {"$in": ["eu", "us"]}}`, match: true},
+		{name: "$in fails", filter: `{"data.region": {"$in": ["africa"]}}`, match: false},
+		{name: "$nin", filter: `{"data.region": {"$nin": ["africa"]}}`, match: true},
+		{name: "$exists", filter: `{"data.customer.phone": {"$exists": true}}`, match: true},
+		{name: "$exists false", filter: `{"data.customer.address": {"$exists": false}}`, match: true},
+		{name: "$exists fails", filter: `{"data.customer.address": {"$exists": true}}`, match: false},
+		{name: "$regex", filter: `{"data.customer.email": {"$regex": "@example\\.com$"}}`, match: true},
+		{name: "$regex fails", filter: `{"data.customer.email": {"$regex": "^admin@"}}`, match: false},
+		{name: "array contains", filter: `{"data.tags": "recurring"}`, match: true},
+		{name: "array $in", filter: `{"data.tags": {"$in": ["one-off", "card"]}}`, match: true},
+		{name: "array $nin", filter: `{"data.tags": {"$nin": ["card"]}}`, match: false},
+		{name: "array equality", filter: `{"data.tags": ["card", "recurring"]}`, match: true},
+		{name: "array index", filter: `{"data.tags.0": "card"}`, match: true},
+		{name: "$or", filter: `{"$or": [{"data.region": "us"}, {"data.amount": {"$gt": 1000}}]}`, match: true},
+		{name: "$or fails", filter: `{"$or": [{"data.region": "us"}, {"data.amount": {"$gt": 2000}}]}`, match: false},
+		{name: "$and", filter: `{"$and": [{"data.region": "eu"}, {"data.amount": {"$gt": 1000}}]}`, match: true},
+		{name: "missing field equals null", filter: `{"data.fee": null}`, match: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			matched, err := Match(doc, decode(t, tc.filter))
+			require.NoError(t, err)
+			require.Equal(t, tc.match, matched)
+		})
+	}
+}
+
+func TestMatchHeaders(t *testing.T) {
+	headers := map[string][]string{
+		"X-Event-Source": {"billing"},
+		"X-Tenant":       {"acme", "globex"},
+	}
+
+	matched, err := MatchHeaders(headers, decode(t, `{"x-event-source": "billing", "X-TENANT": {"$in": ["globex"]}}`))
+	require.NoError(t, err)
+	require.True(t, matched)
+
+	matched, err = MatchHeaders(headers, decode(t, `{"$or": [{"X-Event-Source": "orders"}, {"x-missing": {"$exists": true}}]}`))
+	require.NoError(t, err)
+	require.False(t, matched)
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		filter  string
+		wantErr string
+	}{
+		{filter: `{"data.amount": {"$gt": 1000}, "$or": [{"a": 1}, {"b": {"$exists": false}}]}`},
+		{filter: `{"data.amount": {"$between": [1, 2]}}`, wantErr: "invalid filter: unsupported operator $between"},
+		{filter: `{"$where": "this.amount > 1"}`, wantErr: "invalid filter: unsupported operator $where"},
+		{filter: `{"data.region": {"$in": "eu"}}`, wantErr: "invalid filter: $in takes an array"},
+		{filter: `{"data.region": {"$exists": 1}}`, wantErr: "invalid filter: $exists takes a boolean"},
+		{filter: `{"data.region": {"$regex": "("}}`, wantErr: "invalid filter: error parsing regexp: missing closing ): `(`"},
+		{filter: `{"$or": {"a": 1}}`, wantErr: "invalid filter: $or takes an array of filters"},
+		{filter: `{"$and": [{"a": {"$size": 1}}]}`, wantErr: "invalid filter: unsupported operator $size"},
+	}
+
+	for _, tc := range tests {
+		err := Validate(decode(t, tc.filter))
+		if tc.wantErr == "" {
+			require.NoError(t, err)
+			continue
+		}
+
+		require.EqualError(t, err, tc.wantErr)
+		require.True(t, errors.Is(err, ErrInvalidFilter))
+	}
+}
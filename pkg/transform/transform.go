@@ -0,0 +1,161 @@
+// Package transform reshapes an event delivery with a jq program before it
+// is sent.
+//
+// The program's input is the delivery as {"body": <payload>, "headers":
+// {"Name": ["value"]}} and it outputs the delivery to send in the same shape,
+// so a program can rewrite the body, add or remove headers, or output null to
+// drop the delivery. For example
+//
+//	.body |= {id: .data.id, total: .data.amount} | .headers["X-Tenant"] = ["acme"]
+//
+// Programs run without access to the environment and are stopped after
+// Timeout.
+package transform
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/itchyny/gojq"
+)
+
+// Timeout is how long a program can run for a single delivery.
+const Timeout = time.Second
+
+var (
+	ErrInvalidProgram  = errors.New("invalid transform program")
+	ErrTransformFailed = errors.New("transform failed")
+)
+
+type Transform struct {
+	code *gojq.Code
+}
+
+// Result is a transformed delivery. Body and Headers are unset when the
+// program dropped the delivery.
+type Result struct {
+	Body    json.RawMessage       `json:"body"`
+	Headers httpheader.HTTPHeader `json:"headers"`
+	Dropped bool                  `json:"dropped"`
+}
+
+// Compile parses and compiles program.
+func Compile(program string) (*Transform, error) {
+	query, err := gojq.Parse(program)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProgram, err)
+	}
+
+	code, err := gojq.Compile(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidProgram, err)
+	}
+
+	return &Transform{code: code}, nil
+}
+
+// Apply runs the program on a delivery's body and headers.
+func (t *Transform) Apply(ctx context.Context, body []byte, headers httpheader.HTTPHeader) (*Result, error) {
+	var payload interface{}
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, &payload); err != nil {
+			return nil, fmt.Errorf("%w: body is not valid json", ErrTransformFailed)
+		}
+	}
+
+	h := make(map[string]interface{}, len(headers))
+	for k, values := range headers {
+		vs := make([]interface{}, len(values))
+		for i := range values {
+			vs[i] = values[i]
+		}
+		h[k] = vs
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, Timeout)
+	defer cancel()
+
+	iter := t.code.RunWithContext(ctx, map[string]interface{}{"body": payload, "headers": h})
+
+	out, ok := iter.Next()
+	if !ok {
+		// a program that outputs nothing drops the delivery
+		return &Result{Dropped: true}, nil
+	}
+
+	if err, ok := out.(error); ok {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, fmt.Errorf("%w: program ran for longer than %s", ErrTransformFailed, Timeout)
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrTransformFailed, err)
+	}
+
+	if _, ok := iter.Next(); ok {
+		return nil, fmt.Errorf("%w: program must output a single value", ErrTransformFailed)
+	}
+
+	return result(out)
+}
+
+func result(out interface{}) (*Result, error) {
+	if out == nil {
+		return &Result{Dropped: true}, nil
+	}
+
+	delivery, ok := out.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: program must output an object with a body and headers, or null", ErrTransformFailed)
+	}
+
+	body, err := json.Marshal(delivery["body"])
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrTransformFailed, err)
+	}
+
+	headers, err := resultHeaders(delivery["headers"])
+	if err != nil {
+		return nil, err
+	}
+
+	return &Result{Body: body, Headers: headers}, nil
+}
+
+// resultHeaders converts the program's headers, a header's value can be a
+// string or an array of strings.
+func resultHeaders(v interface{}) (httpheader.HTTPHeader, error) {
+	headers := httpheader.HTTPHeader{}
+	if v == nil {
+		return headers, nil
+	}
+
+	m, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: headers must be an object", ErrTransformFailed)
+	}
+
+	for k, value := range m {
+		switch t := value.(type) {
+		case string:
+			headers[k] = []string{t}
+		case []interface{}:
+			values := make([]string, 0, len(t))
+			for _, e := range t {
+				s, ok := e.(string)
+				if !ok {
+					return nil, fmt.Errorf("%w: header %s must be a string or an array of strings", ErrTransformFailed, k)
+				}
+				values = append(values, s)
+			}
+			headers[k] = values
+		default:
+			return nil, fmt.Errorf("%w: header %s must be a string or an array of strings", ErrTransformFailed, k)
+		}
+	}
+
+	return headers, nil
+}
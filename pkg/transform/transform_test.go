@@ -0,0 +1,117 @@
+package transform
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTransform_Apply(t *testing.T) {
+	body := []byte(`{"event": "invoice.paid", "data": {"id": "inv_1", "amount": 1500, "region": "eu"}}`)
+	headers := httpheader.HTTPHeader{"X-Source": []string{"billing"}}
+
+	tests := []struct {
+		name        string
+		program     string
+		wantBody    string
+		wantHeaders httpheader.HTTPHeader
+		wantDropped bool
+		wantErr     string
+	}{
+		{
+			name:        "identity",
+			program:     ".",
+			wantBody:    string(body),
+			wantHeaders: headers,
+		},
+		{
+			name:        "reshape body and add headers",
+			program:     `.body |= {id: .data.id, total: .data.amount} | .headers["X-Tenant"] = "acme" | .headers["X-Tags"] = ["a", "b"]`,
+			wantBody:    `{"id": "inv_1", "total": 1500}`,
+			wantHeaders: httpheader.HTTPHeader{"X-Source": []string{"billing"}, "X-Tenant": []string{"acme"}, "X-Tags": []string{"a", "b"}},
+		},
+		{
+			name:        "remove headers",
+			program:     `del(.headers["X-Source"])`,
+			wantBody:    string(body),
+			wantHeaders: httpheader.HTTPHeader{},
+		},
+		{
+			name:        "drop with null",
+			program:     `if .body.data.region == "eu" then null else . end`,
+			wantDropped: true,
+		},
+		{
+			name:        "drop with empty",
+			program:     `select(.body.data.amount > 2000)`,
+			wantDropped: true,
+		},
+		{
+			name:    "multiple outputs",
+			program: `., .`,
+			wantErr: "transform failed: program must output a single value",
+		},
+		{
+			name:    "not an object",
+			program: `.body.data.amount`,
+			wantErr: "transform failed: program must output an object with a body and headers, or null",
+		},
+		{
+			name:    "invalid header",
+			program: `.headers["X-Count"] = 1`,
+			wantErr: "transform failed: header X-Count must be a string or an array of strings",
+		},
+		{
+			name:    "runtime error",
+			program: `error("unsupported event")`,
+			wantErr: "transform failed: error: unsupported event",
+		},
+		{
+			name:        "no environment",
+			program:     `.body = $ENV`,
+			wantBody:    `{}`,
+			wantHeaders: headers,
+		},
+		{
+			name:    "timeout",
+			program: `last(range(1e18))`,
+			wantErr: "transform failed: program ran for longer than 1s",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tr, err := Compile(tc.program)
+			require.NoError(t, err)
+
+			res, err := tr.Apply(context.Background(), body, headers)
+			if tc.wantErr != "" {
+				require.EqualError(t, err, tc.wantErr)
+				require.True(t, errors.Is(err, ErrTransformFailed))
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.wantDropped, res.Dropped)
+			if tc.wantDropped {
+				return
+			}
+
+			require.JSONEq(t, tc.wantBody, string(res.Body))
+			require.Equal(t, tc.wantHeaders, res.Headers)
+		})
+	}
+}
+
+func TestCompile(t *testing.T) {
+	_, err := Compile(`.body |=`)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrInvalidProgram))
+
+	_, err = Compile(`undefined_function(1)`)
+	require.Error(t, err)
+	require.True(t, errors.Is(err, ErrInvalidProgram))
+}
@@ -0,0 +1,379 @@
+package memqueue
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	log "github.com/sirupsen/logrus"
+)
+
+// maxRetry is the number of times a failed job is retried before it is
+// dropped, it matches asynq's default.
+const maxRetry = 25
+
+var ErrDuplicateJob = errors.New("a job with this id already exists in the queue")
+
+type HandlerFunc func(context.Context, *asynq.Task) error
+
+type Config struct {
+	Concurrency int
+
+	// RetryDelayFunc returns how long to wait before retrying a job that
+	// failed n times.
+	RetryDelayFunc asynq.RetryDelayFunc
+
+	// IsFailure reports whether err should count against the job's retries,
+	// a job that errors without failing is retried without being charged.
+	IsFailure func(error) bool
+}
+
+type job struct {
+	id        string
+	taskName  string
+	queueName string
+	payload   []byte
+	processAt time.Time
+	retried   int
+
+	// index is the job's position in the scheduled heap, -1 when it isn't
+	// scheduled
+	index int
+}
+
+// MemQueue is a queue.Queuer that keeps jobs in memory and processes them in
+// the same process, it lets convoy run without redis. Jobs don't survive a
+// restart and aren't shared between processes, so the server has to run
+// with its workers enabled.
+type MemQueue struct {
+	opts queue.QueueOptions
+
+	mu        sync.Mutex
+	pending   map[string][]*job
+	scheduled scheduledJobs
+	jobs      map[string]*job
+	active    map[string]context.CancelFunc
+	handlers  map[string]HandlerFunc
+	wake      chan struct{}
+	rnd       *rand.Rand
+
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func NewQueue(opts queue.QueueOptions) queue.Queuer {
+	return &MemQueue{
+		opts:     opts,
+		pending:  map[string][]*job{},
+		jobs:     map[string]*job{},
+		active:   map[string]context.CancelFunc{},
+		handlers: map[string]HandlerFunc{},
+		wake:     make(chan struct{}),
+		rnd:      rand.New(rand.NewSource(time.Now().UnixNano())),
+		stop:     make(chan struct{}),
+	}
+}
+
+func (q *MemQueue) Write(taskName convoy.TaskName, queueName convoy.QueueName, j *queue.Job) error {
+	if j.ID == "" {
+		j.ID = uuid.NewString()
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	key := jobKey(string(queueName), j.ID)
+	if _, ok := q.jobs[key]; ok {
+		return ErrDuplicateJob
+	}
+
+	jb := &job{
+		id:        j.ID,
+		taskName:  string(taskName),
+		queueName: string(queueName),
+		payload:   j.Payload,
+		processAt: time.Now().Add(j.Delay),
+		index:     -1,
+	}
+
+	q.jobs[key] = jb
+	q.enqueue(jb)
+	return nil
+}
+
+func (q *MemQueue) Options() queue.QueueOptions {
+	return q.opts
+}
+
+// Len returns the number of jobs in the queue, including the ones being
+// processed and the ones waiting to be retried.
+func (q *MemQueue) Len() int {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	return len(q.jobs)
+}
+
+// Handle registers the handler for jobs written with taskName.
+func (q *MemQueue) Handle(taskName convoy.TaskName, handler HandlerFunc) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.handlers[string(taskName)] = handler
+}
+
+// Start runs cfg.Concurrency workers that process jobs until Stop is called.
+func (q *MemQueue) Start(cfg Config) {
+	if cfg.Concurrency < 1 {
+		cfg.Concurrency = 1
+	}
+
+	if cfg.RetryDelayFunc == nil {
+		cfg.RetryDelayFunc = asynq.DefaultRetryDelayFunc
+	}
+
+	for i := 0; i < cfg.Concurrency; i++ {
+		q.wg.Add(1)
+		go q.work(cfg)
+	}
+}
+
+// Stop waits for the jobs being processed to return, jobs that haven't
+// started are discarded.
+func (q *MemQueue) Stop() {
+	close(q.stop)
+	q.wg.Wait()
+}
+
+// DeleteEventDeliveriesfromQueue removes the jobs with the given ids from
+// queuename, jobs that are being processed are cancelled.
+func (q *MemQueue) DeleteEventDeliveriesfromQueue(queuename convoy.QueueName, ids []string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for _, id := range ids {
+		key := jobKey(string(queuename), id)
+		jb, ok := q.jobs[key]
+		if !ok {
+			continue
+		}
+
+		if cancel, ok := q.active[key]; ok {
+			cancel()
+		}
+
+		q.remove(jb)
+		delete(q.jobs, key)
+	}
+
+	return nil
+}
+
+func (q *MemQueue) work(cfg Config) {
+	defer q.wg.Done()
+
+	for {
+		jb, ctx, ok := q.next()
+		if !ok {
+			return
+		}
+
+		q.process(ctx, cfg, jb)
+	}
+}
+
+// next blocks until a job is due or the queue is stopped, the returned
+// context is cancelled if the job is deleted while it is being processed.
+func (q *MemQueue) next() (*job, context.Context, bool) {
+	for {
+		q.mu.Lock()
+		q.promoteDue(time.Now())
+
+		if jb := q.pick(); jb != nil {
+			ctx, cancel := context.WithCancel(context.Background())
+			q.active[jobKey(jb.queueName, jb.id)] = cancel
+			q.mu.Unlock()
+			return jb, ctx, true
+		}
+
+		wake := q.wake
+		timer := time.NewTimer(time.Hour)
+		if len(q.scheduled) > 0 {
+			timer.Reset(time.Until(q.scheduled[0].processAt))
+		}
+		q.mu.Unlock()
+
+		select {
+		case <-q.stop:
+			timer.Stop()
+			return nil, nil, false
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (q *MemQueue) process(ctx context.Context, cfg Config, jb *job) {
+	key := jobKey(jb.queueName, jb.id)
+
+	q.mu.Lock()
+	handler, ok := q.handlers[jb.taskName]
+	q.mu.Unlock()
+
+	task := asynq.NewTask(jb.taskName, jb.payload, asynq.Queue(jb.queueName), asynq.TaskID(jb.id))
+
+	var err error
+	if ok {
+		err = handler(ctx, task)
+	} else {
+		err = errors.New("handler not found for task " + jb.taskName)
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if cancel, ok := q.active[key]; ok {
+		cancel()
+		delete(q.active, key)
+	}
+
+	// the job was deleted while it was being processed
+	if q.jobs[key] != jb {
+		return
+	}
+
+	if err == nil || errors.Is(err, asynq.SkipRetry) {
+		delete(q.jobs, key)
+		return
+	}
+
+	isFailure := cfg.IsFailure == nil || cfg.IsFailure(err)
+	if isFailure && jb.retried >= maxRetry {
+		log.WithError(err).Errorf("job %s exhausted its retries and was dropped", jb.id)
+		delete(q.jobs, key)
+		return
+	}
+
+	delay := cfg.RetryDelayFunc(jb.retried, err, task)
+	if isFailure {
+		jb.retried++
+	}
+
+	jb.processAt = time.Now().Add(delay)
+	q.enqueue(jb)
+}
+
+// enqueue adds jb to its queue if it is due, otherwise to the scheduled set,
+// and wakes up the idle workers. q.mu must be held.
+func (q *MemQueue) enqueue(jb *job) {
+	if jb.processAt.After(time.Now()) {
+		heap.Push(&q.scheduled, jb)
+	} else {
+		q.pending[jb.queueName] = append(q.pending[jb.queueName], jb)
+	}
+
+	close(q.wake)
+	q.wake = make(chan struct{})
+}
+
+func (q *MemQueue) remove(jb *job) {
+	if jb.index >= 0 {
+		heap.Remove(&q.scheduled, jb.index)
+		return
+	}
+
+	jobs := q.pending[jb.queueName]
+	for i := range jobs {
+		if jobs[i] == jb {
+			q.pending[jb.queueName] = append(jobs[:i], jobs[i+1:]...)
+			return
+		}
+	}
+}
+
+func (q *MemQueue) promoteDue(now time.Time) {
+	for len(q.scheduled) > 0 && !q.scheduled[0].processAt.After(now) {
+		jb := heap.Pop(&q.scheduled).(*job)
+		q.pending[jb.queueName] = append(q.pending[jb.queueName], jb)
+	}
+}
+
+// pick takes the next job off a non empty queue, queues are chosen at random
+// in proportion to their priority in QueueOptions.Names the same way asynq
+// picks them. Queues that aren't named get a priority of 1.
+func (q *MemQueue) pick() *job {
+	total := 0
+	for name, jobs := range q.pending {
+		if len(jobs) > 0 {
+			total += q.priority(name)
+		}
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	n := q.rnd.Intn(total)
+	for name, jobs := range q.pending {
+		if len(jobs) == 0 {
+			continue
+		}
+
+		n -= q.priority(name)
+		if n < 0 {
+			q.pending[name] = jobs[1:]
+			return jobs[0]
+		}
+	}
+
+	return nil
+}
+
+func (q *MemQueue) priority(queueName string) int {
+	if p, ok := q.opts.Names[queueName]; ok && p > 0 {
+		return p
+	}
+
+	return 1
+}
+
+func jobKey(queueName, id string) string {
+	return queueName + ":" + id
+}
+
+// scheduledJobs is a min heap of jobs ordered by when they are due.
+type scheduledJobs []*job
+
+func (s scheduledJobs) Len() int { return len(s) }
+
+func (s scheduledJobs) Less(i, j int) bool { return s[i].processAt.Before(s[j].processAt) }
+
+func (s scheduledJobs) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+	s[i].index = i
+	s[j].index = j
+}
+
+func (s *scheduledJobs) Push(x interface{}) {
+	jb := x.(*job)
+	jb.index = len(*s)
+	*s = append(*s, jb)
+}
+
+func (s *scheduledJobs) Pop() interface{} {
+	old := *s
+	n := len(old)
+	jb := old[n-1]
+	old[n-1] = nil
+	jb.index = -1
+	*s = old[:n-1]
+	return jb
+}
@@ -0,0 +1,186 @@
+package memqueue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+const testTask = convoy.TaskName("test-task")
+
+func newTestQueue(names map[string]int) *MemQueue {
+	return NewQueue(queue.QueueOptions{Names: names}).(*MemQueue)
+}
+
+func waitForEmptyQueue(t *testing.T, q *MemQueue) {
+	require.Eventually(t, func() bool { return q.Len() == 0 }, 5*time.Second, 5*time.Millisecond)
+}
+
+func TestWrite(t *testing.T) {
+	q := newTestQueue(nil)
+
+	job := &queue.Job{Payload: []byte("payload")}
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, job))
+	require.NotEmpty(t, job.ID)
+	require.Equal(t, 1, q.Len())
+
+	err := q.Write(testTask, convoy.EventQueue, &queue.Job{ID: job.ID})
+	require.Equal(t, ErrDuplicateJob, err)
+
+	// ids only have to be unique within a queue
+	require.NoError(t, q.Write(testTask, convoy.DefaultQueue, &queue.Job{ID: job.ID}))
+}
+
+func TestProcess(t *testing.T) {
+	q := newTestQueue(nil)
+
+	var mu sync.Mutex
+	var processed []string
+	q.Handle(testTask, func(ctx context.Context, task *asynq.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+		processed = append(processed, string(task.Payload()))
+		return nil
+	})
+
+	q.Start(Config{Concurrency: 2})
+	defer q.Stop()
+
+	start := time.Now()
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{Payload: []byte("delayed"), Delay: 100 * time.Millisecond}))
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{Payload: []byte("now")}))
+
+	waitForEmptyQueue(t, q)
+	require.GreaterOrEqual(t, int64(time.Since(start)), int64(100*time.Millisecond))
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, []string{"now", "delayed"}, processed)
+}
+
+func TestRetry(t *testing.T) {
+	q := newTestQueue(nil)
+
+	errRateLimited := errors.New("rate limited")
+
+	var mu sync.Mutex
+	var attempts int
+	var retries []int
+	q.Handle(testTask, func(ctx context.Context, task *asynq.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		attempts++
+		switch attempts {
+		case 1:
+			return errors.New("failed")
+		case 2:
+			return errRateLimited
+		}
+		return nil
+	})
+
+	q.Start(Config{
+		Concurrency: 1,
+		RetryDelayFunc: func(n int, err error, task *asynq.Task) time.Duration {
+			mu.Lock()
+			defer mu.Unlock()
+			retries = append(retries, n)
+			return 10 * time.Millisecond
+		},
+		IsFailure: func(err error) bool {
+			return err != errRateLimited
+		},
+	})
+	defer q.Stop()
+
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{}))
+	waitForEmptyQueue(t, q)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 3, attempts)
+
+	// the rate limited attempt isn't counted as a failure
+	require.Equal(t, []int{0, 1}, retries)
+}
+
+func TestSkipRetry(t *testing.T) {
+	q := newTestQueue(nil)
+
+	var mu sync.Mutex
+	var attempts int
+	q.Handle(testTask, func(ctx context.Context, task *asynq.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts++
+		return asynq.SkipRetry
+	})
+
+	q.Start(Config{Concurrency: 1})
+	defer q.Stop()
+
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{}))
+	waitForEmptyQueue(t, q)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Equal(t, 1, attempts)
+}
+
+func TestPriority(t *testing.T) {
+	q := newTestQueue(map[string]int{
+		string(convoy.PriorityQueue): 9,
+		string(convoy.DefaultQueue):  1,
+	})
+
+	for i := 0; i < 100; i++ {
+		require.NoError(t, q.Write(testTask, convoy.PriorityQueue, &queue.Job{Payload: []byte(convoy.PriorityQueue)}))
+		require.NoError(t, q.Write(testTask, convoy.DefaultQueue, &queue.Job{Payload: []byte(convoy.DefaultQueue)}))
+	}
+
+	var mu sync.Mutex
+	counts := map[string]int{}
+	q.Handle(testTask, func(ctx context.Context, task *asynq.Task) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// only look at the first half of the jobs, while both queues are
+		// still full
+		if counts["total"] < 100 {
+			counts[string(task.Payload())]++
+		}
+		counts["total"]++
+		return nil
+	})
+
+	q.Start(Config{Concurrency: 1})
+	defer q.Stop()
+
+	waitForEmptyQueue(t, q)
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.Greater(t, counts[string(convoy.PriorityQueue)], counts[string(convoy.DefaultQueue)]*3)
+}
+
+func TestDeleteEventDeliveriesfromQueue(t *testing.T) {
+	q := newTestQueue(nil)
+
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{ID: "pending"}))
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{ID: "scheduled", Delay: time.Hour}))
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{ID: "kept"}))
+
+	require.NoError(t, q.DeleteEventDeliveriesfromQueue(convoy.EventQueue, []string{"pending", "scheduled", "unknown"}))
+	require.Equal(t, 1, q.Len())
+
+	// deleted ids can be written again
+	require.NoError(t, q.Write(testTask, convoy.EventQueue, &queue.Job{ID: "pending"}))
+}
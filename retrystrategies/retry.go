@@ -1,18 +1,49 @@
 package retrystrategies
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
 )
 
+// defaultMaxRetryAfter caps the Retry-After wait when the metadata doesn't set
+// a cap
+const defaultMaxRetryAfter = time.Hour
+
 type RetryStrategy interface {
 	// NextDuration is how long we should wait before next retry
 	NextDuration(attempts uint64) time.Duration
 }
 
+// ResponseRetryStrategy is a RetryStrategy that also looks at the response
+// to the last attempt.
+type ResponseRetryStrategy interface {
+	RetryStrategy
+
+	// NextDurationForResponse is how long we should wait before next retry
+	// after a response with statusCode and header
+	NextDurationForResponse(attempts uint64, statusCode int, header http.Header) time.Duration
+}
+
 func NewRetryStrategyFromMetadata(m datastore.Metadata) RetryStrategy {
-	if string(m.Strategy) == string(datastore.ExponentialStrategyProvider) {
+	strategy := newBaseRetryStrategy(m)
+
+	if m.HonorRetryAfter {
+		maxRetryAfter := defaultMaxRetryAfter
+		if m.MaxRetryAfter > 0 {
+			maxRetryAfter = time.Duration(m.MaxRetryAfter) * time.Second
+		}
+
+		return NewRetryAfter(strategy, maxRetryAfter)
+	}
+
+	return strategy
+}
+
+func newBaseRetryStrategy(m datastore.Metadata) RetryStrategy {
+	switch string(m.Strategy) {
+	case string(datastore.ExponentialStrategyProvider):
 		// 10 seconds to 15 mins
 		return NewExponential([]uint{
 			10000,  // 10 seconds
@@ -23,7 +54,31 @@ func NewRetryStrategyFromMetadata(m datastore.Metadata) RetryStrategy {
 			600000, // 10 minutes
 			900000, // 15 minutes
 		})
+	case string(datastore.ScheduleStrategyProvider):
+		if len(m.Schedule) > 0 {
+			return NewSchedule(m.Schedule)
+		}
 	}
 
 	return NewDefault(m.IntervalSeconds)
 }
+
+// NextDurationForResponse is how long s waits before the next retry after an
+// attempt that got a response with statusCode and header.
+func NextDurationForResponse(s RetryStrategy, attempts uint64, statusCode int, header http.Header) time.Duration {
+	if rs, ok := s.(ResponseRetryStrategy); ok {
+		return rs.NextDurationForResponse(attempts, statusCode, header)
+	}
+
+	return s.NextDuration(attempts)
+}
+
+// ElapsedTimeExceeded reports whether a delivery created at createdAt would
+// be retried after its max elapsed time if it was retried at next.
+func ElapsedTimeExceeded(m datastore.Metadata, createdAt, next time.Time) bool {
+	if m.MaxElapsedTime == 0 {
+		return false
+	}
+
+	return next.Sub(createdAt) > time.Duration(m.MaxElapsedTime)*time.Second
+}
@@ -0,0 +1,73 @@
+package retrystrategies
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetryAfterRetryStrategy waits as long as the receiver asks for in the
+// Retry-After header of 429 and 503 responses, up to a cap. It falls back to
+// another strategy for other responses.
+type RetryAfterRetryStrategy struct {
+	fallback RetryStrategy
+	cap      time.Duration
+	now      func() time.Time
+}
+
+func (r *RetryAfterRetryStrategy) NextDuration(attempts uint64) time.Duration {
+	return r.fallback.NextDuration(attempts)
+}
+
+func (r *RetryAfterRetryStrategy) NextDurationForResponse(attempts uint64, statusCode int, header http.Header) time.Duration {
+	if statusCode != http.StatusTooManyRequests && statusCode != http.StatusServiceUnavailable {
+		return r.fallback.NextDuration(attempts)
+	}
+
+	d, ok := ParseRetryAfter(header.Get("Retry-After"), r.now())
+	if !ok {
+		return r.fallback.NextDuration(attempts)
+	}
+
+	if d > r.cap {
+		return r.cap
+	}
+
+	return d
+}
+
+func NewRetryAfter(fallback RetryStrategy, cap time.Duration) *RetryAfterRetryStrategy {
+	return &RetryAfterRetryStrategy{
+		fallback: fallback,
+		cap:      cap,
+		now:      time.Now,
+	}
+}
+
+// ParseRetryAfter parses a Retry-After header, which is either a number of
+// seconds or an HTTP date, into how long to wait from now.
+func ParseRetryAfter(v string, now time.Time) (time.Duration, bool) {
+	v = strings.TrimSpace(v)
+	if v == "" {
+		return 0, false
+	}
+
+	if seconds, err := strconv.ParseUint(v, 10, 32); err == nil {
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	t, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+
+	d := t.Sub(now)
+	if d < 0 {
+		d = 0
+	}
+
+	return d, true
+}
+
+var _ ResponseRetryStrategy = (*RetryAfterRetryStrategy)(nil)
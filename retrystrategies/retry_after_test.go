@@ -0,0 +1,64 @@
+package retrystrategies
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestRetryAfterRetryStrategy(t *testing.T) {
+	now := time.Date(2022, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name             string
+		expectedDuration time.Duration
+		statusCode       int
+		retryAfter       string
+	}{
+		{
+			name:             "seconds-on-too-many-requests",
+			expectedDuration: time.Duration(120) * time.Second,
+			statusCode:       http.StatusTooManyRequests,
+			retryAfter:       "120",
+		},
+		{
+			name:             "date-on-service-unavailable",
+			expectedDuration: time.Duration(5) * time.Minute,
+			statusCode:       http.StatusServiceUnavailable,
+			retryAfter:       now.Add(5 * time.Minute).Format(http.TimeFormat),
+		},
+		{
+			name:             "capped",
+			expectedDuration: time.Duration(1) * time.Hour,
+			statusCode:       http.StatusTooManyRequests,
+			retryAfter:       "86400",
+		},
+		{
+			name:             "fallback-on-other-status-codes",
+			expectedDuration: time.Duration(10) * time.Second,
+			statusCode:       http.StatusInternalServerError,
+			retryAfter:       "120",
+		},
+		{
+			name:             "fallback-on-invalid-header",
+			expectedDuration: time.Duration(10) * time.Second,
+			statusCode:       http.StatusTooManyRequests,
+			retryAfter:       "soon",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			retry := NewRetryAfter(NewDefault(10), time.Hour)
+			retry.now = func() time.Time { return now }
+
+			header := http.Header{}
+			header.Set("Retry-After", tc.retryAfter)
+
+			got := retry.NextDurationForResponse(0, tc.statusCode, header)
+			if got != tc.expectedDuration {
+				t.Errorf("Want duration '%v' for Retry-After '%s', got '%v'", tc.expectedDuration, tc.retryAfter, got)
+			}
+		})
+	}
+}
@@ -1,7 +1,9 @@
 package retrystrategies
 
 import (
+	"net/http"
 	"testing"
+	"time"
 
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/stretchr/testify/assert"
@@ -40,3 +42,44 @@ func TestRetry_FallsBackToDefault(t *testing.T) {
 	_, isDefault := r.(*DefaultRetryStrategy)
 	assert.True(t, isDefault)
 }
+
+func TestRetry_CreatesSchedule(t *testing.T) {
+	m := datastore.Metadata{
+		Strategy:   "schedule",
+		RetryLimit: 4,
+		Schedule:   []uint64{10, 60, 300},
+	}
+
+	var r RetryStrategy = NewRetryStrategyFromMetadata(m)
+	_, isSchedule := r.(*ScheduleRetryStrategy)
+	assert.True(t, isSchedule)
+}
+
+func TestRetry_CreatesRetryAfter(t *testing.T) {
+	m := datastore.Metadata{
+		Strategy:        "linear",
+		RetryLimit:      20,
+		IntervalSeconds: 5,
+		HonorRetryAfter: true,
+		MaxRetryAfter:   60,
+	}
+
+	var r RetryStrategy = NewRetryStrategyFromMetadata(m)
+	retryAfter, ok := r.(*RetryAfterRetryStrategy)
+	assert.True(t, ok)
+	assert.Equal(t, time.Minute, retryAfter.cap)
+
+	header := http.Header{}
+	header.Set("Retry-After", "30")
+	assert.Equal(t, 30*time.Second, NextDurationForResponse(r, 0, http.StatusTooManyRequests, header))
+	assert.Equal(t, 5*time.Second, NextDurationForResponse(NewDefault(5), 0, http.StatusTooManyRequests, header))
+}
+
+func TestRetry_ElapsedTimeExceeded(t *testing.T) {
+	createdAt := time.Now()
+	m := datastore.Metadata{MaxElapsedTime: 3600}
+
+	assert.False(t, ElapsedTimeExceeded(m, createdAt, createdAt.Add(time.Hour)))
+	assert.True(t, ElapsedTimeExceeded(m, createdAt, createdAt.Add(time.Hour+time.Second)))
+	assert.False(t, ElapsedTimeExceeded(datastore.Metadata{}, createdAt, createdAt.Add(24*time.Hour)))
+}
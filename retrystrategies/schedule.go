@@ -0,0 +1,27 @@
+package retrystrategies
+
+import (
+	"time"
+)
+
+// ScheduleRetryStrategy waits the durations of a user defined schedule
+// between retries, the last one is repeated once the schedule runs out.
+type ScheduleRetryStrategy struct {
+	seconds []uint64
+}
+
+func (r *ScheduleRetryStrategy) NextDuration(attempts uint64) time.Duration {
+	if int(attempts) >= len(r.seconds) {
+		attempts = uint64(len(r.seconds) - 1)
+	}
+
+	return time.Duration(r.seconds[attempts]) * time.Second
+}
+
+func NewSchedule(seconds []uint64) *ScheduleRetryStrategy {
+	return &ScheduleRetryStrategy{
+		seconds: seconds,
+	}
+}
+
+var _ RetryStrategy = (*ScheduleRetryStrategy)(nil)
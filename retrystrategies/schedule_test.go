@@ -0,0 +1,44 @@
+package retrystrategies
+
+import (
+	"testing"
+	"time"
+)
+
+func TestScheduleRetryStrategy(t *testing.T) {
+	tests := []struct {
+		name             string
+		expectedDuration time.Duration
+		attempts         uint64
+		schedule         []uint64
+	}{
+		{
+			name:             "duration-of-first-step",
+			expectedDuration: time.Duration(10) * time.Second,
+			attempts:         0,
+			schedule:         []uint64{10, 60, 300},
+		},
+		{
+			name:             "duration-dependent-on-attempts",
+			expectedDuration: time.Duration(60) * time.Second,
+			attempts:         1,
+			schedule:         []uint64{10, 60, 300},
+		},
+		{
+			name:             "duration-repeats-last-step",
+			expectedDuration: time.Duration(300) * time.Second,
+			attempts:         9,
+			schedule:         []uint64{10, 60, 300},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			retry := NewSchedule(tc.schedule)
+			got := retry.NextDuration(tc.attempts)
+			if got != tc.expectedDuration {
+				t.Errorf("Want duration '%v' for attempts '%d', got '%v'", tc.expectedDuration, tc.attempts, got)
+			}
+		})
+	}
+}
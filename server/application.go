@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -17,12 +16,12 @@ import (
 )
 
 func createApplicationService(a *ApplicationHandler) *services.AppService {
-	appRepo := mongo.NewApplicationRepo(a.A.Store)
-	eventRepo := mongo.NewEventRepository(a.A.Store)
-	eventDeliveryRepo := mongo.NewEventDeliveryRepository(a.A.Store)
+	appRepo := a.A.DB.AppRepo()
+	eventRepo := a.A.DB.EventRepo()
+	eventDeliveryRepo := a.A.DB.EventDeliveryRepo()
 
 	return services.NewAppService(
-		appRepo, eventRepo, eventDeliveryRepo, a.A.Cache,
+		appRepo, eventRepo, eventDeliveryRepo, a.A.Cache, a.A.EgressPolicy,
 	)
 }
 
@@ -219,8 +218,19 @@ func (a *ApplicationHandler) CreateAppEndpoint(w http.ResponseWriter, r *http.Re
 // @Security ApiKeyAuth
 // @Router /api/v1/applications/{appID}/endpoints/{endpointID} [get]
 func (a *ApplicationHandler) GetAppEndpoint(w http.ResponseWriter, r *http.Request) {
+	endpoint := *m.GetApplicationEndpointFromContext(r.Context())
+	group := m.GetGroupFromContext(r.Context())
+	appService := createApplicationService(a)
+
+	endpoints := []datastore.Endpoint{endpoint}
+	err := appService.LoadEndpointCircuitBreakers(r.Context(), group, endpoints)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
 	_ = render.Render(w, r, util.NewServerResponse("App endpoint fetched successfully",
-		*m.GetApplicationFromContext(r.Context()), http.StatusOK))
+		endpoints[0], http.StatusOK))
 }
 
 // GetAppEndpoints
@@ -238,7 +248,16 @@ func (a *ApplicationHandler) GetAppEndpoint(w http.ResponseWriter, r *http.Reque
 func (a *ApplicationHandler) GetAppEndpoints(w http.ResponseWriter, r *http.Request) {
 	app := m.GetApplicationFromContext(r.Context())
 
+	group := m.GetGroupFromContext(r.Context())
+	appService := createApplicationService(a)
+
 	app.Endpoints = m.FilterDeletedEndpoints(app.Endpoints)
+	err := appService.LoadEndpointCircuitBreakers(r.Context(), group, app.Endpoints)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
 	_ = render.Render(w, r, util.NewServerResponse("App endpoints fetched successfully", app.Endpoints, http.StatusOK))
 }
 
@@ -277,6 +296,41 @@ func (a *ApplicationHandler) UpdateAppEndpoint(w http.ResponseWriter, r *http.Re
 	_ = render.Render(w, r, util.NewServerResponse("Apps endpoint updated successfully", endpoint, http.StatusAccepted))
 }
 
+// RotateAppEndpointSecret
+// @Summary Rotate an application endpoint's secret
+// @Description This endpoint replaces an application endpoint's secret, deliveries are signed with the old secret as well until it expires
+// @Tags Application Endpoints
+// @Accept  json
+// @Produce  json
+// @Param groupId query string true "group id"
+// @Param appID path string true "application id"
+// @Param endpointID path string true "endpoint id"
+// @Param secret body models.RotateEndpointSecret true "Secret Details"
+// @Success 200 {object} util.ServerResponse{data=datastore.Endpoint}
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/applications/{appID}/endpoints/{endpointID}/rotate_secret [post]
+func (a *ApplicationHandler) RotateAppEndpointSecret(w http.ResponseWriter, r *http.Request) {
+	var e models.RotateEndpointSecret
+	err := util.ReadJSON(r, &e)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	app := m.GetApplicationFromContext(r.Context())
+	endPointId := chi.URLParam(r, "endpointID")
+	appService := createApplicationService(a)
+
+	endpoint, err := appService.RotateAppEndpointSecret(r.Context(), &e, endPointId, app)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("App endpoint secret rotated successfully", endpoint, http.StatusOK))
+}
+
 // DeleteAppEndpoint
 // @Summary Delete application endpoint
 // @Description This endpoint deletes an application endpoint
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -13,7 +12,7 @@ import (
 )
 
 func createConfigService(a *ApplicationHandler) *services.ConfigService {
-	configRepo := mongo.NewConfigRepo(a.A.Store)
+	configRepo := a.A.DB.ConfigRepo()
 
 	return services.NewConfigService(
 		configRepo,
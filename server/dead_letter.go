@@ -0,0 +1,187 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/server/models"
+	"github.com/frain-dev/convoy/services"
+	"github.com/frain-dev/convoy/util"
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/render"
+	log "github.com/sirupsen/logrus"
+
+	m "github.com/frain-dev/convoy/internal/pkg/middleware"
+)
+
+func createDeadLetterService(a *ApplicationHandler) *services.DeadLetterService {
+	deadLetterRepo := a.A.DB.DeadLetterRepo()
+	eventDeliveryRepo := a.A.DB.EventDeliveryRepo()
+	subRepo := a.A.DB.SubRepo()
+
+	return services.NewDeadLetterService(deadLetterRepo, eventDeliveryRepo, subRepo, a.A.Queue)
+}
+
+// GetDeadLettersPaged
+// @Summary Get dead letters with pagination
+// @Description This endpoint fetches the event deliveries that failed for good
+// @Tags DeadLetters
+// @Accept  json
+// @Produce  json
+// @Param appId query string false "application id"
+// @Param endpointId query string false "endpoint id"
+// @Param subscriptionId query string false "subscription id"
+// @Param groupId query string true "group id"
+// @Param startDate query string false "start date"
+// @Param endDate query string false "end date"
+// @Param status query []string false "dead letter status"
+// @Param perPage query string false "results per page"
+// @Param page query string false "page number"
+// @Param sort query string false "sort order"
+// @Success 200 {object} util.ServerResponse{data=pagedResponse{content=[]datastore.DeadLetter}}
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/deadletters [get]
+func (a *ApplicationHandler) GetDeadLettersPaged(w http.ResponseWriter, r *http.Request) {
+	f, err := getDeadLetterFilter(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	deadLetterService := createDeadLetterService(a)
+	deadLetters, paginationData, err := deadLetterService.LoadDeadLettersPaged(r.Context(), f, m.GetPageableFromContext(r.Context()))
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Dead letters fetched successfully",
+		pagedResponse{Content: &deadLetters, Pagination: &paginationData}, http.StatusOK))
+}
+
+// GetDeadLetter
+// @Summary Get dead letter
+// @Description This endpoint fetches a dead letter with the attempts its event delivery failed with
+// @Tags DeadLetters
+// @Accept  json
+// @Produce  json
+// @Param groupId query string true "group id"
+// @Param deadLetterID path string true "dead letter id"
+// @Success 200 {object} util.ServerResponse{data=datastore.DeadLetter}
+// @Failure 400,401,404,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/deadletters/{deadLetterID} [get]
+func (a *ApplicationHandler) GetDeadLetter(w http.ResponseWriter, r *http.Request) {
+	group := m.GetGroupFromContext(r.Context())
+
+	deadLetterService := createDeadLetterService(a)
+	deadLetter, err := deadLetterService.FindDeadLetterByID(r.Context(), group.UID, chi.URLParam(r, "deadLetterID"))
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Dead letter fetched successfully", deadLetter, http.StatusOK))
+}
+
+// ExportDeadLetters
+// @Summary Export dead letters
+// @Description This endpoint exports the dead letters matching its filters as newline delimited json
+// @Tags DeadLetters
+// @Produce  json
+// @Param appId query string false "application id"
+// @Param endpointId query string false "endpoint id"
+// @Param subscriptionId query string false "subscription id"
+// @Param groupId query string true "group id"
+// @Param startDate query string false "start date"
+// @Param endDate query string false "end date"
+// @Param status query []string false "dead letter status"
+// @Success 200 {array} datastore.DeadLetter
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/deadletters/export [get]
+func (a *ApplicationHandler) ExportDeadLetters(w http.ResponseWriter, r *http.Request) {
+	f, err := getDeadLetterFilter(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s-dead-letters.jsonl", f.GroupID))
+
+	deadLetterService := createDeadLetterService(a)
+	err = deadLetterService.ExportDeadLetters(r.Context(), f, w)
+	if err != nil {
+		// the export may be partly written by now, so there's no
+		// response to send
+		log.WithError(err).Error("failed to export dead letters")
+	}
+}
+
+// RedriveDeadLetters
+// @Summary Redrive dead letters
+// @Description This endpoint requeues the event deliveries of the given dead letters, or of every pending dead letter matching its filters
+// @Tags DeadLetters
+// @Accept  json
+// @Produce  json
+// @Param appId query string false "application id"
+// @Param endpointId query string false "endpoint id"
+// @Param subscriptionId query string false "subscription id"
+// @Param groupId query string true "group id"
+// @Param startDate query string false "start date"
+// @Param endDate query string false "end date"
+// @Param redrive body models.RedriveDeadLetters true "dead letter ids and rate limit"
+// @Success 200 {object} util.ServerResponse{data=Stub}
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/deadletters/redrive [post]
+func (a *ApplicationHandler) RedriveDeadLetters(w http.ResponseWriter, r *http.Request) {
+	var redrive models.RedriveDeadLetters
+	err := json.NewDecoder(r.Body).Decode(&redrive)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse("Request is invalid", http.StatusBadRequest))
+		return
+	}
+
+	f, err := getDeadLetterFilter(r)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	deadLetterService := createDeadLetterService(a)
+	successes, failures, err := deadLetterService.RedriveDeadLetters(r.Context(), m.GetGroupFromContext(r.Context()), &redrive, f)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse(fmt.Sprintf("%d successful, %d failed", successes, failures), nil, http.StatusOK))
+}
+
+func getDeadLetterFilter(r *http.Request) (*datastore.DeadLetterFilter, error) {
+	searchParams, err := getSearchParams(r)
+	if err != nil {
+		return nil, err
+	}
+
+	status := make([]datastore.DeadLetterStatus, 0)
+	for _, s := range r.URL.Query()["status"] {
+		if !util.IsStringEmpty(s) {
+			status = append(status, datastore.DeadLetterStatus(s))
+		}
+	}
+
+	return &datastore.DeadLetterFilter{
+		GroupID:        m.GetGroupFromContext(r.Context()).UID,
+		AppID:          m.GetAppIDFromContext(r),
+		EndpointID:     r.URL.Query().Get("endpointId"),
+		SubscriptionID: r.URL.Query().Get("subscriptionId"),
+		Status:         status,
+		SearchParams:   searchParams,
+	}, nil
+}
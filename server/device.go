@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	m "github.com/frain-dev/convoy/internal/pkg/middleware"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -12,7 +11,7 @@ import (
 )
 
 func createDeviceService(a *ApplicationHandler) *services.DeviceService {
-	deviceRepo := mongo.NewDeviceRepository(a.A.Store)
+	deviceRepo := a.A.DB.DeviceRepo()
 
 	return services.NewDeviceService(deviceRepo)
 }
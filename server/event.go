@@ -9,7 +9,7 @@ import (
 
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
+	"github.com/frain-dev/convoy/internal/pkg/idempotency"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -20,12 +20,12 @@ import (
 )
 
 func createEventService(a *ApplicationHandler) *services.EventService {
-	sourceRepo := mongo.NewSourceRepo(a.A.Store)
-	appRepo := mongo.NewApplicationRepo(a.A.Store)
-	subRepo := mongo.NewSubscriptionRepo(a.A.Store)
-	eventRepo := mongo.NewEventRepository(a.A.Store)
-	eventDeliveryRepo := mongo.NewEventDeliveryRepository(a.A.Store)
-	deviceRepo := mongo.NewDeviceRepository(a.A.Store)
+	sourceRepo := a.A.DB.SourceRepo()
+	appRepo := a.A.DB.AppRepo()
+	subRepo := a.A.DB.SubRepo()
+	eventRepo := a.A.DB.EventRepo()
+	eventDeliveryRepo := a.A.DB.EventDeliveryRepo()
+	deviceRepo := a.A.DB.DeviceRepo()
 
 	return services.NewEventService(
 		appRepo, eventRepo, eventDeliveryRepo,
@@ -53,6 +53,10 @@ func (a *ApplicationHandler) CreateAppEvent(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	if key := r.Header.Get(idempotency.Header); !util.IsStringEmpty(key) {
+		newMessage.ProviderID = key
+	}
+
 	g := m.GetGroupFromContext(r.Context())
 	eventService := createEventService(a)
 
@@ -65,6 +69,38 @@ func (a *ApplicationHandler) CreateAppEvent(w http.ResponseWriter, r *http.Reque
 	_ = render.Render(w, r, util.NewServerResponse("App event created successfully", event, http.StatusCreated))
 }
 
+// BroadcastEvent
+// @Summary Broadcast event
+// @Description This endpoint creates an event that is sent to every app with a subscription to its event type
+// @Tags Events
+// @Accept  json
+// @Produce  json
+// @Param groupId query string true "group id"
+// @Param event body models.BroadcastEvent true "Broadcast Event Details"
+// @Success 200 {object} util.ServerResponse{data=datastore.Event{data=Stub}}
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/events/broadcast [post]
+func (a *ApplicationHandler) BroadcastEvent(w http.ResponseWriter, r *http.Request) {
+	var newMessage models.BroadcastEvent
+	err := util.ReadJSON(r, &newMessage)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	g := m.GetGroupFromContext(r.Context())
+	eventService := createEventService(a)
+
+	event, err := eventService.BroadcastEvent(r.Context(), &newMessage, g)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Broadcast event created successfully", event, http.StatusCreated))
+}
+
 // ReplayAppEvent
 // @Summary Replay app event
 // @Description This endpoint replays an app event
@@ -91,6 +127,31 @@ func (a *ApplicationHandler) ReplayAppEvent(w http.ResponseWriter, r *http.Reque
 	_ = render.Render(w, r, util.NewServerResponse("App event replayed successfully", event, http.StatusOK))
 }
 
+// CancelAppEvent
+// @Summary Cancel a scheduled app event
+// @Description This endpoint cancels the scheduled deliveries of an app event that haven't been sent yet
+// @Tags Events
+// @Accept  json
+// @Produce  json
+// @Param groupId query string true "group id"
+// @Param eventID path string true "event id"
+// @Success 200 {object} util.ServerResponse{data=Stub}
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/events/{eventID}/cancel [put]
+func (a *ApplicationHandler) CancelAppEvent(w http.ResponseWriter, r *http.Request) {
+	event := m.GetEventFromContext(r.Context())
+	eventService := createEventService(a)
+
+	cancelled, err := eventService.CancelAppEvent(r.Context(), event)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse(fmt.Sprintf("%d event deliveries cancelled", cancelled), nil, http.StatusOK))
+}
+
 // GetAppEvent
 // @Summary Get app event
 // @Description This endpoint fetches an app event
@@ -364,6 +425,7 @@ func (a *ApplicationHandler) GetEventsPaged(w http.ResponseWriter, r *http.Reque
 // @Param page query string false "page number"
 // @Param sort query string false "sort order"
 // @Param status query []string false "status"
+// @Param upcoming query bool false "only the scheduled deliveries that are yet to be sent"
 // @Success 200 {object} util.ServerResponse{data=pagedResponse{content=[]datastore.EventDelivery{data=Stub}}}
 // @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
 // @Security ApiKeyAuth
@@ -389,6 +451,7 @@ func (a *ApplicationHandler) GetEventDeliveriesPaged(w http.ResponseWriter, r *h
 		Status:       status,
 		Pageable:     m.GetPageableFromContext(r.Context()),
 		SearchParams: searchParams,
+		Upcoming:     r.URL.Query().Get("upcoming") == "true",
 	}
 
 	eventService := createEventService(a)
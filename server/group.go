@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -14,11 +13,11 @@ import (
 )
 
 func createGroupService(a *ApplicationHandler) *services.GroupService {
-	apiKeyRepo := mongo.NewApiKeyRepo(a.A.Store)
-	appRepo := mongo.NewApplicationRepo(a.A.Store)
-	groupRepo := mongo.NewGroupRepo(a.A.Store)
-	eventRepo := mongo.NewEventRepository(a.A.Store)
-	eventDeliveryRepo := mongo.NewEventDeliveryRepository(a.A.Store)
+	apiKeyRepo := a.A.DB.APIKeyRepo()
+	appRepo := a.A.DB.AppRepo()
+	groupRepo := a.A.DB.GroupRepo()
+	eventRepo := a.A.DB.EventRepo()
+	eventDeliveryRepo := a.A.DB.EventDeliveryRepo()
 
 	return services.NewGroupService(
 		apiKeyRepo, appRepo, groupRepo,
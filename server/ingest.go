@@ -1,7 +1,9 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"time"
@@ -9,8 +11,8 @@ import (
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/internal/pkg/crc"
+	"github.com/frain-dev/convoy/internal/pkg/idempotency"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/pkg/verifier"
 	"github.com/frain-dev/convoy/queue"
@@ -99,12 +101,47 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// 3.2 On success
+	// 3.2 Skip events the provider has already delivered.
+	idempotencyKey := idempotency.SourceKey(source, r.Header)
+	store := idempotency.NewStore(a.A.Cache)
+
+	var window time.Duration
+	if !util.IsStringEmpty(idempotencyKey) {
+		g, err := a.A.DB.GroupRepo().FetchGroupByID(r.Context(), source.GroupID)
+		if err != nil {
+			log.WithError(err).Error("failed to fetch group of source")
+		} else {
+			window = idempotency.Window(g)
+		}
+	}
+
+	// the key is reserved before the event is created, so a redelivery that
+	// races the original doesn't create the event again
+	if window > 0 {
+		original, err := store.Reserve(r.Context(), source.GroupID, source.UID, idempotencyKey, window)
+		if errors.Is(err, idempotency.ErrInProgress) {
+			_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusConflict))
+			return
+		}
+
+		if err != nil {
+			log.WithError(err).Error("failed to reserve idempotency key")
+			window = 0
+		}
+
+		if original != nil {
+			_ = render.Render(w, r, util.NewServerResponse("Event received", nil, http.StatusOK))
+			return
+		}
+	}
+
+	// 3.3 On success
 	// Attach Source to Event.
 	// Write Event to the Ingestion Queue.
 	event := &datastore.Event{
 		UID:            uuid.New().String(),
 		EventType:      datastore.EventType(maskID),
+		ProviderID:     idempotencyKey,
 		SourceID:       source.UID,
 		GroupID:        source.GroupID,
 		Data:           payload,
@@ -116,6 +153,7 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 
 	eventByte, err := json.Marshal(event)
 	if err != nil {
+		releaseIdempotencyKey(r.Context(), store, source, idempotencyKey, window)
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
 		return
 	}
@@ -129,12 +167,31 @@ func (a *ApplicationHandler) IngestEvent(w http.ResponseWriter, r *http.Request)
 	err = a.A.Queue.Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, job)
 	if err != nil {
 		log.Errorf("Error occurred sending new event to the queue %s", err)
+		releaseIdempotencyKey(r.Context(), store, source, idempotencyKey, window)
+	} else if window > 0 {
+		err = store.Save(r.Context(), source.UID, idempotencyKey, event, window)
+		if err != nil {
+			log.WithError(err).Error("failed to save event idempotency key")
+		}
 	}
 
 	// 4. Return 200
 	_ = render.Render(w, r, util.NewServerResponse("Event received", nil, http.StatusOK))
 }
 
+// releaseIdempotencyKey drops the reservation of the key of an event that
+// couldn't be created, so the provider's redelivery creates it.
+func releaseIdempotencyKey(ctx context.Context, store *idempotency.Store, source *datastore.Source, key string, window time.Duration) {
+	if window <= 0 {
+		return
+	}
+
+	err := store.Release(ctx, source.GroupID, source.UID, key)
+	if err != nil {
+		log.WithError(err).Error("failed to release idempotency key")
+	}
+}
+
 func (a *ApplicationHandler) HandleCrcCheck(w http.ResponseWriter, r *http.Request) {
 	maskID := chi.URLParam(r, "maskID")
 
@@ -181,7 +238,7 @@ func (a *ApplicationHandler) HandleCrcCheck(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	sourceRepo := mongo.NewSourceRepo(a.A.Store)
+	sourceRepo := a.A.DB.SourceRepo()
 	err = c.HandleRequest(w, r, source, sourceRepo)
 	if err != nil {
 		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
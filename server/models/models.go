@@ -6,6 +6,7 @@ import (
 
 	"github.com/frain-dev/convoy/auth"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/httpheader"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
@@ -102,16 +103,19 @@ type PortalAPIKeyResponse struct {
 }
 
 type SourceResponse struct {
-	UID            string                    `json:"uid"`
-	MaskID         string                    `json:"mask_id"`
-	GroupID        string                    `json:"group_id"`
-	Name           string                    `json:"name"`
-	Type           datastore.SourceType      `json:"type"`
-	URL            string                    `json:"url"`
-	IsDisabled     bool                      `json:"is_disabled"`
-	Verifier       *datastore.VerifierConfig `json:"verifier"`
-	Provider       datastore.SourceProvider  `json:"provider"`
-	ProviderConfig *datastore.ProviderConfig `json:"provider_config"`
+	UID            string                          `json:"uid"`
+	MaskID         string                          `json:"mask_id"`
+	GroupID        string                          `json:"group_id"`
+	Name           string                          `json:"name"`
+	Type           datastore.SourceType            `json:"type"`
+	URL            string                          `json:"url"`
+	IsDisabled     bool                            `json:"is_disabled"`
+	Verifier       *datastore.VerifierConfig       `json:"verifier"`
+	Provider       datastore.SourceProvider        `json:"provider"`
+	ProviderConfig *datastore.ProviderConfig       `json:"provider_config"`
+	PubSub         *datastore.PubSubConfig         `json:"pub_sub"`
+	DBChangeStream *datastore.DBChangeStreamConfig `json:"db_change_stream"`
+	RestApi        *datastore.RestApiConfig        `json:"rest_api"`
 
 	CreatedAt primitive.DateTime `json:"created_at,omitempty"`
 	UpdatedAt primitive.DateTime `json:"updated_at,omitempty"`
@@ -176,35 +180,80 @@ type UpdateApplication struct {
 }
 
 type Source struct {
-	Name       string                   `json:"name" valid:"required~please provide a source name"`
-	Type       datastore.SourceType     `json:"type" valid:"required~please provide a type,supported_source~unsupported source type"`
-	Provider   datastore.SourceProvider `json:"provider"`
-	IsDisabled bool                     `json:"is_disabled"`
-	Verifier   datastore.VerifierConfig `json:"verifier" valid:"required~please provide a verifier"`
+	Name           string                          `json:"name" valid:"required~please provide a source name"`
+	Type           datastore.SourceType            `json:"type" valid:"required~please provide a type,supported_source~unsupported source type"`
+	Provider       datastore.SourceProvider        `json:"provider"`
+	IsDisabled     bool                            `json:"is_disabled"`
+	Verifier       datastore.VerifierConfig        `json:"verifier" valid:"required~please provide a verifier"`
+	PubSub         *datastore.PubSubConfig         `json:"pub_sub"`
+	DBChangeStream *datastore.DBChangeStreamConfig `json:"db_change_stream"`
+	RestApi        *datastore.RestApiConfig        `json:"rest_api"`
 }
 
 type UpdateSource struct {
-	Name           *string                  `json:"name" valid:"required~please provide a source name"`
-	Type           datastore.SourceType     `json:"type" valid:"required~please provide a type,supported_source~unsupported source type"`
-	IsDisabled     *bool                    `json:"is_disabled"`
-	ForwardHeaders []string                 `json:"forward_headers"`
-	Verifier       datastore.VerifierConfig `json:"verifier" valid:"required~please provide a verifier"`
+	Name           *string                         `json:"name" valid:"required~please provide a source name"`
+	Type           datastore.SourceType            `json:"type" valid:"required~please provide a type,supported_source~unsupported source type"`
+	IsDisabled     *bool                           `json:"is_disabled"`
+	ForwardHeaders []string                        `json:"forward_headers"`
+	Verifier       datastore.VerifierConfig        `json:"verifier" valid:"required~please provide a verifier"`
+	PubSub         *datastore.PubSubConfig         `json:"pub_sub"`
+	DBChangeStream *datastore.DBChangeStreamConfig `json:"db_change_stream"`
+	RestApi        *datastore.RestApiConfig        `json:"rest_api"`
 }
 
 type Event struct {
 	AppID     string `json:"app_id" bson:"app_id" valid:"required~please provide an app id"`
 	EventType string `json:"event_type" bson:"event_type" valid:"required~please provide an event type"`
 
+	// ProviderID is the event's idempotency key, an event with the key of
+	// one created within the group's deduplication window isn't created again.
+	// The Idempotency-Key header takes precedence over it.
+	ProviderID string `json:"provider_id,omitempty" bson:"provider_id"`
+
 	// Data is an arbitrary JSON value that gets sent as the body of the
 	// webhook to the endpoints
 	Data          json.RawMessage   `json:"data" bson:"data" valid:"required~please provide your data"`
 	CustomHeaders map[string]string `json:"custom_headers"`
+
+	// DeliverAt schedules the event's deliveries for a later time, Delay
+	// schedules them for a duration like "30m" or "72h" after the event is
+	// created. Only one of them can be set.
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+	Delay     string     `json:"delay,omitempty"`
+
+	// TTL is how long after they are due the event's deliveries expire,
+	// an expired delivery isn't sent anymore.
+	TTL string `json:"ttl,omitempty" valid:"duration~please provide a valid ttl duration"`
+}
+
+// BroadcastEvent is sent to every application in the group with an active
+// subscription to its event type.
+type BroadcastEvent struct {
+	EventType     string            `json:"event_type" valid:"required~please provide an event type"`
+	Data          json.RawMessage   `json:"data" valid:"required~please provide your data"`
+	CustomHeaders map[string]string `json:"custom_headers"`
+
+	DeliverAt *time.Time `json:"deliver_at,omitempty"`
+	Delay     string     `json:"delay,omitempty"`
+	TTL       string     `json:"ttl,omitempty" valid:"duration~please provide a valid ttl duration"`
+
+	// RateLimit is the number of the event's deliveries sent a second
+	RateLimit int `json:"rate_limit"`
 }
 
 type IDs struct {
 	IDs []string `json:"ids"`
 }
 
+type RedriveDeadLetters struct {
+	// IDs are the dead letters to redrive, every dead letter matching the
+	// request's filters is redriven when it is empty
+	IDs []string `json:"ids"`
+
+	// RateLimit is the number of deliveries requeued per second
+	RateLimit int `json:"rate_limit"`
+}
+
 type DeliveryAttempt struct {
 	MessageID  string `json:"msg_id" bson:"msg_id"`
 	APIVersion string `json:"api_version" bson:"api_version"`
@@ -227,10 +276,49 @@ type Endpoint struct {
 	Description string   `json:"description" bson:"description"`
 	Events      []string `json:"events" bson:"events"`
 
-	HttpTimeout       string                            `json:"http_timeout" bson:"http_timeout"`
-	RateLimit         int                               `json:"rate_limit" bson:"rate_limit"`
-	RateLimitDuration string                            `json:"rate_limit_duration" bson:"rate_limit_duration"`
-	Authentication    *datastore.EndpointAuthentication `json:"authentication"`
+	HttpTimeout       string                  `json:"http_timeout" bson:"http_timeout"`
+	RateLimit         int                     `json:"rate_limit" bson:"rate_limit"`
+	RateLimitDuration string                  `json:"rate_limit_duration" bson:"rate_limit_duration"`
+	Authentication    *EndpointAuthentication `json:"authentication"`
+	TLSConfig         *EndpointTLSConfig      `json:"tls_config"`
+}
+
+// EndpointAuthentication is how an endpoint authenticates deliveries, see
+// datastore.EndpointAuthentication.
+type EndpointAuthentication struct {
+	Type   datastore.EndpointAuthenticationType `json:"type,omitempty" valid:"optional,in(api_key|oauth2)~unsupported authentication type"`
+	ApiKey *datastore.ApiKey                    `json:"api_key"`
+	OAuth2 *EndpointOAuth2                      `json:"oauth2,omitempty"`
+}
+
+// EndpointOAuth2 is an endpoint's oauth2 client, see datastore.OAuth2. The
+// client secret can be left out on update to keep the endpoint's secret for
+// an unchanged client.
+type EndpointOAuth2 struct {
+	TokenURL     string   `json:"token_url" valid:"required~please provide a token url,url~please provide a valid token url"`
+	ClientID     string   `json:"client_id" valid:"required~please provide a client id"`
+	ClientSecret string   `json:"client_secret"`
+	Scopes       []string `json:"scopes,omitempty"`
+	Audience     string   `json:"audience,omitempty"`
+}
+
+// EndpointTLSConfig is an endpoint's tls configuration, see
+// datastore.TLSConfiguration. The client key can be left out on update to
+// keep the endpoint's key for an unchanged client certificate, and an empty
+// configuration removes it.
+type EndpointTLSConfig struct {
+	ClientCert string               `json:"client_cert"`
+	ClientKey  string               `json:"client_key"`
+	CACert     string               `json:"ca_cert"`
+	MinVersion datastore.TLSVersion `json:"min_version" valid:"optional,in(1.0|1.1|1.2|1.3)~unsupported tls version"`
+}
+
+// RotateEndpointSecret replaces an endpoint's secret. Deliveries are signed
+// with the old secret as well until it expires after Expiration seconds,
+// datastore.DefaultSecretExpiration when it is unset.
+type RotateEndpointSecret struct {
+	Secret     string `json:"secret"`
+	Expiration int    `json:"expiration" valid:"range(0|2592000)~expiration must be between 0 seconds and 30 days"`
 }
 
 type DashboardSummary struct {
@@ -255,7 +343,12 @@ type Subscription struct {
 	RetryConfig     *RetryConfiguration               `json:"retry_config,omitempty" bson:"retry_config,omitempty"`
 	FilterConfig    *datastore.FilterConfiguration    `json:"filter_config,omitempty" bson:"filter_config,omitempty"`
 	RateLimitConfig *datastore.RateLimitConfiguration `json:"rate_limit_config,omitempty" bson:"rate_limit_config,omitempty"`
+	TransformConfig *datastore.TransformConfiguration `json:"transform_config,omitempty" bson:"transform_config,omitempty"`
+	OrderingConfig  *datastore.OrderingConfiguration  `json:"ordering_config,omitempty" bson:"ordering_config,omitempty"`
+	BatchConfig     *datastore.BatchConfiguration     `json:"batch_config,omitempty" bson:"batch_config,omitempty"`
+	FailurePolicy   *datastore.FailurePolicy          `json:"failure_policy,omitempty" bson:"failure_policy,omitempty"`
 	DisableEndpoint *bool                             `json:"disable_endpoint" bson:"disable_endpoint"`
+	TTL             string                            `json:"ttl,omitempty" bson:"ttl,omitempty" valid:"duration~please provide a valid ttl duration"`
 }
 
 type UpdateSubscription struct {
@@ -268,7 +361,30 @@ type UpdateSubscription struct {
 	RetryConfig     *RetryConfiguration               `json:"retry_config,omitempty"`
 	FilterConfig    *datastore.FilterConfiguration    `json:"filter_config,omitempty"`
 	RateLimitConfig *datastore.RateLimitConfiguration `json:"rate_limit_config,omitempty"`
+	TransformConfig *datastore.TransformConfiguration `json:"transform_config,omitempty"`
+	OrderingConfig  *datastore.OrderingConfiguration  `json:"ordering_config,omitempty"`
+	BatchConfig     *datastore.BatchConfiguration     `json:"batch_config,omitempty"`
+	FailurePolicy   *datastore.FailurePolicy          `json:"failure_policy,omitempty"`
 	DisableEndpoint *bool                             `json:"disable_endpoint" bson:"disable_endpoint"`
+
+	// TTL replaces the subscription's TTL, "0s" removes it.
+	TTL string `json:"ttl,omitempty" valid:"duration~please provide a valid ttl duration"`
+}
+
+type TestFilter struct {
+	Request FilterRequest          `json:"request"`
+	Schema  datastore.FilterSchema `json:"schema"`
+}
+
+// FilterRequest is the sample event a filter schema is tested against.
+type FilterRequest struct {
+	Headers httpheader.HTTPHeader `json:"headers"`
+	Body    json.RawMessage       `json:"body"`
+}
+
+type TestTransform struct {
+	Program string `json:"program" valid:"required~please provide a transform program"`
+	EventID string `json:"event_id" valid:"required~please provide an event id"`
 }
 
 type RetryConfiguration struct {
@@ -276,6 +392,13 @@ type RetryConfiguration struct {
 	Duration        string                     `json:"duration,omitempty" valid:"duration~please provide a valid time duration"`
 	IntervalSeconds uint64                     `json:"interval_seconds" valid:"int~please provide a valid interval seconds"`
 	RetryCount      uint64                     `json:"retry_count" valid:"int~please provide a valid retry count"`
+
+	// Schedule is the seconds the schedule strategy waits before each retry,
+	// such as [10, 60, 300], the format groups and responses use
+	Schedule        []uint64 `json:"schedule,omitempty"`
+	HonorRetryAfter bool     `json:"honor_retry_after"`
+	MaxRetryAfter   string   `json:"max_retry_after,omitempty" valid:"duration~please provide a valid max retry after duration"`
+	MaxElapsedTime  string   `json:"max_elapsed_time,omitempty" valid:"duration~please provide a valid max elapsed time duration"`
 }
 
 type UpdateUser struct {
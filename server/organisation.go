@@ -3,7 +3,6 @@ package server
 import (
 	"net/http"
 
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -14,8 +13,8 @@ import (
 )
 
 func createOrganisationService(a *ApplicationHandler) *services.OrganisationService {
-	orgRepo := mongo.NewOrgRepo(a.A.Store)
-	orgMemberRepo := mongo.NewOrgMemberRepo(a.A.Store)
+	orgRepo := a.A.DB.OrgRepo()
+	orgMemberRepo := a.A.DB.OrgMemberRepo()
 
 	return services.NewOrganisationService(orgRepo, orgMemberRepo)
 }
@@ -6,7 +6,6 @@ import (
 	"strconv"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/services"
 
 	"github.com/frain-dev/convoy/server/models"
@@ -19,10 +18,10 @@ import (
 )
 
 func CreateOrganisationInviteService(a *ApplicationHandler) *services.OrganisationInviteService {
-	userRepo := mongo.NewUserRepo(a.A.Store)
-	orgRepo := mongo.NewOrgRepo(a.A.Store)
-	orgMemberRepo := mongo.NewOrgMemberRepo(a.A.Store)
-	orgInviteRepo := mongo.NewOrgInviteRepo(a.A.Store)
+	userRepo := a.A.DB.UserRepo()
+	orgRepo := a.A.DB.OrgRepo()
+	orgMemberRepo := a.A.DB.OrgMemberRepo()
+	orgInviteRepo := a.A.DB.OrgInviteRepo()
 
 	return services.NewOrganisationInviteService(
 		orgRepo, userRepo, orgMemberRepo,
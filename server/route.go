@@ -10,12 +10,12 @@ import (
 	"github.com/frain-dev/convoy/auth"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
-	cm "github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/internal/pkg/metrics"
 	"github.com/frain-dev/convoy/internal/pkg/middleware"
 	"github.com/frain-dev/convoy/internal/pkg/searcher"
 	"github.com/frain-dev/convoy/limiter"
 	"github.com/frain-dev/convoy/logger"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/queue"
 	redisqueue "github.com/frain-dev/convoy/queue/redis"
 	"github.com/frain-dev/convoy/tracer"
@@ -32,6 +32,7 @@ type ApplicationHandler struct {
 }
 
 type App struct {
+	DB       datastore.Database
 	Store    datastore.Store
 	Queue    queue.Queuer
 	Logger   logger.Logger
@@ -39,6 +40,8 @@ type App struct {
 	Cache    cache.Cache
 	Limiter  limiter.RateLimiter
 	Searcher searcher.Searcher
+
+	EgressPolicy *net.EgressPolicy
 }
 
 //go:embed ui/build
@@ -70,24 +73,25 @@ func NewApplicationHandler(a App) *ApplicationHandler {
 		Logger:            a.Logger,
 		Limiter:           a.Limiter,
 		Tracer:            a.Tracer,
-		EventRepo:         cm.NewEventRepository(a.Store),
-		EventDeliveryRepo: cm.NewEventDeliveryRepository(a.Store),
-		AppRepo:           cm.NewApplicationRepo(a.Store),
-		GroupRepo:         cm.NewGroupRepo(a.Store),
-		ApiKeyRepo:        cm.NewApiKeyRepo(a.Store),
-		SubRepo:           cm.NewSubscriptionRepo(a.Store),
-		SourceRepo:        cm.NewSourceRepo(a.Store),
-		OrgRepo:           cm.NewOrgRepo(a.Store),
-		OrgMemberRepo:     cm.NewOrgMemberRepo(a.Store),
-		OrgInviteRepo:     cm.NewOrgInviteRepo(a.Store),
-		UserRepo:          cm.NewUserRepo(a.Store),
-		ConfigRepo:        cm.NewConfigRepo(a.Store),
-		DeviceRepo:        cm.NewDeviceRepository(a.Store),
+		EventRepo:         a.DB.EventRepo(),
+		EventDeliveryRepo: a.DB.EventDeliveryRepo(),
+		AppRepo:           a.DB.AppRepo(),
+		GroupRepo:         a.DB.GroupRepo(),
+		ApiKeyRepo:        a.DB.APIKeyRepo(),
+		SubRepo:           a.DB.SubRepo(),
+		SourceRepo:        a.DB.SourceRepo(),
+		OrgRepo:           a.DB.OrgRepo(),
+		OrgMemberRepo:     a.DB.OrgMemberRepo(),
+		OrgInviteRepo:     a.DB.OrgInviteRepo(),
+		UserRepo:          a.DB.UserRepo(),
+		ConfigRepo:        a.DB.ConfigRepo(),
+		DeviceRepo:        a.DB.DeviceRepo(),
 	})
 
 	return &ApplicationHandler{
 		M: m,
 		A: App{
+			DB:       a.DB,
 			Store:    a.Store,
 			Queue:    a.Queue,
 			Cache:    a.Cache,
@@ -149,6 +153,7 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 							e.Get("/", a.GetAppEndpoint)
 							e.Put("/", a.UpdateAppEndpoint)
 							e.Delete("/", a.DeleteAppEndpoint)
+							e.Post("/rotate_secret", a.RotateAppEndpointSecret)
 						})
 					})
 				})
@@ -160,12 +165,14 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 				eventRouter.Use(a.M.RequirePermission(auth.RoleAdmin))
 
 				eventRouter.With(a.M.InstrumentPath("/events")).Post("/", a.CreateAppEvent)
+				eventRouter.With(a.M.InstrumentPath("/events/broadcast")).Post("/broadcast", a.BroadcastEvent)
 				eventRouter.With(a.M.Pagination).Get("/", a.GetEventsPaged)
 
 				eventRouter.Route("/{eventID}", func(eventSubRouter chi.Router) {
 					eventSubRouter.Use(a.M.RequireEvent())
 					eventSubRouter.Get("/", a.GetAppEvent)
 					eventSubRouter.Put("/replay", a.ReplayAppEvent)
+					eventSubRouter.Put("/cancel", a.CancelAppEvent)
 				})
 			})
 
@@ -193,6 +200,16 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 				})
 			})
 
+			r.Route("/deadletters", func(deadLetterRouter chi.Router) {
+				deadLetterRouter.Use(a.M.RequireGroup())
+				deadLetterRouter.Use(a.M.RequirePermission(auth.RoleAdmin))
+
+				deadLetterRouter.With(a.M.Pagination).Get("/", a.GetDeadLettersPaged)
+				deadLetterRouter.Get("/export", a.ExportDeadLetters)
+				deadLetterRouter.Post("/redrive", a.RedriveDeadLetters)
+				deadLetterRouter.Get("/{deadLetterID}", a.GetDeadLetter)
+			})
+
 			r.Route("/security", func(securityRouter chi.Router) {
 				securityRouter.Route("/applications/{appID}/keys", func(securitySubRouter chi.Router) {
 					securitySubRouter.Use(a.M.RequireGroup())
@@ -209,6 +226,8 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 				subscriptionRouter.Use(a.M.RequirePermission(auth.RoleAdmin))
 
 				subscriptionRouter.Post("/", a.CreateSubscription)
+				subscriptionRouter.Post("/test_filter", a.TestSubscriptionFilter)
+				subscriptionRouter.Post("/test_transform", a.TestSubscriptionTransform)
 				subscriptionRouter.With(a.M.Pagination).Get("/", a.GetSubscriptions)
 				subscriptionRouter.Delete("/{subscriptionID}", a.DeleteSubscription)
 				subscriptionRouter.Get("/{subscriptionID}", a.GetSubscription)
@@ -352,6 +371,7 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 										e.Get("/", a.GetAppEndpoint)
 										e.Put("/", a.UpdateAppEndpoint)
 										e.Delete("/", a.DeleteAppEndpoint)
+										e.Post("/rotate_secret", a.RotateAppEndpointSecret)
 									})
 								})
 
@@ -365,12 +385,14 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 							eventRouter.Use(a.M.RequireOrganisationMemberRole(auth.RoleAdmin))
 
 							eventRouter.Post("/", a.CreateAppEvent)
+							eventRouter.Post("/broadcast", a.BroadcastEvent)
 							eventRouter.With(a.M.Pagination).Get("/", a.GetEventsPaged)
 
 							eventRouter.Route("/{eventID}", func(eventSubRouter chi.Router) {
 								eventSubRouter.Use(a.M.RequireEvent())
 								eventSubRouter.Get("/", a.GetAppEvent)
 								eventSubRouter.Put("/replay", a.ReplayAppEvent)
+								eventSubRouter.Put("/cancel", a.CancelAppEvent)
 							})
 						})
 
@@ -397,10 +419,21 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 							})
 						})
 
+						groupSubRouter.Route("/deadletters", func(deadLetterRouter chi.Router) {
+							deadLetterRouter.Use(a.M.RequireOrganisationMemberRole(auth.RoleSuperUser))
+
+							deadLetterRouter.With(a.M.Pagination).Get("/", a.GetDeadLettersPaged)
+							deadLetterRouter.Get("/export", a.ExportDeadLetters)
+							deadLetterRouter.Post("/redrive", a.RedriveDeadLetters)
+							deadLetterRouter.Get("/{deadLetterID}", a.GetDeadLetter)
+						})
+
 						groupSubRouter.Route("/subscriptions", func(subscriptionRouter chi.Router) {
 							subscriptionRouter.Use(a.M.RequireOrganisationMemberRole(auth.RoleAdmin))
 
 							subscriptionRouter.Post("/", a.CreateSubscription)
+							subscriptionRouter.Post("/test_filter", a.TestSubscriptionFilter)
+							subscriptionRouter.Post("/test_transform", a.TestSubscriptionTransform)
 							subscriptionRouter.With(a.M.Pagination).Get("/", a.GetSubscriptions)
 							subscriptionRouter.Delete("/{subscriptionID}", a.DeleteSubscription)
 							subscriptionRouter.Get("/{subscriptionID}", a.GetSubscription)
@@ -458,6 +491,7 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 
 					e.Get("/", a.GetAppEndpoint)
 					e.Put("/", a.UpdateAppEndpoint)
+					e.Post("/rotate_secret", a.RotateAppEndpointSecret)
 				})
 			})
 
@@ -513,11 +547,14 @@ func (a *ApplicationHandler) BuildRoutes() http.Handler {
 		})
 	})
 
-	router.Handle("/queue/monitoring/*", a.A.Queue.(*redisqueue.RedisQueue).Monitor())
+	if rq, ok := a.A.Queue.(*redisqueue.RedisQueue); ok {
+		router.Handle("/queue/monitoring/*", rq.Monitor())
+	}
 	router.Handle("/metrics", promhttp.HandlerFor(metrics.Reg(), promhttp.HandlerOpts{}))
 	router.HandleFunc("/*", reactRootHandler)
 
 	metrics.RegisterQueueMetrics(a.A.Queue)
+	metrics.RegisterDeadLetterMetrics(a.A.DB.DeadLetterRepo())
 	prometheus.MustRegister(metrics.RequestDuration())
 
 	return router
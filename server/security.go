@@ -9,7 +9,6 @@ import (
 	"github.com/cip8/autoname"
 	"github.com/frain-dev/convoy/auth"
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -21,8 +20,8 @@ import (
 )
 
 func createSecurityService(a *ApplicationHandler) *services.SecurityService {
-	groupRepo := mongo.NewGroupRepo(a.A.Store)
-	apiKeyRepo := mongo.NewApiKeyRepo(a.A.Store)
+	groupRepo := a.A.DB.GroupRepo()
+	apiKeyRepo := a.A.DB.APIKeyRepo()
 
 	return services.NewSecurityService(groupRepo, apiKeyRepo)
 }
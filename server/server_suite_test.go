@@ -115,6 +115,7 @@ func buildServer() *ApplicationHandler {
 
 	return NewApplicationHandler(
 		App{
+			DB:       &db,
 			Store:    store,
 			Queue:    queue,
 			Logger:   logger,
@@ -5,7 +5,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -16,9 +15,9 @@ import (
 )
 
 func createSourceService(a *ApplicationHandler) *services.SourceService {
-	sourceRepo := mongo.NewSourceRepo(a.A.Store)
+	sourceRepo := a.A.DB.SourceRepo()
 
-	return services.NewSourceService(sourceRepo, a.A.Cache)
+	return services.NewSourceService(sourceRepo, a.A.Cache, a.A.EgressPolicy)
 }
 
 // CreateSource
@@ -203,6 +202,9 @@ func sourceResponse(s *datastore.Source, baseUrl string) *models.SourceResponse
 		Type:           s.Type,
 		Provider:       s.Provider,
 		ProviderConfig: s.ProviderConfig,
+		PubSub:         s.PubSub,
+		DBChangeStream: s.DBChangeStream,
+		RestApi:        s.RestApi,
 		URL:            fmt.Sprintf("%s/ingest/%s", baseUrl, s.MaskID),
 		IsDisabled:     s.IsDisabled,
 		Verifier:       s.Verifier,
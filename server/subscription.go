@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 
@@ -17,11 +16,12 @@ import (
 )
 
 func createSubscriptionService(a *ApplicationHandler) *services.SubcriptionService {
-	subRepo := mongo.NewSubscriptionRepo(a.A.Store)
-	appRepo := mongo.NewApplicationRepo(a.A.Store)
-	sourceRepo := mongo.NewSourceRepo(a.A.Store)
+	subRepo := a.A.DB.SubRepo()
+	appRepo := a.A.DB.AppRepo()
+	sourceRepo := a.A.DB.SourceRepo()
+	eventRepo := a.A.DB.EventRepo()
 
-	return services.NewSubscriptionService(subRepo, appRepo, sourceRepo)
+	return services.NewSubscriptionService(subRepo, appRepo, sourceRepo, eventRepo)
 }
 
 // GetSubscriptions
@@ -207,3 +207,64 @@ func (a *ApplicationHandler) ToggleSubscriptionStatus(w http.ResponseWriter, r *
 
 	_ = render.Render(w, r, util.NewServerResponse("Subscription status updated successfully", sub, http.StatusAccepted))
 }
+
+// TestSubscriptionFilter
+// @Summary Test a subscription filter
+// @Description This endpoint tests a subscription's filter schema against a sample request
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param groupId query string true "group id"
+// @Param filter body models.TestFilter true "Filter schema and sample request"
+// @Success 200 {object} util.ServerResponse{data=boolean}
+// @Failure 400,401,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/subscriptions/test_filter [post]
+func (a *ApplicationHandler) TestSubscriptionFilter(w http.ResponseWriter, r *http.Request) {
+	var test models.TestFilter
+	err := util.ReadJSON(r, &test)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	subService := createSubscriptionService(a)
+	isMatched, err := subService.TestSubscriptionFilter(r.Context(), &test)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Subscription filter tested successfully", isMatched, http.StatusOK))
+}
+
+// TestSubscriptionTransform
+// @Summary Test a subscription transform
+// @Description This endpoint runs a transform program on a stored event without delivering it
+// @Tags Subscriptions
+// @Accept json
+// @Produce json
+// @Param groupId query string true "group id"
+// @Param transform body models.TestTransform true "Transform program and event id"
+// @Success 200 {object} util.ServerResponse{data=transform.Result}
+// @Failure 400,401,404,500 {object} util.ServerResponse{data=Stub}
+// @Security ApiKeyAuth
+// @Router /api/v1/subscriptions/test_transform [post]
+func (a *ApplicationHandler) TestSubscriptionTransform(w http.ResponseWriter, r *http.Request) {
+	var test models.TestTransform
+	err := util.ReadJSON(r, &test)
+	if err != nil {
+		_ = render.Render(w, r, util.NewErrorResponse(err.Error(), http.StatusBadRequest))
+		return
+	}
+
+	group := m.GetGroupFromContext(r.Context())
+	subService := createSubscriptionService(a)
+	result, err := subService.TestSubscriptionTransform(r.Context(), group, &test)
+	if err != nil {
+		_ = render.Render(w, r, util.NewServiceErrResponse(err))
+		return
+	}
+
+	_ = render.Render(w, r, util.NewServerResponse("Subscription transform tested successfully", result, http.StatusOK))
+}
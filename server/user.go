@@ -4,7 +4,6 @@ import (
 	"net/http"
 
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/services"
 	"github.com/frain-dev/convoy/util"
@@ -14,7 +13,7 @@ import (
 )
 
 func createUserService(a *ApplicationHandler) *services.UserService {
-	userRepo := mongo.NewUserRepo(a.A.Store)
+	userRepo := a.A.DB.UserRepo()
 	configService := createConfigService(a)
 	orgService := createOrganisationService(a)
 
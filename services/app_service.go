@@ -11,6 +11,8 @@ import (
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/circuitbreaker"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/google/uuid"
@@ -23,10 +25,11 @@ type AppService struct {
 	eventRepo         datastore.EventRepository
 	eventDeliveryRepo datastore.EventDeliveryRepository
 	cache             cache.Cache
+	egressPolicy      *net.EgressPolicy
 }
 
-func NewAppService(appRepo datastore.ApplicationRepository, eventRepo datastore.EventRepository, eventDeliveryRepo datastore.EventDeliveryRepository, cache cache.Cache) *AppService {
-	return &AppService{appRepo: appRepo, eventRepo: eventRepo, eventDeliveryRepo: eventDeliveryRepo, cache: cache}
+func NewAppService(appRepo datastore.ApplicationRepository, eventRepo datastore.EventRepository, eventDeliveryRepo datastore.EventDeliveryRepository, cache cache.Cache, egressPolicy *net.EgressPolicy) *AppService {
+	return &AppService{appRepo: appRepo, eventRepo: eventRepo, eventDeliveryRepo: eventDeliveryRepo, cache: cache, egressPolicy: egressPolicy}
 }
 
 func (a *AppService) CreateApp(ctx context.Context, newApp *models.Application, g *datastore.Group) (*datastore.Application, error) {
@@ -156,6 +159,11 @@ func (a *AppService) CreateAppEndpoint(ctx context.Context, e models.Endpoint, a
 		return nil, util.NewServiceError(http.StatusBadRequest, fmt.Errorf("an error occurred parsing the rate limit duration: %v", err))
 	}
 
+	err = a.checkEgress(ctx, e)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
 	endpoint := &datastore.Endpoint{
 		UID:               uuid.New().String(),
 		TargetURL:         e.URL,
@@ -176,13 +184,18 @@ func (a *AppService) CreateAppEndpoint(ctx context.Context, e models.Endpoint, a
 		}
 	}
 
-	auth, err := validateEndpointAuthentication(e)
+	auth, err := validateEndpointAuthentication(e, nil)
 	if err != nil {
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
 	}
 	
 	endpoint.Authentication = auth
 
+	endpoint.TLSConfig, err = validateEndpointTLSConfig(e.TLSConfig, nil)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
 	err = a.appRepo.CreateApplicationEndpoint(ctx, app.GroupID, app.UID, endpoint)
 	if err != nil {
 		log.WithError(err).Error("failed to create application endpoint")
@@ -204,11 +217,31 @@ func (a *AppService) CreateAppEndpoint(ctx context.Context, e models.Endpoint, a
 	return endpoint, nil
 }
 
+// checkEgress checks the urls the endpoint's deliveries are sent to, its
+// url and the token url of its oauth2 client, against the egress policy.
+func (a *AppService) checkEgress(ctx context.Context, e models.Endpoint) error {
+	if err := a.egressPolicy.CheckEndpoint(ctx, e.URL); err != nil {
+		return err
+	}
+
+	if auth := e.Authentication; auth != nil && auth.OAuth2 != nil {
+		if err := a.egressPolicy.CheckEndpoint(ctx, auth.OAuth2.TokenURL); err != nil {
+			return fmt.Errorf("token url: %w", err)
+		}
+	}
+
+	return nil
+}
+
 func (a *AppService) UpdateAppEndpoint(ctx context.Context, e models.Endpoint, endPointId string, app *datastore.Application) (*datastore.Endpoint, error) {
 	if err := util.Validate(e); err != nil {
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
 	}
 
+	if err := a.checkEgress(ctx, e); err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
 	endpoints, endpoint, err := updateEndpointIfFound(&app.Endpoints, endPointId, e)
 	if err != nil {
 		return endpoint, util.NewServiceError(http.StatusBadRequest, err)
@@ -229,6 +262,69 @@ func (a *AppService) UpdateAppEndpoint(ctx context.Context, e models.Endpoint, e
 	return endpoint, nil
 }
 
+// RotateAppEndpointSecret replaces the endpoint's secret with a new one and
+// keeps signing deliveries with the old secret until it expires, so
+// consumers can switch secrets without failing to verify any delivery.
+func (a *AppService) RotateAppEndpointSecret(ctx context.Context, e *models.RotateEndpointSecret, endPointId string, app *datastore.Application) (*datastore.Endpoint, error) {
+	if err := util.Validate(e); err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	expiration := datastore.DefaultSecretExpiration
+	if e.Expiration > 0 {
+		expiration = time.Duration(e.Expiration) * time.Second
+	}
+
+	secret := e.Secret
+	if util.IsStringEmpty(secret) {
+		var err error
+		secret, err = util.GenerateSecret()
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, fmt.Errorf("could not generate secret...%v", err))
+		}
+	}
+
+	var endpoint *datastore.Endpoint
+	for i := range app.Endpoints {
+		if app.Endpoints[i].UID == endPointId && app.Endpoints[i].DeletedAt == 0 {
+			endpoint = &app.Endpoints[i]
+			break
+		}
+	}
+
+	if endpoint == nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, datastore.ErrEndpointNotFound)
+	}
+
+	if endpoint.Secret == secret {
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("the new secret must be different from the current secret"))
+	}
+
+	now := time.Now()
+	endpoint.PruneSecrets(now)
+	endpoint.Secrets = append(endpoint.Secrets, datastore.EndpointSecret{
+		UID:       uuid.New().String(),
+		Value:     endpoint.Secret,
+		ExpiresAt: primitive.NewDateTimeFromTime(now.Add(expiration)),
+	})
+	endpoint.Secret = secret
+	endpoint.UpdatedAt = primitive.NewDateTimeFromTime(now)
+
+	err := a.appRepo.UpdateApplication(ctx, app, app.GroupID)
+	if err != nil {
+		log.WithError(err).Error("failed to rotate app endpoint secret")
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("an error occurred while rotating app endpoint secret"))
+	}
+
+	appCacheKey := convoy.ApplicationsCacheKey.Get(app.UID).String()
+	err = a.cache.Set(ctx, appCacheKey, &app, time.Minute*5)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("failed to update application cache"))
+	}
+
+	return endpoint, nil
+}
+
 func (a *AppService) DeleteAppEndpoint(ctx context.Context, e *datastore.Endpoint, app *datastore.Application) error {
 
 	for i, endpoint := range app.Endpoints {
@@ -253,6 +349,28 @@ func (a *AppService) DeleteAppEndpoint(ctx context.Context, e *datastore.Endpoin
 	return nil
 }
 
+// LoadEndpointCircuitBreakers sets the state of each endpoint's circuit
+// breaker, the endpoints are left as they are when the group's breakers are
+// disabled.
+func (a *AppService) LoadEndpointCircuitBreakers(ctx context.Context, g *datastore.Group, endpoints []datastore.Endpoint) error {
+	if g.Config == nil || g.Config.CircuitBreaker == nil {
+		return nil
+	}
+
+	m := circuitbreaker.NewManager(a.cache, circuitbreaker.NewConfig(g.Config.CircuitBreaker))
+	for i := range endpoints {
+		b, err := m.Get(ctx, circuitbreaker.Key(endpoints[i].UID))
+		if err != nil {
+			log.WithError(err).Error("failed to load endpoint circuit breaker")
+			return util.NewServiceError(http.StatusInternalServerError, errors.New("failed to load endpoint circuit breaker"))
+		}
+
+		endpoints[i].CircuitBreaker = b.Status()
+	}
+
+	return nil
+}
+
 func (a *AppService) CountGroupApplications(ctx context.Context, groupID string) (int64, error) {
 	apps, err := a.appRepo.CountGroupApplications(ctx, groupID)
 	if err != nil {
@@ -290,12 +408,21 @@ func updateEndpointIfFound(endpoints *[]datastore.Endpoint, id string, e models.
 				endpoint.Secret = e.Secret
 			}
 
-			auth, err := validateEndpointAuthentication(e)
+			auth, err := validateEndpointAuthentication(e, endpoint.Authentication)
 			if err != nil {
 				return nil, nil, err
 			}
 
-			e.Authentication = auth
+			if auth != nil {
+				endpoint.Authentication = auth
+			}
+
+			if e.TLSConfig != nil {
+				endpoint.TLSConfig, err = validateEndpointTLSConfig(e.TLSConfig, endpoint.TLSConfig)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
 
 			endpoint.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
 			(*endpoints)[i] = endpoint
@@ -305,7 +432,10 @@ func updateEndpointIfFound(endpoints *[]datastore.Endpoint, id string, e models.
 	return endpoints, nil, datastore.ErrEndpointNotFound
 }
 
-func validateEndpointAuthentication(e models.Endpoint) (*datastore.EndpointAuthentication, error) {
+// validateEndpointAuthentication returns the endpoint's authentication from
+// the request. current is the endpoint's authentication before the update,
+// if there is one.
+func validateEndpointAuthentication(e models.Endpoint, current *datastore.EndpointAuthentication) (*datastore.EndpointAuthentication, error) {
 	if e.Authentication != nil && !util.IsStringEmpty(string(e.Authentication.Type)) {
 		if err := util.Validate(e); err != nil {
 			return nil, err
@@ -315,8 +445,70 @@ func validateEndpointAuthentication(e models.Endpoint) (*datastore.EndpointAuthe
 			return nil, util.NewServiceError(http.StatusBadRequest, errors.New("api key field is required"))
 		}
 
-		return e.Authentication, nil
+		if e.Authentication.OAuth2 == nil && e.Authentication.Type == datastore.OAuth2Authentication {
+			return nil, util.NewServiceError(http.StatusBadRequest, errors.New("oauth2 field is required"))
+		}
+
+		auth := &datastore.EndpointAuthentication{
+			Type:   e.Authentication.Type,
+			ApiKey: e.Authentication.ApiKey,
+		}
+
+		if cfg := e.Authentication.OAuth2; cfg != nil {
+			auth.OAuth2 = &datastore.OAuth2{
+				TokenURL:     cfg.TokenURL,
+				ClientID:     cfg.ClientID,
+				ClientSecret: cfg.ClientSecret,
+				Scopes:       cfg.Scopes,
+				Audience:     cfg.Audience,
+			}
+
+			// the client secret isn't returned by the api, so it is kept
+			// when the client doesn't change
+			if util.IsStringEmpty(cfg.ClientSecret) && current != nil && current.OAuth2 != nil &&
+				current.OAuth2.TokenURL == cfg.TokenURL && current.OAuth2.ClientID == cfg.ClientID {
+				auth.OAuth2.ClientSecret = current.OAuth2.ClientSecret
+			}
+
+			if util.IsStringEmpty(auth.OAuth2.ClientSecret) {
+				return nil, util.NewServiceError(http.StatusBadRequest, errors.New("please provide a client secret"))
+			}
+		}
+
+		return auth, nil
 	}
 
 	return nil, nil
 }
+
+// validateEndpointTLSConfig checks that the certificates and key in cfg can
+// be loaded and returns the endpoint's tls configuration. current is the
+// endpoint's configuration before the update, if there is one.
+func validateEndpointTLSConfig(cfg *models.EndpointTLSConfig, current *datastore.TLSConfiguration) (*datastore.TLSConfiguration, error) {
+	if cfg == nil || *cfg == (models.EndpointTLSConfig{}) {
+		return nil, nil
+	}
+
+	if err := util.Validate(cfg); err != nil {
+		return nil, err
+	}
+
+	tlsConfig := &datastore.TLSConfiguration{
+		ClientCert: cfg.ClientCert,
+		ClientKey:  cfg.ClientKey,
+		CACert:     cfg.CACert,
+		MinVersion: cfg.MinVersion,
+	}
+
+	// the client key isn't returned by the api, so it is kept when the
+	// client certificate doesn't change
+	if util.IsStringEmpty(tlsConfig.ClientKey) && current != nil && current.ClientCert == tlsConfig.ClientCert {
+		tlsConfig.ClientKey = current.ClientKey
+	}
+
+	if _, err := net.NewTLSConfig(tlsConfig); err != nil {
+		return nil, err
+	}
+
+	return tlsConfig, nil
+}
@@ -2,16 +2,28 @@ package services
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
 	"errors"
+	"math/big"
 	"net/http"
 	"testing"
+	"time"
 
+	mcache "github.com/frain-dev/convoy/cache/memory"
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/circuitbreaker"
 	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func provideAppService(ctrl *gomock.Controller) *AppService {
@@ -19,7 +31,7 @@ func provideAppService(ctrl *gomock.Controller) *AppService {
 	eventRepo := mocks.NewMockEventRepository(ctrl)
 	eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
 	cache := mocks.NewMockCache(ctrl)
-	return NewAppService(appRepo, eventRepo, eventDeliveryRepo, cache)
+	return NewAppService(appRepo, eventRepo, eventDeliveryRepo, cache, nil)
 }
 
 func boolPtr(b bool) *bool {
@@ -702,7 +714,7 @@ func TestAppService_CreateAppEndpoint(t *testing.T) {
 					RateLimitDuration: "1m",
 					URL:               "https://google.com",
 					Description:       "test_endpoint",
-					Authentication: &datastore.EndpointAuthentication{
+					Authentication: &models.EndpointAuthentication{
 						Type: datastore.APIKeyAuthentication,
 						ApiKey: &datastore.ApiKey{
 							HeaderName:  "x-api-key",
@@ -753,6 +765,27 @@ func TestAppService_CreateAppEndpoint(t *testing.T) {
 			wantErr: false,
 		},
 
+		{
+			name: "should_error_for_missing_oauth2_credentials",
+			args: args{
+				ctx: ctx,
+				e: models.Endpoint{
+					Secret:            "1234",
+					RateLimit:         100,
+					RateLimitDuration: "1m",
+					URL:               "https://google.com",
+					Description:       "test_endpoint",
+					Authentication: &models.EndpointAuthentication{
+						Type: datastore.OAuth2Authentication,
+					},
+				},
+				app: &datastore.Application{UID: "abc"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "oauth2 field is required",
+		},
+
 		{
 			name: "should_error_for_invalid_rate_limit_duration",
 			args: args{
@@ -1180,3 +1213,261 @@ func TestAppService_DeleteAppEndpoint(t *testing.T) {
 		})
 	}
 }
+
+func TestAppService_RotateAppEndpointSecret(t *testing.T) {
+	ctx := context.Background()
+	expired := primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour))
+
+	tests := []struct {
+		name        string
+		rotate      *models.RotateEndpointSecret
+		endPointId  string
+		dbFn        func(as *AppService)
+		wantSecret  string
+		wantExpiry  time.Duration
+		wantErrCode int
+		wantErrMsg  string
+	}{
+		{
+			name:       "should_rotate_secret",
+			rotate:     &models.RotateEndpointSecret{Secret: "new-secret", Expiration: 3600},
+			endPointId: "endpoint1",
+			dbFn: func(as *AppService) {
+				a, _ := as.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().UpdateApplication(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+				c, _ := as.cache.(*mocks.MockCache)
+				c.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			},
+			wantSecret: "new-secret",
+			wantExpiry: time.Hour,
+		},
+		{
+			name:       "should_generate_secret",
+			rotate:     &models.RotateEndpointSecret{},
+			endPointId: "endpoint1",
+			dbFn: func(as *AppService) {
+				a, _ := as.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().UpdateApplication(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+				c, _ := as.cache.(*mocks.MockCache)
+				c.EXPECT().Set(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any())
+			},
+			wantExpiry: datastore.DefaultSecretExpiration,
+		},
+		{
+			name:        "should_error_for_same_secret",
+			rotate:      &models.RotateEndpointSecret{Secret: "old-secret"},
+			endPointId:  "endpoint1",
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "the new secret must be different from the current secret",
+		},
+		{
+			name:        "should_error_for_invalid_expiration",
+			rotate:      &models.RotateEndpointSecret{Expiration: -1},
+			endPointId:  "endpoint1",
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "expiration:expiration must be between 0 seconds and 30 days",
+		},
+		{
+			name:        "should_error_for_endpoint_not_found",
+			rotate:      &models.RotateEndpointSecret{},
+			endPointId:  "endpoint2",
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "endpoint not found",
+		},
+		{
+			name:       "should_fail_to_update_application",
+			rotate:     &models.RotateEndpointSecret{},
+			endPointId: "endpoint1",
+			dbFn: func(as *AppService) {
+				a, _ := as.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().UpdateApplication(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(errors.New("failed"))
+			},
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "an error occurred while rotating app endpoint secret",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			as := provideAppService(ctrl)
+
+			if tc.dbFn != nil {
+				tc.dbFn(as)
+			}
+
+			app := &datastore.Application{
+				UID: "1234",
+				Endpoints: []datastore.Endpoint{
+					{
+						UID:     "endpoint1",
+						Secret:  "old-secret",
+						Secrets: []datastore.EndpointSecret{{UID: "1", Value: "older-secret", ExpiresAt: expired}},
+					},
+				},
+			}
+
+			endpoint, err := as.RotateAppEndpointSecret(ctx, tc.rotate, tc.endPointId, app)
+			if tc.wantErrMsg != "" {
+				require.NotNil(t, err)
+				require.Equal(t, tc.wantErrCode, err.(*util.ServiceError).ErrCode())
+				require.Equal(t, tc.wantErrMsg, err.(*util.ServiceError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.NotEqual(t, "old-secret", endpoint.Secret)
+			if tc.wantSecret != "" {
+				require.Equal(t, tc.wantSecret, endpoint.Secret)
+			}
+
+			// the expired secret is pruned and the old one is kept until it expires
+			require.Len(t, endpoint.Secrets, 1)
+			require.Equal(t, "old-secret", endpoint.Secrets[0].Value)
+			require.WithinDuration(t, time.Now().Add(tc.wantExpiry), endpoint.Secrets[0].ExpiresAt.Time(), time.Minute)
+			require.Equal(t, app.Endpoints[0], *endpoint)
+		})
+	}
+}
+
+func TestValidateEndpointTLSConfig(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{SerialNumber: big.NewInt(1), NotAfter: time.Now().Add(time.Hour)}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	require.NoError(t, err)
+
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPEM := string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+
+	cfg, err := validateEndpointTLSConfig(&models.EndpointTLSConfig{ClientCert: certPEM, ClientKey: keyPEM, CACert: certPEM, MinVersion: datastore.TLSVersion12}, nil)
+	require.NoError(t, err)
+	require.Equal(t, &datastore.TLSConfiguration{ClientCert: certPEM, ClientKey: keyPEM, CACert: certPEM, MinVersion: datastore.TLSVersion12}, cfg)
+
+	// the key is kept when the certificate doesn't change
+	updated, err := validateEndpointTLSConfig(&models.EndpointTLSConfig{ClientCert: certPEM, MinVersion: datastore.TLSVersion13}, cfg)
+	require.NoError(t, err)
+	require.Equal(t, keyPEM, updated.ClientKey)
+	require.Equal(t, datastore.TLSVersion13, updated.MinVersion)
+
+	// an empty configuration removes it
+	updated, err = validateEndpointTLSConfig(&models.EndpointTLSConfig{}, cfg)
+	require.NoError(t, err)
+	require.Nil(t, updated)
+
+	_, err = validateEndpointTLSConfig(&models.EndpointTLSConfig{ClientCert: certPEM}, nil)
+	require.Error(t, err)
+
+	_, err = validateEndpointTLSConfig(&models.EndpointTLSConfig{MinVersion: "1.4"}, nil)
+	require.EqualError(t, err, "min_version:unsupported tls version")
+}
+
+func TestValidateEndpointAuthentication(t *testing.T) {
+	e := models.Endpoint{Authentication: &models.EndpointAuthentication{
+		Type:   datastore.OAuth2Authentication,
+		OAuth2: &models.EndpointOAuth2{TokenURL: "https://auth.example.com/token", ClientID: "client", ClientSecret: "secret"},
+	}}
+
+	auth, err := validateEndpointAuthentication(e, nil)
+	require.NoError(t, err)
+	require.Equal(t, "secret", auth.OAuth2.ClientSecret)
+
+	// the secret is never serialised in api responses
+	b, err := json.Marshal(auth)
+	require.NoError(t, err)
+	require.NotContains(t, string(b), "secret")
+
+	// the secret is kept when the client doesn't change
+	e.Authentication.OAuth2.ClientSecret = ""
+	updated, err := validateEndpointAuthentication(e, auth)
+	require.NoError(t, err)
+	require.Equal(t, "secret", updated.OAuth2.ClientSecret)
+
+	// a new client needs its own secret
+	e.Authentication.OAuth2.ClientID = "other-client"
+	_, err = validateEndpointAuthentication(e, auth)
+	require.EqualError(t, err, "please provide a client secret")
+
+	_, err = validateEndpointAuthentication(e, nil)
+	require.EqualError(t, err, "please provide a client secret")
+}
+
+func TestAppService_CreateAppEndpoint_EgressPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	policy, err := net.NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true})
+	require.NoError(t, err)
+
+	as := provideAppService(ctrl)
+	as.egressPolicy = policy
+
+	app := &datastore.Application{UID: "1234", GroupID: "group-1"}
+	for _, url := range []string{"http://169.254.169.254/latest/meta-data", "http://localhost:27017", "ftp://example.com"} {
+		_, err = as.CreateAppEndpoint(context.Background(), models.Endpoint{URL: url, Description: "endpoint"}, app)
+		require.NotNil(t, err)
+		require.Equal(t, http.StatusBadRequest, err.(*util.ServiceError).ErrCode())
+		require.Contains(t, err.Error(), "blocked by egress policy")
+	}
+
+	_, err = as.UpdateAppEndpoint(context.Background(), models.Endpoint{URL: "http://10.0.0.4"}, "endpoint1", app)
+	require.NotNil(t, err)
+	require.Equal(t, "blocked by egress policy: address 10.0.0.4 is in the blocked range 10.0.0.0/8", err.Error())
+
+	// the token url of the endpoint's oauth2 client is checked too
+	_, err = as.CreateAppEndpoint(context.Background(), models.Endpoint{
+		URL:         "https://93.184.216.34",
+		Description: "endpoint",
+		Authentication: &models.EndpointAuthentication{
+			Type:   datastore.OAuth2Authentication,
+			OAuth2: &models.EndpointOAuth2{TokenURL: "http://169.254.169.254/token", ClientID: "client", ClientSecret: "secret"},
+		},
+	}, app)
+	require.NotNil(t, err)
+	require.Equal(t, "token url: blocked by egress policy: address 169.254.169.254 is in the blocked range 169.254.0.0/16", err.Error())
+}
+
+func TestAppService_LoadEndpointCircuitBreakers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	as := provideAppService(ctrl)
+	as.cache = mcache.NewMemoryCache()
+	ctx := context.Background()
+
+	endpoints := []datastore.Endpoint{{UID: "endpoint-1"}, {UID: "endpoint-2"}}
+
+	// the breakers are disabled
+	err := as.LoadEndpointCircuitBreakers(ctx, &datastore.Group{Config: &datastore.GroupConfig{}}, endpoints)
+	require.NoError(t, err)
+	require.Nil(t, endpoints[0].CircuitBreaker)
+
+	cfg := &datastore.CircuitBreakerConfiguration{FailureThreshold: 1}
+	_, err = circuitbreaker.NewManager(as.cache, circuitbreaker.NewConfig(cfg)).
+		Record(ctx, circuitbreaker.Key("endpoint-1"), false)
+	require.NoError(t, err)
+
+	err = as.LoadEndpointCircuitBreakers(ctx, &datastore.Group{Config: &datastore.GroupConfig{CircuitBreaker: cfg}}, endpoints)
+	require.NoError(t, err)
+
+	require.Equal(t, "open", endpoints[0].CircuitBreaker.State)
+	require.Equal(t, uint64(1), endpoints[0].CircuitBreaker.ConsecutiveFailures)
+	require.NotNil(t, endpoints[0].CircuitBreaker.OpenedAt)
+	require.Equal(t, &datastore.CircuitBreakerStatus{State: "closed"}, endpoints[1].CircuitBreaker)
+
+	// the cache is down
+	c := mocks.NewMockCache(ctrl)
+	c.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any()).Return(errors.New("connection refused"))
+	as.cache = c
+
+	err = as.LoadEndpointCircuitBreakers(ctx, &datastore.Group{Config: &datastore.GroupConfig{CircuitBreaker: cfg}}, endpoints)
+	require.Error(t, err)
+	require.Equal(t, http.StatusInternalServerError, err.(*util.ServiceError).ErrCode())
+}
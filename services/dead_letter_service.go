@@ -0,0 +1,233 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/server/models"
+	"github.com/frain-dev/convoy/util"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrDeadLetterNotPending = errors.New("dead letter has already been redriven")
+
+const (
+	defaultRedriveRateLimit = 10
+	maxRedriveRateLimit     = 1000
+
+	// exportPageSize is how many dead letters are loaded at a time when
+	// they are exported or redriven
+	exportPageSize = 100
+)
+
+type DeadLetterService struct {
+	deadLetterRepo    datastore.DeadLetterRepository
+	eventDeliveryRepo datastore.EventDeliveryRepository
+	subRepo           datastore.SubscriptionRepository
+	queue             queue.Queuer
+}
+
+func NewDeadLetterService(deadLetterRepo datastore.DeadLetterRepository, eventDeliveryRepo datastore.EventDeliveryRepository, subRepo datastore.SubscriptionRepository, queue queue.Queuer) *DeadLetterService {
+	return &DeadLetterService{
+		deadLetterRepo:    deadLetterRepo,
+		eventDeliveryRepo: eventDeliveryRepo,
+		subRepo:           subRepo,
+		queue:             queue,
+	}
+}
+
+func (d *DeadLetterService) LoadDeadLettersPaged(ctx context.Context, filter *datastore.DeadLetterFilter, pageable datastore.Pageable) ([]datastore.DeadLetter, datastore.PaginationData, error) {
+	deadLetters, paginationData, err := d.deadLetterRepo.LoadDeadLettersPaged(ctx, filter, pageable)
+	if err != nil {
+		log.WithError(err).Error("failed to load dead letters")
+		return nil, datastore.PaginationData{}, util.NewServiceError(http.StatusInternalServerError, errors.New("an error occurred while fetching dead letters"))
+	}
+
+	return deadLetters, paginationData, nil
+}
+
+func (d *DeadLetterService) FindDeadLetterByID(ctx context.Context, groupID, id string) (*datastore.DeadLetter, error) {
+	deadLetter, err := d.deadLetterRepo.FindDeadLetterByID(ctx, groupID, id)
+	if err != nil {
+		if errors.Is(err, datastore.ErrDeadLetterNotFound) {
+			return nil, util.NewServiceError(http.StatusNotFound, err)
+		}
+
+		log.WithError(err).Error("failed to find dead letter")
+		return nil, util.NewServiceError(http.StatusInternalServerError, errors.New("failed to find dead letter"))
+	}
+
+	return deadLetter, nil
+}
+
+// ExportDeadLetters writes every dead letter matching filter to w as
+// newline delimited json, a page at a time.
+func (d *DeadLetterService) ExportDeadLetters(ctx context.Context, filter *datastore.DeadLetterFilter, w io.Writer) error {
+	enc := json.NewEncoder(w)
+	pageable := datastore.Pageable{Page: 1, PerPage: exportPageSize, Sort: -1}
+
+	for {
+		deadLetters, paginationData, err := d.deadLetterRepo.LoadDeadLettersPaged(ctx, filter, pageable)
+		if err != nil {
+			log.WithError(err).Error("failed to load dead letters")
+			return util.NewServiceError(http.StatusInternalServerError, errors.New("an error occurred while exporting dead letters"))
+		}
+
+		for i := range deadLetters {
+			err = enc.Encode(&deadLetters[i])
+			if err != nil {
+				return err
+			}
+		}
+
+		if paginationData.Next == 0 {
+			return nil
+		}
+
+		pageable.Page = int(paginationData.Next)
+	}
+}
+
+// RedriveDeadLetters requeues the event deliveries of the dead letters in
+// r, or of every pending dead letter matching filter when r has no ids. The
+// deliveries are spread out so no more than r.RateLimit are sent a second.
+func (d *DeadLetterService) RedriveDeadLetters(ctx context.Context, g *datastore.Group, r *models.RedriveDeadLetters, filter *datastore.DeadLetterFilter) (int, int, error) {
+	rateLimit := r.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultRedriveRateLimit
+	}
+
+	if rateLimit < 0 || rateLimit > maxRedriveRateLimit {
+		return 0, 0, util.NewServiceError(http.StatusBadRequest, fmt.Errorf("rate limit must be between 1 and %d", maxRedriveRateLimit))
+	}
+
+	if len(r.IDs) > 0 {
+		deadLetters, err := d.deadLetterRepo.FindDeadLettersByIDs(ctx, g.UID, r.IDs)
+		if err != nil {
+			log.WithError(err).Error("failed to fetch dead letters")
+			return 0, 0, util.NewServiceError(http.StatusInternalServerError, errors.New("failed to fetch dead letters"))
+		}
+
+		redriven, failures := d.redriveDeadLetters(ctx, g, deadLetters, rateLimit, 0)
+		return redriven, failures, nil
+	}
+
+	filter.GroupID = g.UID
+	filter.Status = []datastore.DeadLetterStatus{datastore.PendingDeadLetterStatus}
+
+	// redriven dead letters are no longer pending, so they drop out of the
+	// pages while the ones that failed stay in them. The dead letters that
+	// are left to redrive come right after the failed ones.
+	redriven, failures := 0, 0
+	for {
+		pageable := datastore.Pageable{Page: failures/exportPageSize + 1, PerPage: exportPageSize, Sort: -1}
+		deadLetters, paginationData, err := d.deadLetterRepo.LoadDeadLettersPaged(ctx, filter, pageable)
+		if err != nil {
+			log.WithError(err).Error("failed to fetch dead letters")
+			return redriven, failures, util.NewServiceError(http.StatusInternalServerError, errors.New("failed to fetch dead letters"))
+		}
+
+		if skip := failures % exportPageSize; skip < len(deadLetters) {
+			n, f := d.redriveDeadLetters(ctx, g, deadLetters[skip:], rateLimit, redriven)
+			redriven, failures = redriven+n, failures+f
+		}
+
+		if paginationData.Next == 0 {
+			return redriven, failures, nil
+		}
+	}
+}
+
+// redriveDeadLetters redrives deadLetters and returns how many were
+// redriven and how many failed. sent is how many deliveries the redrive
+// already requeued, the new ones are delayed behind them.
+func (d *DeadLetterService) redriveDeadLetters(ctx context.Context, g *datastore.Group, deadLetters []datastore.DeadLetter, rateLimit, sent int) (int, int) {
+	failures := 0
+	redriven := make([]string, 0, len(deadLetters))
+	for i := range deadLetters {
+		delay := time.Second + time.Duration(sent+len(redriven))*time.Second/time.Duration(rateLimit)
+
+		err := d.redriveDeadLetter(ctx, &deadLetters[i], g, delay)
+		if err != nil {
+			failures++
+			log.WithError(err).Errorf("failed to redrive dead letter %s", deadLetters[i].UID)
+			continue
+		}
+
+		redriven = append(redriven, deadLetters[i].UID)
+	}
+
+	if len(redriven) > 0 {
+		err := d.deadLetterRepo.UpdateStatusOfDeadLetters(ctx, redriven, datastore.RedrivenDeadLetterStatus)
+		if err != nil {
+			log.WithError(err).Error("failed to update status of redriven dead letters")
+		}
+
+		metrics.DeadLettersRedriven().WithLabelValues(g.UID).Add(float64(len(redriven)))
+	}
+
+	return len(redriven), failures
+}
+
+func (d *DeadLetterService) redriveDeadLetter(ctx context.Context, deadLetter *datastore.DeadLetter, g *datastore.Group, delay time.Duration) error {
+	if deadLetter.Status != datastore.PendingDeadLetterStatus {
+		return ErrDeadLetterNotPending
+	}
+
+	eventDelivery, err := d.eventDeliveryRepo.FindEventDeliveryByID(ctx, deadLetter.EventDeliveryID)
+	if err != nil {
+		return err
+	}
+
+	if eventDelivery.Status != datastore.FailureEventStatus {
+		return errors.New("event delivery has been retried since it was dead lettered")
+	}
+
+	sub, err := d.subRepo.FindSubscriptionByID(ctx, g.UID, eventDelivery.SubscriptionID)
+	if err != nil {
+		return ErrSubscriptionNotFound
+	}
+
+	if sub.Status == datastore.PendingSubscriptionStatus {
+		return errors.New("subscription is being re-activated")
+	}
+
+	if sub.Status == datastore.InactiveSubscriptionStatus {
+		err = d.subRepo.UpdateSubscriptionStatus(ctx, g.UID, sub.UID, datastore.PendingSubscriptionStatus)
+		if err != nil {
+			return errors.New("failed to update subscription status")
+		}
+	}
+
+	err = clearPassedExpiry(ctx, d.eventDeliveryRepo, eventDelivery)
+	if err != nil {
+		return err
+	}
+
+	err = d.eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, *eventDelivery, datastore.ScheduledEventStatus)
+	if err != nil {
+		return errors.New("an error occurred while trying to redrive event delivery")
+	}
+
+	job := &queue.Job{
+		ID:      eventDelivery.UID,
+		Payload: json.RawMessage(eventDelivery.UID),
+		Delay:   delay,
+	}
+
+	err = d.queue.Write(convoy.EventProcessor, convoy.EventQueue, job)
+	if err != nil {
+		return fmt.Errorf("error occurred re-enqueing dead letter - %s: %v", deadLetter.UID, err)
+	}
+
+	return nil
+}
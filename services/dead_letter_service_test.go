@@ -0,0 +1,311 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/server/models"
+	"github.com/frain-dev/convoy/util"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+)
+
+func provideDeadLetterService(ctrl *gomock.Controller) *DeadLetterService {
+	deadLetterRepo := mocks.NewMockDeadLetterRepository(ctrl)
+	eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	queue := mocks.NewMockQueuer(ctrl)
+	return NewDeadLetterService(deadLetterRepo, eventDeliveryRepo, subRepo, queue)
+}
+
+func TestDeadLetterService_FindDeadLetterByID(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ds := provideDeadLetterService(ctrl)
+
+	dl, _ := ds.deadLetterRepo.(*mocks.MockDeadLetterRepository)
+	dl.EXPECT().FindDeadLetterByID(gomock.Any(), "group-1", "dl-1").
+		Times(1).Return(nil, datastore.ErrDeadLetterNotFound)
+
+	_, err := ds.FindDeadLetterByID(context.Background(), "group-1", "dl-1")
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusNotFound, err.(*util.ServiceError).ErrCode())
+}
+
+func TestDeadLetterService_ExportDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	ds := provideDeadLetterService(ctrl)
+
+	filter := &datastore.DeadLetterFilter{GroupID: "group-1"}
+
+	dl, _ := ds.deadLetterRepo.(*mocks.MockDeadLetterRepository)
+	dl.EXPECT().LoadDeadLettersPaged(gomock.Any(), filter, datastore.Pageable{Page: 1, PerPage: exportPageSize, Sort: -1}).
+		Times(1).Return([]datastore.DeadLetter{{UID: "dl-1"}, {UID: "dl-2"}}, datastore.PaginationData{Page: 1, Next: 2}, nil)
+	dl.EXPECT().LoadDeadLettersPaged(gomock.Any(), filter, datastore.Pageable{Page: 2, PerPage: exportPageSize, Sort: -1}).
+		Times(1).Return([]datastore.DeadLetter{{UID: "dl-3"}}, datastore.PaginationData{Page: 2, Next: 0}, nil)
+
+	var buf bytes.Buffer
+	err := ds.ExportDeadLetters(context.Background(), filter, &buf)
+	require.Nil(t, err)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	for i, uid := range []string{"dl-1", "dl-2", "dl-3"} {
+		var d datastore.DeadLetter
+		require.NoError(t, json.Unmarshal([]byte(lines[i]), &d))
+		require.Equal(t, uid, d.UID)
+	}
+}
+
+func TestDeadLetterService_RedriveDeadLetters(t *testing.T) {
+	ctx := context.Background()
+	type args struct {
+		ctx     context.Context
+		g       *datastore.Group
+		redrive *models.RedriveDeadLetters
+		filter  *datastore.DeadLetterFilter
+	}
+	tests := []struct {
+		name          string
+		args          args
+		dbFn          func(ds *DeadLetterService)
+		wantSuccesses int
+		wantFailures  int
+		wantErr       bool
+		wantErrCode   int
+		wantErrMsg    string
+	}{
+		{
+			name: "should_redrive_selected_dead_letters",
+			args: args{
+				ctx:     ctx,
+				g:       &datastore.Group{UID: "group-1"},
+				redrive: &models.RedriveDeadLetters{IDs: []string{"dl-1", "dl-2"}, RateLimit: 2},
+				filter:  &datastore.DeadLetterFilter{},
+			},
+			dbFn: func(ds *DeadLetterService) {
+				dl, _ := ds.deadLetterRepo.(*mocks.MockDeadLetterRepository)
+				dl.EXPECT().FindDeadLettersByIDs(gomock.Any(), "group-1", []string{"dl-1", "dl-2"}).
+					Times(1).
+					Return([]datastore.DeadLetter{
+						{UID: "dl-1", EventDeliveryID: "ed-1", Status: datastore.PendingDeadLetterStatus},
+						{UID: "dl-2", EventDeliveryID: "ed-2", Status: datastore.RedrivenDeadLetterStatus},
+					}, nil)
+
+				ed, _ := ds.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), "ed-1").
+					Times(1).Return(&datastore.EventDelivery{UID: "ed-1", SubscriptionID: "sub-1", Status: datastore.FailureEventStatus}, nil)
+
+				s, _ := ds.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "group-1", "sub-1").
+					Times(1).Return(&datastore.Subscription{UID: "sub-1", Status: datastore.InactiveSubscriptionStatus}, nil)
+				s.EXPECT().UpdateSubscriptionStatus(gomock.Any(), "group-1", "sub-1", datastore.PendingSubscriptionStatus).
+					Times(1).Return(nil)
+
+				ed.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ScheduledEventStatus).
+					Times(1).Return(nil)
+
+				q, _ := ds.queue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).
+					Times(1).Return(nil)
+
+				dl.EXPECT().UpdateStatusOfDeadLetters(gomock.Any(), []string{"dl-1"}, datastore.RedrivenDeadLetterStatus).
+					Times(1).Return(nil)
+			},
+			wantSuccesses: 1,
+			wantFailures:  1,
+		},
+		{
+			name: "should_redrive_all_pending_dead_letters_at_rate_limit",
+			args: args{
+				ctx:     ctx,
+				g:       &datastore.Group{UID: "group-1"},
+				redrive: &models.RedriveDeadLetters{RateLimit: 2},
+				filter:  &datastore.DeadLetterFilter{AppID: "app-1"},
+			},
+			dbFn: func(ds *DeadLetterService) {
+				dl, _ := ds.deadLetterRepo.(*mocks.MockDeadLetterRepository)
+				dl.EXPECT().LoadDeadLettersPaged(gomock.Any(), &datastore.DeadLetterFilter{
+					GroupID: "group-1",
+					AppID:   "app-1",
+					Status:  []datastore.DeadLetterStatus{datastore.PendingDeadLetterStatus},
+				}, datastore.Pageable{Page: 1, PerPage: exportPageSize, Sort: -1}).
+					Times(1).
+					Return([]datastore.DeadLetter{
+						{UID: "dl-1", EventDeliveryID: "ed-1", Status: datastore.PendingDeadLetterStatus},
+						{UID: "dl-2", EventDeliveryID: "ed-2", Status: datastore.PendingDeadLetterStatus},
+						{UID: "dl-3", EventDeliveryID: "ed-3", Status: datastore.PendingDeadLetterStatus},
+					}, datastore.PaginationData{}, nil)
+
+				ed, _ := ds.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Times(3).
+					DoAndReturn(func(_ context.Context, id string) (*datastore.EventDelivery, error) {
+						return &datastore.EventDelivery{UID: id, SubscriptionID: "sub-1", Status: datastore.FailureEventStatus}, nil
+					})
+
+				s, _ := ds.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "group-1", "sub-1").
+					Times(3).Return(&datastore.Subscription{UID: "sub-1", Status: datastore.ActiveSubscriptionStatus}, nil)
+
+				ed.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ScheduledEventStatus).
+					Times(3).Return(nil)
+
+				// two deliveries a second, so each is half a second behind the last
+				q, _ := ds.queue.(*mocks.MockQueuer)
+				delays := []int64{1000, 1500, 2000}
+				n := 0
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).
+					Times(3).
+					DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+						if job.Delay.Milliseconds() != delays[n] {
+							return errors.New("unexpected delay")
+						}
+						n++
+						return nil
+					})
+
+				dl.EXPECT().UpdateStatusOfDeadLetters(gomock.Any(), []string{"dl-1", "dl-2", "dl-3"}, datastore.RedrivenDeadLetterStatus).
+					Times(1).Return(nil)
+			},
+			wantSuccesses: 3,
+			wantFailures:  0,
+		},
+		{
+			name: "should_page_through_pending_dead_letters",
+			args: args{
+				ctx:     ctx,
+				g:       &datastore.Group{UID: "group-1"},
+				redrive: &models.RedriveDeadLetters{RateLimit: 1},
+				filter:  &datastore.DeadLetterFilter{},
+			},
+			dbFn: func(ds *DeadLetterService) {
+				filter := &datastore.DeadLetterFilter{
+					GroupID: "group-1",
+					Status:  []datastore.DeadLetterStatus{datastore.PendingDeadLetterStatus},
+				}
+				pageable := datastore.Pageable{Page: 1, PerPage: exportPageSize, Sort: -1}
+
+				// dl-2's delivery was retried, so it stays pending and is
+				// skipped on the next page, which starts after it
+				dl, _ := ds.deadLetterRepo.(*mocks.MockDeadLetterRepository)
+				gomock.InOrder(
+					dl.EXPECT().LoadDeadLettersPaged(gomock.Any(), filter, pageable).
+						Times(1).
+						Return([]datastore.DeadLetter{
+							{UID: "dl-1", EventDeliveryID: "ed-1", Status: datastore.PendingDeadLetterStatus},
+							{UID: "dl-2", EventDeliveryID: "ed-2", Status: datastore.PendingDeadLetterStatus},
+						}, datastore.PaginationData{Page: 1, Next: 2}, nil),
+					dl.EXPECT().LoadDeadLettersPaged(gomock.Any(), filter, pageable).
+						Times(1).
+						Return([]datastore.DeadLetter{
+							{UID: "dl-2", EventDeliveryID: "ed-2", Status: datastore.PendingDeadLetterStatus},
+							{UID: "dl-3", EventDeliveryID: "ed-3", Status: datastore.PendingDeadLetterStatus},
+						}, datastore.PaginationData{Page: 1}, nil),
+				)
+
+				ed, _ := ds.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Times(3).
+					DoAndReturn(func(_ context.Context, id string) (*datastore.EventDelivery, error) {
+						status := datastore.FailureEventStatus
+						if id == "ed-2" {
+							status = datastore.RetryEventStatus
+						}
+						return &datastore.EventDelivery{UID: id, SubscriptionID: "sub-1", Status: status}, nil
+					})
+
+				s, _ := ds.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), "group-1", "sub-1").
+					Times(2).Return(&datastore.Subscription{UID: "sub-1", Status: datastore.ActiveSubscriptionStatus}, nil)
+
+				ed.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ScheduledEventStatus).
+					Times(2).Return(nil)
+
+				// the second page's delivery is delayed behind the first's
+				q, _ := ds.queue.(*mocks.MockQueuer)
+				delays := []int64{1000, 2000}
+				n := 0
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).
+					Times(2).
+					DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+						if job.Delay.Milliseconds() != delays[n] {
+							return errors.New("unexpected delay")
+						}
+						n++
+						return nil
+					})
+
+				dl.EXPECT().UpdateStatusOfDeadLetters(gomock.Any(), []string{"dl-1"}, datastore.RedrivenDeadLetterStatus).
+					Times(1).Return(nil)
+				dl.EXPECT().UpdateStatusOfDeadLetters(gomock.Any(), []string{"dl-3"}, datastore.RedrivenDeadLetterStatus).
+					Times(1).Return(nil)
+			},
+			wantSuccesses: 2,
+			wantFailures:  1,
+		},
+		{
+			name: "should_error_for_invalid_rate_limit",
+			args: args{
+				ctx:     ctx,
+				g:       &datastore.Group{UID: "group-1"},
+				redrive: &models.RedriveDeadLetters{RateLimit: maxRedriveRateLimit + 1},
+				filter:  &datastore.DeadLetterFilter{},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "rate limit must be between 1 and 1000",
+		},
+		{
+			name: "should_error_for_failed_fetch",
+			args: args{
+				ctx:     ctx,
+				g:       &datastore.Group{UID: "group-1"},
+				redrive: &models.RedriveDeadLetters{IDs: []string{"dl-1"}},
+				filter:  &datastore.DeadLetterFilter{},
+			},
+			dbFn: func(ds *DeadLetterService) {
+				dl, _ := ds.deadLetterRepo.(*mocks.MockDeadLetterRepository)
+				dl.EXPECT().FindDeadLettersByIDs(gomock.Any(), "group-1", []string{"dl-1"}).
+					Times(1).Return(nil, errors.New("failed"))
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusInternalServerError,
+			wantErrMsg:  "failed to fetch dead letters",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			ds := provideDeadLetterService(ctrl)
+
+			if tc.dbFn != nil {
+				tc.dbFn(ds)
+			}
+
+			successes, failures, err := ds.RedriveDeadLetters(tc.args.ctx, tc.args.g, tc.args.redrive, tc.args.filter)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tc.wantErrCode, err.(*util.ServiceError).ErrCode())
+				require.Equal(t, tc.wantErrMsg, err.(*util.ServiceError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.wantSuccesses, successes)
+			require.Equal(t, tc.wantFailures, failures)
+		})
+	}
+}
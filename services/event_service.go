@@ -11,6 +11,7 @@ import (
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/idempotency"
 	"github.com/frain-dev/convoy/internal/pkg/searcher"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/queue"
@@ -23,6 +24,11 @@ import (
 
 var ErrInvalidEventDeliveryStatus = errors.New("only successful events can be force resent")
 
+const (
+	defaultBroadcastRateLimit = 100
+	maxBroadcastRateLimit     = 1000
+)
+
 type EventService struct {
 	appRepo           datastore.ApplicationRepository
 	sourceRepo        datastore.SourceRepository
@@ -50,10 +56,24 @@ func (e *EventService) CreateAppEvent(ctx context.Context, newMessage *models.Ev
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
 	}
 
+	deliverAt, err := getDeliverAt(newMessage, time.Now())
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	ttl, err := durationSeconds(newMessage.TTL)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	idempotencyKey := newMessage.ProviderID
+	window := idempotency.Window(g)
+	dedup := !util.IsStringEmpty(idempotencyKey) && window > 0
+
 	var app *datastore.Application
 	appCacheKey := convoy.ApplicationsCacheKey.Get(newMessage.AppID).String()
 
-	err := e.cache.Get(ctx, appCacheKey, &app)
+	err = e.cache.Get(ctx, appCacheKey, &app)
 	if err != nil {
 		return nil, err
 	}
@@ -87,8 +107,10 @@ func (e *EventService) CreateAppEvent(ctx context.Context, newMessage *models.Ev
 	event := &datastore.Event{
 		UID:            uuid.New().String(),
 		EventType:      datastore.EventType(newMessage.EventType),
+		ProviderID:     idempotencyKey,
 		Data:           newMessage.Data,
 		Headers:        e.getCustomHeaders(newMessage),
+		TTL:            ttl,
 		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
 		UpdatedAt:      primitive.NewDateTimeFromTime(time.Now()),
 		AppID:          app.UID,
@@ -96,8 +118,11 @@ func (e *EventService) CreateAppEvent(ctx context.Context, newMessage *models.Ev
 		DocumentStatus: datastore.ActiveDocumentStatus,
 	}
 
-	if (g.Config == nil || g.Config.Strategy == nil) ||
-		(g.Config.Strategy != nil && g.Config.Strategy.Type != datastore.LinearStrategyProvider && g.Config.Strategy.Type != datastore.ExponentialStrategyProvider) {
+	if !deliverAt.IsZero() {
+		event.DeliverAt = primitive.NewDateTimeFromTime(deliverAt)
+	}
+
+	if !hasRetryStrategy(g) {
 		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("retry strategy not defined in configuration"))
 	}
 
@@ -109,6 +134,25 @@ func (e *EventService) CreateAppEvent(ctx context.Context, newMessage *models.Ev
 
 	payload := json.RawMessage(eventByte)
 
+	// the key is reserved before the event is created, so concurrent
+	// requests with it don't both create the event
+	store := idempotency.NewStore(e.cache)
+	if dedup {
+		original, err := store.Reserve(ctx, g.UID, app.UID, idempotencyKey, window)
+		if errors.Is(err, idempotency.ErrInProgress) {
+			return nil, util.NewServiceError(http.StatusConflict, err)
+		}
+
+		if err != nil {
+			log.WithError(err).Error("failed to reserve idempotency key")
+			dedup = false
+		}
+
+		if original != nil {
+			return original, nil
+		}
+	}
+
 	job := &queue.Job{
 		ID:      event.UID,
 		Payload: payload,
@@ -117,13 +161,121 @@ func (e *EventService) CreateAppEvent(ctx context.Context, newMessage *models.Ev
 	err = e.queue.Write(taskName, convoy.CreateEventQueue, job)
 	if err != nil {
 		log.Errorf("Error occurred sending new event to the queue %s", err)
+
+		if dedup {
+			err = store.Release(ctx, g.UID, app.UID, idempotencyKey)
+			if err != nil {
+				log.WithError(err).Error("failed to release idempotency key")
+			}
+		}
+
+		return event, nil
+	}
+
+	if dedup {
+		err = store.Save(ctx, app.UID, idempotencyKey, event, window)
+		if err != nil {
+			log.WithError(err).Error("failed to save event idempotency key")
+		}
+	}
+
+	return event, nil
+}
+
+// BroadcastEvent creates an event that is sent to every application in the
+// group with an active subscription to its event type. The worker fans it
+// out on the broadcast queue, no more than newMessage.RateLimit deliveries
+// are sent a second.
+func (e *EventService) BroadcastEvent(ctx context.Context, newMessage *models.BroadcastEvent, g *datastore.Group) (*datastore.Event, error) {
+	if g == nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("an error occurred while creating event - invalid group"))
+	}
+
+	if g.Type != datastore.OutgoingGroup {
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("events can only be broadcast in outgoing groups"))
+	}
+
+	if err := util.Validate(newMessage); err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	rateLimit := newMessage.RateLimit
+	if rateLimit == 0 {
+		rateLimit = defaultBroadcastRateLimit
+	}
+
+	if rateLimit < 0 || rateLimit > maxBroadcastRateLimit {
+		return nil, util.NewServiceError(http.StatusBadRequest, fmt.Errorf("rate limit must be between 1 and %d", maxBroadcastRateLimit))
+	}
+
+	msg := &models.Event{
+		EventType:     newMessage.EventType,
+		Data:          newMessage.Data,
+		CustomHeaders: newMessage.CustomHeaders,
+		DeliverAt:     newMessage.DeliverAt,
+		Delay:         newMessage.Delay,
+	}
+
+	deliverAt, err := getDeliverAt(msg, time.Now())
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	ttl, err := durationSeconds(newMessage.TTL)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	if !hasRetryStrategy(g) {
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("retry strategy not defined in configuration"))
+	}
+
+	event := &datastore.Event{
+		UID:            uuid.New().String(),
+		EventType:      datastore.EventType(newMessage.EventType),
+		Data:           newMessage.Data,
+		Headers:        e.getCustomHeaders(msg),
+		TTL:            ttl,
+		Broadcast:      &datastore.BroadcastConfiguration{RateLimit: rateLimit},
+		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		UpdatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+		GroupID:        g.UID,
+		DocumentStatus: datastore.ActiveDocumentStatus,
+	}
+
+	if !deliverAt.IsZero() {
+		event.DeliverAt = primitive.NewDateTimeFromTime(deliverAt)
+	}
+
+	eventByte, err := json.Marshal(event)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	job := &queue.Job{
+		ID:      event.UID,
+		Payload: json.RawMessage(eventByte),
+		Delay:   0,
+	}
+	err = e.queue.Write(convoy.BroadcastProcessor, convoy.BroadcastQueue, job)
+	if err != nil {
+		log.WithError(err).Error("broadcast_event: failed to write event to the queue")
+		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("failed to write event to queue"))
 	}
 
 	return event, nil
 }
 
 func (e *EventService) ReplayAppEvent(ctx context.Context, event *datastore.Event, g *datastore.Group) error {
-	taskName := convoy.CreateEventProcessor
+	taskName, queueName := convoy.CreateEventProcessor, convoy.CreateEventQueue
+	if event.Broadcast != nil {
+		taskName, queueName = convoy.BroadcastProcessor, convoy.BroadcastQueue
+
+		// the broadcast's delivery ids are derived from UpdatedAt, the
+		// replay gets new deliveries
+		event.UpdatedAt = primitive.NewDateTimeFromTime(time.Now())
+	}
+
 	eventByte, err := json.Marshal(event)
 	if err != nil {
 		return util.NewServiceError(http.StatusBadRequest, err)
@@ -135,7 +287,7 @@ func (e *EventService) ReplayAppEvent(ctx context.Context, event *datastore.Even
 		Payload: payload,
 		Delay:   0,
 	}
-	err = e.queue.Write(taskName, convoy.CreateEventQueue, job)
+	err = e.queue.Write(taskName, queueName, job)
 	if err != nil {
 		log.WithError(err).Error("replay_event: failed to write event to the queue")
 		return util.NewServiceError(http.StatusBadRequest, errors.New("failed to write event to queue"))
@@ -144,6 +296,37 @@ func (e *EventService) ReplayAppEvent(ctx context.Context, event *datastore.Even
 	return nil
 }
 
+// CancelAppEvent cancels the event's scheduled deliveries that haven't been
+// attempted yet, it returns how many were cancelled.
+func (e *EventService) CancelAppEvent(ctx context.Context, event *datastore.Event) (int, error) {
+	deliveries, err := e.eventDeliveryRepo.FindEventDeliveriesByEventID(ctx, event.UID)
+	if err != nil {
+		log.WithError(err).Error("failed to fetch event deliveries")
+		return 0, util.NewServiceError(http.StatusInternalServerError, errors.New("an error occurred while fetching event deliveries"))
+	}
+
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		if d.Status == datastore.ScheduledEventStatus && (d.Metadata == nil || d.Metadata.NumTrials == 0) {
+			ids = append(ids, d.UID)
+		}
+	}
+
+	if len(ids) == 0 {
+		return 0, util.NewServiceError(http.StatusBadRequest, errors.New("event has no scheduled deliveries to cancel"))
+	}
+
+	// the deliveries' tasks are left in the queue, they do nothing once
+	// the deliveries are cancelled
+	err = e.eventDeliveryRepo.UpdateStatusOfEventDeliveries(ctx, ids, datastore.CancelledEventStatus)
+	if err != nil {
+		log.WithError(err).Error("failed to cancel event deliveries")
+		return 0, util.NewServiceError(http.StatusInternalServerError, errors.New("an error occurred while cancelling event deliveries"))
+	}
+
+	return len(ids), nil
+}
+
 func (e *EventService) GetAppEvent(ctx context.Context, id string) (*datastore.Event, error) {
 	event, err := e.eventRepo.FindEventByID(ctx, id)
 	if err != nil {
@@ -258,12 +441,17 @@ func (e *EventService) GetEventsPaged(ctx context.Context, filter *datastore.Fil
 
 	for i, event := range events {
 		if _, ok := appMap[event.AppID]; !ok {
-			a, _ := e.appRepo.FindApplicationByID(ctx, event.AppID)
-			aa := &datastore.Application{
-				UID:          a.UID,
-				Title:        a.Title,
-				GroupID:      a.GroupID,
-				SupportEmail: a.SupportEmail,
+			// broadcast events don't belong to an app, they are left
+			// without app metadata
+			var aa *datastore.Application
+			a, err := e.appRepo.FindApplicationByID(ctx, event.AppID)
+			if err == nil {
+				aa = &datastore.Application{
+					UID:          a.UID,
+					Title:        a.Title,
+					GroupID:      a.GroupID,
+					SupportEmail: a.SupportEmail,
+				}
 			}
 			appMap[event.AppID] = aa
 		}
@@ -287,7 +475,16 @@ func (e *EventService) GetEventsPaged(ctx context.Context, filter *datastore.Fil
 }
 
 func (e *EventService) GetEventDeliveriesPaged(ctx context.Context, filter *datastore.Filter) ([]datastore.EventDelivery, datastore.PaginationData, error) {
-	deliveries, paginationData, err := e.eventDeliveryRepo.LoadEventDeliveriesPaged(ctx, filter.Group.UID, filter.AppID, filter.EventID, filter.Status, filter.SearchParams, filter.Pageable)
+	var deliveries []datastore.EventDelivery
+	var paginationData datastore.PaginationData
+	var err error
+
+	if filter.Upcoming {
+		deliveries, paginationData, err = e.eventDeliveryRepo.LoadUpcomingEventDeliveriesPaged(ctx, filter.Group.UID, filter.AppID, filter.Pageable)
+	} else {
+		deliveries, paginationData, err = e.eventDeliveryRepo.LoadEventDeliveriesPaged(ctx, filter.Group.UID, filter.AppID, filter.EventID, filter.Status, filter.SearchParams, filter.Pageable)
+	}
+
 	if err != nil {
 		log.WithError(err).Error("failed to fetch event deliveries")
 		return nil, datastore.PaginationData{}, util.NewServiceError(http.StatusInternalServerError, errors.New("an error occurred while fetching event deliveries"))
@@ -397,6 +594,11 @@ func (e *EventService) RetryEventDelivery(ctx context.Context, eventDelivery *da
 		}
 	}
 
+	err = clearPassedExpiry(ctx, e.eventDeliveryRepo, eventDelivery)
+	if err != nil {
+		return err
+	}
+
 	return e.requeueEventDelivery(ctx, eventDelivery, g)
 }
 
@@ -444,6 +646,67 @@ func (e *EventService) requeueEventDelivery(ctx context.Context, eventDelivery *
 	return nil
 }
 
+// clearPassedExpiry removes the expiry of a delivery whose ttl has passed, so
+// it is sent when it is retried by hand.
+func clearPassedExpiry(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, eventDelivery *datastore.EventDelivery) error {
+	if eventDelivery.ExpiresAt == 0 || time.Now().Before(eventDelivery.ExpiresAt.Time()) {
+		return nil
+	}
+
+	err := eventDeliveryRepo.UpdateExpiryOfEventDelivery(ctx, *eventDelivery, 0)
+	if err != nil {
+		log.WithError(err).Error("failed to clear event delivery expiry")
+		return errors.New("failed to clear event delivery expiry")
+	}
+
+	eventDelivery.ExpiresAt = 0
+	return nil
+}
+
+// getDeliverAt returns when the event's deliveries are sent, it is zero when
+// they are sent right away.
+func getDeliverAt(event *models.Event, now time.Time) (time.Time, error) {
+	if event.DeliverAt != nil && !util.IsStringEmpty(event.Delay) {
+		return time.Time{}, errors.New("only one of deliver_at and delay can be set")
+	}
+
+	if event.DeliverAt != nil {
+		if !event.DeliverAt.After(now) {
+			return time.Time{}, errors.New("deliver_at must be in the future")
+		}
+
+		return *event.DeliverAt, nil
+	}
+
+	if util.IsStringEmpty(event.Delay) {
+		return time.Time{}, nil
+	}
+
+	delay, err := time.ParseDuration(event.Delay)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid delay %q, use a duration like 30m or 72h", event.Delay)
+	}
+
+	if delay <= 0 {
+		return time.Time{}, errors.New("delay must be positive")
+	}
+
+	return now.Add(delay), nil
+}
+
+func hasRetryStrategy(g *datastore.Group) bool {
+	if g.Config == nil || g.Config.Strategy == nil {
+		return false
+	}
+
+	switch g.Config.Strategy.Type {
+	case datastore.LinearStrategyProvider, datastore.ExponentialStrategyProvider, datastore.ScheduleStrategyProvider:
+		return true
+	}
+
+	return false
+}
+
 func (e *EventService) getCustomHeaders(event *models.Event) httpheader.HTTPHeader {
 	var headers map[string][]string
 
@@ -3,18 +3,22 @@ package services
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/mocks"
 	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 func provideEventService(ctrl *gomock.Controller) *EventService {
@@ -393,6 +397,225 @@ func TestEventService_CreateAppEvent(t *testing.T) {
 			wantErrMsg:  "app has no configured endpoints",
 		},
 
+		{
+			name: "should_create_event_with_idempotency_key",
+			dbFn: func(es *EventService) {
+				c, _ := es.cache.(*mocks.MockCache)
+				c.EXPECT().Get(gomock.Any(), "applications:123", gomock.Any())
+				c.EXPECT().Set(gomock.Any(), "applications:123", gomock.Any(), gomock.Any())
+				c.EXPECT().SetNX(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any(), 24*time.Hour).
+					Times(1).Return(true, nil)
+				c.EXPECT().Set(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any(), 24*time.Hour).Times(1)
+
+				a, _ := es.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "123").
+					Times(1).Return(&datastore.Application{
+					Title:     "test_app",
+					UID:       "123",
+					GroupID:   "abc",
+					Endpoints: []datastore.Endpoint{{UID: "ref"}},
+				}, nil)
+
+				eq, _ := es.queue.(*mocks.MockQueuer)
+				eq.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).
+					Times(1).Return(nil)
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.Event{
+					AppID:      "123",
+					EventType:  "payment.created",
+					ProviderID: "key-1",
+					Data:       bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Group{
+					UID:  "abc",
+					Name: "test_group",
+					Config: &datastore.GroupConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       "linear",
+							Duration:   1000,
+							RetryCount: 10,
+						},
+						Signature: &datastore.SignatureConfiguration{},
+					},
+				},
+			},
+			wantEvent: &datastore.Event{
+				EventType:      datastore.EventType("payment.created"),
+				ProviderID:     "key-1",
+				Data:           bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				AppID:          "123",
+				GroupID:        "abc",
+				DocumentStatus: datastore.ActiveDocumentStatus,
+			},
+		},
+		{
+			name: "should_return_original_event_for_repeated_idempotency_key",
+			dbFn: func(es *EventService) {
+				c, _ := es.cache.(*mocks.MockCache)
+				c.EXPECT().Get(gomock.Any(), "applications:123", gomock.Any())
+				c.EXPECT().Set(gomock.Any(), "applications:123", gomock.Any(), gomock.Any())
+
+				a, _ := es.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "123").
+					Times(1).Return(&datastore.Application{
+					Title:     "test_app",
+					UID:       "123",
+					GroupID:   "abc",
+					Endpoints: []datastore.Endpoint{{UID: "ref"}},
+				}, nil)
+				c.EXPECT().SetNX(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any(), 24*time.Hour).
+					Times(1).Return(false, nil)
+				c.EXPECT().Get(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, data interface{}) error {
+						*data.(**datastore.Event) = &datastore.Event{
+							UID:            "event-1",
+							EventType:      datastore.EventType("payment.created"),
+							ProviderID:     "key-1",
+							Data:           bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+							AppID:          "123",
+							GroupID:        "abc",
+							CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+							UpdatedAt:      primitive.NewDateTimeFromTime(time.Now()),
+							DocumentStatus: datastore.ActiveDocumentStatus,
+						}
+						return nil
+					})
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.Event{
+					AppID:      "123",
+					EventType:  "payment.created",
+					ProviderID: "key-1",
+					Data:       bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Group{
+					UID:  "abc",
+					Name: "test_group",
+					Config: &datastore.GroupConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       "linear",
+							Duration:   1000,
+							RetryCount: 10,
+						},
+						Signature: &datastore.SignatureConfiguration{},
+					},
+				},
+			},
+			wantEvent: &datastore.Event{
+				EventType:      datastore.EventType("payment.created"),
+				ProviderID:     "key-1",
+				Data:           bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				AppID:          "123",
+				GroupID:        "abc",
+				DocumentStatus: datastore.ActiveDocumentStatus,
+			},
+		},
+		{
+			name: "should_fail_for_idempotency_key_in_use",
+			dbFn: func(es *EventService) {
+				c, _ := es.cache.(*mocks.MockCache)
+				c.EXPECT().Get(gomock.Any(), "applications:123", gomock.Any())
+				c.EXPECT().Set(gomock.Any(), "applications:123", gomock.Any(), gomock.Any())
+
+				a, _ := es.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "123").
+					Times(1).Return(&datastore.Application{
+					Title:     "test_app",
+					UID:       "123",
+					GroupID:   "abc",
+					Endpoints: []datastore.Endpoint{{UID: "ref"}},
+				}, nil)
+				c.EXPECT().SetNX(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any(), 24*time.Hour).
+					Times(1).Return(false, nil)
+
+				// the event is still being created
+				c.EXPECT().Get(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any()).
+					DoAndReturn(func(_ context.Context, _ string, data interface{}) error {
+						*data.(**datastore.Event) = &datastore.Event{}
+						return nil
+					})
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.Event{
+					AppID:      "123",
+					EventType:  "payment.created",
+					ProviderID: "key-1",
+					Data:       bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Group{
+					UID:  "abc",
+					Name: "test_group",
+					Config: &datastore.GroupConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       "linear",
+							Duration:   1000,
+							RetryCount: 10,
+						},
+						Signature: &datastore.SignatureConfiguration{},
+					},
+				},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusConflict,
+			wantErrMsg:  "an event with this idempotency key is being created",
+		},
+		{
+			name: "should_release_idempotency_key_when_event_is_not_queued",
+			dbFn: func(es *EventService) {
+				c, _ := es.cache.(*mocks.MockCache)
+				c.EXPECT().Get(gomock.Any(), "applications:123", gomock.Any())
+				c.EXPECT().Set(gomock.Any(), "applications:123", gomock.Any(), gomock.Any())
+
+				a, _ := es.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "123").
+					Times(1).Return(&datastore.Application{
+					Title:     "test_app",
+					UID:       "123",
+					GroupID:   "abc",
+					Endpoints: []datastore.Endpoint{{UID: "ref"}},
+				}, nil)
+				c.EXPECT().SetNX(gomock.Any(), "idempotency_keys:abc:123:key-1", gomock.Any(), 24*time.Hour).
+					Times(1).Return(true, nil)
+				c.EXPECT().Delete(gomock.Any(), "idempotency_keys:abc:123:key-1").Times(1)
+
+				eq, _ := es.queue.(*mocks.MockQueuer)
+				eq.EXPECT().Write(convoy.CreateEventProcessor, convoy.CreateEventQueue, gomock.Any()).
+					Times(1).Return(errors.New("failed"))
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.Event{
+					AppID:      "123",
+					EventType:  "payment.created",
+					ProviderID: "key-1",
+					Data:       bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				},
+				g: &datastore.Group{
+					UID:  "abc",
+					Name: "test_group",
+					Config: &datastore.GroupConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       "linear",
+							Duration:   1000,
+							RetryCount: 10,
+						},
+						Signature: &datastore.SignatureConfiguration{},
+					},
+				},
+			},
+			wantEvent: &datastore.Event{
+				EventType:      datastore.EventType("payment.created"),
+				ProviderID:     "key-1",
+				Data:           bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+				AppID:          "123",
+				GroupID:        "abc",
+				DocumentStatus: datastore.ActiveDocumentStatus,
+			},
+		},
 		{
 			name: "should_fail_to_create_event",
 			dbFn: func(es *EventService) {},
@@ -408,6 +631,40 @@ func TestEventService_CreateAppEvent(t *testing.T) {
 			wantErrCode: http.StatusBadRequest,
 			wantErrMsg:  "an error occurred while creating event - invalid group",
 		},
+		{
+			name: "should_fail_to_create_event_with_past_deliver_at",
+			dbFn: func(es *EventService) {},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.Event{
+					AppID:     "123",
+					EventType: "payment.created",
+					Data:      bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+					DeliverAt: timePtr(time.Now().Add(-time.Minute)),
+				},
+				g: &datastore.Group{UID: "abc", Name: "test_group"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "deliver_at must be in the future",
+		},
+		{
+			name: "should_fail_to_create_event_with_negative_ttl",
+			dbFn: func(es *EventService) {},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.Event{
+					AppID:     "123",
+					EventType: "payment.created",
+					Data:      bytes.NewBufferString(`{"name":"convoy"}`).Bytes(),
+					TTL:       "-5m",
+				},
+				g: &datastore.Group{UID: "abc", Name: "test_group"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "duration -5m can't be negative",
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -446,6 +703,304 @@ func TestEventService_CreateAppEvent(t *testing.T) {
 	}
 }
 
+func Test_getDeliverAt(t *testing.T) {
+	now := time.Date(2022, 10, 17, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		event      *models.Event
+		want       time.Time
+		wantErrMsg string
+	}{
+		{
+			name:  "should_send_right_away",
+			event: &models.Event{},
+		},
+		{
+			name:  "should_deliver_at",
+			event: &models.Event{DeliverAt: timePtr(now.Add(24 * time.Hour))},
+			want:  now.Add(24 * time.Hour),
+		},
+		{
+			name:  "should_delay",
+			event: &models.Event{Delay: "72h"},
+			want:  now.Add(72 * time.Hour),
+		},
+		{
+			name:       "should_fail_with_deliver_at_and_delay",
+			event:      &models.Event{DeliverAt: timePtr(now.Add(time.Hour)), Delay: "1h"},
+			wantErrMsg: "only one of deliver_at and delay can be set",
+		},
+		{
+			name:       "should_fail_with_past_deliver_at",
+			event:      &models.Event{DeliverAt: timePtr(now)},
+			wantErrMsg: "deliver_at must be in the future",
+		},
+		{
+			name:       "should_fail_with_invalid_delay",
+			event:      &models.Event{Delay: "3 days"},
+			wantErrMsg: `invalid delay "3 days", use a duration like 30m or 72h`,
+		},
+		{
+			name:       "should_fail_with_negative_delay",
+			event:      &models.Event{Delay: "-5m"},
+			wantErrMsg: "delay must be positive",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			deliverAt, err := getDeliverAt(tc.event, now)
+			if tc.wantErrMsg != "" {
+				require.EqualError(t, err, tc.wantErrMsg)
+				return
+			}
+
+			require.NoError(t, err)
+			require.Equal(t, tc.want, deliverAt)
+		})
+	}
+}
+
+func TestEventService_CancelAppEvent(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name          string
+		dbFn          func(es *EventService)
+		wantCancelled int
+		wantErr       bool
+		wantErrCode   int
+		wantErrMsg    string
+	}{
+		{
+			name: "should_cancel_scheduled_deliveries",
+			dbFn: func(es *EventService) {
+				ed, _ := es.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveriesByEventID(gomock.Any(), "123").
+					Times(1).Return([]datastore.EventDelivery{
+					{UID: "1", Status: datastore.ScheduledEventStatus, Metadata: &datastore.Metadata{}},
+					{UID: "2", Status: datastore.ScheduledEventStatus, Metadata: &datastore.Metadata{NumTrials: 1}},
+					{UID: "3", Status: datastore.SuccessEventStatus, Metadata: &datastore.Metadata{NumTrials: 1}},
+					{UID: "4", Status: datastore.ScheduledEventStatus, Metadata: &datastore.Metadata{}},
+				}, nil)
+
+				ed.EXPECT().UpdateStatusOfEventDeliveries(gomock.Any(), []string{"1", "4"}, datastore.CancelledEventStatus).
+					Times(1).Return(nil)
+			},
+			wantCancelled: 2,
+		},
+		{
+			name: "should_fail_without_scheduled_deliveries",
+			dbFn: func(es *EventService) {
+				ed, _ := es.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveriesByEventID(gomock.Any(), "123").
+					Times(1).Return([]datastore.EventDelivery{
+					{UID: "1", Status: datastore.SuccessEventStatus, Metadata: &datastore.Metadata{NumTrials: 1}},
+				}, nil)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "event has no scheduled deliveries to cancel",
+		},
+		{
+			name: "should_fail_to_cancel_deliveries",
+			dbFn: func(es *EventService) {
+				ed, _ := es.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveriesByEventID(gomock.Any(), "123").
+					Times(1).Return([]datastore.EventDelivery{
+					{UID: "1", Status: datastore.ScheduledEventStatus, Metadata: &datastore.Metadata{}},
+				}, nil)
+
+				ed.EXPECT().UpdateStatusOfEventDeliveries(gomock.Any(), []string{"1"}, datastore.CancelledEventStatus).
+					Times(1).Return(errors.New("failed"))
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusInternalServerError,
+			wantErrMsg:  "an error occurred while cancelling event deliveries",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			es := provideEventService(ctrl)
+
+			if tc.dbFn != nil {
+				tc.dbFn(es)
+			}
+
+			cancelled, err := es.CancelAppEvent(ctx, &datastore.Event{UID: "123"})
+			if tc.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tc.wantErrCode, err.(*util.ServiceError).ErrCode())
+				require.Equal(t, tc.wantErrMsg, err.(*util.ServiceError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.wantCancelled, cancelled)
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestEventService_BroadcastEvent(t *testing.T) {
+	ctx := context.Background()
+	group := &datastore.Group{
+		UID:  "abc",
+		Type: datastore.OutgoingGroup,
+		Config: &datastore.GroupConfig{
+			Strategy: &datastore.StrategyConfiguration{
+				Type:       datastore.LinearStrategyProvider,
+				Duration:   10,
+				RetryCount: 3,
+			},
+		},
+	}
+
+	type args struct {
+		ctx        context.Context
+		newMessage *models.BroadcastEvent
+		g          *datastore.Group
+	}
+	tests := []struct {
+		name          string
+		dbFn          func(es *EventService)
+		args          args
+		wantRateLimit int
+		wantErr       bool
+		wantErrCode   int
+		wantErrMsg    string
+	}{
+		{
+			name: "should_broadcast_event",
+			dbFn: func(es *EventService) {
+				eq, _ := es.queue.(*mocks.MockQueuer)
+				eq.EXPECT().Write(convoy.BroadcastProcessor, convoy.BroadcastQueue, gomock.Any()).
+					Times(1).Return(nil)
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.BroadcastEvent{
+					EventType:     "system.maintenance",
+					Data:          []byte(`{"starts_at": "2022-08-01T00:00:00Z"}`),
+					CustomHeaders: map[string]string{"X-Maintenance": "true"},
+					TTL:           "1h",
+				},
+				g: group,
+			},
+			wantRateLimit: defaultBroadcastRateLimit,
+		},
+		{
+			name: "should_broadcast_event_with_rate_limit",
+			dbFn: func(es *EventService) {
+				eq, _ := es.queue.(*mocks.MockQueuer)
+				eq.EXPECT().Write(convoy.BroadcastProcessor, convoy.BroadcastQueue, gomock.Any()).
+					Times(1).Return(nil)
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.BroadcastEvent{
+					EventType: "system.maintenance",
+					Data:      []byte(`{}`),
+					RateLimit: 10,
+				},
+				g: group,
+			},
+			wantRateLimit: 10,
+		},
+		{
+			name: "should_fail_to_broadcast_event_in_incoming_group",
+			args: args{
+				ctx: ctx,
+				newMessage: &models.BroadcastEvent{
+					EventType: "system.maintenance",
+					Data:      []byte(`{}`),
+				},
+				g: &datastore.Group{UID: "abc", Type: datastore.IncomingGroup},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "events can only be broadcast in outgoing groups",
+		},
+		{
+			name: "should_fail_to_broadcast_event_with_invalid_rate_limit",
+			args: args{
+				ctx: ctx,
+				newMessage: &models.BroadcastEvent{
+					EventType: "system.maintenance",
+					Data:      []byte(`{}`),
+					RateLimit: 5000,
+				},
+				g: group,
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "rate limit must be between 1 and 1000",
+		},
+		{
+			name: "should_fail_to_broadcast_event_without_event_type",
+			args: args{
+				ctx: ctx,
+				newMessage: &models.BroadcastEvent{
+					Data: []byte(`{}`),
+				},
+				g: group,
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "event_type:please provide an event type",
+		},
+		{
+			name: "should_fail_to_write_broadcast_event_to_queue",
+			dbFn: func(es *EventService) {
+				eq, _ := es.queue.(*mocks.MockQueuer)
+				eq.EXPECT().Write(convoy.BroadcastProcessor, convoy.BroadcastQueue, gomock.Any()).
+					Times(1).Return(errors.New("failed"))
+			},
+			args: args{
+				ctx: ctx,
+				newMessage: &models.BroadcastEvent{
+					EventType: "system.maintenance",
+					Data:      []byte(`{}`),
+				},
+				g: group,
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "failed to write event to queue",
+		},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+			es := provideEventService(ctrl)
+
+			if tc.dbFn != nil {
+				tc.dbFn(es)
+			}
+
+			event, err := es.BroadcastEvent(tc.args.ctx, tc.args.newMessage, tc.args.g)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tc.wantErrCode, err.(*util.ServiceError).ErrCode())
+				require.Equal(t, tc.wantErrMsg, err.(*util.ServiceError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.NotEmpty(t, event.UID)
+			require.Empty(t, event.AppID)
+			require.Equal(t, tc.args.g.UID, event.GroupID)
+			require.Equal(t, datastore.EventType(tc.args.newMessage.EventType), event.EventType)
+			require.Equal(t, tc.wantRateLimit, event.Broadcast.RateLimit)
+		})
+	}
+}
+
 func TestEventService_GetAppEvent(t *testing.T) {
 	ctx := context.Background()
 	type args struct {
@@ -543,6 +1098,26 @@ func TestEventService_ReplayAppEvent(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should_replay_broadcast_event",
+			args: args{
+				ctx:   ctx,
+				event: &datastore.Event{UID: "123", Broadcast: &datastore.BroadcastConfiguration{RateLimit: 100}},
+				g:     &datastore.Group{UID: "123", Name: "test_group"},
+			},
+			dbFn: func(es *EventService) {
+				eq, _ := es.queue.(*mocks.MockQueuer)
+				// the replay is a new run of the broadcast
+				eq.EXPECT().Write(convoy.BroadcastProcessor, convoy.BroadcastQueue, gomock.Any()).
+					Times(1).DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+					var event datastore.Event
+					require.NoError(t, json.Unmarshal(job.Payload, &event))
+					require.NotZero(t, event.UpdatedAt)
+					return nil
+				})
+			},
+			wantErr: false,
+		},
 		{
 			name: "should_fail_to_replay_app_event",
 			args: args{
@@ -1148,6 +1723,28 @@ func TestEventService_GetEventsPaged(t *testing.T) {
 				TotalPage: 2,
 			},
 		},
+		{
+			name: "should_get_broadcast_events_paged",
+			args: args{
+				ctx: ctx,
+				filter: &datastore.Filter{
+					Group:    &datastore.Group{UID: "123"},
+					Pageable: datastore.Pageable{Page: 1, PerPage: 10, Sort: -1},
+				},
+			},
+			dbFn: func(es *EventService) {
+				ed, _ := es.eventRepo.(*mocks.MockEventRepository)
+				ed.EXPECT().LoadEventsPaged(gomock.Any(), gomock.Any()).
+					Times(1).
+					Return([]datastore.Event{{UID: "1234"}, {UID: "5678"}}, datastore.PaginationData{Total: 2, Page: 1, PerPage: 10}, nil)
+
+				ap, _ := es.appRepo.(*mocks.MockApplicationRepository)
+				ap.EXPECT().FindApplicationByID(gomock.Any(), "").
+					Times(1).Return(nil, datastore.ErrApplicationNotFound)
+			},
+			wantEvents:         []datastore.Event{{UID: "1234"}, {UID: "5678"}},
+			wantPaginationData: datastore.PaginationData{Total: 2, Page: 1, PerPage: 10},
+		},
 		{
 			name: "should_fail_to_get_events_paged",
 			args: args{
@@ -1435,6 +2032,27 @@ func TestEventService_GetEventDeliveriesPaged(t *testing.T) {
 				TotalPage: 2,
 			},
 		},
+		{
+			name: "should_get_upcoming_event_deliveries_paged",
+			args: args{
+				ctx: ctx,
+				filter: &datastore.Filter{
+					Group:    &datastore.Group{UID: "123"},
+					AppID:    "abc",
+					Pageable: datastore.Pageable{Page: 1, PerPage: 10},
+					Status:   []datastore.EventDeliveryStatus{datastore.SuccessEventStatus},
+					Upcoming: true,
+				},
+			},
+			dbFn: func(es *EventService) {
+				ed, _ := es.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().LoadUpcomingEventDeliveriesPaged(gomock.Any(), "123", "abc", datastore.Pageable{Page: 1, PerPage: 10}).
+					Times(1).
+					Return([]datastore.EventDelivery{}, datastore.PaginationData{Page: 1, PerPage: 10}, nil)
+			},
+			wantEventDeliveries: []datastore.EventDelivery{},
+			wantPaginationData:  datastore.PaginationData{Page: 1, PerPage: 10},
+		},
 		{
 			name: "should_fail_to_get_events_deliveries_paged",
 			args: args{
@@ -1596,6 +2214,32 @@ func TestEventService_RetryEventDelivery(t *testing.T) {
 				g: &datastore.Group{UID: "abc"},
 			},
 		},
+		{
+			name: "should_retry_expired_event_delivery",
+			dbFn: func(es *EventService) {
+				s, _ := es.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).Return(&datastore.Subscription{}, nil)
+
+				ed, _ := es.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().UpdateExpiryOfEventDelivery(gomock.Any(), gomock.Any(), primitive.DateTime(0)).
+					Times(1).Return(nil)
+				ed.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ScheduledEventStatus)
+
+				q, _ := es.queue.(*mocks.MockQueuer)
+				q.EXPECT().Write(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).Return(nil)
+			},
+			args: args{
+				ctx: ctx,
+				eventDelivery: &datastore.EventDelivery{
+					UID:       "123",
+					Status:    datastore.ExpiredEventStatus,
+					ExpiresAt: primitive.NewDateTimeFromTime(time.Now().Add(-time.Minute)),
+				},
+				g: &datastore.Group{UID: "abc"},
+			},
+		},
 		{
 			name: "should_error_for_success_status",
 			args: args{
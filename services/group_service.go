@@ -76,6 +76,17 @@ func (gs *GroupService) CreateGroup(ctx context.Context, newGroup *models.Group,
 
 	}
 
+	err = setSignatureKeys(nil, config.Signature)
+	if err != nil {
+		return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	err = validateStrategyConfig(config.Strategy)
+	if err != nil {
+		return nil, nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+	setStrategyDefaults(config.Strategy)
+
 	if newGroup.RateLimit == 0 {
 		newGroup.RateLimit = convoy.RATE_LIMIT
 	}
@@ -151,6 +162,22 @@ func (gs *GroupService) UpdateGroup(ctx context.Context, group *datastore.Group,
 	}
 
 	if update.Config != nil {
+		var current *datastore.SignatureConfiguration
+		if group.Config != nil {
+			current = group.Config.Signature
+		}
+
+		err = setSignatureKeys(current, update.Config.Signature)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		err = validateStrategyConfig(update.Config.Strategy)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+		setStrategyDefaults(update.Config.Strategy)
+
 		group.Config = update.Config
 	}
 
@@ -207,3 +234,62 @@ func (gs *GroupService) DeleteGroup(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// validateStrategyConfig checks that a schedule strategy has a schedule.
+func validateStrategyConfig(cfg *datastore.StrategyConfiguration) error {
+	if cfg == nil || cfg.Type != datastore.ScheduleStrategyProvider {
+		return nil
+	}
+
+	if len(cfg.Schedule) == 0 {
+		return errors.New("please provide a retry schedule")
+	}
+
+	return nil
+}
+
+// setStrategyDefaults defaults the retry count of a schedule strategy that
+// doesn't set one to the length of its schedule.
+func setStrategyDefaults(cfg *datastore.StrategyConfiguration) {
+	if cfg == nil || cfg.Type != datastore.ScheduleStrategyProvider || cfg.RetryCount != 0 {
+		return
+	}
+
+	cfg.RetryCount = scheduleRetryCount(cfg.Schedule)
+}
+
+// scheduleRetryCount is the retry count of a schedule strategy, the first
+// attempt and a retry for each step of the schedule.
+func scheduleRetryCount(schedule []uint64) uint64 {
+	return uint64(len(schedule)) + 1
+}
+
+// setSignatureKeys carries the group's Ed25519 key pair over from current to
+// cfg, since clients never send the private key, and generates a key pair
+// when the group starts signing with ed25519.
+func setSignatureKeys(current, cfg *datastore.SignatureConfiguration) error {
+	if cfg == nil {
+		return nil
+	}
+
+	var publicKey, privateKey string
+	if current != nil {
+		publicKey, privateKey = current.PublicKey, current.PrivateKey
+	}
+
+	if cfg.Scheme == datastore.Ed25519SignatureScheme && util.IsStringEmpty(privateKey) {
+		var err error
+		publicKey, privateKey, err = util.GenerateEd25519KeyPair()
+		if err != nil {
+			log.WithError(err).Error("failed to generate signature key pair")
+			return errors.New("failed to generate signature key pair")
+		}
+	}
+
+	// cfg may be the shared default config, only write to it when it changes
+	if cfg.PublicKey != publicKey || cfg.PrivateKey != privateKey {
+		cfg.PublicKey, cfg.PrivateKey = publicKey, privateKey
+	}
+
+	return nil
+}
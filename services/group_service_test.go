@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/frain-dev/convoy/auth"
@@ -390,6 +391,26 @@ func TestGroupService_CreateGroup(t *testing.T) {
 			wantErrCode: http.StatusBadRequest,
 			wantErrMsg:  "a group with this name already exists",
 		},
+		{
+			name: "should_error_for_schedule_strategy_without_schedule",
+			args: args{
+				ctx: ctx,
+				newGroup: &models.Group{
+					Name: "test_group",
+					Type: "outgoing",
+					Config: &datastore.GroupConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type: "schedule",
+						},
+					},
+				},
+				org:    &datastore.Organisation{UID: "1234"},
+				member: &datastore.OrganisationMember{},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "please provide a retry schedule",
+		},
 	}
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
@@ -950,3 +971,37 @@ func TestGroupService_DeleteGroup(t *testing.T) {
 		})
 	}
 }
+
+func TestSetSignatureKeys(t *testing.T) {
+	// keys sent by a client are ignored
+	cfg := &datastore.SignatureConfiguration{Scheme: datastore.DefaultSignatureScheme, PublicKey: "whpk_abc", PrivateKey: "whsk_abc"}
+	require.NoError(t, setSignatureKeys(nil, cfg))
+	require.Empty(t, cfg.PublicKey)
+	require.Empty(t, cfg.PrivateKey)
+
+	cfg = &datastore.SignatureConfiguration{Scheme: datastore.Ed25519SignatureScheme}
+	require.NoError(t, setSignatureKeys(nil, cfg))
+	require.True(t, strings.HasPrefix(cfg.PublicKey, util.StandardWebhooksPublicKeyPrefix))
+	require.True(t, strings.HasPrefix(cfg.PrivateKey, util.StandardWebhooksPrivateKeyPrefix))
+
+	// an update keeps the group's key pair
+	update := &datastore.SignatureConfiguration{Scheme: datastore.Ed25519SignatureScheme}
+	require.NoError(t, setSignatureKeys(cfg, update))
+	require.Equal(t, cfg.PublicKey, update.PublicKey)
+	require.Equal(t, cfg.PrivateKey, update.PrivateKey)
+}
+
+func TestSetStrategyDefaults(t *testing.T) {
+	cfg := &datastore.StrategyConfiguration{Type: datastore.ScheduleStrategyProvider, Schedule: []uint64{10, 60}}
+	setStrategyDefaults(cfg)
+	require.Equal(t, uint64(3), cfg.RetryCount)
+
+	// a retry count that is set is kept
+	cfg = &datastore.StrategyConfiguration{Type: datastore.ScheduleStrategyProvider, Schedule: []uint64{10, 60}, RetryCount: 5}
+	setStrategyDefaults(cfg)
+	require.Equal(t, uint64(5), cfg.RetryCount)
+
+	cfg = &datastore.StrategyConfiguration{Type: datastore.LinearStrategyProvider, Duration: 10}
+	setStrategyDefaults(cfg)
+	require.Zero(t, cfg.RetryCount)
+}
@@ -11,23 +11,42 @@ import (
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/google/uuid"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/mongo/driver/connstring"
 )
 
 type SourceService struct {
-	sourceRepo datastore.SourceRepository
-	cache      cache.Cache
+	sourceRepo   datastore.SourceRepository
+	cache        cache.Cache
+	egressPolicy *net.EgressPolicy
 }
 
-func NewSourceService(sourceRepo datastore.SourceRepository, cache cache.Cache) *SourceService {
-	return &SourceService{sourceRepo: sourceRepo, cache: cache}
+func NewSourceService(sourceRepo datastore.SourceRepository, cache cache.Cache, egressPolicy *net.EgressPolicy) *SourceService {
+	return &SourceService{sourceRepo: sourceRepo, cache: cache, egressPolicy: egressPolicy}
 }
 
 func (s *SourceService) CreateSource(ctx context.Context, newSource *models.Source, g *datastore.Group) (*datastore.Source, error) {
-	if newSource.Provider.IsValid() {
+	if newSource.Type == datastore.PubSubSource {
+		if err := validatePubSubSource(newSource.Name, newSource.PubSub); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	} else if newSource.Type == datastore.DBChangeStream {
+		if err := validateDBChangeStreamSource(newSource.Name, newSource.DBChangeStream); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	} else if newSource.Type == datastore.RestApiSource {
+		if err := validateRestApiSource(newSource.Name, newSource.RestApi); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		if err := s.egressPolicy.CheckEndpoint(ctx, newSource.RestApi.URL); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	} else if newSource.Provider.IsValid() {
 		if err := validateSourceForProvider(newSource); err != nil {
 			return nil, util.NewServiceError(http.StatusBadRequest, err)
 		}
@@ -37,6 +56,12 @@ func (s *SourceService) CreateSource(ctx context.Context, newSource *models.Sour
 		}
 	}
 
+	// only http sources are sent events through their ingest url, the
+	// others read them from somewhere, so there's nothing to verify
+	if !isHTTPSource(newSource.Type) && util.IsStringEmpty(string(newSource.Verifier.Type)) {
+		newSource.Verifier.Type = datastore.NoopVerifier
+	}
+
 	if newSource.Verifier.Type == datastore.HMacVerifier && newSource.Verifier.HMac == nil {
 		return nil, util.NewServiceError(http.StatusBadRequest, errors.New("Invalid verifier config for hmac"))
 	}
@@ -57,6 +82,9 @@ func (s *SourceService) CreateSource(ctx context.Context, newSource *models.Sour
 		Type:           newSource.Type,
 		Provider:       newSource.Provider,
 		Verifier:       &newSource.Verifier,
+		PubSub:         newSource.PubSub,
+		DBChangeStream: newSource.DBChangeStream,
+		RestApi:        newSource.RestApi,
 		CreatedAt:      primitive.NewDateTimeFromTime(time.Now()),
 		UpdatedAt:      primitive.NewDateTimeFromTime(time.Now()),
 		DocumentStatus: datastore.ActiveDocumentStatus,
@@ -96,9 +124,170 @@ func validateSourceForProvider(newSource *models.Source) error {
 	return nil
 }
 
+func validatePubSubSource(name string, cfg *datastore.PubSubConfig) error {
+	if util.IsStringEmpty(name) {
+		return errors.New("please provide a source name")
+	}
+
+	if cfg == nil {
+		return errors.New("please provide a pub sub config")
+	}
+
+	if err := util.Validate(cfg); err != nil {
+		return err
+	}
+
+	switch cfg.Type {
+	case datastore.SqsPubSub:
+		if cfg.Sqs == nil {
+			return errors.New("sqs config is required for sqs pub sub")
+		}
+	case datastore.KafkaPubSub:
+		if cfg.Kafka == nil {
+			return errors.New("kafka config is required for kafka pub sub")
+		}
+	case datastore.AmqpPubSub:
+		if cfg.Amqp == nil {
+			return errors.New("amqp config is required for amqp pub sub")
+		}
+	}
+
+	if cfg.Workers < 0 {
+		return errors.New("pub sub workers cannot be negative")
+	}
+
+	return nil
+}
+
+func validateDBChangeStreamSource(name string, cfg *datastore.DBChangeStreamConfig) error {
+	if util.IsStringEmpty(name) {
+		return errors.New("please provide a source name")
+	}
+
+	if cfg == nil {
+		return errors.New("please provide a db change stream config")
+	}
+
+	if err := util.Validate(cfg); err != nil {
+		return err
+	}
+
+	cs, err := connstring.ParseAndValidate(cfg.Dsn)
+	if err != nil {
+		return errors.New("please provide a valid mongodb dsn")
+	}
+
+	if util.IsStringEmpty(cfg.Database) && util.IsStringEmpty(cs.Database) {
+		return errors.New("please provide a database")
+	}
+
+	return nil
+}
+
+func validateRestApiSource(name string, cfg *datastore.RestApiConfig) error {
+	if util.IsStringEmpty(name) {
+		return errors.New("please provide a source name")
+	}
+
+	if cfg == nil {
+		return errors.New("please provide a rest api config")
+	}
+
+	if err := util.Validate(cfg); err != nil {
+		return err
+	}
+
+	if cfg.Interval != 0 && cfg.Interval < datastore.MinRestApiInterval {
+		return fmt.Errorf("rest api interval cannot be less than %d seconds", datastore.MinRestApiInterval)
+	}
+
+	if cfg.Pagination != nil {
+		if err := util.Validate(cfg.Pagination); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Auth == nil {
+		return nil
+	}
+
+	if err := util.Validate(cfg.Auth); err != nil {
+		return err
+	}
+
+	switch cfg.Auth.Type {
+	case datastore.BasicRestApiAuth:
+		if cfg.Auth.BasicAuth == nil {
+			return errors.New("basic auth credentials are required for basic_auth")
+		}
+
+		return util.Validate(cfg.Auth.BasicAuth)
+	case datastore.BearerRestApiAuth:
+		if util.IsStringEmpty(cfg.Auth.BearerToken) {
+			return errors.New("please provide a bearer token")
+		}
+	case datastore.APIKeyRestApiAuth:
+		if cfg.Auth.ApiKey == nil {
+			return errors.New("api key is required for api_key auth")
+		}
+
+		return util.Validate(cfg.Auth.ApiKey)
+	}
+
+	return nil
+}
+
+// isHTTPSource reports whether events are sent to sources of type t over
+// their ingest url, instead of being read from somewhere by convoy.
+func isHTTPSource(t datastore.SourceType) bool {
+	switch t {
+	case datastore.PubSubSource, datastore.DBChangeStream, datastore.RestApiSource:
+		return false
+	}
+
+	return true
+}
+
 func (s *SourceService) UpdateSource(ctx context.Context, g *datastore.Group, sourceUpdate *models.UpdateSource, source *datastore.Source) (*datastore.Source, error) {
-	if err := util.Validate(sourceUpdate); err != nil {
-		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	if !isHTTPSource(sourceUpdate.Type) {
+		if sourceUpdate.Name == nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, errors.New("please provide a source name"))
+		}
+
+		if util.IsStringEmpty(string(sourceUpdate.Verifier.Type)) {
+			sourceUpdate.Verifier.Type = datastore.NoopVerifier
+		}
+	}
+
+	source.PubSub, source.DBChangeStream, source.RestApi = nil, nil, nil
+
+	switch sourceUpdate.Type {
+	case datastore.PubSubSource:
+		if err := validatePubSubSource(*sourceUpdate.Name, sourceUpdate.PubSub); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		source.PubSub = sourceUpdate.PubSub
+	case datastore.DBChangeStream:
+		if err := validateDBChangeStreamSource(*sourceUpdate.Name, sourceUpdate.DBChangeStream); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		source.DBChangeStream = sourceUpdate.DBChangeStream
+	case datastore.RestApiSource:
+		if err := validateRestApiSource(*sourceUpdate.Name, sourceUpdate.RestApi); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		if err := s.egressPolicy.CheckEndpoint(ctx, sourceUpdate.RestApi.URL); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		source.RestApi = sourceUpdate.RestApi
+	default:
+		if err := util.Validate(sourceUpdate); err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
 	}
 
 	source.Name = *sourceUpdate.Name
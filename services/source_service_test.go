@@ -6,8 +6,10 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/golang/mock/gomock"
@@ -17,7 +19,7 @@ import (
 func provideSourceService(ctrl *gomock.Controller) *SourceService {
 	sourceRepo := mocks.NewMockSourceRepository(ctrl)
 	cache := mocks.NewMockCache(ctrl)
-	return NewSourceService(sourceRepo, cache)
+	return NewSourceService(sourceRepo, cache, nil)
 }
 
 func TestSourceService_CreateSource(t *testing.T) {
@@ -274,6 +276,234 @@ func TestSourceService_CreateSource(t *testing.T) {
 			wantErrCode: http.StatusBadRequest,
 			wantErrMsg:  "Invalid verifier config for hmac",
 		},
+		{
+			name: "should_create_pub_sub_source",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.PubSubSource,
+					PubSub: &datastore.PubSubConfig{
+						Type:    datastore.SqsPubSub,
+						Workers: 2,
+						Sqs: &datastore.SQSPubSubConfig{
+							AccessKeyID:   "access-key-id",
+							SecretKey:     "secret-key",
+							DefaultRegion: "us-east-1",
+							QueueName:     "convoy-events",
+						},
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantSource: &datastore.Source{
+				Name:     "Convoy-Prod",
+				Type:     datastore.PubSubSource,
+				Verifier: &datastore.VerifierConfig{Type: datastore.NoopVerifier},
+				PubSub: &datastore.PubSubConfig{
+					Type:    datastore.SqsPubSub,
+					Workers: 2,
+					Sqs: &datastore.SQSPubSubConfig{
+						AccessKeyID:   "access-key-id",
+						SecretKey:     "secret-key",
+						DefaultRegion: "us-east-1",
+						QueueName:     "convoy-events",
+					},
+				},
+			},
+			dbFn: func(so *SourceService) {
+				s, _ := so.sourceRepo.(*mocks.MockSourceRepository)
+				s.EXPECT().CreateSource(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_error_for_missing_pub_sub_config",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.PubSubSource,
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "please provide a pub sub config",
+		},
+		{
+			name: "should_error_for_missing_kafka_config",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name:   "Convoy-Prod",
+					Type:   datastore.PubSubSource,
+					PubSub: &datastore.PubSubConfig{Type: datastore.KafkaPubSub},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "kafka config is required for kafka pub sub",
+		},
+		{
+			name: "should_error_for_invalid_pub_sub_type",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name:   "Convoy-Prod",
+					Type:   datastore.PubSubSource,
+					PubSub: &datastore.PubSubConfig{Type: "nats"},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "type:please provide a valid pub sub type",
+		},
+		{
+			name: "should_create_db_change_stream_source",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.DBChangeStream,
+					DBChangeStream: &datastore.DBChangeStreamConfig{
+						Dsn:        "mongodb://localhost:27017/shop",
+						Collection: "orders",
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantSource: &datastore.Source{
+				Name:     "Convoy-Prod",
+				Type:     datastore.DBChangeStream,
+				Verifier: &datastore.VerifierConfig{Type: datastore.NoopVerifier},
+				DBChangeStream: &datastore.DBChangeStreamConfig{
+					Dsn:        "mongodb://localhost:27017/shop",
+					Collection: "orders",
+				},
+			},
+			dbFn: func(so *SourceService) {
+				s, _ := so.sourceRepo.(*mocks.MockSourceRepository)
+				s.EXPECT().CreateSource(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_error_for_invalid_db_change_stream_dsn",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.DBChangeStream,
+					DBChangeStream: &datastore.DBChangeStreamConfig{
+						Dsn:        "postgres://localhost:5432/shop",
+						Collection: "orders",
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "please provide a valid mongodb dsn",
+		},
+		{
+			name: "should_error_for_missing_db_change_stream_database",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.DBChangeStream,
+					DBChangeStream: &datastore.DBChangeStreamConfig{
+						Dsn:        "mongodb://localhost:27017",
+						Collection: "orders",
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "please provide a database",
+		},
+		{
+			name: "should_create_rest_api_source",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.RestApiSource,
+					RestApi: &datastore.RestApiConfig{
+						URL:         "https://api.example.com/events",
+						Interval:    30,
+						RecordsPath: "data",
+						IDPath:      "id",
+						Auth: &datastore.RestApiAuth{
+							Type:        datastore.BearerRestApiAuth,
+							BearerToken: "token",
+						},
+						Pagination: &datastore.RestApiPagination{CursorPath: "next", CursorParam: "after"},
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantSource: &datastore.Source{
+				Name:     "Convoy-Prod",
+				Type:     datastore.RestApiSource,
+				Verifier: &datastore.VerifierConfig{Type: datastore.NoopVerifier},
+				RestApi: &datastore.RestApiConfig{
+					URL:         "https://api.example.com/events",
+					Interval:    30,
+					RecordsPath: "data",
+					IDPath:      "id",
+					Auth: &datastore.RestApiAuth{
+						Type:        datastore.BearerRestApiAuth,
+						BearerToken: "token",
+					},
+					Pagination: &datastore.RestApiPagination{CursorPath: "next", CursorParam: "after"},
+				},
+			},
+			dbFn: func(so *SourceService) {
+				s, _ := so.sourceRepo.(*mocks.MockSourceRepository)
+				s.EXPECT().CreateSource(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
+		{
+			name: "should_error_for_short_rest_api_interval",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.RestApiSource,
+					RestApi: &datastore.RestApiConfig{
+						URL:      "https://api.example.com/events",
+						Interval: 5,
+						IDPath:   "id",
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "rest api interval cannot be less than 10 seconds",
+		},
+		{
+			name: "should_error_for_missing_rest_api_bearer_token",
+			args: args{
+				ctx: ctx,
+				newSource: &models.Source{
+					Name: "Convoy-Prod",
+					Type: datastore.RestApiSource,
+					RestApi: &datastore.RestApiConfig{
+						URL:    "https://api.example.com/events",
+						IDPath: "id",
+						Auth:   &datastore.RestApiAuth{Type: datastore.BearerRestApiAuth},
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "please provide a bearer token",
+		},
 	}
 
 	for _, tc := range tests {
@@ -301,11 +531,40 @@ func TestSourceService_CreateSource(t *testing.T) {
 			require.Equal(t, source.Name, tc.wantSource.Name)
 			require.Equal(t, source.Type, tc.wantSource.Type)
 			require.Equal(t, source.Verifier.Type, tc.wantSource.Verifier.Type)
-			require.Equal(t, source.Verifier.HMac.Header, tc.wantSource.Verifier.HMac.Header)
+			require.Equal(t, tc.wantSource.PubSub, source.PubSub)
+			require.Equal(t, tc.wantSource.DBChangeStream, source.DBChangeStream)
+			require.Equal(t, tc.wantSource.RestApi, source.RestApi)
+
+			if tc.wantSource.Verifier.HMac != nil {
+				require.Equal(t, source.Verifier.HMac.Header, tc.wantSource.Verifier.HMac.Header)
+			}
 		})
 	}
 }
 
+func TestSourceService_CreateSource_EgressPolicy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	policy, err := net.NewEgressPolicy(config.EgressPolicyConfiguration{Enabled: true})
+	require.NoError(t, err)
+
+	so := provideSourceService(ctrl)
+	so.egressPolicy = policy
+
+	_, err = so.CreateSource(context.Background(), &models.Source{
+		Name: "Convoy-Prod",
+		Type: datastore.RestApiSource,
+		RestApi: &datastore.RestApiConfig{
+			URL:    "http://169.254.169.254/latest/meta-data",
+			IDPath: "id",
+		},
+	}, &datastore.Group{UID: "12345"})
+	require.NotNil(t, err)
+	require.Equal(t, http.StatusBadRequest, err.(*util.ServiceError).ErrCode())
+	require.Equal(t, "blocked by egress policy: address 169.254.169.254 is in the blocked range 169.254.0.0/16", err.Error())
+}
+
 func TestSourceService_UpdateSource(t *testing.T) {
 	ctx := context.Background()
 
@@ -392,6 +651,42 @@ func TestSourceService_UpdateSource(t *testing.T) {
 			wantErrCode: http.StatusBadRequest,
 			wantErrMsg:  "an error occurred while updating source",
 		},
+
+		{
+			name: "should_update_source_to_db_change_stream",
+			args: args{
+				ctx: ctx,
+				source: &datastore.Source{
+					UID:    "12345",
+					Type:   datastore.PubSubSource,
+					PubSub: &datastore.PubSubConfig{Type: datastore.AmqpPubSub},
+				},
+				update: &models.UpdateSource{
+					Name: stringPtr("Convoy-Prod"),
+					Type: datastore.DBChangeStream,
+					DBChangeStream: &datastore.DBChangeStreamConfig{
+						Dsn:        "mongodb://localhost:27017",
+						Database:   "shop",
+						Collection: "orders",
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantSource: &datastore.Source{
+				Name:     "Convoy-Prod",
+				Type:     datastore.DBChangeStream,
+				Verifier: &datastore.VerifierConfig{Type: datastore.NoopVerifier},
+				DBChangeStream: &datastore.DBChangeStreamConfig{
+					Dsn:        "mongodb://localhost:27017",
+					Database:   "shop",
+					Collection: "orders",
+				},
+			},
+			dbFn: func(so *SourceService) {
+				s, _ := so.sourceRepo.(*mocks.MockSourceRepository)
+				s.EXPECT().UpdateSource(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).Return(nil)
+			},
+		},
 	}
 
 	for _, tc := range tests {
@@ -419,7 +714,12 @@ func TestSourceService_UpdateSource(t *testing.T) {
 			require.Equal(t, source.Name, tc.wantSource.Name)
 			require.Equal(t, source.Type, tc.wantSource.Type)
 			require.Equal(t, source.Verifier.Type, tc.wantSource.Verifier.Type)
-			require.Equal(t, source.Verifier.HMac.Header, tc.wantSource.Verifier.HMac.Header)
+			require.Equal(t, tc.wantSource.PubSub, source.PubSub)
+			require.Equal(t, tc.wantSource.DBChangeStream, source.DBChangeStream)
+
+			if tc.wantSource.Verifier.HMac != nil {
+				require.Equal(t, source.Verifier.HMac.Header, tc.wantSource.Verifier.HMac.Header)
+			}
 		})
 	}
 }
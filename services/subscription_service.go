@@ -2,12 +2,15 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"time"
 
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/pkg/filter"
+	"github.com/frain-dev/convoy/pkg/transform"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/google/uuid"
@@ -24,14 +27,24 @@ var (
 	ErrCannotFetchSubcriptionsError = errors.New("an error occurred while fetching subscriptions")
 )
 
+const (
+	defaultBatchMaxSize  = 100
+	defaultBatchMaxWait  = 10
+	defaultBatchMaxBytes = 1 << 20
+
+	maxBatchSize = 1000
+	maxBatchWait = 300
+)
+
 type SubcriptionService struct {
 	subRepo    datastore.SubscriptionRepository
 	appRepo    datastore.ApplicationRepository
 	sourceRepo datastore.SourceRepository
+	eventRepo  datastore.EventRepository
 }
 
-func NewSubscriptionService(subRepo datastore.SubscriptionRepository, appRepo datastore.ApplicationRepository, sourceRepo datastore.SourceRepository) *SubcriptionService {
-	return &SubcriptionService{subRepo: subRepo, sourceRepo: sourceRepo, appRepo: appRepo}
+func NewSubscriptionService(subRepo datastore.SubscriptionRepository, appRepo datastore.ApplicationRepository, sourceRepo datastore.SourceRepository, eventRepo datastore.EventRepository) *SubcriptionService {
+	return &SubcriptionService{subRepo: subRepo, sourceRepo: sourceRepo, appRepo: appRepo, eventRepo: eventRepo}
 }
 
 func (s *SubcriptionService) CreateSubscription(ctx context.Context, group *datastore.Group, newSubscription *models.Subscription) (*datastore.Subscription, error) {
@@ -69,6 +82,38 @@ func (s *SubcriptionService) CreateSubscription(ctx context.Context, group *data
 		return nil, util.NewServiceError(http.StatusBadRequest, err)
 	}
 
+	if newSubscription.FilterConfig != nil {
+		err = validateFilterConfig(newSubscription.FilterConfig)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
+	transformConfig, err := getTransformConfig(newSubscription.TransformConfig)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	orderingConfig, err := getOrderingConfig(newSubscription.OrderingConfig)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	batchConfig, err := getBatchConfig(newSubscription.BatchConfig, orderingConfig)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	failurePolicy, err := getFailurePolicy(newSubscription.FailurePolicy)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	ttl, err := durationSeconds(newSubscription.TTL)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
 	subscription := &datastore.Subscription{
 		GroupID:    group.UID,
 		UID:        uuid.New().String(),
@@ -82,6 +127,11 @@ func (s *SubcriptionService) CreateSubscription(ctx context.Context, group *data
 		AlertConfig:     newSubscription.AlertConfig,
 		FilterConfig:    newSubscription.FilterConfig,
 		RateLimitConfig: newSubscription.RateLimitConfig,
+		TransformConfig: transformConfig,
+		OrderingConfig:  orderingConfig,
+		BatchConfig:     batchConfig,
+		FailurePolicy:   failurePolicy,
+		TTL:             ttl,
 
 		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
 		UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
@@ -94,10 +144,12 @@ func (s *SubcriptionService) CreateSubscription(ctx context.Context, group *data
 		subscription.DisableEndpoint = newSubscription.DisableEndpoint
 	}
 
-	if subscription.FilterConfig == nil ||
-		subscription.FilterConfig.EventTypes == nil ||
-		len(subscription.FilterConfig.EventTypes) == 0 {
-		subscription.FilterConfig = &datastore.FilterConfiguration{EventTypes: []string{"*"}}
+	if subscription.FilterConfig == nil {
+		subscription.FilterConfig = &datastore.FilterConfiguration{}
+	}
+
+	if len(subscription.FilterConfig.EventTypes) == 0 {
+		subscription.FilterConfig.EventTypes = []string{"*"}
 	}
 
 	err = s.subRepo.CreateSubscription(ctx, group.UID, subscription)
@@ -195,10 +247,49 @@ func (s *SubcriptionService) UpdateSubscription(ctx context.Context, groupId str
 		subscription.RetryConfig.RetryCount = update.RetryConfig.RetryCount
 	}
 
+	if update.RetryConfig != nil {
+		if subscription.RetryConfig == nil {
+			subscription.RetryConfig = &datastore.RetryConfiguration{}
+		}
+
+		subscription.RetryConfig.HonorRetryAfter = retryConfig.HonorRetryAfter
+
+		if len(retryConfig.Schedule) > 0 {
+			subscription.RetryConfig.Schedule = retryConfig.Schedule
+		}
+
+		if !util.IsStringEmpty(update.RetryConfig.MaxRetryAfter) {
+			subscription.RetryConfig.MaxRetryAfter = retryConfig.MaxRetryAfter
+		}
+
+		if !util.IsStringEmpty(update.RetryConfig.MaxElapsedTime) {
+			subscription.RetryConfig.MaxElapsedTime = retryConfig.MaxElapsedTime
+		}
+	}
+
+	if update.FilterConfig != nil && subscription.FilterConfig == nil {
+		subscription.FilterConfig = &datastore.FilterConfiguration{EventTypes: []string{"*"}}
+	}
+
 	if update.FilterConfig != nil && len(update.FilterConfig.EventTypes) > 0 {
 		subscription.FilterConfig.EventTypes = update.FilterConfig.EventTypes
 	}
 
+	if update.FilterConfig != nil {
+		err = validateFilterConfig(update.FilterConfig)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+
+		if update.FilterConfig.Filter.Body != nil {
+			subscription.FilterConfig.Filter.Body = update.FilterConfig.Filter.Body
+		}
+
+		if update.FilterConfig.Filter.Headers != nil {
+			subscription.FilterConfig.Filter.Headers = update.FilterConfig.Filter.Headers
+		}
+	}
+
 	if update.RateLimitConfig != nil && update.RateLimitConfig.Count > 0 {
 		if subscription.RateLimitConfig == nil {
 			subscription.RateLimitConfig = &datastore.RateLimitConfiguration{}
@@ -213,10 +304,54 @@ func (s *SubcriptionService) UpdateSubscription(ctx context.Context, groupId str
 		subscription.RateLimitConfig.Duration = update.RateLimitConfig.Duration
 	}
 
+	if update.TransformConfig != nil {
+		// an empty program removes the subscription's transform
+		subscription.TransformConfig, err = getTransformConfig(update.TransformConfig)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
+	if update.OrderingConfig != nil {
+		// disabling ordering removes the subscription's ordering config
+		subscription.OrderingConfig, err = getOrderingConfig(update.OrderingConfig)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
+	if update.BatchConfig != nil || update.OrderingConfig != nil {
+		// disabling batching removes the subscription's batch config
+		batchConfig := subscription.BatchConfig
+		if update.BatchConfig != nil {
+			batchConfig = update.BatchConfig
+		}
+
+		subscription.BatchConfig, err = getBatchConfig(batchConfig, subscription.OrderingConfig)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
+	if update.FailurePolicy != nil {
+		// an empty policy removes the subscription's failure policy
+		subscription.FailurePolicy, err = getFailurePolicy(update.FailurePolicy)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
 	if update.DisableEndpoint != nil {
 		subscription.DisableEndpoint = update.DisableEndpoint
 	}
 
+	if !util.IsStringEmpty(update.TTL) {
+		subscription.TTL, err = durationSeconds(update.TTL)
+		if err != nil {
+			return nil, util.NewServiceError(http.StatusBadRequest, err)
+		}
+	}
+
 	err = s.subRepo.UpdateSubscription(ctx, groupId, subscription)
 	if err != nil {
 		log.WithError(err).Error(ErrUpateSubscriptionError.Error())
@@ -384,12 +519,223 @@ func (s *SubcriptionService) LoadSubscriptionsPaged(ctx context.Context, filter
 	return subscriptions, paginatedData, nil
 }
 
+// TestSubscriptionFilter reports whether the sample request matches the
+// filter schema, so a filter can be tried out before it is saved.
+func (s *SubcriptionService) TestSubscriptionFilter(ctx context.Context, testFilter *models.TestFilter) (bool, error) {
+	if err := validateFilterSchema(&testFilter.Schema); err != nil {
+		return false, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	var body interface{}
+	if len(testFilter.Request.Body) > 0 {
+		if err := json.Unmarshal(testFilter.Request.Body, &body); err != nil {
+			return false, util.NewServiceError(http.StatusBadRequest, errors.New("request body is not valid json"))
+		}
+	}
+
+	matched, err := filter.Match(body, testFilter.Schema.Body)
+	if err != nil || !matched {
+		return false, err
+	}
+
+	return filter.MatchHeaders(testFilter.Request.Headers, testFilter.Schema.Headers)
+}
+
+// TestSubscriptionTransform runs a transform program on a stored event
+// without delivering it, so a program can be tried out before it is saved.
+func (s *SubcriptionService) TestSubscriptionTransform(ctx context.Context, group *datastore.Group, test *models.TestTransform) (*transform.Result, error) {
+	if err := util.Validate(test); err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	t, err := transform.Compile(test.Program)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	event, err := s.eventRepo.FindEventByID(ctx, test.EventID)
+	if err != nil {
+		log.WithError(err).Error("failed to find event by id")
+		return nil, util.NewServiceError(http.StatusNotFound, errors.New("event not found"))
+	}
+
+	if event.GroupID != group.UID {
+		return nil, util.NewServiceError(http.StatusNotFound, errors.New("event not found"))
+	}
+
+	result, err := t.Apply(ctx, event.Data, event.Headers)
+	if err != nil {
+		return nil, util.NewServiceError(http.StatusBadRequest, err)
+	}
+
+	return result, nil
+}
+
+func getTransformConfig(cfg *datastore.TransformConfiguration) (*datastore.TransformConfiguration, error) {
+	if cfg == nil || util.IsStringEmpty(cfg.Program) {
+		return nil, nil
+	}
+
+	if _, err := transform.Compile(cfg.Program); err != nil {
+		return nil, err
+	}
+
+	return &datastore.TransformConfiguration{Program: cfg.Program}, nil
+}
+
+func getOrderingConfig(cfg *datastore.OrderingConfiguration) (*datastore.OrderingConfiguration, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	if !util.IsStringEmpty(cfg.KeyPath) && !util.IsStringEmpty(cfg.KeyHeader) {
+		return nil, errors.New("ordering key can come from either a payload path or a header, not both")
+	}
+
+	return &datastore.OrderingConfiguration{Enabled: true, KeyPath: cfg.KeyPath, KeyHeader: cfg.KeyHeader}, nil
+}
+
+// getBatchConfig fills in the defaults of a batch config, batching can't be
+// combined with ordering.
+func getBatchConfig(cfg *datastore.BatchConfiguration, ordering *datastore.OrderingConfiguration) (*datastore.BatchConfiguration, error) {
+	if cfg == nil || !cfg.Enabled {
+		return nil, nil
+	}
+
+	if ordering != nil {
+		return nil, errors.New("a subscription can't have both ordering and batching enabled")
+	}
+
+	bc := &datastore.BatchConfiguration{Enabled: true, MaxSize: cfg.MaxSize, MaxWait: cfg.MaxWait, MaxBytes: cfg.MaxBytes}
+	if bc.MaxSize == 0 {
+		bc.MaxSize = defaultBatchMaxSize
+	}
+
+	if bc.MaxWait == 0 {
+		bc.MaxWait = defaultBatchMaxWait
+	}
+
+	if bc.MaxBytes == 0 {
+		bc.MaxBytes = defaultBatchMaxBytes
+	}
+
+	if bc.MaxSize < 0 || bc.MaxSize > maxBatchSize {
+		return nil, fmt.Errorf("batch max size must be between 1 and %d", maxBatchSize)
+	}
+
+	if bc.MaxWait > maxBatchWait {
+		return nil, fmt.Errorf("batch max wait can't be more than %d seconds", maxBatchWait)
+	}
+
+	if bc.MaxBytes < 0 {
+		return nil, errors.New("batch max bytes can't be negative")
+	}
+
+	return bc, nil
+}
+
+// getFailurePolicy validates a failure policy's status codes and network
+// errors, an empty policy is the same as no policy.
+func getFailurePolicy(p *datastore.FailurePolicy) (*datastore.FailurePolicy, error) {
+	if p == nil {
+		return nil, nil
+	}
+
+	if len(p.PermanentStatusCodes) == 0 && len(p.FailureStatusCodes) == 0 &&
+		len(p.RetryableNetworkErrors) == 0 && !p.DisableEndpoint {
+		return nil, nil
+	}
+
+	for _, code := range p.PermanentStatusCodes {
+		if code < 100 || code > 599 {
+			return nil, fmt.Errorf("invalid permanent status code: %d", code)
+		}
+	}
+
+	for _, code := range p.FailureStatusCodes {
+		if code < 200 || code > 399 {
+			return nil, fmt.Errorf("failure status code %d must be a 2xx or 3xx status code", code)
+		}
+	}
+
+	for _, e := range p.RetryableNetworkErrors {
+		if !e.IsValid() {
+			return nil, fmt.Errorf("unsupported network error: %s", e)
+		}
+	}
+
+	return p, nil
+}
+
+// durationSeconds parses a duration such as "5m" into seconds, an empty
+// duration is zero.
+func durationSeconds(d string) (uint64, error) {
+	if util.IsStringEmpty(d) {
+		return 0, nil
+	}
+
+	interval, err := time.ParseDuration(d)
+	if err != nil {
+		return 0, err
+	}
+
+	if interval < 0 {
+		return 0, fmt.Errorf("duration %s can't be negative", d)
+	}
+
+	return uint64(interval.Seconds()), nil
+}
+
+func validateFilterConfig(cfg *datastore.FilterConfiguration) error {
+	for _, eventType := range cfg.EventTypes {
+		if err := filter.ValidateEventType(eventType); err != nil {
+			return err
+		}
+	}
+
+	return validateFilterSchema(&cfg.Filter)
+}
+
+func validateFilterSchema(schema *datastore.FilterSchema) error {
+	if err := filter.Validate(schema.Body); err != nil {
+		return fmt.Errorf("body filter: %w", err)
+	}
+
+	if err := filter.Validate(schema.Headers); err != nil {
+		return fmt.Errorf("header filter: %w", err)
+	}
+
+	return nil
+}
+
 func getRetryConfig(cfg *models.RetryConfiguration) (*datastore.RetryConfiguration, error) {
 	if cfg == nil {
 		return nil, nil
 	}
 
-	strategyConfig := &datastore.RetryConfiguration{Type: cfg.Type, RetryCount: cfg.RetryCount}
+	strategyConfig := &datastore.RetryConfiguration{Type: cfg.Type, RetryCount: cfg.RetryCount, Schedule: cfg.Schedule, HonorRetryAfter: cfg.HonorRetryAfter}
+
+	if cfg.Type == datastore.ScheduleStrategyProvider {
+		if len(strategyConfig.Schedule) == 0 {
+			return nil, errors.New("please provide a retry schedule")
+		}
+
+		if strategyConfig.RetryCount == 0 {
+			strategyConfig.RetryCount = scheduleRetryCount(strategyConfig.Schedule)
+		}
+	}
+
+	var err error
+	strategyConfig.MaxRetryAfter, err = durationSeconds(cfg.MaxRetryAfter)
+	if err != nil {
+		return nil, err
+	}
+
+	strategyConfig.MaxElapsedTime, err = durationSeconds(cfg.MaxElapsedTime)
+	if err != nil {
+		return nil, err
+	}
+
 	if !util.IsStringEmpty(cfg.Duration) {
 		interval, err := time.ParseDuration(cfg.Duration)
 		if err != nil {
@@ -8,6 +8,8 @@ import (
 
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/transform"
 	"github.com/frain-dev/convoy/server/models"
 	"github.com/frain-dev/convoy/util"
 	"github.com/golang/mock/gomock"
@@ -18,7 +20,8 @@ func provideSubsctiptionService(ctrl *gomock.Controller) *SubcriptionService {
 	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
 	appRepo := mocks.NewMockApplicationRepository(ctrl)
 	sourceRepo := mocks.NewMockSourceRepository(ctrl)
-	return NewSubscriptionService(subRepo, appRepo, sourceRepo)
+	eventRepo := mocks.NewMockEventRepository(ctrl)
+	return NewSubscriptionService(subRepo, appRepo, sourceRepo, eventRepo)
 }
 
 func TestSubscription_CreateSubscription(t *testing.T) {
@@ -125,6 +128,448 @@ func TestSubscription_CreateSubscription(t *testing.T) {
 				)
 			},
 		},
+		{
+			name: "should fail to create subscription with invalid filter",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:       "sub 1",
+					AppID:      "app-id-1",
+					EndpointID: "endpoint-id-1",
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{
+							Body: datastore.FilterDocument{"data.amount": map[string]interface{}{"$between": []interface{}{1, 2}}},
+						},
+					},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "body filter: invalid filter: unsupported operator $between",
+		},
+		{
+			name: "should fail to create subscription with invalid event type pattern",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:       "sub 1",
+					AppID:      "app-id-1",
+					EndpointID: "endpoint-id-1",
+					FilterConfig: &datastore.FilterConfiguration{
+						EventTypes: []string{"invoice.paid", "invoice*"},
+					},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  `invalid filter: event type "invoice*" can only use * and ** as whole segments`,
+		},
+		{
+			name: "should fail to create subscription with invalid transform",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:            "sub 1",
+					AppID:           "app-id-1",
+					EndpointID:      "endpoint-id-1",
+					TransformConfig: &datastore.TransformConfiguration{Program: `.body |=`},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "invalid transform program: unexpected token <EOF>",
+		},
+		{
+			name: "should create ordered subscription",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:           "sub 1",
+					AppID:          "app-id-1",
+					EndpointID:     "endpoint-id-1",
+					OrderingConfig: &datastore.OrderingConfiguration{Enabled: true, KeyPath: "data.order_id"},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name:           "sub 1",
+				Type:           datastore.SubscriptionTypeAPI,
+				OrderingConfig: &datastore.OrderingConfiguration{Enabled: true, KeyPath: "data.order_id"},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+		},
+		{
+			name: "should fail to create ordered subscription with two ordering keys",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:       "sub 1",
+					AppID:      "app-id-1",
+					EndpointID: "endpoint-id-1",
+					OrderingConfig: &datastore.OrderingConfiguration{
+						Enabled:   true,
+						KeyPath:   "data.order_id",
+						KeyHeader: "X-Order-Id",
+					},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "ordering key can come from either a payload path or a header, not both",
+		},
+		{
+			name: "should create batched subscription with default limits",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:        "sub 1",
+					AppID:       "app-id-1",
+					EndpointID:  "endpoint-id-1",
+					BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 50},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name: "sub 1",
+				Type: datastore.SubscriptionTypeAPI,
+				BatchConfig: &datastore.BatchConfiguration{
+					Enabled:  true,
+					MaxSize:  50,
+					MaxWait:  defaultBatchMaxWait,
+					MaxBytes: defaultBatchMaxBytes,
+				},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+		},
+		{
+			name: "should fail to create subscription that is both ordered and batched",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:           "sub 1",
+					AppID:          "app-id-1",
+					EndpointID:     "endpoint-id-1",
+					OrderingConfig: &datastore.OrderingConfiguration{Enabled: true},
+					BatchConfig:    &datastore.BatchConfiguration{Enabled: true},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "a subscription can't have both ordering and batching enabled",
+		},
+		{
+			name: "should fail to create batched subscription with a large max size",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:        "sub 1",
+					AppID:       "app-id-1",
+					EndpointID:  "endpoint-id-1",
+					BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 5000},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "batch max size must be between 1 and 1000",
+		},
+		{
+			name: "should create subscription with a failure policy",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:       "sub 1",
+					AppID:      "app-id-1",
+					EndpointID: "endpoint-id-1",
+					FailurePolicy: &datastore.FailurePolicy{
+						PermanentStatusCodes:   []int{400, 401, 404, 410},
+						RetryableNetworkErrors: []datastore.NetworkError{datastore.TimeoutNetworkError},
+						DisableEndpoint:        true,
+					},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name: "sub 1",
+				Type: datastore.SubscriptionTypeAPI,
+				FailurePolicy: &datastore.FailurePolicy{
+					PermanentStatusCodes:   []int{400, 401, 404, 410},
+					RetryableNetworkErrors: []datastore.NetworkError{datastore.TimeoutNetworkError},
+					DisableEndpoint:        true,
+				},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+		},
+		{
+			name: "should fail to create subscription with a 5xx failure status code",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:          "sub 1",
+					AppID:         "app-id-1",
+					EndpointID:    "endpoint-id-1",
+					FailurePolicy: &datastore.FailurePolicy{FailureStatusCodes: []int{500}},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "failure status code 500 must be a 2xx or 3xx status code",
+		},
+		{
+			name: "should fail to create subscription with an unsupported network error",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:          "sub 1",
+					AppID:         "app-id-1",
+					EndpointID:    "endpoint-id-1",
+					FailurePolicy: &datastore.FailurePolicy{RetryableNetworkErrors: []datastore.NetworkError{"eof"}},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "unsupported network error: eof",
+		},
+		{
+			name: "should create subscription with a retry schedule",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:       "sub 1",
+					AppID:      "app-id-1",
+					EndpointID: "endpoint-id-1",
+					RetryConfig: &models.RetryConfiguration{
+						Type:            datastore.ScheduleStrategyProvider,
+						Schedule:        []uint64{10, 60, 300},
+						HonorRetryAfter: true,
+						MaxRetryAfter:   "10m",
+						MaxElapsedTime:  "24h",
+					},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name: "sub 1",
+				Type: datastore.SubscriptionTypeAPI,
+				RetryConfig: &datastore.RetryConfiguration{
+					Type:            datastore.ScheduleStrategyProvider,
+					RetryCount:      4,
+					Schedule:        []uint64{10, 60, 300},
+					HonorRetryAfter: true,
+					MaxRetryAfter:   600,
+					MaxElapsedTime:  86400,
+				},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().CreateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).
+					Return(nil)
+
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+		},
+		{
+			name: "should fail to create subscription with an empty retry schedule",
+			args: args{
+				ctx: ctx,
+				newSubscription: &models.Subscription{
+					Name:        "sub 1",
+					AppID:       "app-id-1",
+					EndpointID:  "endpoint-id-1",
+					RetryConfig: &models.RetryConfiguration{Type: datastore.ScheduleStrategyProvider},
+				},
+				group: &datastore.Group{UID: "12345", Type: datastore.OutgoingGroup},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				a, _ := ss.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").
+					Times(1).Return(
+					&datastore.Application{
+						GroupID: "12345",
+						Endpoints: []datastore.Endpoint{
+							{UID: "endpoint-id-1"},
+						},
+					},
+					nil,
+				)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "please provide a retry schedule",
+		},
 		{
 			name: "should fail to find source",
 			args: args{
@@ -372,6 +817,14 @@ func TestSubscription_CreateSubscription(t *testing.T) {
 				require.Equal(t, subscription.FilterConfig.EventTypes,
 					tc.wantSubscription.FilterConfig.EventTypes)
 			}
+
+			require.Equal(t, tc.wantSubscription.OrderingConfig, subscription.OrderingConfig)
+			require.Equal(t, tc.wantSubscription.BatchConfig, subscription.BatchConfig)
+			require.Equal(t, tc.wantSubscription.FailurePolicy, subscription.FailurePolicy)
+
+			if tc.wantSubscription.RetryConfig != nil {
+				require.Equal(t, tc.wantSubscription.RetryConfig, subscription.RetryConfig)
+			}
 		})
 	}
 }
@@ -427,6 +880,99 @@ func TestSubscription_UpdateSubscription(t *testing.T) {
 					Return(nil)
 			},
 		},
+		{
+			name: "should update subscription filter",
+			args: args{
+				ctx: ctx,
+				update: &models.UpdateSubscription{
+					Name: "sub 1",
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{
+							Headers: datastore.FilterDocument{"x-tenant": "acme"},
+						},
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name: "sub 1",
+				Type: datastore.SubscriptionTypeAPI,
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).Return(&datastore.Subscription{
+					UID:  "sub-uid-1",
+					Type: datastore.SubscriptionTypeAPI,
+					FilterConfig: &datastore.FilterConfiguration{
+						EventTypes: []string{"payment.success"},
+						Filter: datastore.FilterSchema{
+							Body: datastore.FilterDocument{"data.region": "eu"},
+						},
+					},
+				}, nil)
+
+				s.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).DoAndReturn(func(_ context.Context, _ string, sub *datastore.Subscription) error {
+					require.Equal(t, []string{"payment.success"}, sub.FilterConfig.EventTypes)
+					require.Equal(t, datastore.FilterDocument{"data.region": "eu"}, sub.FilterConfig.Filter.Body)
+					require.Equal(t, datastore.FilterDocument{"x-tenant": "acme"}, sub.FilterConfig.Filter.Headers)
+					return nil
+				})
+			},
+		},
+		{
+			name: "should update subscription ttl",
+			args: args{
+				ctx: ctx,
+				update: &models.UpdateSubscription{
+					Name: "sub 1",
+					TTL:  "15m",
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			wantSubscription: &datastore.Subscription{
+				Name: "sub 1",
+				Type: datastore.SubscriptionTypeAPI,
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).Return(&datastore.Subscription{
+					UID:  "sub-uid-1",
+					Type: datastore.SubscriptionTypeAPI,
+					TTL:  3600,
+				}, nil)
+
+				s.EXPECT().UpdateSubscription(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).DoAndReturn(func(_ context.Context, _ string, sub *datastore.Subscription) error {
+					require.Equal(t, uint64(900), sub.TTL)
+					return nil
+				})
+			},
+		},
+		{
+			name: "should fail to update subscription with invalid filter",
+			args: args{
+				ctx: ctx,
+				update: &models.UpdateSubscription{
+					FilterConfig: &datastore.FilterConfiguration{
+						Filter: datastore.FilterSchema{
+							Headers: datastore.FilterDocument{"x-tenant": map[string]interface{}{"$in": "acme"}},
+						},
+					},
+				},
+				group: &datastore.Group{UID: "12345"},
+			},
+			dbFn: func(ss *SubcriptionService) {
+				s, _ := ss.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Times(1).Return(&datastore.Subscription{UID: "sub-uid-1"}, nil)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "header filter: invalid filter: $in takes an array",
+		},
 		{
 			name: "should fail to update subscription",
 			args: args{
@@ -940,3 +1486,202 @@ func TestSubcriptionService_ToggleSubscriptionStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestSubcriptionService_TestSubscriptionFilter(t *testing.T) {
+	tests := []struct {
+		name        string
+		testFilter  *models.TestFilter
+		wantMatch   bool
+		wantErr     bool
+		wantErrCode int
+		wantErrMsg  string
+	}{
+		{
+			name: "should match body and headers",
+			testFilter: &models.TestFilter{
+				Request: models.FilterRequest{
+					Headers: httpheader.HTTPHeader{"X-Tenant": []string{"acme"}},
+					Body:    []byte(`{"data": {"amount": 1500, "region": "eu"}}`),
+				},
+				Schema: datastore.FilterSchema{
+					Headers: datastore.FilterDocument{"x-tenant": "acme"},
+					Body: datastore.FilterDocument{
+						"data.amount": map[string]interface{}{"$gt": 1000},
+						"data.region": map[string]interface{}{"$in": []interface{}{"eu"}},
+					},
+				},
+			},
+			wantMatch: true,
+		},
+		{
+			name: "should not match body",
+			testFilter: &models.TestFilter{
+				Request: models.FilterRequest{Body: []byte(`{"data": {"amount": 500}}`)},
+				Schema: datastore.FilterSchema{
+					Body: datastore.FilterDocument{"data.amount": map[string]interface{}{"$gt": 1000}},
+				},
+			},
+			wantMatch: false,
+		},
+		{
+			name: "should not match headers",
+			testFilter: &models.TestFilter{
+				Request: models.FilterRequest{
+					Headers: httpheader.HTTPHeader{"X-Tenant": []string{"globex"}},
+					Body:    []byte(`{}`),
+				},
+				Schema: datastore.FilterSchema{
+					Headers: datastore.FilterDocument{"x-tenant": "acme"},
+				},
+			},
+			wantMatch: false,
+		},
+		{
+			name: "should error for invalid filter",
+			testFilter: &models.TestFilter{
+				Schema: datastore.FilterSchema{
+					Body: datastore.FilterDocument{"$where": "this.amount > 1000"},
+				},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "body filter: invalid filter: unsupported operator $where",
+		},
+		{
+			name: "should error for invalid request body",
+			testFilter: &models.TestFilter{
+				Request: models.FilterRequest{Body: []byte(`{"data":`)},
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "request body is not valid json",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ss := provideSubsctiptionService(ctrl)
+
+			matched, err := ss.TestSubscriptionFilter(context.Background(), tc.testFilter)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tc.wantErrCode, err.(*util.ServiceError).ErrCode())
+				require.Equal(t, tc.wantErrMsg, err.(*util.ServiceError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.wantMatch, matched)
+		})
+	}
+}
+
+func TestSubcriptionService_TestSubscriptionTransform(t *testing.T) {
+	group := &datastore.Group{UID: "12345"}
+
+	tests := []struct {
+		name        string
+		test        *models.TestTransform
+		dbFn        func(ss *SubcriptionService)
+		wantResult  *transform.Result
+		wantErr     bool
+		wantErrCode int
+		wantErrMsg  string
+	}{
+		{
+			name: "should transform stored event",
+			test: &models.TestTransform{
+				Program: `.body |= {id: .data.id} | .headers["X-Tenant"] = "acme"`,
+				EventID: "event-1",
+			},
+			dbFn: func(ss *SubcriptionService) {
+				e, _ := ss.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), "event-1").Times(1).Return(&datastore.Event{
+					UID:     "event-1",
+					GroupID: "12345",
+					Data:    []byte(`{"data": {"id": "inv_1"}}`),
+				}, nil)
+			},
+			wantResult: &transform.Result{
+				Body:    []byte(`{"id":"inv_1"}`),
+				Headers: httpheader.HTTPHeader{"X-Tenant": []string{"acme"}},
+			},
+		},
+		{
+			name: "should report dropped delivery",
+			test: &models.TestTransform{Program: `empty`, EventID: "event-1"},
+			dbFn: func(ss *SubcriptionService) {
+				e, _ := ss.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), "event-1").Times(1).Return(&datastore.Event{
+					UID:     "event-1",
+					GroupID: "12345",
+					Data:    []byte(`{}`),
+				}, nil)
+			},
+			wantResult: &transform.Result{Dropped: true},
+		},
+		{
+			name:        "should error for invalid program",
+			test:        &models.TestTransform{Program: `.body |=`, EventID: "event-1"},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "invalid transform program: unexpected token <EOF>",
+		},
+		{
+			name: "should error for event in another group",
+			test: &models.TestTransform{Program: `.`, EventID: "event-1"},
+			dbFn: func(ss *SubcriptionService) {
+				e, _ := ss.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), "event-1").Times(1).Return(&datastore.Event{
+					UID:     "event-1",
+					GroupID: "abc",
+				}, nil)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusNotFound,
+			wantErrMsg:  "event not found",
+		},
+		{
+			name: "should error for failed transform",
+			test: &models.TestTransform{Program: `.body.data.id`, EventID: "event-1"},
+			dbFn: func(ss *SubcriptionService) {
+				e, _ := ss.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), "event-1").Times(1).Return(&datastore.Event{
+					UID:     "event-1",
+					GroupID: "12345",
+					Data:    []byte(`{"data": {"id": "inv_1"}}`),
+				}, nil)
+			},
+			wantErr:     true,
+			wantErrCode: http.StatusBadRequest,
+			wantErrMsg:  "transform failed: program must output an object with a body and headers, or null",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			ss := provideSubsctiptionService(ctrl)
+
+			if tc.dbFn != nil {
+				tc.dbFn(ss)
+			}
+
+			result, err := ss.TestSubscriptionTransform(context.Background(), group, tc.test)
+			if tc.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tc.wantErrCode, err.(*util.ServiceError).ErrCode())
+				require.Equal(t, tc.wantErrMsg, err.(*util.ServiceError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+			require.Equal(t, tc.wantResult, result)
+		})
+	}
+}
@@ -67,19 +67,25 @@ func GetVersion() string {
 }
 
 const (
-	EventProcessor        TaskName = "EventProcessor"
-	DeadLetterProcessor   TaskName = "DeadLetterProcessor"
-	CreateEventProcessor  TaskName = "CreateEventProcessor"
-	NotificationProcessor TaskName = "NotificationProcessor"
-	IndexDocument         TaskName = "index document"
-	DailyAnalytics        TaskName = "daily analytics"
-	MonitorTwitterSources TaskName = "monitor twitter sources"
-	RetentionPolicies     TaskName = "retention_policies"
-	EmailProcessor        TaskName = "EmailProcessor"
-	ApplicationsCacheKey  CacheKey = "applications"
-	GroupsCacheKey        CacheKey = "groups"
-	TokenCacheKey         CacheKey = "tokens"
-	SourceCacheKey        CacheKey = "sources"
+	EventProcessor         TaskName = "EventProcessor"
+	DeadLetterProcessor    TaskName = "DeadLetterProcessor"
+	CreateEventProcessor   TaskName = "CreateEventProcessor"
+	BroadcastProcessor     TaskName = "BroadcastProcessor"
+	NotificationProcessor  TaskName = "NotificationProcessor"
+	IndexDocument          TaskName = "index document"
+	DailyAnalytics         TaskName = "daily analytics"
+	MonitorTwitterSources  TaskName = "monitor twitter sources"
+	RetentionPolicies      TaskName = "retention_policies"
+	PruneEndpointSecrets   TaskName = "prune endpoint secrets"
+	EmailProcessor         TaskName = "EmailProcessor"
+	ApplicationsCacheKey   CacheKey = "applications"
+	GroupsCacheKey         CacheKey = "groups"
+	TokenCacheKey          CacheKey = "tokens"
+	SourceCacheKey         CacheKey = "sources"
+	ChangeStreamCacheKey   CacheKey = "change_streams"
+	CircuitBreakerCacheKey CacheKey = "circuit_breakers"
+	IdempotencyKeyCacheKey CacheKey = "idempotency_keys"
+	OAuth2TokenCacheKey    CacheKey = "oauth2_tokens"
 )
 
 // queues
@@ -89,6 +95,10 @@ const (
 	PriorityQueue    QueueName = "PriorityQueue"
 	ScheduleQueue    QueueName = "ScheduleQueue"
 	DefaultQueue     QueueName = "DefaultQueue"
+
+	// BroadcastQueue has a lower priority than EventQueue, so a broadcast
+	// to many applications doesn't hold up other events.
+	BroadcastQueue QueueName = "BroadcastQueue"
 )
 
 // Exports dir
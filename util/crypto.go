@@ -2,11 +2,14 @@ package util
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/hmac"
 	"crypto/md5"
+	"crypto/rand"
 	"crypto/sha1"
 	"crypto/sha256"
 	"crypto/sha512"
+	"encoding/base64"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
@@ -54,18 +57,15 @@ type Signature struct {
 	EncodedData []byte
 }
 
-func GenerateSignatureHeader(replayAttacks bool, hash string, secret string, data json.RawMessage) (*Signature, error) {
-	buf := bytes.NewBuffer([]byte{})
-	encoder := json.NewEncoder(buf)
-	encoder.SetEscapeHTML(false)
-
-	err := encoder.Encode(data)
+// GenerateSignatureHeader signs data with each of secrets. The hmacs are
+// joined with commas in the order of secrets, so while a secret is rotated a
+// consumer can verify the payload with either its old or its new secret.
+func GenerateSignatureHeader(replayAttacks bool, hash string, secrets []string, data json.RawMessage) (*Signature, error) {
+	trimmedBuff, err := encodePayload(data)
 	if err != nil {
-		return nil, fmt.Errorf("failed to encode data: %v", err)
+		return nil, err
 	}
 
-	trimmedBuff := bytes.TrimSuffix(buf.Bytes(), []byte("\n"))
-
 	var signedPayload strings.Builder
 	var timestamp string
 	if replayAttacks {
@@ -75,18 +75,152 @@ func GenerateSignatureHeader(replayAttacks bool, hash string, secret string, dat
 	}
 	signedPayload.WriteString(string(trimmedBuff))
 
-	hmacStr, err := ComputeJSONHmac(hash, signedPayload.String(), secret, false)
-	if err != nil {
-		return nil, fmt.Errorf("error occurred while generating hmac: %v", err)
+	hmacs := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		hmacStr, err := ComputeJSONHmac(hash, signedPayload.String(), secret, false)
+		if err != nil {
+			return nil, fmt.Errorf("error occurred while generating hmac: %v", err)
+		}
+
+		hmacs = append(hmacs, hmacStr)
 	}
 
 	return &Signature{
 		Timestamp:   timestamp,
-		Hmac:        hmacStr,
+		Hmac:        strings.Join(hmacs, ","),
 		EncodedData: trimmedBuff,
 	}, nil
 }
 
+// encodePayload compacts data, without escaping html, into the payload that
+// is signed and sent.
+func encodePayload(data json.RawMessage) ([]byte, error) {
+	buf := bytes.NewBuffer([]byte{})
+	encoder := json.NewEncoder(buf)
+	encoder.SetEscapeHTML(false)
+
+	err := encoder.Encode(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode data: %v", err)
+	}
+
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+const (
+	StandardWebhooksSecretPrefix     = "whsec_"
+	StandardWebhooksPublicKeyPrefix  = "whpk_"
+	StandardWebhooksPrivateKeyPrefix = "whsk_"
+)
+
+// StandardWebhooksSignature is a payload signed as the Standard Webhooks
+// spec describes, https://www.standardwebhooks.com. Its fields are sent as
+// the webhook-id, webhook-timestamp and webhook-signature headers.
+type StandardWebhooksSignature struct {
+	ID          string
+	Timestamp   string
+	Signature   string
+	EncodedData []byte
+}
+
+// GenerateStandardWebhooksSignature signs "<id>.<timestamp>.<payload>" with
+// HMAC-SHA256 into a v1 signature for each of secrets, separated by spaces. A
+// secret with the whsec_ prefix is the base64 encoding of the key, any other
+// secret is used as the key as it is, so libraries verify it with whsec_
+// followed by the base64 encoded secret.
+func GenerateStandardWebhooksSignature(id string, secrets []string, data json.RawMessage) (*StandardWebhooksSignature, error) {
+	keys := make([][]byte, 0, len(secrets))
+	for _, secret := range secrets {
+		key := []byte(secret)
+		if strings.HasPrefix(secret, StandardWebhooksSecretPrefix) {
+			k, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, StandardWebhooksSecretPrefix))
+			if err != nil {
+				return nil, fmt.Errorf("invalid standard webhooks secret: %v", err)
+			}
+			key = k
+		}
+
+		keys = append(keys, key)
+	}
+
+	sig, err := newStandardWebhooksSignature(id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sig.signHMAC(keys)
+	return sig, nil
+}
+
+// GenerateEd25519Signature signs "<id>.<timestamp>.<payload>" with an
+// Ed25519 private key, whsk_ followed by the base64 encoded key, into a v1a
+// signature.
+func GenerateEd25519Signature(id, privateKey string, data json.RawMessage) (*StandardWebhooksSignature, error) {
+	k, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(privateKey, StandardWebhooksPrivateKeyPrefix))
+	if err != nil || len(k) != ed25519.PrivateKeySize {
+		return nil, errors.New("invalid ed25519 private key")
+	}
+
+	sig, err := newStandardWebhooksSignature(id, data)
+	if err != nil {
+		return nil, err
+	}
+
+	sig.signEd25519(ed25519.PrivateKey(k))
+	return sig, nil
+}
+
+// GenerateEd25519KeyPair returns a new key pair as whpk_ and whsk_ followed
+// by the base64 encoded keys.
+func GenerateEd25519KeyPair() (publicKey string, privateKey string, err error) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return "", "", err
+	}
+
+	return StandardWebhooksPublicKeyPrefix + base64.StdEncoding.EncodeToString(pub),
+		StandardWebhooksPrivateKeyPrefix + base64.StdEncoding.EncodeToString(priv), nil
+}
+
+func newStandardWebhooksSignature(id string, data json.RawMessage) (*StandardWebhooksSignature, error) {
+	payload, err := encodePayload(data)
+	if err != nil {
+		return nil, err
+	}
+
+	return &StandardWebhooksSignature{
+		ID:          id,
+		Timestamp:   fmt.Sprint(time.Now().Unix()),
+		EncodedData: payload,
+	}, nil
+}
+
+func (s *StandardWebhooksSignature) signHMAC(keys [][]byte) {
+	content := s.signedContent()
+
+	signatures := make([]string, 0, len(keys))
+	for _, key := range keys {
+		h := hmac.New(sha256.New, key)
+		h.Write(content)
+		signatures = append(signatures, "v1,"+base64.StdEncoding.EncodeToString(h.Sum(nil)))
+	}
+
+	s.Signature = strings.Join(signatures, " ")
+}
+
+func (s *StandardWebhooksSignature) signEd25519(key ed25519.PrivateKey) {
+	s.Signature = "v1a," + base64.StdEncoding.EncodeToString(ed25519.Sign(key, s.signedContent()))
+}
+
+func (s *StandardWebhooksSignature) signedContent() []byte {
+	content := make([]byte, 0, len(s.ID)+len(s.Timestamp)+len(s.EncodedData)+2)
+	content = append(content, s.ID...)
+	content = append(content, '.')
+	content = append(content, s.Timestamp...)
+	content = append(content, '.')
+	return append(content, s.EncodedData...)
+}
+
 func getHashFunction(algorithm string) (func() hash.Hash, error) {
 	switch algorithm {
 	case algo.MD5:
@@ -1,6 +1,14 @@
 package util
 
-import "testing"
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+)
 
 func Test_computeJSONHmac(t *testing.T) {
 	type args struct {
@@ -268,3 +276,129 @@ func Test_computeJSONHmac_DifferentHashes(t *testing.T) {
 		})
 	}
 }
+
+func TestStandardWebhooksSignature_signHMAC(t *testing.T) {
+	// the test vector from the Standard Webhooks spec
+	secret := "whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw"
+	key, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, StandardWebhooksSecretPrefix))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sig := &StandardWebhooksSignature{
+		ID:          "msg_p5jXN8AQM9LWM0D4loKWxJek",
+		Timestamp:   "1614265330",
+		EncodedData: []byte(`{"test": 2432232314}`),
+	}
+	sig.signHMAC([][]byte{key})
+
+	if want := "v1,g0hM9SsE+OTPJTGt/tmIKtSyZlE3uFJELVlNIOLJ1OE="; sig.Signature != want {
+		t.Errorf("signHMAC() got = %v, want %v", sig.Signature, want)
+	}
+}
+
+func TestGenerateStandardWebhooksSignature(t *testing.T) {
+	for _, secret := range []string{"whsec_MfKQ9r8GKYqrTwjUPD8ILPZIo2LaLaSw", "my-long-secret"} {
+		sig, err := GenerateStandardWebhooksSignature("msg_1", []string{secret}, json.RawMessage(`{"a": 1, "b": "<html>"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(sig.EncodedData) != `{"a":1,"b":"<html>"}` {
+			t.Errorf("GenerateStandardWebhooksSignature() encoded data = %s", sig.EncodedData)
+		}
+
+		key := []byte(secret)
+		if strings.HasPrefix(secret, StandardWebhooksSecretPrefix) {
+			key, _ = base64.StdEncoding.DecodeString(strings.TrimPrefix(secret, StandardWebhooksSecretPrefix))
+		}
+
+		h := hmac.New(sha256.New, key)
+		h.Write([]byte("msg_1." + sig.Timestamp + "." + string(sig.EncodedData)))
+		if want := "v1," + base64.StdEncoding.EncodeToString(h.Sum(nil)); sig.Signature != want {
+			t.Errorf("GenerateStandardWebhooksSignature() got = %v, want %v", sig.Signature, want)
+		}
+	}
+
+	_, err := GenerateStandardWebhooksSignature("msg_1", []string{"whsec_not base64"}, json.RawMessage(`{}`))
+	if err == nil {
+		t.Error("GenerateStandardWebhooksSignature() expected an error for an invalid secret")
+	}
+}
+
+func TestGenerateStandardWebhooksSignature_rotatedSecrets(t *testing.T) {
+	sig, err := GenerateStandardWebhooksSignature("msg_1", []string{"new-secret", "old-secret"}, json.RawMessage(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signatures := strings.Split(sig.Signature, " ")
+	if len(signatures) != 2 {
+		t.Fatalf("GenerateStandardWebhooksSignature() got %d signatures, want 2", len(signatures))
+	}
+
+	for i, secret := range []string{"new-secret", "old-secret"} {
+		h := hmac.New(sha256.New, []byte(secret))
+		h.Write([]byte("msg_1." + sig.Timestamp + ".{}"))
+		if want := "v1," + base64.StdEncoding.EncodeToString(h.Sum(nil)); signatures[i] != want {
+			t.Errorf("GenerateStandardWebhooksSignature() signature %d = %v, want %v", i, signatures[i], want)
+		}
+	}
+}
+
+func TestGenerateSignatureHeader(t *testing.T) {
+	data := json.RawMessage(`{"name": "daniel"}`)
+
+	sig, err := GenerateSignatureHeader(false, "SHA256", []string{"new-secret", "old-secret"}, data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []string
+	for _, secret := range []string{"new-secret", "old-secret"} {
+		hmacStr, err := ComputeJSONHmac("SHA256", `{"name":"daniel"}`, secret, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want = append(want, hmacStr)
+	}
+
+	if got := strings.Join(want, ","); sig.Hmac != got {
+		t.Errorf("GenerateSignatureHeader() got = %v, want %v", sig.Hmac, got)
+	}
+
+	if sig.Timestamp != "" {
+		t.Errorf("GenerateSignatureHeader() timestamp = %v, want none", sig.Timestamp)
+	}
+}
+
+func TestGenerateEd25519Signature(t *testing.T) {
+	publicKey, privateKey, err := GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(publicKey, StandardWebhooksPublicKeyPrefix) || !strings.HasPrefix(privateKey, StandardWebhooksPrivateKeyPrefix) {
+		t.Fatalf("GenerateEd25519KeyPair() got = %v, %v", publicKey, privateKey)
+	}
+
+	sig, err := GenerateEd25519Signature("msg_1", privateKey, json.RawMessage(`{"a": 1}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(sig.Signature, "v1a,") {
+		t.Fatalf("GenerateEd25519Signature() got = %v", sig.Signature)
+	}
+
+	pub, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(publicKey, StandardWebhooksPublicKeyPrefix))
+	s, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(sig.Signature, "v1a,"))
+	if !ed25519.Verify(pub, []byte("msg_1."+sig.Timestamp+`.{"a":1}`), s) {
+		t.Error("GenerateEd25519Signature() signature does not verify with the public key")
+	}
+
+	_, err = GenerateEd25519Signature("msg_1", "whsk_short", json.RawMessage(`{}`))
+	if err == nil {
+		t.Error("GenerateEd25519Signature() expected an error for an invalid private key")
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strconv"
+	"strings"
 )
 
 func IsJSON(s string) bool {
@@ -56,3 +58,32 @@ func ReadJSON(r *http.Request, dst interface{}) error {
 
 	return nil
 }
+
+// JSONPath returns the value at path in v, a value decoded from json. The
+// path is a dot separated list of object keys and array indexes like
+// data.items.0.id, an empty path returns v itself.
+func JSONPath(v interface{}, path string) (interface{}, bool) {
+	if path == "" {
+		return v, true
+	}
+
+	for _, key := range strings.Split(path, ".") {
+		switch t := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = t[key]; !ok {
+				return nil, false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(t) {
+				return nil, false
+			}
+			v = t[i]
+		default:
+			return nil, false
+		}
+	}
+
+	return v, true
+}
@@ -0,0 +1,35 @@
+package util
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestJSONPath(t *testing.T) {
+	var v interface{}
+	err := json.Unmarshal([]byte(`{"data":{"items":[{"id":"evt_1"},{"id":"evt_2"}]},"next":null}`), &v)
+	require.NoError(t, err)
+
+	tt := []struct {
+		path  string
+		value interface{}
+		found bool
+	}{
+		{"data.items.1.id", "evt_2", true},
+		{"data.items.0", map[string]interface{}{"id": "evt_1"}, true},
+		{"next", nil, true},
+		{"", v, true},
+		{"data.items.2.id", nil, false},
+		{"data.items.id", nil, false},
+		{"data.count", nil, false},
+		{"data.items.0.id.value", nil, false},
+	}
+
+	for _, tc := range tt {
+		value, found := JSONPath(v, tc.path)
+		require.Equal(t, tc.found, found, tc.path)
+		require.Equal(t, tc.value, value, tc.path)
+	}
+}
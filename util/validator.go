@@ -41,6 +41,10 @@ func init() {
 		return datastore.SourceType(source).IsValid()
 	})
 
+	govalidator.TagMap["supported_pub_sub"] = govalidator.Validator(func(pubSub string) bool {
+		return datastore.PubSubType(pubSub).IsValid()
+	})
+
 	govalidator.TagMap["supported_verifier"] = govalidator.Validator(func(verifier string) bool {
 		verifiers := map[string]bool{
 			string(datastore.NoopVerifier):      true,
@@ -73,6 +77,7 @@ func init() {
 		encoders := map[string]bool{
 			string(datastore.LinearStrategyProvider):      true,
 			string(datastore.ExponentialStrategyProvider): true,
+			string(datastore.ScheduleStrategyProvider):    true,
 		}
 
 		if _, ok := encoders[encoder]; !ok {
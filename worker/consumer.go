@@ -5,6 +5,7 @@ import (
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/queue/memqueue"
 	"github.com/frain-dev/convoy/worker/task"
 	"github.com/hibiken/asynq"
 	log "github.com/sirupsen/logrus"
@@ -17,17 +18,17 @@ type Consumer struct {
 }
 
 func NewConsumer(q queue.Queuer) (*Consumer, error) {
+	// the in-memory queue processes its own jobs
+	if _, ok := q.(*memqueue.MemQueue); ok {
+		return &Consumer{queue: q}, nil
+	}
+
 	srv := asynq.NewServer(
 		q.Options().RedisClient,
 		asynq.Config{
-			Concurrency: convoy.Concurrency,
-			Queues:      q.Options().Names,
-			IsFailure: func(err error) bool {
-				if _, ok := err.(*task.RateLimitError); ok {
-					return false
-				}
-				return true
-			},
+			Concurrency:    convoy.Concurrency,
+			Queues:         q.Options().Names,
+			IsFailure:      isFailure,
 			RetryDelayFunc: task.GetRetryDelay,
 		},
 	)
@@ -42,16 +43,51 @@ func NewConsumer(q queue.Queuer) (*Consumer, error) {
 }
 
 func (c *Consumer) Start() {
+	if mq, ok := c.queue.(*memqueue.MemQueue); ok {
+		mq.Start(memqueue.Config{
+			Concurrency:    convoy.Concurrency,
+			IsFailure:      isFailure,
+			RetryDelayFunc: task.GetRetryDelay,
+		})
+		return
+	}
+
 	if err := c.srv.Start(c.mux); err != nil {
 		log.WithError(err).Fatal("error starting worker")
 	}
 }
 
 func (c *Consumer) RegisterHandlers(taskName convoy.TaskName, handler func(context.Context, *asynq.Task) error) {
+	if mq, ok := c.queue.(*memqueue.MemQueue); ok {
+		mq.Handle(taskName, handler)
+		return
+	}
+
 	c.mux.HandleFunc(string(taskName), handler)
 }
 
 func (c *Consumer) Stop() {
+	if mq, ok := c.queue.(*memqueue.MemQueue); ok {
+		mq.Stop()
+		return
+	}
+
 	c.srv.Stop()
 	c.srv.Shutdown()
 }
+
+func isFailure(err error) bool {
+	if _, ok := err.(*task.RateLimitError); ok {
+		return false
+	}
+	if _, ok := err.(*task.CircuitBreakerError); ok {
+		return false
+	}
+	if _, ok := err.(*task.OrderingError); ok {
+		return false
+	}
+	if _, ok := err.(*task.BatchError); ok {
+		return false
+	}
+	return true
+}
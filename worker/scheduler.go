@@ -3,16 +3,28 @@ package worker
 import (
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/queue/memqueue"
 	"github.com/hibiken/asynq"
+	"github.com/robfig/cron/v3"
 	log "github.com/sirupsen/logrus"
 )
 
 type Scheduler struct {
 	queue queue.Queuer
 	inner *asynq.Scheduler
+
+	// cron enqueues the periodic tasks when running on the in-memory queue
+	cron *cron.Cron
 }
 
 func NewScheduler(queue queue.Queuer) *Scheduler {
+	if _, ok := queue.(*memqueue.MemQueue); ok {
+		return &Scheduler{
+			cron:  cron.New(),
+			queue: queue,
+		}
+	}
+
 	scheduler := asynq.NewScheduler(queue.Options().RedisClient, nil)
 
 	return &Scheduler{
@@ -22,19 +34,42 @@ func NewScheduler(queue queue.Queuer) *Scheduler {
 }
 
 func (s *Scheduler) Start() {
+	if s.cron != nil {
+		s.cron.Start()
+		return
+	}
+
 	if err := s.inner.Start(); err != nil {
 		log.Fatal(err)
 	}
 }
 
-func (s *Scheduler) RegisterTask(cronspec string, queue convoy.QueueName, taskName convoy.TaskName) {
+func (s *Scheduler) RegisterTask(cronspec string, queueName convoy.QueueName, taskName convoy.TaskName) {
+	if s.cron != nil {
+		_, err := s.cron.AddFunc(cronspec, func() {
+			err := s.queue.Write(taskName, queueName, &queue.Job{})
+			if err != nil {
+				log.WithError(err).Errorf("Failed to enqueue %s scheduler task", taskName)
+			}
+		})
+		if err != nil {
+			log.WithError(err).Fatalf("Failed to register %s scheduler task", taskName)
+		}
+		return
+	}
+
 	task := asynq.NewTask(string(taskName), nil)
-	_, err := s.inner.Register(cronspec, task, asynq.Queue(string(queue)))
+	_, err := s.inner.Register(cronspec, task, asynq.Queue(string(queueName)))
 	if err != nil {
 		log.WithError(err).Fatalf("Failed to register %s scheduler task", taskName)
 	}
 }
 
 func (s *Scheduler) Stop() {
+	if s.cron != nil {
+		<-s.cron.Stop().Done()
+		return
+	}
+
 	s.inner.Shutdown()
 }
@@ -0,0 +1,126 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrBatchNotReady = errors.New("event delivery batch isn't ready to be sent")
+var ErrBatchInFlight = errors.New("event delivery is being sent in a batch")
+
+// batchPollDelay is how long a delivery that is being sent in another
+// delivery's batch waits before checking on it again
+const batchPollDelay = 10 * time.Second
+
+// batchStatuses are the statuses of the deliveries that can be sent in a batch
+var batchStatuses = []datastore.EventDeliveryStatus{
+	datastore.ScheduledEventStatus,
+	datastore.RetryEventStatus,
+}
+
+// collectBatch returns ed followed by the other deliveries of its subscription
+// that are due to be sent, oldest first, up to the batch's size and byte limits.
+func collectBatch(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, cfg *datastore.BatchConfiguration, ed *datastore.EventDelivery, now time.Time) ([]*datastore.EventDelivery, error) {
+	pending, err := eventDeliveryRepo.FindDueEventDeliveriesBySubscriptionID(ctx, ed.SubscriptionID, batchStatuses, cfg.MaxSize)
+	if err != nil {
+		return nil, err
+	}
+
+	batch := []*datastore.EventDelivery{ed}
+	size := len(ed.Metadata.Data)
+
+	for i := range pending {
+		d := &pending[i]
+		if len(batch) >= cfg.MaxSize {
+			break
+		}
+
+		if d.UID == ed.UID || d.Metadata == nil || d.Metadata.NextSendTime.Time().After(now) || isExpired(d, now) {
+			continue
+		}
+
+		if cfg.MaxBytes > 0 && size+len(d.Metadata.Data) > cfg.MaxBytes {
+			break
+		}
+
+		size += len(d.Metadata.Data)
+		batch = append(batch, d)
+	}
+
+	return batch, nil
+}
+
+// claimBatch sets the batch's deliveries to processing and returns the ones it
+// claimed, in the batch's order. A delivery that was claimed by a concurrent
+// batch in the meantime is left out, so it isn't sent twice.
+func claimBatch(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, batch []*datastore.EventDelivery) ([]*datastore.EventDelivery, error) {
+	ids, err := eventDeliveryRepo.ClaimEventDeliveries(ctx, deliveryIDs(batch), batchStatuses)
+	if err != nil {
+		return nil, err
+	}
+
+	claimed := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		claimed[id] = true
+	}
+
+	deliveries := make([]*datastore.EventDelivery, 0, len(ids))
+	for _, d := range batch {
+		if claimed[d.UID] {
+			deliveries = append(deliveries, d)
+		}
+	}
+
+	return deliveries, nil
+}
+
+// releaseDeliveries sets deliveries that were set to processing but couldn't
+// be sent back to retry, their tasks send them again.
+func releaseDeliveries(eventDeliveryRepo datastore.EventDeliveryRepository, deliveries []*datastore.EventDelivery) {
+	if len(deliveries) == 0 {
+		return
+	}
+
+	err := eventDeliveryRepo.UpdateStatusOfEventDeliveries(context.Background(), deliveryIDs(deliveries), datastore.RetryEventStatus)
+	if err != nil {
+		log.WithError(err).Error("failed to release event deliveries")
+	}
+}
+
+// batchDelay is how long ed waits before its batch of size deliveries is sent,
+// a new delivery waits up to the batch's max wait for the batch to fill up.
+func batchDelay(cfg *datastore.BatchConfiguration, ed *datastore.EventDelivery, size int, now time.Time) time.Duration {
+	if next := ed.Metadata.NextSendTime.Time(); next.After(now) {
+		return next.Sub(now)
+	}
+
+	if ed.Metadata.NumTrials > 0 || size >= cfg.MaxSize {
+		return 0
+	}
+
+	sendAt := ed.CreatedAt.Time().Add(time.Duration(cfg.MaxWait) * time.Second)
+	if sendAt.After(now) {
+		return sendAt.Sub(now)
+	}
+
+	return 0
+}
+
+// batchPayload joins the payloads of a batch's deliveries into a json array.
+func batchPayload(bodies []json.RawMessage) (json.RawMessage, error) {
+	return json.Marshal(bodies)
+}
+
+func deliveryIDs(deliveries []*datastore.EventDelivery) []string {
+	ids := make([]string, 0, len(deliveries))
+	for _, d := range deliveries {
+		ids = append(ids, d.UID)
+	}
+
+	return ids
+}
@@ -0,0 +1,191 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestCollectBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	now := time.Now()
+	delivery := func(uid, data string, next time.Time) datastore.EventDelivery {
+		return datastore.EventDelivery{
+			UID:            uid,
+			SubscriptionID: "sub-1",
+			Metadata: &datastore.Metadata{
+				Data:         []byte(data),
+				NextSendTime: primitive.NewDateTimeFromTime(next),
+			},
+		}
+	}
+
+	ed := delivery("delivery-2", `{"id":2}`, now)
+
+	tests := []struct {
+		name     string
+		cfg      *datastore.BatchConfiguration
+		pending  []datastore.EventDelivery
+		expected []string
+	}{
+		{
+			name: "should add due deliveries after ed",
+			cfg:  &datastore.BatchConfiguration{MaxSize: 10, MaxBytes: 1024},
+			pending: []datastore.EventDelivery{
+				delivery("delivery-1", `{"id":1}`, now.Add(-time.Second)),
+				ed,
+				delivery("delivery-3", `{"id":3}`, now),
+			},
+			expected: []string{"delivery-2", "delivery-1", "delivery-3"},
+		},
+		{
+			name: "should skip deliveries that aren't due",
+			cfg:  &datastore.BatchConfiguration{MaxSize: 10, MaxBytes: 1024},
+			pending: []datastore.EventDelivery{
+				delivery("delivery-1", `{"id":1}`, now.Add(time.Minute)),
+				delivery("delivery-3", `{"id":3}`, now),
+			},
+			expected: []string{"delivery-2", "delivery-3"},
+		},
+		{
+			name: "should skip expired deliveries",
+			cfg:  &datastore.BatchConfiguration{MaxSize: 10, MaxBytes: 1024},
+			pending: []datastore.EventDelivery{
+				func() datastore.EventDelivery {
+					d := delivery("delivery-1", `{"id":1}`, now.Add(-time.Second))
+					d.ExpiresAt = primitive.NewDateTimeFromTime(now.Add(-time.Millisecond))
+					return d
+				}(),
+				delivery("delivery-3", `{"id":3}`, now),
+			},
+			expected: []string{"delivery-2", "delivery-3"},
+		},
+		{
+			name: "should stop at the max size",
+			cfg:  &datastore.BatchConfiguration{MaxSize: 2, MaxBytes: 1024},
+			pending: []datastore.EventDelivery{
+				delivery("delivery-1", `{"id":1}`, now),
+				delivery("delivery-3", `{"id":3}`, now),
+			},
+			expected: []string{"delivery-2", "delivery-1"},
+		},
+		{
+			name: "should stop at the max bytes",
+			cfg:  &datastore.BatchConfiguration{MaxSize: 10, MaxBytes: 20},
+			pending: []datastore.EventDelivery{
+				delivery("delivery-1", `{"id":1}`, now),
+				delivery("delivery-3", `{"id":3}`, now),
+			},
+			expected: []string{"delivery-2", "delivery-1"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			repo := mocks.NewMockEventDeliveryRepository(ctrl)
+			repo.EXPECT().
+				FindDueEventDeliveriesBySubscriptionID(gomock.Any(), "sub-1", gomock.Any(), tc.cfg.MaxSize).
+				Return(tc.pending, nil)
+
+			batch, err := collectBatch(context.Background(), repo, tc.cfg, &ed, now)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, deliveryIDs(batch))
+		})
+	}
+}
+
+func TestClaimBatch(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	batch := []*datastore.EventDelivery{{UID: "delivery-1"}, {UID: "delivery-2"}, {UID: "delivery-3"}}
+
+	repo := mocks.NewMockEventDeliveryRepository(ctrl)
+	repo.EXPECT().
+		ClaimEventDeliveries(gomock.Any(), []string{"delivery-1", "delivery-2", "delivery-3"}, batchStatuses).
+		Return([]string{"delivery-3", "delivery-1"}, nil)
+
+	// delivery-2 was claimed by a concurrent batch
+	claimed, err := claimBatch(context.Background(), repo, batch)
+	require.NoError(t, err)
+	require.Equal(t, []string{"delivery-1", "delivery-3"}, deliveryIDs(claimed))
+}
+
+func TestBatchDelay(t *testing.T) {
+	now := time.Now()
+	cfg := &datastore.BatchConfiguration{MaxSize: 10, MaxWait: 60}
+
+	tests := []struct {
+		name      string
+		createdAt time.Time
+		next      time.Time
+		numTrials uint64
+		size      int
+		expected  time.Duration
+	}{
+		{
+			name:      "should wait for the batch to fill up",
+			createdAt: now.Add(-20 * time.Second),
+			next:      now,
+			size:      1,
+			expected:  40 * time.Second,
+		},
+		{
+			name:      "should send a full batch",
+			createdAt: now,
+			next:      now,
+			size:      10,
+		},
+		{
+			name:      "should send once the max wait has passed",
+			createdAt: now.Add(-time.Minute),
+			next:      now,
+			size:      1,
+		},
+		{
+			name:      "should not wait to retry",
+			createdAt: now,
+			next:      now,
+			numTrials: 1,
+			size:      1,
+		},
+		{
+			name:      "should wait for the next retry",
+			createdAt: now.Add(-time.Hour),
+			next:      now.Add(time.Minute),
+			numTrials: 1,
+			size:      10,
+			expected:  time.Minute,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ed := &datastore.EventDelivery{
+				Metadata: &datastore.Metadata{
+					NumTrials:    tc.numTrials,
+					NextSendTime: primitive.NewDateTimeFromTime(tc.next),
+				},
+				CreatedAt: primitive.NewDateTimeFromTime(tc.createdAt),
+			}
+
+			// primitive.DateTime keeps milliseconds
+			require.InDelta(t, tc.expected, batchDelay(cfg, ed, tc.size, now), float64(time.Millisecond))
+		})
+	}
+}
+
+func TestBatchPayload(t *testing.T) {
+	payload, err := batchPayload([]json.RawMessage{[]byte(`{"id": 1}`), []byte(`{"id":2}`)})
+	require.NoError(t, err)
+	require.Equal(t, `[{"id":1},{"id":2}]`, string(payload))
+}
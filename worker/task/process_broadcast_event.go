@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	log "github.com/sirupsen/logrus"
+)
+
+// broadcastPageSize is how many of the group's subscriptions are loaded at
+// a time while a broadcast event is fanned out.
+const broadcastPageSize = 500
+
+// ProcessBroadcastEvent sends a broadcast event to every application in its
+// group with an active subscription to the event type. The event is created
+// once, its deliveries go on the broadcast queue spread out by the event's
+// rate limit. The task can be retried, the event and deliveries it already
+// created aren't created again.
+func ProcessBroadcastEvent(appRepo datastore.ApplicationRepository, eventRepo datastore.EventRepository, groupRepo datastore.GroupRepository, eventDeliveryRepo datastore.EventDeliveryRepository, cache cache.Cache, eventQueue queue.Queuer, subRepo datastore.SubscriptionRepository, deviceRepo datastore.DeviceRepository) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		var event datastore.Event
+		err := json.Unmarshal(t.Payload(), &event)
+		if err != nil {
+			return &EndpointError{Err: err, delay: defaultDelay}
+		}
+		event.DocumentStatus = datastore.ActiveDocumentStatus
+
+		group, err := fetchGroup(ctx, cache, groupRepo, event.GroupID)
+		if err != nil {
+			return &EndpointError{Err: err, delay: 10 * time.Second}
+		}
+
+		if group.Type != datastore.OutgoingGroup {
+			log.Errorf("event %s can't be broadcast in incoming group %s", event.UID, group.UID)
+			return nil
+		}
+
+		subscriptions, err := findBroadcastSubscriptions(ctx, subRepo, group.UID, string(event.EventType))
+		if err != nil {
+			log.WithError(err).Error("error fetching subscriptions for broadcast event")
+			return &EndpointError{Err: err, delay: 10 * time.Second}
+		}
+
+		subscriptions = matchSubscriptionsUsingFilter(&event, subscriptions)
+
+		event.MatchedEndpoints = len(subscriptions)
+
+		// the event exists when the task is retried or the event replayed
+		_, err = eventRepo.FindEventByID(ctx, event.UID)
+		if errors.Is(err, datastore.ErrEventNotFound) {
+			err = eventRepo.CreateEvent(ctx, &event)
+		}
+
+		if err != nil {
+			return &EndpointError{Err: err, delay: 10 * time.Second}
+		}
+
+		rateLimit := 0
+		if event.Broadcast != nil {
+			rateLimit = event.Broadcast.RateLimit
+		}
+
+		err = createEventDeliveries(ctx, appRepo, eventDeliveryRepo, eventQueue, deviceRepo, group, &event, subscriptions, convoy.BroadcastQueue, rateLimit, func(subscriptionID string) string {
+			return broadcastDeliveryID(&event, subscriptionID)
+		})
+		if err != nil {
+			return err
+		}
+
+		indexEvent(eventQueue, event.UID, t.Payload())
+
+		return nil
+	}
+}
+
+// broadcastDeliveryID returns the id of the broadcast event's delivery to a
+// subscription. It is the same every time the task runs for the event, a
+// replay gives the event a new UpdatedAt and so new deliveries.
+func broadcastDeliveryID(event *datastore.Event, subscriptionID string) string {
+	name := fmt.Sprintf("%s/%s/%d", event.UID, subscriptionID, event.UpdatedAt)
+	return uuid.NewSHA1(uuid.NameSpaceOID, []byte(name)).String()
+}
+
+// findBroadcastSubscriptions returns the group's active subscriptions that
+// match eventType, a page at a time.
+func findBroadcastSubscriptions(ctx context.Context, subRepo datastore.SubscriptionRepository, groupID, eventType string) ([]datastore.Subscription, error) {
+	var matched []datastore.Subscription
+	p := datastore.Pageable{Page: 1, PerPage: broadcastPageSize, Sort: -1}
+
+	for {
+		subs, pagination, err := subRepo.LoadSubscriptionsPaged(ctx, groupID, &datastore.FilterBy{GroupID: groupID}, p)
+		if err != nil {
+			return nil, err
+		}
+
+		active := make([]datastore.Subscription, 0, len(subs))
+		for _, s := range subs {
+			if s.Status == datastore.ActiveSubscriptionStatus {
+				active = append(active, s)
+			}
+		}
+
+		matched = append(matched, matchSubscriptions(eventType, active)...)
+
+		if pagination.Next <= int64(p.Page) || len(subs) == 0 {
+			return matched, nil
+		}
+		p.Page = int(pagination.Next)
+	}
+}
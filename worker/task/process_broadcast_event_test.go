@@ -0,0 +1,259 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestProcessBroadcastEvent(t *testing.T) {
+	group := &datastore.Group{
+		UID:  "group-id-1",
+		Type: datastore.OutgoingGroup,
+		Config: &datastore.GroupConfig{
+			Strategy: &datastore.StrategyConfiguration{
+				Type:       datastore.LinearStrategyProvider,
+				Duration:   10,
+				RetryCount: 3,
+			},
+		},
+	}
+
+	subscription := func(uid, appID, eventType string, status datastore.SubscriptionStatus) datastore.Subscription {
+		return datastore.Subscription{
+			UID:          uid,
+			AppID:        appID,
+			EndpointID:   "endpoint-" + uid,
+			Type:         datastore.SubscriptionTypeAPI,
+			Status:       status,
+			FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{eventType}},
+		}
+	}
+
+	retried := &datastore.Event{
+		UID:       uuid.NewString(),
+		EventType: "system.maintenance",
+		GroupID:   "group-id-1",
+		Data:      []byte(`{}`),
+		Broadcast: &datastore.BroadcastConfiguration{RateLimit: 2},
+		CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+		UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
+	}
+
+	tests := []struct {
+		name       string
+		event      *datastore.Event
+		dbFn       func(args *args)
+		wantErr    bool
+		wantErrMsg string
+	}{
+		{
+			name: "should_fan_out_to_every_subscribed_app",
+			event: &datastore.Event{
+				UID:       uuid.NewString(),
+				EventType: "system.maintenance",
+				GroupID:   "group-id-1",
+				Data:      []byte(`{}`),
+				Broadcast: &datastore.BroadcastConfiguration{RateLimit: 2},
+				CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+				UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
+			},
+			dbFn: func(args *args) {
+				mockCache, _ := args.cache.(*mocks.MockCache)
+				mockCache.EXPECT().Get(gomock.Any(), "groups:group-id-1", gomock.Any()).Times(1).
+					SetArg(2, group).Return(nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().LoadSubscriptionsPaged(gomock.Any(), "group-id-1", gomock.Any(), datastore.Pageable{Page: 1, PerPage: broadcastPageSize, Sort: -1}).
+					Times(1).Return([]datastore.Subscription{
+					subscription("1", "app-id-1", "system.*", datastore.ActiveSubscriptionStatus),
+					subscription("2", "app-id-2", "system.maintenance", datastore.InactiveSubscriptionStatus),
+					subscription("3", "app-id-3", "invoice.paid", datastore.ActiveSubscriptionStatus),
+				}, datastore.PaginationData{Page: 1, Next: 2}, nil)
+				s.EXPECT().LoadSubscriptionsPaged(gomock.Any(), "group-id-1", gomock.Any(), datastore.Pageable{Page: 2, PerPage: broadcastPageSize, Sort: -1}).
+					Times(1).Return([]datastore.Subscription{
+					subscription("4", "app-id-4", "system.maintenance", datastore.ActiveSubscriptionStatus),
+					subscription("5", "app-id-1", "*", datastore.ActiveSubscriptionStatus),
+				}, datastore.PaginationData{Page: 2, Next: 2}, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), gomock.Any()).Times(1).Return(nil, datastore.ErrEventNotFound)
+				e.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, event *datastore.Event) error {
+					require.Equal(t, 3, event.MatchedEndpoints)
+					return nil
+				})
+
+				a, _ := args.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").Times(1).Return(&datastore.Application{UID: "app-id-1"}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-4").Times(1).Return(&datastore.Application{UID: "app-id-4"}, nil)
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(3).
+					Return(&datastore.Endpoint{TargetURL: "https://google.com"}, nil)
+
+				var apps []string
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).Times(3).Return(nil, datastore.ErrEventDeliveryNotFound)
+				ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(3).DoAndReturn(func(_ context.Context, d *datastore.EventDelivery) error {
+					apps = append(apps, d.AppID)
+					require.Equal(t, datastore.ScheduledEventStatus, d.Status)
+					return nil
+				})
+
+				// the deliveries are spread out at two a second
+				var delays []time.Duration
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.BroadcastQueue, gomock.Any()).Times(3).DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+					delays = append(delays, job.Delay)
+					return nil
+				})
+
+				q.EXPECT().Write(convoy.IndexDocument, convoy.PriorityQueue, gomock.Any()).Times(1).DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, _ *queue.Job) error {
+					require.Equal(t, []string{"app-id-1", "app-id-4", "app-id-1"}, apps)
+					require.Equal(t, []time.Duration{time.Second, 1500 * time.Millisecond, 2 * time.Second}, delays)
+					return nil
+				})
+			},
+		},
+		{
+			name:  "should_not_create_the_event_or_its_deliveries_again_on_retry",
+			event: retried,
+			dbFn: func(args *args) {
+				mockCache, _ := args.cache.(*mocks.MockCache)
+				mockCache.EXPECT().Get(gomock.Any(), "groups:group-id-1", gomock.Any()).Times(1).
+					SetArg(2, group).Return(nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().LoadSubscriptionsPaged(gomock.Any(), "group-id-1", gomock.Any(), gomock.Any()).
+					Times(1).Return([]datastore.Subscription{
+					subscription("1", "app-id-1", "system.*", datastore.ActiveSubscriptionStatus),
+					subscription("4", "app-id-4", "system.maintenance", datastore.ActiveSubscriptionStatus),
+					subscription("5", "app-id-1", "*", datastore.ActiveSubscriptionStatus),
+				}, datastore.PaginationData{Page: 1, Next: 1}, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().FindEventByID(gomock.Any(), retried.UID).Times(1).Return(retried, nil)
+
+				// the first delivery was created but not sent, the second was
+				// sent and the last wasn't created
+				scheduled := &datastore.EventDelivery{
+					UID:      broadcastDeliveryID(retried, "1"),
+					Status:   datastore.ScheduledEventStatus,
+					Metadata: &datastore.Metadata{NextSendTime: primitive.NewDateTimeFromTime(time.Now().Add(-time.Minute))},
+				}
+				sent := &datastore.EventDelivery{UID: broadcastDeliveryID(retried, "4"), Status: datastore.SuccessEventStatus}
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), scheduled.UID).Times(1).Return(scheduled, nil)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), sent.UID).Times(1).Return(sent, nil)
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), broadcastDeliveryID(retried, "5")).Times(1).Return(nil, datastore.ErrEventDeliveryNotFound)
+				ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, d *datastore.EventDelivery) error {
+					require.Equal(t, broadcastDeliveryID(retried, "5"), d.UID)
+					return nil
+				})
+
+				a, _ := args.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").Times(1).Return(&datastore.Application{UID: "app-id-1"}, nil)
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).Times(1).
+					Return(&datastore.Endpoint{TargetURL: "https://google.com"}, nil)
+
+				var queued []string
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.BroadcastQueue, gomock.Any()).Times(2).DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+					queued = append(queued, job.ID)
+					return nil
+				})
+
+				q.EXPECT().Write(convoy.IndexDocument, convoy.PriorityQueue, gomock.Any()).Times(1).DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, _ *queue.Job) error {
+					require.Equal(t, []string{scheduled.UID, broadcastDeliveryID(retried, "5")}, queued)
+					return nil
+				})
+			},
+		},
+		{
+			name: "should_retry_when_subscriptions_can't_be_loaded",
+			event: &datastore.Event{
+				UID:       uuid.NewString(),
+				EventType: "system.maintenance",
+				GroupID:   "group-id-1",
+				Data:      []byte(`{}`),
+				Broadcast: &datastore.BroadcastConfiguration{RateLimit: 100},
+			},
+			dbFn: func(args *args) {
+				mockCache, _ := args.cache.(*mocks.MockCache)
+				mockCache.EXPECT().Get(gomock.Any(), "groups:group-id-1", gomock.Any()).Times(1).
+					SetArg(2, group).Return(nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				s.EXPECT().LoadSubscriptionsPaged(gomock.Any(), "group-id-1", gomock.Any(), gomock.Any()).
+					Times(1).Return(nil, datastore.PaginationData{}, datastore.ErrSubscriptionNotFound)
+			},
+			wantErr:    true,
+			wantErrMsg: datastore.ErrSubscriptionNotFound.Error(),
+		},
+		{
+			name: "should_not_broadcast_in_incoming_group",
+			event: &datastore.Event{
+				UID:       uuid.NewString(),
+				EventType: "system.maintenance",
+				GroupID:   "group-id-2",
+				Data:      []byte(`{}`),
+				Broadcast: &datastore.BroadcastConfiguration{RateLimit: 100},
+			},
+			dbFn: func(args *args) {
+				mockCache, _ := args.cache.(*mocks.MockCache)
+				mockCache.EXPECT().Get(gomock.Any(), "groups:group-id-2", gomock.Any()).Times(1).
+					SetArg(2, &datastore.Group{UID: "group-id-2", Type: datastore.IncomingGroup}).Return(nil)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			args := provideArgs(ctrl)
+
+			if tt.dbFn != nil {
+				tt.dbFn(args)
+			}
+
+			payload, err := json.Marshal(tt.event)
+			require.NoError(t, err)
+
+			task := asynq.NewTask(string(convoy.BroadcastProcessor), payload, asynq.Queue(string(convoy.BroadcastQueue)))
+
+			fn := ProcessBroadcastEvent(args.appRepo, args.eventRepo, args.groupRepo, args.eventDeliveryRepo, args.cache, args.eventQueue, args.subRepo, args.deviceRepo)
+			err = fn(context.Background(), task)
+			if tt.wantErr {
+				require.NotNil(t, err)
+				require.Equal(t, tt.wantErrMsg, err.(*EndpointError).Error())
+				return
+			}
+
+			require.Nil(t, err)
+		})
+	}
+}
+
+func TestBroadcastDeliveryID(t *testing.T) {
+	event := &datastore.Event{UID: "event-1", UpdatedAt: primitive.NewDateTimeFromTime(time.Now())}
+
+	id := broadcastDeliveryID(event, "subscription-1")
+	require.Equal(t, id, broadcastDeliveryID(event, "subscription-1"))
+	require.NotEqual(t, id, broadcastDeliveryID(event, "subscription-2"))
+
+	// a replay gets new deliveries
+	event.UpdatedAt = primitive.NewDateTimeFromTime(time.Now().Add(time.Second))
+	require.NotEqual(t, id, broadcastDeliveryID(event, "subscription-1"))
+}
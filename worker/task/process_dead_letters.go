@@ -1,7 +1,103 @@
 package task
 
-import "github.com/frain-dev/convoy/queue"
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
 
-func ProcessDeadLetters(job *queue.Job) {
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/util"
+	"github.com/hibiken/asynq"
+	log "github.com/sirupsen/logrus"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
 
+// ProcessDeadLetters records the event delivery in the task's payload as a
+// dead letter, along with the error and attempts it failed with.
+func ProcessDeadLetters(eventDeliveryRepo datastore.EventDeliveryRepository, deadLetterRepo datastore.DeadLetterRepository) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		id := string(t.Payload())
+
+		ed, err := eventDeliveryRepo.FindEventDeliveryByID(ctx, id)
+		if err != nil {
+			if errors.Is(err, datastore.ErrEventDeliveryNotFound) {
+				return nil
+			}
+
+			log.WithError(err).Errorf("failed to load event delivery %s", id)
+			return err
+		}
+
+		if ed.Status != datastore.FailureEventStatus {
+			// the delivery was retried before we got to it
+			return nil
+		}
+
+		// the task may run more than once for the same failure
+		n, err := deadLetterRepo.CountDeadLetters(ctx, &datastore.DeadLetterFilter{
+			GroupID:         ed.GroupID,
+			EventDeliveryID: ed.UID,
+			Status:          []datastore.DeadLetterStatus{datastore.PendingDeadLetterStatus},
+		})
+		if err != nil {
+			return err
+		}
+
+		if n > 0 {
+			return nil
+		}
+
+		err = deadLetterRepo.CreateDeadLetter(ctx, newDeadLetter(ed))
+		if err != nil {
+			log.WithError(err).Errorf("failed to dead letter event delivery %s", ed.UID)
+			return err
+		}
+
+		metrics.DeadLetters().WithLabelValues(ed.GroupID).Inc()
+		return nil
+	}
+}
+
+func newDeadLetter(ed *datastore.EventDelivery) *datastore.DeadLetter {
+	var lastError string
+	if n := len(ed.DeliveryAttempts); n > 0 {
+		last := ed.DeliveryAttempts[n-1]
+		lastError = last.Error
+		if util.IsStringEmpty(lastError) {
+			lastError = last.HttpResponseCode
+		}
+	}
+
+	return &datastore.DeadLetter{
+		GroupID:         ed.GroupID,
+		AppID:           ed.AppID,
+		EventID:         ed.EventID,
+		EventDeliveryID: ed.UID,
+		EndpointID:      ed.EndpointID,
+		SubscriptionID:  ed.SubscriptionID,
+		Reason:          ed.Description,
+		LastError:       lastError,
+		Attempts:        ed.DeliveryAttempts,
+		Status:          datastore.PendingDeadLetterStatus,
+		CreatedAt:       primitive.NewDateTimeFromTime(time.Now()),
+		UpdatedAt:       primitive.NewDateTimeFromTime(time.Now()),
+		DocumentStatus:  datastore.ActiveDocumentStatus,
+	}
+}
+
+// sendToDeadLetterQueue queues ed, which has failed for good, to be recorded
+// as a dead letter.
+func sendToDeadLetterQueue(q queue.Queuer, ed *datastore.EventDelivery) {
+	job := &queue.Job{
+		Payload: json.RawMessage(ed.UID),
+	}
+
+	err := q.Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, job)
+	if err != nil {
+		log.WithError(err).Errorf("failed to queue event delivery %s for the dead letter queue", ed.UID)
+	}
 }
@@ -0,0 +1,100 @@
+package task
+
+import (
+	"context"
+	"testing"
+
+	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/hibiken/asynq"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProcessDeadLetters(t *testing.T) {
+	tests := []struct {
+		name string
+		dbFn func(ed *mocks.MockEventDeliveryRepository, dl *mocks.MockDeadLetterRepository)
+	}{
+		{
+			name: "should_record_failed_event_delivery",
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, dl *mocks.MockDeadLetterRepository) {
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), "ed-1").Times(1).
+					Return(&datastore.EventDelivery{
+						UID:            "ed-1",
+						GroupID:        "group-1",
+						AppID:          "app-1",
+						EventID:        "event-1",
+						EndpointID:     "endpoint-1",
+						SubscriptionID: "sub-1",
+						Status:         datastore.FailureEventStatus,
+						Description:    "Retry limit exceeded",
+						DeliveryAttempts: []datastore.DeliveryAttempt{
+							{Error: "connection refused"},
+							{HttpResponseCode: "500 Internal Server Error"},
+						},
+					}, nil)
+
+				dl.EXPECT().CountDeadLetters(gomock.Any(), &datastore.DeadLetterFilter{
+					GroupID:         "group-1",
+					EventDeliveryID: "ed-1",
+					Status:          []datastore.DeadLetterStatus{datastore.PendingDeadLetterStatus},
+				}).Times(1).Return(int64(0), nil)
+
+				dl.EXPECT().CreateDeadLetter(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, d *datastore.DeadLetter) error {
+						require.Equal(t, "group-1", d.GroupID)
+						require.Equal(t, "app-1", d.AppID)
+						require.Equal(t, "ed-1", d.EventDeliveryID)
+						require.Equal(t, "sub-1", d.SubscriptionID)
+						require.Equal(t, "Retry limit exceeded", d.Reason)
+						require.Equal(t, "500 Internal Server Error", d.LastError)
+						require.Len(t, d.Attempts, 2)
+						require.Equal(t, datastore.PendingDeadLetterStatus, d.Status)
+						return nil
+					})
+			},
+		},
+		{
+			name: "should_skip_retried_event_delivery",
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, dl *mocks.MockDeadLetterRepository) {
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), "ed-1").Times(1).
+					Return(&datastore.EventDelivery{UID: "ed-1", Status: datastore.SuccessEventStatus}, nil)
+			},
+		},
+		{
+			name: "should_skip_event_delivery_already_dead_lettered",
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, dl *mocks.MockDeadLetterRepository) {
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), "ed-1").Times(1).
+					Return(&datastore.EventDelivery{UID: "ed-1", GroupID: "group-1", Status: datastore.FailureEventStatus}, nil)
+
+				dl.EXPECT().CountDeadLetters(gomock.Any(), gomock.Any()).Times(1).Return(int64(1), nil)
+			},
+		},
+		{
+			name: "should_skip_deleted_event_delivery",
+			dbFn: func(ed *mocks.MockEventDeliveryRepository, dl *mocks.MockDeadLetterRepository) {
+				ed.EXPECT().FindEventDeliveryByID(gomock.Any(), "ed-1").Times(1).
+					Return(nil, datastore.ErrEventDeliveryNotFound)
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			eventDeliveryRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			deadLetterRepo := mocks.NewMockDeadLetterRepository(ctrl)
+
+			tc.dbFn(eventDeliveryRepo, deadLetterRepo)
+
+			task := asynq.NewTask(string(convoy.DeadLetterProcessor), []byte("ed-1"), asynq.Queue(string(convoy.DefaultQueue)))
+
+			err := ProcessDeadLetters(eventDeliveryRepo, deadLetterRepo)(context.Background(), task)
+			require.NoError(t, err)
+		})
+	}
+}
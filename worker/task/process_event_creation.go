@@ -1,15 +1,18 @@
 package task
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/searcher"
+	"github.com/frain-dev/convoy/pkg/filter"
 	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/util"
@@ -29,27 +32,13 @@ func ProcessEventCreation(appRepo datastore.ApplicationRepository, eventRepo dat
 		}
 		event.DocumentStatus = datastore.ActiveDocumentStatus
 
-		var group *datastore.Group
 		var subscriptions []datastore.Subscription
 
-		groupCacheKey := convoy.GroupsCacheKey.Get(event.GroupID).String()
-		err = cache.Get(ctx, groupCacheKey, &group)
+		group, err := fetchGroup(ctx, cache, groupRepo, event.GroupID)
 		if err != nil {
 			return &EndpointError{Err: err, delay: 10 * time.Second}
 		}
 
-		if group == nil {
-			group, err = groupRepo.FetchGroupByID(ctx, event.GroupID)
-			if err != nil {
-				return &EndpointError{Err: err, delay: 10 * time.Second}
-			}
-
-			err = cache.Set(ctx, groupCacheKey, group, 10*time.Minute)
-			if err != nil {
-				return &EndpointError{Err: err, delay: 10 * time.Second}
-			}
-		}
-
 		if group.Type == datastore.OutgoingGroup {
 			var app *datastore.Application
 
@@ -86,123 +75,251 @@ func ProcessEventCreation(appRepo datastore.ApplicationRepository, eventRepo dat
 			}
 		}
 
+		subscriptions = matchSubscriptionsUsingFilter(&event, subscriptions)
+
 		event.MatchedEndpoints = len(subscriptions)
 		err = eventRepo.CreateEvent(ctx, &event)
 		if err != nil {
 			return &EndpointError{Err: err, delay: 10 * time.Second}
 		}
 
-		ec := &EventDeliveryConfig{group: group}
+		err = createEventDeliveries(ctx, appRepo, eventDeliveryRepo, eventQueue, deviceRepo, group, &event, subscriptions, convoy.EventQueue, 0, nil)
+		if err != nil {
+			return err
+		}
 
-		for _, s := range subscriptions {
-			ec.subscription = &s
-			headers := event.Headers
-			app, err := appRepo.FindApplicationByID(ctx, s.AppID)
-			if err != nil {
-				log.Errorf("Error fetching applcation %s", err)
-				return &EndpointError{Err: err, delay: 10 * time.Second}
-			}
+		indexEvent(eventQueue, event.UID, t.Payload())
+		return nil
+	}
+}
 
-			if s.Type == datastore.SubscriptionTypeAPI {
-				endpoint, err := appRepo.FindApplicationEndpointByID(ctx, app.UID, s.EndpointID)
-				if err != nil {
-					log.Errorf("Error fetching endpoint %s", err)
-					return &EndpointError{Err: err, delay: 10 * time.Second}
-				}
+// fetchGroup loads the group from the cache, or from the db when it isn't
+// cached.
+func fetchGroup(ctx context.Context, c cache.Cache, groupRepo datastore.GroupRepository, groupID string) (*datastore.Group, error) {
+	var group *datastore.Group
 
-				if endpoint.Authentication != nil && endpoint.Authentication.Type == datastore.APIKeyAuthentication {
-					headers = make(httpheader.HTTPHeader)
-					headers[endpoint.Authentication.ApiKey.HeaderName] = []string{endpoint.Authentication.ApiKey.HeaderValue}
-					headers.MergeHeaders(event.Headers)
-				}
+	groupCacheKey := convoy.GroupsCacheKey.Get(groupID).String()
+	err := c.Get(ctx, groupCacheKey, &group)
+	if err != nil {
+		return nil, err
+	}
 
-				s.Endpoint = endpoint
-			}
+	if group != nil {
+		return group, nil
+	}
 
-			rc, err := ec.retryConfig()
-			if err != nil {
-				return &EndpointError{Err: err, delay: 10 * time.Second}
+	group, err = groupRepo.FetchGroupByID(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
 
-			}
+	err = c.Set(ctx, groupCacheKey, group, 10*time.Minute)
+	if err != nil {
+		return nil, err
+	}
 
-			metadata := &datastore.Metadata{
-				NumTrials:       0,
-				RetryLimit:      rc.RetryCount,
-				Data:            event.Data,
-				IntervalSeconds: rc.Duration,
-				Strategy:        rc.Type,
-				NextSendTime:    primitive.NewDateTimeFromTime(time.Now()),
+	return group, nil
+}
+
+// createEventDeliveries creates the event's deliveries to subscriptions and
+// queues them on queueName. When rateLimit is set the deliveries are spread
+// out so no more than rateLimit of them are sent a second.
+//
+// When deliveryID is set it gives the id of the delivery to a subscription,
+// a delivery that already exists isn't created again so the task can be
+// retried. It is queued again if it hasn't been sent, the task could have
+// stopped before queueing it.
+func createEventDeliveries(ctx context.Context, appRepo datastore.ApplicationRepository, eventDeliveryRepo datastore.EventDeliveryRepository, eventQueue queue.Queuer, deviceRepo datastore.DeviceRepository, group *datastore.Group, event *datastore.Event, subscriptions []datastore.Subscription, queueName convoy.QueueName, rateLimit int, deliveryID func(subscriptionID string) string) error {
+	ec := &EventDeliveryConfig{group: group}
+
+	// scheduled events are held until they are due
+	sendAt := time.Now()
+	if deliverAt := event.DeliverAt.Time(); event.DeliverAt != 0 && deliverAt.After(sendAt) {
+		sendAt = deliverAt
+	}
+
+	// a broadcast has many subscriptions of the same app
+	apps := map[string]*datastore.Application{}
+	queued := 0
+
+	queueDelivery := func(eventDelivery *datastore.EventDelivery) {
+		delay := deliveryDelay(eventDelivery.Metadata.NextSendTime.Time())
+		if rateLimit > 0 {
+			delay += time.Duration(queued) * time.Second / time.Duration(rateLimit)
+		}
+		queued++
+
+		job := &queue.Job{
+			ID:      eventDelivery.UID,
+			Payload: json.RawMessage(eventDelivery.UID),
+			Delay:   delay,
+		}
+		err := eventQueue.Write(convoy.EventProcessor, queueName, job)
+		if err != nil {
+			log.Errorf("[asynq]: an error occurred sending event delivery to be dispatched %s", err)
+		}
+	}
+
+	for _, s := range subscriptions {
+		ec.subscription = &s
+		headers := event.Headers
+
+		id := uuid.New().String()
+		if deliveryID != nil {
+			id = deliveryID(s.UID)
+
+			existing, err := eventDeliveryRepo.FindEventDeliveryByID(ctx, id)
+			if err == nil {
+				if existing.Status == datastore.ScheduledEventStatus && s.Type != datastore.SubscriptionTypeCLI {
+					queueDelivery(existing)
+				}
+				continue
 			}
 
-			eventDelivery := &datastore.EventDelivery{UID: uuid.New().String(),
-				SubscriptionID: s.UID,
-				AppID:          app.UID,
-				Metadata:       metadata,
-				GroupID:        group.UID,
-				EventID:        event.UID,
-				EndpointID:     s.EndpointID,
-				DeviceID:       s.DeviceID,
-				Headers:        headers,
-
-				Status:           getEventDeliveryStatus(ctx, &s, app, deviceRepo),
-				DeliveryAttempts: []datastore.DeliveryAttempt{},
-				DocumentStatus:   datastore.ActiveDocumentStatus,
-				CreatedAt:        primitive.NewDateTimeFromTime(time.Now()),
-				UpdatedAt:        primitive.NewDateTimeFromTime(time.Now()),
+			if !errors.Is(err, datastore.ErrEventDeliveryNotFound) {
+				log.WithError(err).Error("error occurred fetching event delivery")
+				return &EndpointError{Err: err, delay: 10 * time.Second}
 			}
+		}
 
-			if s.Type == datastore.SubscriptionTypeCLI {
-				eventDelivery.CLIMetadata = &datastore.CLIMetadata{EventType: string(event.EventType)}
+		app, ok := apps[s.AppID]
+		if !ok {
+			var err error
+			app, err = appRepo.FindApplicationByID(ctx, s.AppID)
+			if err != nil {
+				log.Errorf("Error fetching applcation %s", err)
+				return &EndpointError{Err: err, delay: 10 * time.Second}
 			}
+			apps[s.AppID] = app
+		}
 
-			err = eventDeliveryRepo.CreateEventDelivery(ctx, eventDelivery)
+		if s.Type == datastore.SubscriptionTypeAPI {
+			endpoint, err := appRepo.FindApplicationEndpointByID(ctx, app.UID, s.EndpointID)
 			if err != nil {
-				log.WithError(err).Error("error occurred creating event delivery")
+				log.Errorf("Error fetching endpoint %s", err)
 				return &EndpointError{Err: err, delay: 10 * time.Second}
 			}
 
-			taskName := convoy.EventProcessor
+			if endpoint.Authentication != nil && endpoint.Authentication.Type == datastore.APIKeyAuthentication {
+				headers = make(httpheader.HTTPHeader)
+				headers[endpoint.Authentication.ApiKey.HeaderName] = []string{endpoint.Authentication.ApiKey.HeaderValue}
+				headers.MergeHeaders(event.Headers)
+			}
 
-			// This event delivery will be picked up by the convoy stream command(if it is currently running).
-			// Otherwise, it will be lost to the wind? workaround for this is to disable the subscriptions created
-			// while the command was running, however in that scenario the event deliveries will still be created,
-			// but will be in the datastore.DiscardedEventStatus status, we can also delete the subscription, that is a firmer solution
-			if eventDelivery.Status != datastore.DiscardedEventStatus && s.Type != datastore.SubscriptionTypeCLI {
-				payload := json.RawMessage(eventDelivery.UID)
+			s.Endpoint = endpoint
+		}
+
+		rc, err := ec.retryConfig()
+		if err != nil {
+			return &EndpointError{Err: err, delay: 10 * time.Second}
 
-				job := &queue.Job{
-					ID:      eventDelivery.UID,
-					Payload: payload,
-					Delay:   1 * time.Second,
-				}
-				err = eventQueue.Write(taskName, convoy.EventQueue, job)
-				if err != nil {
-					log.Errorf("[asynq]: an error occurred sending event delivery to be dispatched %s", err)
-				}
-			}
 		}
 
-		job := &queue.Job{
-			ID:      event.UID,
-			Payload: t.Payload(), // t.Payload() is the original event bytes
-			Delay:   5 * time.Second,
+		metadata := &datastore.Metadata{
+			NumTrials:       0,
+			RetryLimit:      rc.RetryCount,
+			Data:            event.Data,
+			IntervalSeconds: rc.Duration,
+			Strategy:        rc.Type,
+			NextSendTime:    primitive.NewDateTimeFromTime(sendAt),
+			Schedule:        rc.Schedule,
+			HonorRetryAfter: rc.HonorRetryAfter,
+			MaxRetryAfter:   rc.MaxRetryAfter,
+			MaxElapsedTime:  rc.MaxElapsedTime,
 		}
 
-		err = eventQueue.Write(convoy.IndexDocument, convoy.PriorityQueue, job)
+		eventDelivery := &datastore.EventDelivery{UID: id,
+			SubscriptionID: s.UID,
+			AppID:          app.UID,
+			Metadata:       metadata,
+			GroupID:        group.UID,
+			EventID:        event.UID,
+			EndpointID:     s.EndpointID,
+			DeviceID:       s.DeviceID,
+			Headers:        headers,
+
+			Status:           getEventDeliveryStatus(ctx, &s, app, deviceRepo),
+			DeliveryAttempts: []datastore.DeliveryAttempt{},
+			DocumentStatus:   datastore.ActiveDocumentStatus,
+			CreatedAt:        primitive.NewDateTimeFromTime(time.Now()),
+			UpdatedAt:        primitive.NewDateTimeFromTime(time.Now()),
+		}
+
+		if s.OrderingConfig != nil && s.OrderingConfig.Enabled {
+			eventDelivery.OrderingKey = orderingKey(s.OrderingConfig, event)
+		}
+
+		if ttl := deliveryTTL(event.TTL, s.TTL); ttl > 0 {
+			eventDelivery.ExpiresAt = primitive.NewDateTimeFromTime(sendAt.Add(ttl))
+		}
+
+		if s.Type == datastore.SubscriptionTypeCLI {
+			eventDelivery.CLIMetadata = &datastore.CLIMetadata{EventType: string(event.EventType)}
+		}
+
+		err = eventDeliveryRepo.CreateEventDelivery(ctx, eventDelivery)
 		if err != nil {
-			log.Errorf("[asynq]: an error occurred sending event to be indexed %s", err)
+			log.WithError(err).Error("error occurred creating event delivery")
+			return &EndpointError{Err: err, delay: 10 * time.Second}
 		}
 
-		return nil
+		// This event delivery will be picked up by the convoy stream command(if it is currently running).
+		// Otherwise, it will be lost to the wind? workaround for this is to disable the subscriptions created
+		// while the command was running, however in that scenario the event deliveries will still be created,
+		// but will be in the datastore.DiscardedEventStatus status, we can also delete the subscription, that is a firmer solution
+		if eventDelivery.Status != datastore.DiscardedEventStatus && s.Type != datastore.SubscriptionTypeCLI {
+			queueDelivery(eventDelivery)
+		}
 	}
+
+	return nil
 }
 
+// indexEvent queues the event to be indexed by the search backend, payload
+// is the event's json.
+func indexEvent(eventQueue queue.Queuer, eventID string, payload []byte) {
+	job := &queue.Job{
+		ID:      eventID,
+		Payload: payload,
+		Delay:   5 * time.Second,
+	}
+
+	err := eventQueue.Write(convoy.IndexDocument, convoy.PriorityQueue, job)
+	if err != nil {
+		log.Errorf("[asynq]: an error occurred sending event to be indexed %s", err)
+	}
+}
+
+// deliveryDelay returns how long a delivery sent at sendAt is queued for, new
+// deliveries wait at least a second.
+func deliveryDelay(sendAt time.Time) time.Duration {
+	if d := time.Until(sendAt); d > time.Second {
+		return d
+	}
+
+	return 1 * time.Second
+}
+
+// deliveryTTL returns the shorter of the event's and the subscription's TTL,
+// it is zero when neither of them has one.
+func deliveryTTL(eventTTL, subscriptionTTL uint64) time.Duration {
+	ttl := eventTTL
+	if ttl == 0 || (subscriptionTTL > 0 && subscriptionTTL < ttl) {
+		ttl = subscriptionTTL
+	}
+
+	return time.Duration(ttl) * time.Second
+}
+
+// matchSubscriptions returns the subscriptions with an event type pattern
+// that matches eventType, see filter.MatchEventType.
 func matchSubscriptions(eventType string, subscriptions []datastore.Subscription) []datastore.Subscription {
 	var matched []datastore.Subscription
 	for _, sub := range subscriptions {
 		for _, ev := range sub.FilterConfig.EventTypes {
-			if ev == eventType || ev == "*" { // if this event type matches, or is *, add the subscription to matched
+			if filter.MatchEventType(ev, eventType) {
 				matched = append(matched, sub)
+				break
 			}
 		}
 	}
@@ -210,6 +327,89 @@ func matchSubscriptions(eventType string, subscriptions []datastore.Subscription
 	return matched
 }
 
+// matchSubscriptionsUsingFilter returns the subscriptions whose body and
+// header filters match the event. A subscription with an invalid filter
+// matches nothing.
+func matchSubscriptionsUsingFilter(event *datastore.Event, subscriptions []datastore.Subscription) []datastore.Subscription {
+	var payload interface{}
+	if err := json.Unmarshal(event.Data, &payload); err != nil {
+		log.WithError(err).Errorf("event %s data is not valid json", event.UID)
+	}
+
+	var matched []datastore.Subscription
+	for _, sub := range subscriptions {
+		if sub.FilterConfig == nil {
+			matched = append(matched, sub)
+			continue
+		}
+
+		isMatched, err := filter.Match(payload, sub.FilterConfig.Filter.Body)
+		if err != nil {
+			log.WithError(err).Errorf("subscription %s has an invalid body filter", sub.UID)
+			continue
+		}
+
+		if !isMatched {
+			continue
+		}
+
+		isMatched, err = filter.MatchHeaders(event.Headers, sub.FilterConfig.Filter.Headers)
+		if err != nil {
+			log.WithError(err).Errorf("subscription %s has an invalid header filter", sub.UID)
+			continue
+		}
+
+		if isMatched {
+			matched = append(matched, sub)
+		}
+	}
+
+	return matched
+}
+
+// orderingKey returns the ordering key of the event's deliveries to an
+// ordered subscription, it is empty when the event doesn't have the key.
+func orderingKey(cfg *datastore.OrderingConfiguration, event *datastore.Event) string {
+	if !util.IsStringEmpty(cfg.KeyHeader) {
+		for k, v := range event.Headers {
+			if strings.EqualFold(k, cfg.KeyHeader) && len(v) > 0 {
+				return v[0]
+			}
+		}
+
+		return ""
+	}
+
+	if util.IsStringEmpty(cfg.KeyPath) {
+		return ""
+	}
+
+	// numbers are kept as they are, so large ids don't lose precision
+	var payload interface{}
+	d := json.NewDecoder(bytes.NewReader(event.Data))
+	d.UseNumber()
+	if err := d.Decode(&payload); err != nil {
+		return ""
+	}
+
+	v, ok := util.JSONPath(payload, cfg.KeyPath)
+	if !ok || v == nil {
+		return ""
+	}
+
+	if s, ok := v.(string); ok {
+		return s
+	}
+
+	// numbers, booleans and objects are keyed by their json
+	b, err := json.Marshal(v)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
 func getEventDeliveryStatus(ctx context.Context, subscription *datastore.Subscription, app *datastore.Application, deviceRepo datastore.DeviceRepository) datastore.EventDeliveryStatus {
 	if app.IsDisabled {
 		return datastore.DiscardedEventStatus
@@ -13,6 +13,7 @@ import (
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/pkg/searcher"
 	"github.com/frain-dev/convoy/mocks"
+	"github.com/frain-dev/convoy/pkg/httpheader"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -55,6 +56,8 @@ func provideArgs(ctrl *gomock.Controller) *args {
 }
 
 func TestProcessEventCreated(t *testing.T) {
+	deliverAt := time.Now().Add(2 * time.Hour)
+
 	tests := []struct {
 		name       string
 		event      *datastore.Event
@@ -142,6 +145,82 @@ func TestProcessEventCreated(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should_hold_scheduled_event_until_deliver_at",
+			event: &datastore.Event{
+				UID:       uuid.NewString(),
+				EventType: "*",
+				GroupID:   "group-id-1",
+				AppID:     "app-id-1",
+				Data:      []byte(`{}`),
+				DeliverAt: primitive.NewDateTimeFromTime(deliverAt),
+				TTL:       600,
+				CreatedAt: primitive.NewDateTimeFromTime(time.Now()),
+				UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
+			},
+			dbFn: func(args *args) {
+				mockCache, _ := args.cache.(*mocks.MockCache)
+				group := &datastore.Group{
+					UID:  "group-id-1",
+					Type: datastore.OutgoingGroup,
+					Config: &datastore.GroupConfig{
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   10,
+							RetryCount: 3,
+						},
+					},
+				}
+				mockCache.EXPECT().Get(gomock.Any(), "groups:group-id-1", gomock.Any()).Times(1).
+					SetArg(2, group).Return(nil)
+
+				app := &datastore.Application{UID: "app-id-1"}
+				mockCache.EXPECT().Get(gomock.Any(), "applications:app-id-1", gomock.Any()).Times(1).
+					SetArg(2, app).Return(nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				subscriptions := []datastore.Subscription{
+					{
+						UID:        "456",
+						AppID:      "app-id-1",
+						EndpointID: "098",
+						Type:       datastore.SubscriptionTypeAPI,
+						Status:     datastore.ActiveSubscriptionStatus,
+						FilterConfig: &datastore.FilterConfiguration{
+							EventTypes: []string{"*"},
+						},
+					},
+				}
+				s.EXPECT().FindSubscriptionsByAppID(gomock.Any(), "group-id-1", "app-id-1").Times(1).Return(subscriptions, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Times(1).Return(nil)
+
+				a, _ := args.appRepo.(*mocks.MockApplicationRepository)
+				a.EXPECT().FindApplicationByID(gomock.Any(), "app-id-1").Times(1).Return(app, nil)
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), "app-id-1", "098").
+					Times(1).Return(&datastore.Endpoint{UID: "098", TargetURL: "https://google.com"}, nil)
+
+				ed, _ := args.eventDeliveryRepo.(*mocks.MockEventDeliveryRepository)
+				ed.EXPECT().CreateEventDelivery(gomock.Any(), gomock.Any()).Times(1).
+					DoAndReturn(func(_ context.Context, delivery *datastore.EventDelivery) error {
+						require.Equal(t, datastore.ScheduledEventStatus, delivery.Status)
+						require.Equal(t, primitive.NewDateTimeFromTime(deliverAt), delivery.Metadata.NextSendTime)
+						require.Equal(t, primitive.NewDateTimeFromTime(deliverAt.Add(10*time.Minute)), delivery.ExpiresAt)
+						return nil
+					})
+
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.EventProcessor, convoy.EventQueue, gomock.Any()).Times(1).
+					DoAndReturn(func(_ convoy.TaskName, _ convoy.QueueName, job *queue.Job) error {
+						require.InDelta(t, float64(2*time.Hour), float64(job.Delay), float64(time.Minute))
+						return nil
+					})
+
+				q.EXPECT().Write(convoy.IndexDocument, convoy.PriorityQueue, gomock.Any()).Times(1).Return(nil)
+			},
+			wantErr: false,
+		},
 		{
 			name: "should_process_event_for_incoming_group",
 			event: &datastore.Event{
@@ -216,6 +295,59 @@ func TestProcessEventCreated(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "should_skip_subscriptions_whose_filter_does_not_match",
+			event: &datastore.Event{
+				UID:        uuid.NewString(),
+				EventType:  "payment.success",
+				ProviderID: uuid.NewString(),
+				SourceID:   "source-id-1",
+				GroupID:    "group-id-1",
+				AppID:      "app-id-1",
+				Data:       []byte(`{"data": {"amount": 500}}`),
+				CreatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+				UpdatedAt:  primitive.NewDateTimeFromTime(time.Now()),
+			},
+			dbFn: func(args *args) {
+				mockCache, _ := args.cache.(*mocks.MockCache)
+				var gr *datastore.Group
+				mockCache.EXPECT().Get(gomock.Any(), "groups:group-id-1", &gr).Times(1).Return(nil)
+
+				group := &datastore.Group{UID: "group-id-1", Type: datastore.IncomingGroup}
+
+				g, _ := args.groupRepo.(*mocks.MockGroupRepository)
+				g.EXPECT().FetchGroupByID(gomock.Any(), "group-id-1").Times(1).Return(group, nil)
+				mockCache.EXPECT().Set(gomock.Any(), "groups:group-id-1", group, 10*time.Minute).Times(1).Return(nil)
+
+				s, _ := args.subRepo.(*mocks.MockSubscriptionRepository)
+				subscriptions := []datastore.Subscription{
+					{
+						UID:        "456",
+						AppID:      "app-id-1",
+						EndpointID: "098",
+						Type:       datastore.SubscriptionTypeAPI,
+						Status:     datastore.ActiveSubscriptionStatus,
+						FilterConfig: &datastore.FilterConfiguration{
+							EventTypes: []string{"*"},
+							Filter: datastore.FilterSchema{
+								Body: datastore.FilterDocument{"data.amount": map[string]interface{}{"$gt": 1000}},
+							},
+						},
+					},
+				}
+				s.EXPECT().FindSubscriptionsBySourceIDs(gomock.Any(), "group-id-1", "source-id-1").Times(1).Return(subscriptions, nil)
+
+				e, _ := args.eventRepo.(*mocks.MockEventRepository)
+				e.EXPECT().CreateEvent(gomock.Any(), gomock.Any()).Times(1).DoAndReturn(func(_ context.Context, event *datastore.Event) error {
+					require.Equal(t, 0, event.MatchedEndpoints)
+					return nil
+				})
+
+				q, _ := args.eventQueue.(*mocks.MockQueuer)
+				q.EXPECT().Write(convoy.IndexDocument, convoy.PriorityQueue, gomock.Any()).Times(1).Return(nil)
+			},
+			wantErr: false,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -250,3 +382,113 @@ func TestProcessEventCreated(t *testing.T) {
 		})
 	}
 }
+
+func TestDeliveryTTL(t *testing.T) {
+	require.Equal(t, time.Duration(0), deliveryTTL(0, 0))
+	require.Equal(t, time.Minute, deliveryTTL(60, 0))
+	require.Equal(t, time.Hour, deliveryTTL(0, 3600))
+	require.Equal(t, time.Minute, deliveryTTL(60, 3600))
+	require.Equal(t, time.Minute, deliveryTTL(3600, 60))
+}
+
+func TestDeliveryDelay(t *testing.T) {
+	require.Equal(t, time.Second, deliveryDelay(time.Now()))
+	require.Equal(t, time.Second, deliveryDelay(time.Now().Add(-time.Hour)))
+	require.InDelta(t, float64(time.Hour), float64(deliveryDelay(time.Now().Add(time.Hour))), float64(time.Second))
+}
+
+func TestMatchSubscriptionsUsingFilter(t *testing.T) {
+	event := &datastore.Event{
+		Data:    []byte(`{"data": {"amount": 1500, "region": "eu"}}`),
+		Headers: httpheader.HTTPHeader{"X-Tenant": []string{"acme"}},
+	}
+
+	subscriptions := []datastore.Subscription{
+		{UID: "no-filter-config"},
+		{UID: "empty-filter", FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{"*"}}},
+		{
+			UID: "body-match",
+			FilterConfig: &datastore.FilterConfiguration{Filter: datastore.FilterSchema{
+				Body: datastore.FilterDocument{"data.region": map[string]interface{}{"$in": []interface{}{"eu"}}},
+			}},
+		},
+		{
+			UID: "body-mismatch",
+			FilterConfig: &datastore.FilterConfiguration{Filter: datastore.FilterSchema{
+				Body: datastore.FilterDocument{"data.amount": map[string]interface{}{"$lt": 1000}},
+			}},
+		},
+		{
+			UID: "header-match",
+			FilterConfig: &datastore.FilterConfiguration{Filter: datastore.FilterSchema{
+				Headers: datastore.FilterDocument{"x-tenant": "acme"},
+			}},
+		},
+		{
+			UID: "header-mismatch",
+			FilterConfig: &datastore.FilterConfiguration{Filter: datastore.FilterSchema{
+				Body:    datastore.FilterDocument{"data.region": "eu"},
+				Headers: datastore.FilterDocument{"x-tenant": "globex"},
+			}},
+		},
+		{
+			UID: "invalid-filter",
+			FilterConfig: &datastore.FilterConfiguration{Filter: datastore.FilterSchema{
+				Body: datastore.FilterDocument{"data.amount": map[string]interface{}{"$between": []interface{}{1, 2}}},
+			}},
+		},
+	}
+
+	var uids []string
+	for _, s := range matchSubscriptionsUsingFilter(event, subscriptions) {
+		uids = append(uids, s.UID)
+	}
+
+	require.Equal(t, []string{"no-filter-config", "empty-filter", "body-match", "header-match"}, uids)
+}
+
+func TestOrderingKey(t *testing.T) {
+	event := &datastore.Event{
+		Data:    []byte(`{"data": {"order_id": "ord_1", "customer_id": 90071992547409931, "items": [1, 2]}}`),
+		Headers: httpheader.HTTPHeader{"X-Tenant": []string{"acme"}},
+	}
+
+	tests := []struct {
+		cfg  datastore.OrderingConfiguration
+		want string
+	}{
+		{cfg: datastore.OrderingConfiguration{}, want: ""},
+		{cfg: datastore.OrderingConfiguration{KeyPath: "data.order_id"}, want: "ord_1"},
+		{cfg: datastore.OrderingConfiguration{KeyPath: "data.customer_id"}, want: "90071992547409931"},
+		{cfg: datastore.OrderingConfiguration{KeyPath: "data.items"}, want: "[1,2]"},
+		{cfg: datastore.OrderingConfiguration{KeyPath: "data.missing"}, want: ""},
+		{cfg: datastore.OrderingConfiguration{KeyHeader: "x-tenant"}, want: "acme"},
+		{cfg: datastore.OrderingConfiguration{KeyHeader: "X-Missing"}, want: ""},
+	}
+
+	for _, tc := range tests {
+		require.Equal(t, tc.want, orderingKey(&tc.cfg, event))
+	}
+}
+
+func TestMatchSubscriptions(t *testing.T) {
+	subscriptions := []datastore.Subscription{
+		{UID: "all", FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{"*"}}},
+		{UID: "exact", FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{"invoice.paid"}}},
+		{UID: "invoices", FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{"invoice.*", "invoice.paid"}}},
+		{UID: "created", FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{"*.created"}}},
+		{UID: "payments", FilterConfig: &datastore.FilterConfiguration{EventTypes: []string{"payment.**"}}},
+	}
+
+	uids := func(subs []datastore.Subscription) []string {
+		var ids []string
+		for _, s := range subs {
+			ids = append(ids, s.UID)
+		}
+		return ids
+	}
+
+	require.Equal(t, []string{"all", "exact", "invoices"}, uids(matchSubscriptions("invoice.paid", subscriptions)))
+	require.Equal(t, []string{"all", "created"}, uids(matchSubscriptions("user.created", subscriptions)))
+	require.Equal(t, []string{"all", "payments"}, uids(matchSubscriptions("payment.card.failed", subscriptions)))
+}
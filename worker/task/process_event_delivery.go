@@ -2,16 +2,27 @@ package task
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/frain-dev/convoy"
+	"github.com/frain-dev/convoy/cache"
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/datastore"
 	"github.com/frain-dev/convoy/internal/notifications"
+	"github.com/frain-dev/convoy/internal/pkg/circuitbreaker"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/internal/pkg/oauth2"
 	"github.com/frain-dev/convoy/limiter"
 	"github.com/frain-dev/convoy/net"
+	"github.com/frain-dev/convoy/pkg/httpheader"
+	"github.com/frain-dev/convoy/pkg/transform"
 	"github.com/frain-dev/convoy/queue"
 	"github.com/frain-dev/convoy/retrystrategies"
 	"github.com/frain-dev/convoy/util"
@@ -23,14 +34,26 @@ import (
 
 var ErrDeliveryAttemptFailed = errors.New("error sending event")
 var ErrRateLimit = errors.New("rate limit error")
+var ErrDeliveryOutOfOrder = errors.New("an earlier event delivery hasn't been sent")
 var defaultDelay time.Duration = 30
 
+// minOrderingDelay is the shortest time an ordered delivery waits for the
+// delivery ahead of it
+const minOrderingDelay = 5 * time.Second
+
+// processingGracePeriod is how long past its endpoint's timeout a delivery
+// can stay in processing before it is taken to be stuck
+const processingGracePeriod = time.Minute
+
 type SignatureValues struct {
 	HMAC      string
 	Timestamp string
 }
 
-func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDeliveryRepo datastore.EventDeliveryRepository, groupRepo datastore.GroupRepository, rateLimiter limiter.RateLimiter, subRepo datastore.SubscriptionRepository, notificationQueue queue.Queuer) func(context.Context, *asynq.Task) error {
+func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDeliveryRepo datastore.EventDeliveryRepository, groupRepo datastore.GroupRepository, rateLimiter limiter.RateLimiter, subRepo datastore.SubscriptionRepository, cache cache.Cache, notificationQueue queue.Queuer) func(context.Context, *asynq.Task) error {
+	// the deliveries share the pool's connections
+	dispatchers := &dispatcherPool{}
+
 	return func(ctx context.Context, t *asynq.Task) error {
 		Id := string(t.Payload())
 
@@ -64,12 +87,110 @@ func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDelivery
 			return &EndpointError{Err: err, delay: delayDuration}
 		}
 
+		batchConfig := subscription.BatchConfig
+		batched := batchConfig != nil && batchConfig.Enabled
+
 		switch ed.Status {
-		case datastore.ProcessingEventStatus,
-			datastore.SuccessEventStatus:
+		case datastore.ProcessingEventStatus:
+			timeout, err := endpointTimeout(endpoint)
+			stale := err == nil && isStaleProcessing(ed, timeout, time.Now())
+
+			if batched && !stale {
+				// the delivery is in another delivery's batch, its task stays
+				// around in case the batch has to be retried
+				return &BatchError{Err: ErrBatchInFlight, delay: batchPollDelay}
+			}
+
+			if !stale {
+				return nil
+			}
+
+			// the worker sending it stopped before recording the attempt
+			log.Warnf("event delivery %s has been processing for too long, sending it again", ed.UID)
+			if batched {
+				// only deliveries that are due can be claimed for a batch
+				err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, *ed, datastore.RetryEventStatus)
+				if err != nil {
+					log.WithError(err).Error("failed to update status of event delivery")
+					return &EndpointError{Err: err, delay: 10 * time.Second}
+				}
+				ed.Status = datastore.RetryEventStatus
+			}
+		case datastore.SuccessEventStatus, datastore.CancelledEventStatus, datastore.ExpiredEventStatus:
+			return nil
+		}
+
+		if isExpired(ed, time.Now()) {
+			log.Debugf("event delivery %s expired at %s", ed.UID, ed.ExpiresAt.Time())
+			err = eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, *ed, datastore.ExpiredEventStatus)
+			if err != nil {
+				log.WithError(err).Error("failed to mark event delivery as expired")
+				return &EndpointError{Err: err, delay: 10 * time.Second}
+			}
+
 			return nil
 		}
 
+		if subscription.OrderingConfig != nil && subscription.OrderingConfig.Enabled {
+			head, err := eventDeliveryRepo.FindFirstPendingEventDelivery(ctx, subscription.UID, ed.OrderingKey)
+			if err != nil && !errors.Is(err, datastore.ErrEventDeliveryNotFound) {
+				log.WithError(err).Error("failed to find first pending event delivery")
+				return &EndpointError{Err: err, delay: 10 * time.Second}
+			}
+
+			if head != nil && head.UID != ed.UID {
+				timeout, err := endpointTimeout(endpoint)
+				if err == nil && isStaleProcessing(head, timeout, time.Now()) {
+					requeueStaleDelivery(ctx, eventDeliveryRepo, notificationQueue, head)
+				}
+
+				log.Debugf("event delivery %s is waiting for event delivery %s", ed.UID, head.UID)
+				return &OrderingError{Err: ErrDeliveryOutOfOrder, delay: orderingDelay(head)}
+			}
+		}
+
+		batch := []*datastore.EventDelivery{ed}
+		if batched {
+			now := time.Now()
+			batch, err = collectBatch(ctx, eventDeliveryRepo, batchConfig, ed, now)
+			if err != nil {
+				log.WithError(err).Error("failed to collect event delivery batch")
+				return &EndpointError{Err: err, delay: 10 * time.Second}
+			}
+
+			if wait := batchDelay(batchConfig, ed, len(batch), now); wait > 0 {
+				log.Debugf("event delivery %s is waiting for its batch to fill up", ed.UID)
+				return &BatchError{Err: ErrBatchNotReady, delay: wait}
+			}
+		}
+
+		var secrets = endpoint.ActiveSecrets(time.Now())
+
+		// the dispatcher is built before the delivery is claimed, so a
+		// failure to build it leaves the delivery to be retried
+		cfg, err := config.Get()
+		if err != nil {
+			return &EndpointError{Err: err, delay: delayDuration}
+		}
+
+		httpDuration, err := endpointTimeout(endpoint)
+		if err != nil {
+			log.WithError(err).Errorf("failed to parse endpoint duration")
+			return nil
+		}
+
+		pool, err := dispatchers.get(cfg)
+		if err != nil {
+			log.WithError(err).Error("failed to load egress policy")
+			return &EndpointError{Err: err, delay: delayDuration}
+		}
+
+		dispatch, err := pool.Dispatcher(httpDuration, endpoint.TLSConfig)
+		if err != nil {
+			log.WithError(err).Errorf("failed to load tls config of endpoint %s", endpoint.UID)
+			return &EndpointError{Err: err, delay: delayDuration}
+		}
+
 		ec := &EventDeliveryConfig{subscription: subscription, group: g}
 		rlc := ec.rateLimitConfig()
 
@@ -91,60 +212,130 @@ func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDelivery
 			return nil
 		}
 
-		err = eventDeliveryRepo.UpdateStatusOfEventDelivery(context.Background(), *ed, datastore.ProcessingEventStatus)
-		if err != nil {
-			log.WithError(err).Error("failed to update status of messages - ")
-			return &EndpointError{Err: err, delay: delayDuration}
+		var done = true
+
+		e := endpoint
+		if ed.Status == datastore.SuccessEventStatus {
+			log.Debugf("endpoint %s already merged with message %s\n", e.TargetURL, ed.UID)
+			return nil
+		}
+
+		if subscription.Status == datastore.InactiveSubscriptionStatus {
+			log.Debugf("subscription %s is inactive, failing to send.", e.TargetURL)
+			return nil
 		}
 
-		var attempt datastore.DeliveryAttempt
-		var secret = endpoint.Secret
+		var breaker *circuitbreaker.Manager
+		breakerKey := circuitbreaker.Key(endpoint.UID)
+		if g.Config.CircuitBreaker != nil {
+			breaker = newCircuitBreaker(cache, g.Config.CircuitBreaker, g.UID, endpoint.UID)
 
-		cfg, err := config.Get()
-		if err != nil {
-			return &EndpointError{Err: err, delay: delayDuration}
+			// the breaker is asked last, a half open breaker's probe is
+			// claimed here and has to be sent
+			allowed, wait, err := breaker.Allow(ctx, breakerKey)
+			if err != nil {
+				// deliveries carry on without the breaker when the cache is down
+				log.WithError(err).Errorf("failed to load circuit breaker of endpoint %s", endpoint.UID)
+				breaker = nil
+			} else if !allowed {
+				log.Debugf("circuit breaker of endpoint %s is open, requeueing event delivery %s", endpoint.UID, ed.UID)
+
+				// the jitter spreads out the deliveries waiting on the breaker
+				jitter := time.Duration(rand.Int63n(int64(time.Second)))
+				return &CircuitBreakerError{Err: circuitbreaker.ErrOpen, delay: wait + jitter}
+			}
 		}
 
-		var httpDuration time.Duration
-		if util.IsStringEmpty(endpoint.HttpTimeout) {
-			httpDuration, err = time.ParseDuration(convoy.HTTP_TIMEOUT)
+		// deliveries are claimed right before they are sent, a failure to
+		// send them releases them so they don't stay in processing
+		if batched {
+			batch, err = claimBatch(ctx, eventDeliveryRepo, batch)
 			if err != nil {
-				log.WithError(err).Errorf("failed to parse endpoint duration")
-				return nil
+				log.WithError(err).Error("failed to claim event delivery batch")
+				return &EndpointError{Err: err, delay: delayDuration}
+			}
+
+			if len(batch) == 0 || batch[0] != ed {
+				// a concurrent batch claimed the delivery first
+				releaseDeliveries(eventDeliveryRepo, batch)
+				return &BatchError{Err: ErrBatchInFlight, delay: batchPollDelay}
 			}
 		} else {
-			httpDuration, err = time.ParseDuration(endpoint.HttpTimeout)
+			err = eventDeliveryRepo.UpdateStatusOfEventDelivery(context.Background(), *ed, datastore.ProcessingEventStatus)
 			if err != nil {
-				log.WithError(err).Errorf("failed to parse endpoint duration")
-				return nil
+				log.WithError(err).Error("failed to update status of messages - ")
+				return &EndpointError{Err: err, delay: delayDuration}
 			}
 		}
 
-		dispatch := net.NewDispatcher(httpDuration)
+		// the deliveries that are sent, along with their payloads
+		deliveries := make([]*datastore.EventDelivery, 0, len(batch))
+		bodies := make([]json.RawMessage, 0, len(batch))
+		var headers httpheader.HTTPHeader
 
-		var done = true
+		for _, d := range batch {
+			body, h := d.Metadata.Data, d.Headers
+			if subscription.TransformConfig != nil {
+				result, err := transformDelivery(ctx, subscription.TransformConfig, d)
+				if err != nil {
+					// the program fails the same way on every retry, so the
+					// delivery fails until the transform is fixed and it is retried
+					log.WithError(err).Errorf("subscription %s failed to transform event delivery %s", subscription.UID, d.UID)
+					err = eventDeliveryRepo.UpdateStatusOfEventDelivery(context.Background(), *d, datastore.FailureEventStatus)
+					if err != nil {
+						log.WithError(err).Error("failed to update status of event delivery")
+					} else {
+						sendToDeadLetterQueue(notificationQueue, d)
+					}
+					continue
+				}
 
-		e := endpoint
-		if ed.Status == datastore.SuccessEventStatus {
-			log.Debugf("endpoint %s already merged with message %s\n", e.TargetURL, ed.UID)
-			return nil
+				if result.Dropped {
+					log.Debugf("subscription %s transform dropped event delivery %s", subscription.UID, d.UID)
+					err = eventDeliveryRepo.UpdateStatusOfEventDelivery(context.Background(), *d, datastore.DiscardedEventStatus)
+					if err != nil {
+						log.WithError(err).Error("failed to update status of event delivery")
+					}
+					continue
+				}
+
+				body, h = result.Body, result.Headers
+			}
+
+			// a batch carries the headers of its first delivery
+			if len(deliveries) == 0 {
+				headers = h
+			}
+
+			deliveries = append(deliveries, d)
+			bodies = append(bodies, body)
 		}
 
-		if subscription.Status == datastore.InactiveSubscriptionStatus {
-			log.Debugf("subscription %s is inactive, failing to send.", e.TargetURL)
+		if len(deliveries) == 0 {
 			return nil
 		}
 
-		sig, err := util.GenerateSignatureHeader(g.Config.ReplayAttacks, g.Config.Signature.Hash, secret, ed.Metadata.Data)
+		body := bodies[0]
+		if batched {
+			body, err = batchPayload(bodies)
+			if err != nil {
+				log.WithError(err).Error("failed to build event delivery batch")
+				releaseDeliveries(eventDeliveryRepo, deliveries)
+				return &EndpointError{Err: err, delay: delayDuration}
+			}
+		}
+
+		payload, signatureHeaders, err := signPayload(g, secrets, deliveries[0].UID, body)
 		if err != nil {
-			log.Errorf("error occurred while generating hmac - %+v\n", err)
+			log.Errorf("error occurred while generating signature - %+v\n", err)
+			releaseDeliveries(eventDeliveryRepo, deliveries)
 			return &EndpointError{Err: err, delay: delayDuration}
 		}
 
-		attemptStatus := false
 		start := time.Now()
 
-		resp, err := dispatch.SendRequest(e.TargetURL, string(convoy.HttpPost), sig.EncodedData, g, sig.Hmac, sig.Timestamp, int64(cfg.MaxResponseSize), ed.Headers)
+		resp, err := sendWebhook(ctx, dispatch, cache, e, payload, signatureHeaders, int64(cfg.MaxResponseSize), headers)
+		blocked := errors.Is(err, net.ErrEgressDenied)
 		status := "-"
 		statusCode := 0
 		if resp != nil {
@@ -161,26 +352,16 @@ func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDelivery
 			"duration": duration,
 		})
 
-		if err == nil && statusCode >= 200 && statusCode <= 299 {
+		class := classifyAttempt(subscription.FailurePolicy, statusCode, err)
+		attemptStatus := class == datastore.SuccessAttempt
+		if attemptStatus {
 			requestLogger.Infof("%s", ed.UID)
 			log.Infof("%s sent", ed.UID)
-			attemptStatus = true
 			// e.Sent = true
-
-			ed.Status = datastore.SuccessEventStatus
-			ed.Description = ""
 		} else {
 			requestLogger.Errorf("%s", ed.UID)
 			done = false
 			// e.Sent = false
-
-			ed.Status = datastore.RetryEventStatus
-
-			nextTime := time.Now().Add(delayDuration)
-			ed.Metadata.NextSendTime = primitive.NewDateTimeFromTime(nextTime)
-			attempts := ed.Metadata.NumTrials + 1
-
-			log.Errorf("%s next retry time is %s (strategy = %s, delay = %d, attempts = %d/%d)\n", ed.UID, nextTime.Format(time.ANSIC), ed.Metadata.Strategy, ed.Metadata.IntervalSeconds, attempts, ed.Metadata.RetryLimit)
 		}
 
 		// Request failed but statusCode is 200 <= x <= 299
@@ -188,6 +369,13 @@ func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDelivery
 			log.Errorf("%s failed. Reason: %s", ed.UID, err)
 		}
 
+		if breaker != nil && !blocked {
+			_, err = breaker.Record(ctx, breakerKey, attemptStatus)
+			if err != nil {
+				log.WithError(err).Errorf("failed to update circuit breaker of endpoint %s", endpoint.UID)
+			}
+		}
+
 		if done && subscription.Status == datastore.PendingSubscriptionStatus && ec.disableEndpoint() {
 			subscriptionStatus := datastore.ActiveSubscriptionStatus
 			err := subRepo.UpdateSubscriptionStatus(context.Background(), g.UID, subscription.UID, subscriptionStatus)
@@ -210,50 +398,336 @@ func ProcessEventDelivery(appRepo datastore.ApplicationRepository, eventDelivery
 			}
 		}
 
-		attempt = parseAttemptFromResponse(ed, endpoint, resp, attemptStatus)
+		exhausted := false
+		for _, d := range deliveries {
+			attempt := parseAttemptFromResponse(d, endpoint, resp, attemptStatus)
+			attempt.Classification = class
 
-		ed.Metadata.NumTrials++
+			retryDelay, out := recordAttempt(d, resp, class, blocked)
+			if out {
+				exhausted = true
+			}
 
-		if ed.Metadata.NumTrials >= ed.Metadata.RetryLimit {
-			if done {
-				if ed.Status != datastore.SuccessEventStatus {
-					log.Errorln("an anomaly has occurred. retry limit exceeded, fan out is done but event status is not successful")
-					ed.Status = datastore.FailureEventStatus
-				}
-			} else {
-				log.Errorf("%s retry limit exceeded ", ed.UID)
-				ed.Description = "Retry limit exceeded"
-				ed.Status = datastore.FailureEventStatus
+			if d == ed {
+				delayDuration = retryDelay
 			}
 
-			if ec.disableEndpoint() && subscription.Status != datastore.PendingSubscriptionStatus {
-				subscriptionStatus := datastore.InactiveSubscriptionStatus
+			err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(context.Background(), *d, attempt)
+			if err != nil {
+				log.WithError(err).Error("failed to update message ", d.UID)
+			} else if d.Status == datastore.FailureEventStatus {
+				sendToDeadLetterQueue(notificationQueue, d)
+			}
+		}
 
-				err := subRepo.UpdateSubscriptionStatus(context.Background(), g.UID, subscription.UID, subscriptionStatus)
-				if err != nil {
-					log.WithError(err).Error("Failed to reactivate endpoint after successful retry")
-				}
+		// a permanent failure only disables the endpoint when the policy asks for it
+		permanent := class == datastore.PermanentFailureAttempt && !blocked &&
+			subscription.FailurePolicy != nil && subscription.FailurePolicy.DisableEndpoint
 
-				// send endpoint deactivation notification
-				err = notifications.SendEndpointNotification(context.Background(), app, endpoint, g, subscriptionStatus, notificationQueue, true)
-				if err != nil {
-					log.WithError(err).Error("failed to send notification")
-				}
+		if (exhausted && ec.disableEndpoint() || permanent) && subscription.Status != datastore.PendingSubscriptionStatus {
+			subscriptionStatus := datastore.InactiveSubscriptionStatus
+
+			err := subRepo.UpdateSubscriptionStatus(context.Background(), g.UID, subscription.UID, subscriptionStatus)
+			if err != nil {
+				log.WithError(err).Error("Failed to reactivate endpoint after successful retry")
+			}
+
+			// send endpoint deactivation notification
+			err = notifications.SendEndpointNotification(context.Background(), app, endpoint, g, subscriptionStatus, notificationQueue, true)
+			if err != nil {
+				log.WithError(err).Error("failed to send notification")
 			}
 		}
 
-		err = eventDeliveryRepo.UpdateEventDeliveryWithAttempt(context.Background(), *ed, attempt)
-		if err != nil {
-			log.WithError(err).Error("failed to update message ", ed.UID)
+		if deliveries[0] != ed {
+			// the transform took ed out of its batch
+			return nil
 		}
 
-		if !done && ed.Metadata.NumTrials < ed.Metadata.RetryLimit {
+		if ed.Status == datastore.RetryEventStatus {
 			return &EndpointError{Err: ErrDeliveryAttemptFailed, delay: delayDuration}
 		}
 
 		return nil
 	}
 }
+
+// recordAttempt updates ed's status and retry schedule with the outcome of an
+// attempt to send it that got resp and was classified as class. It returns how
+// long ed waits before its next retry, and whether ed has run out of retries.
+func recordAttempt(ed *datastore.EventDelivery, resp *net.Response, class datastore.AttemptClassification, blocked bool) (time.Duration, bool) {
+	sent := class == datastore.SuccessAttempt
+	var delayDuration time.Duration
+	elapsed := false
+
+	if sent {
+		ed.Status = datastore.SuccessEventStatus
+		ed.Description = ""
+	} else {
+		strategy := retrystrategies.NewRetryStrategyFromMetadata(*ed.Metadata)
+		delayDuration = strategy.NextDuration(ed.Metadata.NumTrials)
+		if resp != nil {
+			delayDuration = retrystrategies.NextDurationForResponse(strategy, ed.Metadata.NumTrials, resp.StatusCode, resp.ResponseHeader)
+		}
+
+		ed.Status = datastore.RetryEventStatus
+
+		nextTime := time.Now().Add(delayDuration)
+		elapsed = retrystrategies.ElapsedTimeExceeded(*ed.Metadata, ed.CreatedAt.Time(), nextTime)
+		ed.Metadata.NextSendTime = primitive.NewDateTimeFromTime(nextTime)
+		attempts := ed.Metadata.NumTrials + 1
+
+		log.Errorf("%s next retry time is %s (strategy = %s, delay = %s, attempts = %d/%d)\n", ed.UID, nextTime.Format(time.ANSIC), ed.Metadata.Strategy, delayDuration, attempts, ed.Metadata.RetryLimit)
+	}
+
+	ed.Metadata.NumTrials++
+
+	exhausted := ed.Metadata.NumTrials >= ed.Metadata.RetryLimit
+	if elapsed && !exhausted {
+		log.Errorf("%s max elapsed time exceeded ", ed.UID)
+		ed.Description = "Max elapsed time exceeded"
+		ed.Status = datastore.FailureEventStatus
+		exhausted = true
+	} else if exhausted {
+		if sent {
+			if ed.Status != datastore.SuccessEventStatus {
+				log.Errorln("an anomaly has occurred. retry limit exceeded, fan out is done but event status is not successful")
+				ed.Status = datastore.FailureEventStatus
+			}
+		} else {
+			log.Errorf("%s retry limit exceeded ", ed.UID)
+			ed.Description = "Retry limit exceeded"
+			ed.Status = datastore.FailureEventStatus
+		}
+	}
+
+	if class == datastore.PermanentFailureAttempt && !blocked {
+		log.Errorf("%s failed permanently ", ed.UID)
+		ed.Description = "Permanent failure"
+		ed.Status = datastore.FailureEventStatus
+	}
+
+	if blocked {
+		// the egress policy blocks the endpoint, retrying won't change that
+		ed.Status = datastore.FailureEventStatus
+		ed.Description = "Blocked by egress policy"
+	}
+
+	return delayDuration, exhausted
+}
+
+// isExpired reports whether ed's TTL has passed by now.
+func isExpired(ed *datastore.EventDelivery, now time.Time) bool {
+	return ed.ExpiresAt != 0 && !now.Before(ed.ExpiresAt.Time())
+}
+
+// endpointTimeout is how long a request to the endpoint can take.
+func endpointTimeout(endpoint *datastore.Endpoint) (time.Duration, error) {
+	if util.IsStringEmpty(endpoint.HttpTimeout) {
+		return time.ParseDuration(convoy.HTTP_TIMEOUT)
+	}
+
+	return time.ParseDuration(endpoint.HttpTimeout)
+}
+
+// isStaleProcessing reports whether ed has been processing for longer than a
+// request with the given timeout can take, the worker that was sending it
+// stopped before it could record the attempt.
+func isStaleProcessing(ed *datastore.EventDelivery, timeout time.Duration, now time.Time) bool {
+	return ed.Status == datastore.ProcessingEventStatus &&
+		now.Sub(ed.UpdatedAt.Time()) > timeout+processingGracePeriod
+}
+
+// requeueStaleDelivery sets a stale delivery back to retry and queues it
+// again, so the ordered deliveries behind it aren't held up for good.
+func requeueStaleDelivery(ctx context.Context, eventDeliveryRepo datastore.EventDeliveryRepository, q queue.Queuer, ed *datastore.EventDelivery) {
+	log.Warnf("event delivery %s has been processing for too long, queueing it again", ed.UID)
+
+	err := eventDeliveryRepo.UpdateStatusOfEventDelivery(ctx, *ed, datastore.RetryEventStatus)
+	if err != nil {
+		log.WithError(err).Errorf("failed to update status of event delivery %s", ed.UID)
+		return
+	}
+
+	job := &queue.Job{
+		ID:      ed.UID,
+		Payload: json.RawMessage(ed.UID),
+	}
+
+	// the write fails when the delivery's task is still queued, it is sent
+	// when that task runs
+	err = q.Write(convoy.EventProcessor, convoy.EventQueue, job)
+	if err != nil {
+		log.WithError(err).Debugf("failed to queue event delivery %s", ed.UID)
+	}
+}
+
+// orderingDelay is how long an ordered delivery waits before checking again
+// whether the delivery ahead of it, head, has been sent.
+func orderingDelay(head *datastore.EventDelivery) time.Duration {
+	delay := minOrderingDelay
+	if head.Metadata != nil {
+		if d := time.Until(head.Metadata.NextSendTime.Time()); d > delay {
+			delay = d + time.Second
+		}
+	}
+
+	return delay
+}
+
+// newDispatcherPool is replaced in tests to stub the endpoints.
+var newDispatcherPool = net.NewDispatcherPool
+
+// dispatcherPool creates the dispatcher pool the first time it is needed,
+// once the config has been loaded.
+type dispatcherPool struct {
+	once sync.Once
+	pool *net.DispatcherPool
+	err  error
+}
+
+func (d *dispatcherPool) get(cfg config.Configuration) (*net.DispatcherPool, error) {
+	d.once.Do(func() {
+		policy, err := net.NewEgressPolicy(cfg.EgressPolicy)
+		if err != nil {
+			d.err = err
+			return
+		}
+
+		d.pool = newDispatcherPool(net.NewTransportConfig(cfg.Dispatcher), policy)
+	})
+
+	return d.pool, d.err
+}
+
+// sendWebhook sends the payload to the endpoint. Deliveries to an endpoint
+// that authenticates with oauth2 carry an access token, which is refreshed
+// and the payload sent again when the endpoint rejects it.
+func sendWebhook(ctx context.Context, dispatch *net.Dispatcher, c cache.Cache, endpoint *datastore.Endpoint, payload json.RawMessage, signatureHeaders httpheader.HTTPHeader, maxResponseSize int64, headers httpheader.HTTPHeader) (*net.Response, error) {
+	auth := endpoint.Authentication
+	if auth == nil || auth.Type != datastore.OAuth2Authentication || auth.OAuth2 == nil {
+		return dispatch.SendWebhook(ctx, endpoint.TargetURL, string(convoy.HttpPost), payload, signatureHeaders, maxResponseSize, headers)
+	}
+
+	// the token is requested with the dispatcher's client, which checks
+	// the addresses it connects to and redirects but not the first url
+	if err := dispatch.CheckURL(auth.OAuth2.TokenURL); err != nil {
+		return tokenFailure(endpoint, err)
+	}
+
+	tokens := oauth2.NewManager(c, dispatch.Client())
+
+	token, err := tokens.Token(ctx, endpoint.UID, auth.OAuth2)
+	if err != nil {
+		return tokenFailure(endpoint, fmt.Errorf("failed to fetch oauth2 token: %w", err))
+	}
+
+	resp, err := dispatch.SendWebhook(ctx, endpoint.TargetURL, string(convoy.HttpPost), payload, signatureHeaders, maxResponseSize, withAuthorization(headers, token))
+	if err == nil && resp.StatusCode == http.StatusUnauthorized {
+		log.Debugf("endpoint %s rejected its oauth2 token, refreshing it", endpoint.UID)
+
+		token, err = tokens.Refresh(ctx, endpoint.UID, auth.OAuth2)
+		if err != nil {
+			return tokenFailure(endpoint, fmt.Errorf("failed to refresh oauth2 token: %w", err))
+		}
+
+		resp, err = dispatch.SendWebhook(ctx, endpoint.TargetURL, string(convoy.HttpPost), payload, signatureHeaders, maxResponseSize, withAuthorization(headers, token))
+	}
+
+	// the token isn't stored with the delivery attempt
+	if resp != nil && resp.RequestHeader != nil {
+		resp.RequestHeader = resp.RequestHeader.Clone()
+		resp.RequestHeader.Set("Authorization", "[REDACTED]")
+	}
+
+	return resp, err
+}
+
+// tokenFailure returns the response recorded when the webhook couldn't be
+// sent because no oauth2 token was available for the endpoint.
+func tokenFailure(endpoint *datastore.Endpoint, err error) (*net.Response, error) {
+	u, _ := url.Parse(endpoint.TargetURL)
+	return &net.Response{URL: u, Method: string(convoy.HttpPost), Error: err.Error()}, err
+}
+
+// withAuthorization returns a copy of headers that carries token, it takes
+// precedence over an Authorization header the event was sent with.
+func withAuthorization(headers httpheader.HTTPHeader, token *oauth2.Token) httpheader.HTTPHeader {
+	h := httpheader.HTTPHeader{"Authorization": []string{token.Authorization()}}
+	h.MergeHeaders(headers)
+	return h
+}
+
+// newCircuitBreaker returns the breaker manager for an endpoint, its state
+// changes are reported in the endpoint's circuit breaker metrics.
+func newCircuitBreaker(c cache.Cache, cfg *datastore.CircuitBreakerConfiguration, groupID, endpointID string) *circuitbreaker.Manager {
+	m := circuitbreaker.NewManager(c, circuitbreaker.NewConfig(cfg))
+	m.OnStateChange = func(_ string, from, to circuitbreaker.State) {
+		log.Infof("circuit breaker of endpoint %s changed from %s to %s", endpointID, from, to)
+
+		// the metric is labelled by group, a series per endpoint would
+		// grow with every endpoint ever created
+		metrics.CircuitBreakerTransitions().WithLabelValues(groupID, string(from), string(to)).Inc()
+	}
+
+	return m
+}
+
+// signPayload signs the payload with the group's signature scheme and returns
+// it along with the headers that carry the signature. The event delivery's id
+// is the Standard Webhooks message id, so it stays the same across retries.
+// HMAC signatures are generated for each of the endpoint's active secrets.
+func signPayload(g *datastore.Group, secrets []string, id string, data json.RawMessage) ([]byte, httpheader.HTTPHeader, error) {
+	switch g.Config.Signature.Scheme {
+	case datastore.StandardWebhooksSignatureScheme, datastore.Ed25519SignatureScheme:
+		var sig *util.StandardWebhooksSignature
+		var err error
+		if g.Config.Signature.Scheme == datastore.Ed25519SignatureScheme {
+			sig, err = util.GenerateEd25519Signature(id, g.Config.Signature.PrivateKey, data)
+		} else {
+			sig, err = util.GenerateStandardWebhooksSignature(id, secrets, data)
+		}
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return sig.EncodedData, httpheader.HTTPHeader{
+			"webhook-id":        []string{sig.ID},
+			"webhook-timestamp": []string{sig.Timestamp},
+			"webhook-signature": []string{sig.Signature},
+		}, nil
+	}
+
+	signatureHeader := g.Config.Signature.Header.String()
+	if util.IsStringEmpty(signatureHeader) {
+		return nil, nil, errors.New("signature header is required")
+	}
+
+	sig, err := util.GenerateSignatureHeader(g.Config.ReplayAttacks, g.Config.Signature.Hash, secrets, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	headers := httpheader.HTTPHeader{signatureHeader: []string{sig.Hmac}}
+	if g.Config.ReplayAttacks {
+		headers["Convoy-Timestamp"] = []string{sig.Timestamp}
+	}
+
+	return sig.EncodedData, headers, nil
+}
+
+// transformDelivery runs the subscription's transform program on the event
+// delivery's payload and headers.
+func transformDelivery(ctx context.Context, cfg *datastore.TransformConfiguration, ed *datastore.EventDelivery) (*transform.Result, error) {
+	t, err := transform.Compile(cfg.Program)
+	if err != nil {
+		return nil, err
+	}
+
+	return t.Apply(ctx, ed.Metadata.Data, ed.Headers)
+}
+
 func parseAttemptFromResponse(m *datastore.EventDelivery, e *datastore.Endpoint, resp *net.Response, attemptStatus bool) datastore.DeliveryAttempt {
 
 	responseHeader := util.ConvertDefaultHeaderToCustomHeader(&resp.ResponseHeader)
@@ -287,9 +761,13 @@ type EventDeliveryConfig struct {
 }
 
 type RetryConfig struct {
-	Type       datastore.StrategyProvider
-	Duration   uint64
-	RetryCount uint64
+	Type            datastore.StrategyProvider
+	Duration        uint64
+	RetryCount      uint64
+	Schedule        []uint64
+	HonorRetryAfter bool
+	MaxRetryAfter   uint64
+	MaxElapsedTime  uint64
 }
 
 type RateLimitConfig struct {
@@ -312,10 +790,18 @@ func (ec *EventDeliveryConfig) retryConfig() (*RetryConfig, error) {
 		rc.Duration = ec.subscription.RetryConfig.Duration
 		rc.RetryCount = ec.subscription.RetryConfig.RetryCount
 		rc.Type = ec.subscription.RetryConfig.Type
+		rc.Schedule = ec.subscription.RetryConfig.Schedule
+		rc.HonorRetryAfter = ec.subscription.RetryConfig.HonorRetryAfter
+		rc.MaxRetryAfter = ec.subscription.RetryConfig.MaxRetryAfter
+		rc.MaxElapsedTime = ec.subscription.RetryConfig.MaxElapsedTime
 	} else {
 		rc.Duration = ec.group.Config.Strategy.Duration
 		rc.RetryCount = ec.group.Config.Strategy.RetryCount
 		rc.Type = ec.group.Config.Strategy.Type
+		rc.Schedule = ec.group.Config.Strategy.Schedule
+		rc.HonorRetryAfter = ec.group.Config.Strategy.HonorRetryAfter
+		rc.MaxRetryAfter = ec.group.Config.Strategy.MaxRetryAfter
+		rc.MaxElapsedTime = ec.group.Config.Strategy.MaxElapsedTime
 	}
 
 	return rc, nil
@@ -334,3 +820,47 @@ func (ec *EventDeliveryConfig) rateLimitConfig() *RateLimitConfig {
 
 	return rlc
 }
+
+// classifyAttempt classifies an attempt that got statusCode or failed with err
+// using the subscription's failure policy. Without a policy, only a 2xx
+// response is a success and every failure is retried.
+func classifyAttempt(policy *datastore.FailurePolicy, statusCode int, err error) datastore.AttemptClassification {
+	if err != nil {
+		if errors.Is(err, net.ErrEgressDenied) {
+			return datastore.PermanentFailureAttempt
+		}
+
+		if policy != nil && len(policy.RetryableNetworkErrors) > 0 {
+			kind := net.ClassifyError(err)
+			for _, e := range policy.RetryableNetworkErrors {
+				if e == kind {
+					return datastore.RetryableFailureAttempt
+				}
+			}
+
+			return datastore.PermanentFailureAttempt
+		}
+
+		return datastore.RetryableFailureAttempt
+	}
+
+	if policy != nil {
+		for _, code := range policy.PermanentStatusCodes {
+			if code == statusCode {
+				return datastore.PermanentFailureAttempt
+			}
+		}
+
+		for _, code := range policy.FailureStatusCodes {
+			if code == statusCode {
+				return datastore.RetryableFailureAttempt
+			}
+		}
+	}
+
+	if statusCode >= 200 && statusCode <= 299 {
+		return datastore.SuccessAttempt
+	}
+
+	return datastore.RetryableFailureAttempt
+}
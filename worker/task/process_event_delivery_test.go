@@ -2,24 +2,63 @@ package task
 
 import (
 	"context"
+	"crypto/ed25519"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	stdnet "net"
+	"net/http"
+	"os"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/auth/realm_chain"
+	mcache "github.com/frain-dev/convoy/cache/memory"
 	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/internal/pkg/circuitbreaker"
+	"github.com/frain-dev/convoy/internal/pkg/metrics"
+	"github.com/frain-dev/convoy/net"
 	"github.com/frain-dev/convoy/queue"
+	"github.com/frain-dev/convoy/util"
 	"github.com/go-redis/redis_rate/v9"
 	"github.com/hibiken/asynq"
 	"github.com/jarcoal/httpmock"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 
 	"github.com/frain-dev/convoy/config"
 	"github.com/frain-dev/convoy/mocks"
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+func TestMain(m *testing.M) {
+	// deliveries go through http.DefaultTransport, which httpmock replaces,
+	// unless an egress policy is configured since it checks real connections
+	newDispatcherPool = func(cfg net.TransportConfig, policy *net.EgressPolicy, opts ...net.PoolOption) *net.DispatcherPool {
+		if policy == nil {
+			opts = append(opts, net.WithTransport(defaultTransport{}))
+		}
+
+		return net.NewDispatcherPool(cfg, policy, opts...)
+	}
+
+	os.Exit(m.Run())
+}
+
+// defaultTransport sends requests through whichever transport is the
+// default when they're sent.
+type defaultTransport struct{}
+
+func (defaultTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	return http.DefaultTransport.RoundTrip(r)
+}
+
 func TestProcessEventDelivery(t *testing.T) {
 	tt := []struct {
 		name          string
@@ -58,6 +97,69 @@ func TestProcessEventDelivery(t *testing.T) {
 					}, nil).Times(1)
 			},
 		},
+		{
+			name:          "Scheduled event was cancelled",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any())
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{RetryConfig: &datastore.DefaultRetryConfig}, nil)
+
+				o.EXPECT().FetchGroupByID(gomock.Any(), gomock.Any()).Return(&datastore.Group{}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status: datastore.CancelledEventStatus,
+					}, nil).Times(1)
+			},
+		},
+		{
+			name:          "Event delivery expired",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any())
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{RetryConfig: &datastore.DefaultRetryConfig}, nil)
+
+				o.EXPECT().FetchGroupByID(gomock.Any(), gomock.Any()).Return(&datastore.Group{}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							NumTrials:       1,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						ExpiresAt: primitive.NewDateTimeFromTime(time.Now().Add(-time.Second)),
+						Status:    datastore.RetryEventStatus,
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ExpiredEventStatus).
+					Return(nil).Times(1)
+			},
+		},
 		{
 			name:          "Endpoint is inactive",
 			cfgPath:       "./testdata/Config/basic-convoy.json",
@@ -104,10 +206,6 @@ func TestProcessEventDelivery(t *testing.T) {
 					Allowed:   10,
 					Remaining: 10,
 				}, nil).Times(1)
-
-				m.EXPECT().
-					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any()).
-					Return(nil).Times(1)
 			},
 		},
 		{
@@ -194,6 +292,190 @@ func TestProcessEventDelivery(t *testing.T) {
 				}
 			},
 		},
+		{
+			name:          "Permanent failure disables subscription",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{
+						GroupID: "123",
+					}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						Status: datastore.ActiveSubscriptionStatus,
+						FailurePolicy: &datastore.FailurePolicy{
+							PermanentStatusCodes: []int{http.StatusGone},
+							DisableEndpoint:      true,
+						},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{
+						LogoURL: "",
+						Config: &datastore.GroupConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Hash:   "SHA256",
+							},
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 1,
+							},
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				s.EXPECT().
+					UpdateSubscriptionStatus(gomock.Any(), gomock.Any(), gomock.Any(), datastore.InactiveSubscriptionStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, ed datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+						require.Equal(t, datastore.FailureEventStatus, ed.Status)
+						require.Equal(t, datastore.PermanentFailureAttempt, attempt.Classification)
+						return nil
+					}).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
+
+				httpmock.RegisterResponder("POST", "https://google.com",
+					httpmock.NewStringResponder(410, ``))
+
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
+			},
+		},
+		{
+			name:          "Endpoint blocked by egress policy",
+			cfgPath:       "./testdata/Config/basic-convoy-egress-policy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "http://169.254.169.254/latest/meta-data",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{
+						GroupID: "123",
+					}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						Status: datastore.ActiveSubscriptionStatus,
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed"}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{
+						Config: &datastore.GroupConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Hash:   "SHA256",
+							},
+							Strategy: &datastore.StrategyConfiguration{
+								Type:       datastore.LinearStrategyProvider,
+								Duration:   60,
+								RetryCount: 1,
+							},
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(nil).Times(1)
+
+				// the delivery fails without being retried and the attempt
+				// records why
+				m.EXPECT().
+					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, ed datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+						assert.Equal(t, datastore.FailureEventStatus, ed.Status)
+						assert.Equal(t, "Blocked by egress policy", ed.Description)
+						assert.Equal(t, "blocked by egress policy: address 169.254.169.254 is in the blocked range 169.254.0.0/16", attempt.Error)
+						return nil
+					}).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
+			},
+		},
 		{
 			name:          "Max retries reached - do not disable subscription - failed",
 			cfgPath:       "./testdata/Config/basic-convoy.json",
@@ -266,6 +548,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -354,6 +640,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -438,6 +728,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -526,6 +820,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -610,6 +908,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -697,6 +999,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				m.EXPECT().
 					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -790,6 +1096,10 @@ func TestProcessEventDelivery(t *testing.T) {
 				q.EXPECT().
 					Write(convoy.NotificationProcessor, convoy.DefaultQueue, gomock.Any()).
 					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
 			},
 			nFn: func() func() {
 				httpmock.Activate()
@@ -896,15 +1206,717 @@ func TestProcessEventDelivery(t *testing.T) {
 				}
 			},
 		},
-	}
-
-	for _, tc := range tt {
-		t.Run(tc.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			defer ctrl.Finish()
-
-			groupRepo := mocks.NewMockGroupRepository(ctrl)
-			appRepo := mocks.NewMockApplicationRepository(ctrl)
+		{
+			name:          "Ordered delivery waits for an earlier delivery",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: &OrderingError{Err: ErrDeliveryOutOfOrder, delay: 5 * time.Second},
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:            "sub-1",
+						Status:         datastore.ActiveSubscriptionStatus,
+						OrderingConfig: &datastore.OrderingConfiguration{Enabled: true, KeyPath: "data.order_id"},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID:         "delivery-2",
+						OrderingKey: "ord_1",
+						Metadata: &datastore.Metadata{
+							Data:       []byte(`{"data": {"order_id": "ord_1"}}`),
+							RetryLimit: 3,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{Config: &datastore.GroupConfig{}}, nil).Times(1)
+
+				m.EXPECT().
+					FindFirstPendingEventDelivery(gomock.Any(), "sub-1", "ord_1").
+					Return(&datastore.EventDelivery{
+						UID:       "delivery-1",
+						Status:    datastore.ProcessingEventStatus,
+						UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
+					}, nil).Times(1)
+			},
+		},
+		{
+			name:          "Ordered delivery requeues a stale delivery ahead of it",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: &OrderingError{Err: ErrDeliveryOutOfOrder, delay: 5 * time.Second},
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:            "sub-1",
+						Status:         datastore.ActiveSubscriptionStatus,
+						OrderingConfig: &datastore.OrderingConfiguration{Enabled: true, KeyPath: "data.order_id"},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID:         "delivery-2",
+						OrderingKey: "ord_1",
+						Metadata: &datastore.Metadata{
+							Data:       []byte(`{"data": {"order_id": "ord_1"}}`),
+							RetryLimit: 3,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{Config: &datastore.GroupConfig{}}, nil).Times(1)
+
+				head := &datastore.EventDelivery{
+					UID:       "delivery-1",
+					Status:    datastore.ProcessingEventStatus,
+					UpdatedAt: primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour)),
+				}
+
+				m.EXPECT().
+					FindFirstPendingEventDelivery(gomock.Any(), "sub-1", "ord_1").
+					Return(head, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), *head, datastore.RetryEventStatus).
+					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.EventProcessor, convoy.EventQueue, &queue.Job{ID: "delivery-1", Payload: json.RawMessage("delivery-1")}).
+					Return(nil).Times(1)
+			},
+		},
+		{
+			name:          "Transform reshapes payload and headers",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						Status:          datastore.ActiveSubscriptionStatus,
+						TransformConfig: &datastore.TransformConfiguration{Program: `.body |= {id: .data.id} | .headers["X-Tenant"] = "acme"`},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed", "data": {"id": "inv_1"}}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{
+						Config: &datastore.GroupConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Hash:   "SHA256",
+							},
+							Strategy:  &datastore.DefaultStrategyConfig,
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, ed datastore.EventDelivery, _ datastore.DeliveryAttempt) error {
+						// the stored payload is left as it is, so retries transform it again
+						assert.JSONEq(t, `{"event": "invoice.completed", "data": {"id": "inv_1"}}`, string(ed.Metadata.Data))
+						assert.Equal(t, datastore.SuccessEventStatus, ed.Status)
+						return nil
+					}).Times(1)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
+
+				httpmock.RegisterResponder("POST", "https://google.com",
+					func(req *http.Request) (*http.Response, error) {
+						body, _ := ioutil.ReadAll(req.Body)
+						if string(body) != `{"id":"inv_1"}` || req.Header.Get("X-Tenant") != "acme" {
+							return httpmock.NewStringResponse(400, ``), nil
+						}
+						return httpmock.NewStringResponse(200, ``), nil
+					})
+
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
+			},
+		},
+		{
+			name:          "Transform drops delivery",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						Status:          datastore.ActiveSubscriptionStatus,
+						TransformConfig: &datastore.TransformConfiguration{Program: `null`},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed", "data": {"id": "inv_1"}}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{
+						Config: &datastore.GroupConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Hash:   "SHA256",
+							},
+							Strategy:  &datastore.DefaultStrategyConfig,
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.DiscardedEventStatus).
+					Return(nil).Times(1)
+			},
+		},
+		{
+			name:          "Transform fails",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						Status:          datastore.ActiveSubscriptionStatus,
+						TransformConfig: &datastore.TransformConfiguration{Program: `error("unsupported event")`},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"event": "invoice.completed", "data": {"id": "inv_1"}}`),
+							NumTrials:       0,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status: datastore.ScheduledEventStatus,
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{
+						Config: &datastore.GroupConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Hash:   "SHA256",
+							},
+							Strategy:  &datastore.DefaultStrategyConfig,
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.ProcessingEventStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.FailureEventStatus).
+					Return(nil).Times(1)
+
+				q.EXPECT().
+					Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).
+					Return(nil).Times(1)
+			},
+		},
+		{
+			name:          "Batched delivery is sent with the subscription's due deliveries",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: nil,
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:         "sub-1",
+						Status:      datastore.ActiveSubscriptionStatus,
+						BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 2, MaxWait: 60, MaxBytes: 1024},
+					}, nil)
+
+				now := primitive.NewDateTimeFromTime(time.Now())
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID:            "delivery-1",
+						SubscriptionID: "sub-1",
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"id":1}`),
+							NextSendTime:    now,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:    datastore.ScheduledEventStatus,
+						CreatedAt: now,
+					}, nil).Times(1)
+
+				m.EXPECT().
+					FindDueEventDeliveriesBySubscriptionID(gomock.Any(), "sub-1", gomock.Any(), 2).
+					Return([]datastore.EventDelivery{
+						{
+							UID:            "delivery-1",
+							SubscriptionID: "sub-1",
+						},
+						{
+							UID:            "delivery-2",
+							SubscriptionID: "sub-1",
+							Metadata: &datastore.Metadata{
+								Data:            []byte(`{"id":2}`),
+								NextSendTime:    now,
+								RetryLimit:      3,
+								IntervalSeconds: 20,
+							},
+							Status:    datastore.ScheduledEventStatus,
+							CreatedAt: now,
+						},
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{
+						Config: &datastore.GroupConfig{
+							Signature: &datastore.SignatureConfiguration{
+								Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+								Hash:   "SHA256",
+							},
+							Strategy:  &datastore.DefaultStrategyConfig,
+							RateLimit: &datastore.DefaultRateLimitConfig,
+						},
+					}, nil).Times(1)
+
+				m.EXPECT().
+					ClaimEventDeliveries(gomock.Any(), []string{"delivery-1", "delivery-2"}, batchStatuses).
+					Return([]string{"delivery-1", "delivery-2"}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(func(_ context.Context, ed datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+						assert.Equal(t, datastore.SuccessEventStatus, ed.Status)
+						assert.Equal(t, uint64(1), ed.Metadata.NumTrials)
+						assert.Equal(t, ed.UID, attempt.MsgID)
+						return nil
+					}).Times(2)
+			},
+			nFn: func() func() {
+				httpmock.Activate()
+
+				httpmock.RegisterResponder("POST", "https://google.com",
+					func(req *http.Request) (*http.Response, error) {
+						body, _ := ioutil.ReadAll(req.Body)
+						if string(body) != `[{"id":1},{"id":2}]` || req.Header.Get("X-Convoy-Signature") == "" {
+							return httpmock.NewStringResponse(400, ``), nil
+						}
+						return httpmock.NewStringResponse(200, ``), nil
+					})
+
+				return func() {
+					httpmock.DeactivateAndReset()
+				}
+			},
+		},
+		{
+			name:          "Batched delivery in another batch waits for it",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: &BatchError{Err: ErrBatchInFlight, delay: batchPollDelay},
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:         "sub-1",
+						Status:      datastore.ActiveSubscriptionStatus,
+						BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 2, MaxWait: 60},
+					}, nil)
+
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID: "delivery-2",
+						Metadata: &datastore.Metadata{
+							RetryLimit: 3,
+						},
+						Status:    datastore.ProcessingEventStatus,
+						UpdatedAt: primitive.NewDateTimeFromTime(time.Now()),
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{Config: &datastore.GroupConfig{}}, nil).Times(1)
+			},
+		},
+		{
+			name:          "Batched delivery stuck in processing is sent again",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: &RateLimitError{Err: ErrRateLimit, delay: 20 * time.Second},
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{TargetURL: "https://google.com"}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:         "sub-1",
+						Status:      datastore.ActiveSubscriptionStatus,
+						BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 2, MaxWait: 60},
+					}, nil)
+
+				hourAgo := primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour))
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID:            "delivery-2",
+						SubscriptionID: "sub-1",
+						Metadata: &datastore.Metadata{
+							NextSendTime:    hourAgo,
+							NumTrials:       1,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:    datastore.ProcessingEventStatus,
+						CreatedAt: hourAgo,
+						UpdatedAt: hourAgo,
+					}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{Config: &datastore.GroupConfig{
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					}}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), datastore.RetryEventStatus).
+					Return(nil).Times(1)
+
+				m.EXPECT().
+					FindDueEventDeliveriesBySubscriptionID(gomock.Any(), "sub-1", gomock.Any(), 2).
+					Return([]datastore.EventDelivery{}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Remaining: 0,
+				}, nil).Times(1)
+			},
+		},
+		{
+			name:          "Batched delivery claimed by a concurrent batch waits for it",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: &BatchError{Err: ErrBatchInFlight, delay: batchPollDelay},
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:         "sub-1",
+						Status:      datastore.ActiveSubscriptionStatus,
+						BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 2, MaxWait: 60},
+					}, nil)
+
+				now := primitive.NewDateTimeFromTime(time.Now())
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID:            "delivery-1",
+						SubscriptionID: "sub-1",
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"id":1}`),
+							NextSendTime:    now,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:    datastore.ScheduledEventStatus,
+						CreatedAt: now,
+					}, nil).Times(1)
+
+				m.EXPECT().
+					FindDueEventDeliveriesBySubscriptionID(gomock.Any(), "sub-1", gomock.Any(), 2).
+					Return([]datastore.EventDelivery{
+						{
+							UID:            "delivery-2",
+							SubscriptionID: "sub-1",
+							Metadata: &datastore.Metadata{
+								Data:            []byte(`{"id":2}`),
+								NextSendTime:    now,
+								RetryLimit:      3,
+								IntervalSeconds: 20,
+							},
+							Status:    datastore.ScheduledEventStatus,
+							CreatedAt: now,
+						},
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{Config: &datastore.GroupConfig{
+						RateLimit: &datastore.DefaultRateLimitConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+					}}, nil).Times(1)
+
+				m.EXPECT().
+					ClaimEventDeliveries(gomock.Any(), []string{"delivery-1", "delivery-2"}, batchStatuses).
+					Return([]string{"delivery-2"}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDeliveries(gomock.Any(), []string{"delivery-2"}, datastore.RetryEventStatus).
+					Return(nil).Times(1)
+			},
+		},
+		{
+			name:          "Batched deliveries are released when the batch can't be signed",
+			cfgPath:       "./testdata/Config/basic-convoy.json",
+			expectedError: &EndpointError{Err: errors.New("signature header is required"), delay: 20 * time.Second},
+			msg: &datastore.EventDelivery{
+				UID: "",
+			},
+			dbFn: func(a *mocks.MockApplicationRepository, o *mocks.MockGroupRepository, m *mocks.MockEventDeliveryRepository, r *mocks.MockRateLimiter, s *mocks.MockSubscriptionRepository, q *mocks.MockQueuer) {
+				a.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Endpoint{
+						TargetURL:         "https://google.com",
+						RateLimit:         10,
+						RateLimitDuration: "1m",
+					}, nil)
+				a.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Application{GroupID: "123"}, nil)
+				s.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+					Return(&datastore.Subscription{
+						UID:         "sub-1",
+						Status:      datastore.ActiveSubscriptionStatus,
+						BatchConfig: &datastore.BatchConfiguration{Enabled: true, MaxSize: 2, MaxWait: 60},
+					}, nil)
+
+				now := primitive.NewDateTimeFromTime(time.Now())
+				m.EXPECT().
+					FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.EventDelivery{
+						UID:            "delivery-1",
+						SubscriptionID: "sub-1",
+						Metadata: &datastore.Metadata{
+							Data:            []byte(`{"id":1}`),
+							NextSendTime:    now,
+							RetryLimit:      3,
+							IntervalSeconds: 20,
+						},
+						Status:    datastore.ScheduledEventStatus,
+						CreatedAt: now,
+					}, nil).Times(1)
+
+				m.EXPECT().
+					FindDueEventDeliveriesBySubscriptionID(gomock.Any(), "sub-1", gomock.Any(), 2).
+					Return([]datastore.EventDelivery{
+						{
+							UID:            "delivery-2",
+							SubscriptionID: "sub-1",
+							Metadata: &datastore.Metadata{
+								Data:            []byte(`{"id":2}`),
+								NextSendTime:    now,
+								RetryLimit:      3,
+								IntervalSeconds: 20,
+							},
+							Status:    datastore.ScheduledEventStatus,
+							CreatedAt: now,
+						},
+					}, nil).Times(1)
+
+				r.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				r.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&redis_rate.Result{
+					Limit:     redis_rate.PerMinute(10),
+					Allowed:   10,
+					Remaining: 10,
+				}, nil).Times(1)
+
+				o.EXPECT().
+					FetchGroupByID(gomock.Any(), gomock.Any()).
+					Return(&datastore.Group{Config: &datastore.GroupConfig{
+						Signature: &datastore.SignatureConfiguration{},
+						RateLimit: &datastore.DefaultRateLimitConfig,
+						Strategy:  &datastore.DefaultStrategyConfig,
+					}}, nil).Times(1)
+
+				m.EXPECT().
+					ClaimEventDeliveries(gomock.Any(), []string{"delivery-1", "delivery-2"}, batchStatuses).
+					Return([]string{"delivery-1", "delivery-2"}, nil).Times(1)
+
+				m.EXPECT().
+					UpdateStatusOfEventDeliveries(gomock.Any(), []string{"delivery-1", "delivery-2"}, datastore.RetryEventStatus).
+					Return(nil).Times(1)
+			},
+		},
+	}
+
+	for _, tc := range tt {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			groupRepo := mocks.NewMockGroupRepository(ctrl)
+			appRepo := mocks.NewMockApplicationRepository(ctrl)
 			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
 			apiKeyRepo := mocks.NewMockAPIKeyRepository(ctrl)
 			userRepo := mocks.NewMockUserRepository(ctrl)
@@ -937,7 +1949,7 @@ func TestProcessEventDelivery(t *testing.T) {
 				tc.dbFn(appRepo, groupRepo, msgRepo, rateLimiter, subRepo, q)
 			}
 
-			processFn := ProcessEventDelivery(appRepo, msgRepo, groupRepo, rateLimiter, subRepo, q)
+			processFn := ProcessEventDelivery(appRepo, msgRepo, groupRepo, rateLimiter, subRepo, cache, q)
 
 			payload := json.RawMessage(tc.msg.UID)
 
@@ -955,6 +1967,416 @@ func TestProcessEventDelivery(t *testing.T) {
 	}
 }
 
+func TestProcessEventDelivery_CircuitBreaker(t *testing.T) {
+	metrics.Reset()
+
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocks.NewMockGroupRepository(ctrl)
+	appRepo := mocks.NewMockApplicationRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	c := mcache.NewMemoryCache()
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	appRepo.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.Endpoint{
+			UID:               "endpoint-1",
+			TargetURL:         "https://google.com",
+			RateLimit:         10,
+			RateLimitDuration: "1m",
+		}, nil).Times(2)
+	appRepo.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.Application{GroupID: "123"}, nil).Times(2)
+	subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.Subscription{Status: datastore.ActiveSubscriptionStatus}, nil).Times(2)
+
+	msgRepo.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(context.Context, string) (*datastore.EventDelivery, error) {
+			return &datastore.EventDelivery{
+				Metadata: &datastore.Metadata{
+					Data:            []byte(`{"event": "invoice.completed"}`),
+					NumTrials:       0,
+					RetryLimit:      3,
+					IntervalSeconds: 20,
+				},
+				Status: datastore.ScheduledEventStatus,
+			}, nil
+		}).Times(2)
+
+	groupRepo.EXPECT().FetchGroupByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.Group{
+			UID: "group-1",
+			Config: &datastore.GroupConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Hash:   "SHA256",
+				},
+				Strategy: &datastore.StrategyConfiguration{
+					Type:       datastore.LinearStrategyProvider,
+					Duration:   60,
+					RetryCount: 1,
+				},
+				RateLimit:      &datastore.DefaultRateLimitConfig,
+				CircuitBreaker: &datastore.CircuitBreakerConfiguration{FailureThreshold: 1, OpenDuration: 60},
+			},
+		}, nil).Times(2)
+
+	// the breaker is asked after the rate limiter, only the first delivery
+	// is claimed and attempted
+	rateLimiter.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&redis_rate.Result{Limit: redis_rate.PerMinute(10), Allowed: 10, Remaining: 10}, nil).Times(2)
+	rateLimiter.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&redis_rate.Result{Limit: redis_rate.PerMinute(10), Allowed: 10, Remaining: 10}, nil).Times(2)
+	msgRepo.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+	msgRepo.EXPECT().UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil).Times(1)
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+	httpmock.RegisterResponder("POST", "https://google.com", httpmock.NewStringResponder(500, ``))
+
+	processFn := ProcessEventDelivery(appRepo, msgRepo, groupRepo, rateLimiter, subRepo, c, q)
+	task := asynq.NewTask(string(convoy.EventProcessor), nil, asynq.Queue(string(convoy.EventQueue)))
+
+	err = processFn(context.Background(), task)
+	require.Equal(t, &EndpointError{Err: ErrDeliveryAttemptFailed, delay: 20 * time.Second}, err)
+	require.Equal(t, 1, httpmock.GetTotalCallCount())
+	require.Equal(t, float64(1), testutil.ToFloat64(metrics.CircuitBreakerTransitions().WithLabelValues("group-1", "closed", "open")))
+
+	// the breaker opened, so the next delivery is requeued without being sent
+	err = processFn(context.Background(), task)
+
+	var cbErr *CircuitBreakerError
+	require.True(t, errors.As(err, &cbErr))
+	require.ErrorIs(t, cbErr.Err, circuitbreaker.ErrOpen)
+	require.Greater(t, cbErr.Delay(), 59*time.Second)
+	require.LessOrEqual(t, cbErr.Delay(), 61*time.Second)
+	require.Equal(t, 1, httpmock.GetTotalCallCount())
+}
+
+func TestProcessEventDelivery_OAuth2(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocks.NewMockGroupRepository(ctrl)
+	appRepo := mocks.NewMockApplicationRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+	c := mcache.NewMemoryCache()
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	appRepo.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.Endpoint{
+			UID:               "endpoint-1",
+			TargetURL:         "https://google.com",
+			RateLimit:         10,
+			RateLimitDuration: "1m",
+			Authentication: &datastore.EndpointAuthentication{
+				Type: datastore.OAuth2Authentication,
+				OAuth2: &datastore.OAuth2{
+					TokenURL:     "https://auth.example.com/token",
+					ClientID:     "client",
+					ClientSecret: "secret",
+				},
+			},
+		}, nil)
+	appRepo.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.Application{GroupID: "123"}, nil)
+	subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.Subscription{Status: datastore.ActiveSubscriptionStatus}, nil)
+
+	msgRepo.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.EventDelivery{
+			Metadata: &datastore.Metadata{
+				Data:            []byte(`{"event": "invoice.completed"}`),
+				NumTrials:       0,
+				RetryLimit:      3,
+				IntervalSeconds: 20,
+			},
+			Status: datastore.ScheduledEventStatus,
+		}, nil)
+
+	groupRepo.EXPECT().FetchGroupByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.Group{
+			Config: &datastore.GroupConfig{
+				Signature: &datastore.SignatureConfiguration{
+					Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+					Hash:   "SHA256",
+				},
+				Strategy: &datastore.StrategyConfiguration{
+					Type:       datastore.LinearStrategyProvider,
+					Duration:   60,
+					RetryCount: 1,
+				},
+				RateLimit: &datastore.DefaultRateLimitConfig,
+			},
+		}, nil)
+
+	rateLimiter.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&redis_rate.Result{Limit: redis_rate.PerMinute(10), Allowed: 10, Remaining: 10}, nil)
+	rateLimiter.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&redis_rate.Result{Limit: redis_rate.PerMinute(10), Allowed: 10, Remaining: 10}, nil)
+	msgRepo.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	msgRepo.EXPECT().UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, ed datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+			require.Equal(t, datastore.SuccessEventStatus, ed.Status)
+			require.NotContains(t, ed.Headers, "Authorization")
+			require.Equal(t, "[REDACTED]", attempt.RequestHeader["Authorization"])
+			return nil
+		})
+
+	httpmock.Activate()
+	defer httpmock.DeactivateAndReset()
+
+	tokens := 0
+	httpmock.RegisterResponder("POST", "https://auth.example.com/token",
+		func(r *http.Request) (*http.Response, error) {
+			tokens++
+			return httpmock.NewJsonResponse(http.StatusOK, map[string]interface{}{
+				"access_token": fmt.Sprintf("token-%d", tokens),
+				"token_type":   "bearer",
+				"expires_in":   3600,
+			})
+		})
+
+	// the first token is rejected, the delivery is sent again with a new one
+	httpmock.RegisterResponder("POST", "https://google.com",
+		func(r *http.Request) (*http.Response, error) {
+			if r.Header.Get("Authorization") != "Bearer token-2" {
+				return httpmock.NewStringResponse(http.StatusUnauthorized, ``), nil
+			}
+			return httpmock.NewStringResponse(http.StatusOK, ``), nil
+		})
+
+	processFn := ProcessEventDelivery(appRepo, msgRepo, groupRepo, rateLimiter, subRepo, c, q)
+	task := asynq.NewTask(string(convoy.EventProcessor), nil, asynq.Queue(string(convoy.EventQueue)))
+
+	err = processFn(context.Background(), task)
+	require.NoError(t, err)
+	require.Equal(t, 2, tokens)
+	require.Equal(t, 4, httpmock.GetTotalCallCount())
+}
+
+func TestProcessEventDelivery_OAuth2TokenFailure(t *testing.T) {
+	tests := []struct {
+		name         string
+		cfgPath      string
+		tokenURL     string
+		status       datastore.EventDeliveryStatus
+		attemptError string
+		wantErr      bool
+	}{
+		{
+			name:         "token endpoint fails",
+			cfgPath:      "./testdata/Config/basic-convoy.json",
+			tokenURL:     "https://auth.example.com/token",
+			status:       datastore.RetryEventStatus,
+			attemptError: "failed to fetch oauth2 token",
+			wantErr:      true,
+		},
+		{
+			name:         "token url blocked by the egress policy",
+			cfgPath:      "./testdata/Config/basic-convoy-egress-policy.json",
+			tokenURL:     "http://169.254.169.254/token",
+			status:       datastore.FailureEventStatus,
+			attemptError: "blocked by egress policy",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			groupRepo := mocks.NewMockGroupRepository(ctrl)
+			appRepo := mocks.NewMockApplicationRepository(ctrl)
+			msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+			rateLimiter := mocks.NewMockRateLimiter(ctrl)
+			subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+			q := mocks.NewMockQueuer(ctrl)
+			c := mcache.NewMemoryCache()
+
+			err := config.LoadConfig(tc.cfgPath)
+			require.NoError(t, err)
+
+			appRepo.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.Endpoint{
+					UID:               "endpoint-1",
+					TargetURL:         "https://google.com",
+					RateLimit:         10,
+					RateLimitDuration: "1m",
+					Authentication: &datastore.EndpointAuthentication{
+						Type: datastore.OAuth2Authentication,
+						OAuth2: &datastore.OAuth2{
+							TokenURL:     tc.tokenURL,
+							ClientID:     "client",
+							ClientSecret: "secret",
+						},
+					},
+				}, nil)
+			appRepo.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+				Return(&datastore.Application{GroupID: "123"}, nil)
+			subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&datastore.Subscription{Status: datastore.ActiveSubscriptionStatus}, nil)
+
+			msgRepo.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+				Return(&datastore.EventDelivery{
+					Metadata: &datastore.Metadata{
+						Data:            []byte(`{"event": "invoice.completed"}`),
+						NumTrials:       0,
+						RetryLimit:      3,
+						IntervalSeconds: 20,
+					},
+					Status: datastore.ScheduledEventStatus,
+				}, nil)
+
+			groupRepo.EXPECT().FetchGroupByID(gomock.Any(), gomock.Any()).
+				Return(&datastore.Group{
+					Config: &datastore.GroupConfig{
+						Signature: &datastore.SignatureConfiguration{
+							Header: config.SignatureHeaderProvider("X-Convoy-Signature"),
+							Hash:   "SHA256",
+						},
+						Strategy: &datastore.StrategyConfiguration{
+							Type:       datastore.LinearStrategyProvider,
+							Duration:   60,
+							RetryCount: 1,
+						},
+						RateLimit: &datastore.DefaultRateLimitConfig,
+					},
+				}, nil)
+
+			rateLimiter.EXPECT().ShouldAllow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&redis_rate.Result{Limit: redis_rate.PerMinute(10), Allowed: 10, Remaining: 10}, nil)
+			rateLimiter.EXPECT().Allow(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+				Return(&redis_rate.Result{Limit: redis_rate.PerMinute(10), Allowed: 10, Remaining: 10}, nil)
+			msgRepo.EXPECT().UpdateStatusOfEventDelivery(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+
+			// the failed token request is recorded as a failed attempt
+			msgRepo.EXPECT().UpdateEventDeliveryWithAttempt(gomock.Any(), gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, ed datastore.EventDelivery, attempt datastore.DeliveryAttempt) error {
+					require.Equal(t, tc.status, ed.Status)
+					require.False(t, attempt.Status)
+					require.Equal(t, "https://google.com", attempt.URL)
+					require.Equal(t, string(convoy.HttpPost), attempt.Method)
+					require.Contains(t, attempt.Error, tc.attemptError)
+					return nil
+				})
+
+			// a delivery that failed for good goes to the dead letter queue
+			q.EXPECT().Write(convoy.DeadLetterProcessor, convoy.DefaultQueue, gomock.Any()).AnyTimes().Return(nil)
+
+			httpmock.Activate()
+			defer httpmock.DeactivateAndReset()
+
+			httpmock.RegisterResponder("POST", "https://auth.example.com/token",
+				httpmock.NewStringResponder(http.StatusInternalServerError, `{"error": "server_error"}`))
+
+			processFn := ProcessEventDelivery(appRepo, msgRepo, groupRepo, rateLimiter, subRepo, c, q)
+			task := asynq.NewTask(string(convoy.EventProcessor), nil, asynq.Queue(string(convoy.EventQueue)))
+
+			err = processFn(context.Background(), task)
+			if tc.wantErr {
+				require.IsType(t, &EndpointError{}, err)
+			} else {
+				require.NoError(t, err)
+			}
+
+			// the webhook isn't sent without a token
+			require.Equal(t, 0, httpmock.GetCallCountInfo()["POST https://google.com"])
+		})
+	}
+}
+
+func TestProcessEventDelivery_InvalidTLSConfig(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocks.NewMockGroupRepository(ctrl)
+	appRepo := mocks.NewMockApplicationRepository(ctrl)
+	msgRepo := mocks.NewMockEventDeliveryRepository(ctrl)
+	rateLimiter := mocks.NewMockRateLimiter(ctrl)
+	subRepo := mocks.NewMockSubscriptionRepository(ctrl)
+	q := mocks.NewMockQueuer(ctrl)
+
+	err := config.LoadConfig("./testdata/Config/basic-convoy.json")
+	require.NoError(t, err)
+
+	appRepo.EXPECT().FindApplicationEndpointByID(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.Endpoint{
+			UID:               "endpoint-1",
+			TargetURL:         "https://google.com",
+			RateLimit:         10,
+			RateLimitDuration: "1m",
+			TLSConfig:         &datastore.TLSConfiguration{CACert: "not a certificate"},
+		}, nil)
+	appRepo.EXPECT().FindApplicationByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.Application{GroupID: "123"}, nil)
+	subRepo.EXPECT().FindSubscriptionByID(gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(&datastore.Subscription{Status: datastore.ActiveSubscriptionStatus}, nil)
+	msgRepo.EXPECT().FindEventDeliveryByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.EventDelivery{
+			Metadata: &datastore.Metadata{
+				Data:            []byte(`{"event": "invoice.completed"}`),
+				RetryLimit:      3,
+				IntervalSeconds: 20,
+			},
+			Status: datastore.ScheduledEventStatus,
+		}, nil)
+	groupRepo.EXPECT().FetchGroupByID(gomock.Any(), gomock.Any()).
+		Return(&datastore.Group{Config: &datastore.GroupConfig{
+			RateLimit: &datastore.DefaultRateLimitConfig,
+			Strategy:  &datastore.DefaultStrategyConfig,
+		}}, nil)
+
+	// the delivery is retried without being claimed, there is no expected
+	// call to UpdateStatusOfEventDelivery that would leave it processing
+	processFn := ProcessEventDelivery(appRepo, msgRepo, groupRepo, rateLimiter, subRepo, mcache.NewMemoryCache(), q)
+	task := asynq.NewTask(string(convoy.EventProcessor), nil, asynq.Queue(string(convoy.EventQueue)))
+
+	err = processFn(context.Background(), task)
+	require.IsType(t, &EndpointError{}, err)
+}
+
+func TestOrderingDelay(t *testing.T) {
+	require.Equal(t, minOrderingDelay, orderingDelay(&datastore.EventDelivery{}))
+
+	head := &datastore.EventDelivery{Metadata: &datastore.Metadata{
+		NextSendTime: primitive.NewDateTimeFromTime(time.Now().Add(time.Minute)),
+	}}
+
+	// the delivery waits until the head's next attempt
+	delay := orderingDelay(head)
+	require.Greater(t, delay, 59*time.Second)
+	require.LessOrEqual(t, delay, 61*time.Second)
+}
+
+func TestIsStaleProcessing(t *testing.T) {
+	now := time.Now()
+	ed := &datastore.EventDelivery{
+		Status:    datastore.ProcessingEventStatus,
+		UpdatedAt: primitive.NewDateTimeFromTime(now.Add(-time.Minute)),
+	}
+
+	// the request could still be in flight
+	require.False(t, isStaleProcessing(ed, 30*time.Second, now))
+
+	ed.UpdatedAt = primitive.NewDateTimeFromTime(now.Add(-2 * time.Minute))
+	require.True(t, isStaleProcessing(ed, 30*time.Second, now))
+
+	ed.Status = datastore.RetryEventStatus
+	require.False(t, isStaleProcessing(ed, 30*time.Second, now))
+}
+
 func TestProcessEventDeliveryConfig(t *testing.T) {
 	tt := []struct {
 		name                string
@@ -1055,3 +2477,212 @@ func TestProcessEventDeliveryConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestSignPayload(t *testing.T) {
+	publicKey, privateKey, err := util.GenerateEd25519KeyPair()
+	assert.NoError(t, err)
+
+	data := json.RawMessage(`{"event": "invoice.completed"}`)
+
+	g := &datastore.Group{Config: &datastore.GroupConfig{
+		Signature:     &datastore.SignatureConfiguration{Header: "X-Convoy-Signature", Hash: "SHA256"},
+		ReplayAttacks: true,
+	}}
+	payload, headers, err := signPayload(g, []string{"secret"}, "delivery-1", data)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"event":"invoice.completed"}`, string(payload))
+	assert.Len(t, headers["X-Convoy-Signature"], 1)
+	assert.Len(t, headers["Convoy-Timestamp"], 1)
+
+	g.Config.Signature.Scheme = datastore.StandardWebhooksSignatureScheme
+	_, headers, err = signPayload(g, []string{"secret"}, "delivery-1", data)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"delivery-1"}, headers["webhook-id"])
+	assert.Len(t, headers["webhook-timestamp"], 1)
+	assert.True(t, strings.HasPrefix(headers["webhook-signature"][0], "v1,"))
+	assert.Nil(t, headers["X-Convoy-Signature"])
+
+	g.Config.Signature.Scheme = datastore.Ed25519SignatureScheme
+	g.Config.Signature.PublicKey, g.Config.Signature.PrivateKey = publicKey, privateKey
+	payload, headers, err = signPayload(g, []string{"secret"}, "delivery-1", data)
+	assert.NoError(t, err)
+
+	pub, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(publicKey, util.StandardWebhooksPublicKeyPrefix))
+	sig, _ := base64.StdEncoding.DecodeString(strings.TrimPrefix(headers["webhook-signature"][0], "v1a,"))
+	signed := "delivery-1." + headers["webhook-timestamp"][0] + "." + string(payload)
+	assert.True(t, ed25519.Verify(pub, []byte(signed), sig))
+}
+
+func TestSignPayload_rotatedSecrets(t *testing.T) {
+	data := json.RawMessage(`{"event": "invoice.completed"}`)
+	g := &datastore.Group{Config: &datastore.GroupConfig{
+		Signature: &datastore.SignatureConfiguration{Header: "X-Convoy-Signature", Hash: "SHA256"},
+	}}
+
+	_, headers, err := signPayload(g, []string{"new-secret", "old-secret"}, "delivery-1", data)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(headers["X-Convoy-Signature"][0], ","), 2)
+
+	g.Config.Signature.Scheme = datastore.StandardWebhooksSignatureScheme
+	_, headers, err = signPayload(g, []string{"new-secret", "old-secret"}, "delivery-1", data)
+	assert.NoError(t, err)
+	assert.Len(t, strings.Split(headers["webhook-signature"][0], " "), 2)
+}
+
+func TestRecordAttempt(t *testing.T) {
+	header := http.Header{}
+	header.Set("Retry-After", "120")
+	resp := &net.Response{StatusCode: http.StatusTooManyRequests, ResponseHeader: header}
+
+	tests := []struct {
+		name          string
+		metadata      *datastore.Metadata
+		class         datastore.AttemptClassification
+		createdAt     time.Time
+		expectedDelay time.Duration
+		expectedOut   bool
+		status        datastore.EventDeliveryStatus
+		description   string
+	}{
+		{
+			name:          "should follow the retry strategy",
+			metadata:      &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 20, RetryLimit: 3},
+			class:         datastore.RetryableFailureAttempt,
+			createdAt:     time.Now(),
+			expectedDelay: 20 * time.Second,
+			status:        datastore.RetryEventStatus,
+		},
+		{
+			name: "should honor retry after",
+			metadata: &datastore.Metadata{
+				Strategy:        datastore.LinearStrategyProvider,
+				IntervalSeconds: 20,
+				RetryLimit:      3,
+				HonorRetryAfter: true,
+			},
+			class:         datastore.RetryableFailureAttempt,
+			createdAt:     time.Now(),
+			expectedDelay: 2 * time.Minute,
+			status:        datastore.RetryEventStatus,
+		},
+		{
+			name: "should fail after the max elapsed time",
+			metadata: &datastore.Metadata{
+				Strategy:       datastore.ScheduleStrategyProvider,
+				Schedule:       []uint64{10, 3600},
+				NumTrials:      1,
+				RetryLimit:     3,
+				MaxElapsedTime: 1800,
+			},
+			class:         datastore.RetryableFailureAttempt,
+			createdAt:     time.Now(),
+			expectedDelay: time.Hour,
+			expectedOut:   true,
+			status:        datastore.FailureEventStatus,
+			description:   "Max elapsed time exceeded",
+		},
+		{
+			name:          "should not retry a permanent failure",
+			metadata:      &datastore.Metadata{Strategy: datastore.LinearStrategyProvider, IntervalSeconds: 20, RetryLimit: 3},
+			class:         datastore.PermanentFailureAttempt,
+			createdAt:     time.Now(),
+			expectedDelay: 20 * time.Second,
+			status:        datastore.FailureEventStatus,
+			description:   "Permanent failure",
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			ed := &datastore.EventDelivery{
+				Metadata:  tc.metadata,
+				CreatedAt: primitive.NewDateTimeFromTime(tc.createdAt),
+			}
+
+			delay, out := recordAttempt(ed, resp, tc.class, false)
+			require.Equal(t, tc.expectedDelay, delay)
+			require.Equal(t, tc.expectedOut, out)
+			require.Equal(t, tc.status, ed.Status)
+			require.Equal(t, tc.description, ed.Description)
+		})
+	}
+}
+
+func TestClassifyAttempt(t *testing.T) {
+	policy := &datastore.FailurePolicy{
+		PermanentStatusCodes:   []int{http.StatusBadRequest, http.StatusGone},
+		FailureStatusCodes:     []int{http.StatusAccepted},
+		RetryableNetworkErrors: []datastore.NetworkError{datastore.TimeoutNetworkError},
+	}
+
+	tests := []struct {
+		name       string
+		policy     *datastore.FailurePolicy
+		statusCode int
+		err        error
+		expected   datastore.AttemptClassification
+	}{
+		{
+			name:       "should succeed on 2xx without a policy",
+			statusCode: http.StatusOK,
+			expected:   datastore.SuccessAttempt,
+		},
+		{
+			name:       "should retry non 2xx without a policy",
+			statusCode: http.StatusGone,
+			expected:   datastore.RetryableFailureAttempt,
+		},
+		{
+			name:     "should retry network errors without a policy",
+			err:      &stdnet.DNSError{Err: "no such host", Name: "example.invalid"},
+			expected: datastore.RetryableFailureAttempt,
+		},
+		{
+			name:     "should not retry egress denied",
+			err:      net.ErrEgressDenied,
+			expected: datastore.PermanentFailureAttempt,
+		},
+		{
+			name:       "should not retry permanent status codes",
+			policy:     policy,
+			statusCode: http.StatusGone,
+			expected:   datastore.PermanentFailureAttempt,
+		},
+		{
+			name:       "should retry failure status codes",
+			policy:     policy,
+			statusCode: http.StatusAccepted,
+			expected:   datastore.RetryableFailureAttempt,
+		},
+		{
+			name:       "should succeed on other 2xx",
+			policy:     policy,
+			statusCode: http.StatusOK,
+			expected:   datastore.SuccessAttempt,
+		},
+		{
+			name:       "should retry other status codes",
+			policy:     policy,
+			statusCode: http.StatusInternalServerError,
+			expected:   datastore.RetryableFailureAttempt,
+		},
+		{
+			name:     "should retry listed network errors",
+			policy:   policy,
+			err:      os.ErrDeadlineExceeded,
+			expected: datastore.RetryableFailureAttempt,
+		},
+		{
+			name:     "should not retry unlisted network errors",
+			policy:   policy,
+			err:      &stdnet.DNSError{Err: "no such host", Name: "example.invalid"},
+			expected: datastore.PermanentFailureAttempt,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, classifyAttempt(tc.policy, tc.statusCode, tc.err))
+		})
+	}
+}
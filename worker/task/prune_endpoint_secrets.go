@@ -0,0 +1,63 @@
+package task
+
+import (
+	"context"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/hibiken/asynq"
+	log "github.com/sirupsen/logrus"
+)
+
+// PruneEndpointSecrets removes the expired secrets of every group's
+// application endpoints. Deliveries aren't signed with expired secrets, this
+// keeps them from piling up on endpoints whose secrets are rotated often.
+func PruneEndpointSecrets(groupRepo datastore.GroupRepository, appRepo datastore.ApplicationRepository) func(context.Context, *asynq.Task) error {
+	return func(ctx context.Context, t *asynq.Task) error {
+		groups, err := groupRepo.LoadGroups(ctx, &datastore.GroupFilter{})
+		if err != nil {
+			log.WithError(err).Error("failed to load groups")
+			return err
+		}
+
+		now := time.Now()
+		for _, g := range groups {
+			p := datastore.Pageable{Page: 1, PerPage: 100}
+			for {
+				apps, pagination, err := appRepo.LoadApplicationsPagedByGroupId(ctx, g.UID, p)
+				if err != nil {
+					log.WithError(err).Errorf("failed to load applications of group %s", g.UID)
+					return err
+				}
+
+				for i := range apps {
+					app := &apps[i]
+
+					pruned := false
+					for j := range app.Endpoints {
+						if app.Endpoints[j].PruneSecrets(now) {
+							pruned = true
+						}
+					}
+
+					if !pruned {
+						continue
+					}
+
+					err = appRepo.UpdateApplication(ctx, app, app.GroupID)
+					if err != nil {
+						log.WithError(err).Errorf("failed to prune endpoint secrets of application %s", app.UID)
+						return err
+					}
+				}
+
+				if pagination.Next <= int64(p.Page) || len(apps) == 0 {
+					break
+				}
+				p.Page = int(pagination.Next)
+			}
+		}
+
+		return nil
+	}
+}
@@ -0,0 +1,57 @@
+package task
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/frain-dev/convoy/datastore"
+	"github.com/frain-dev/convoy/mocks"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestPruneEndpointSecrets(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	groupRepo := mocks.NewMockGroupRepository(ctrl)
+	appRepo := mocks.NewMockApplicationRepository(ctrl)
+
+	expired := primitive.NewDateTimeFromTime(time.Now().Add(-time.Hour))
+	active := primitive.NewDateTimeFromTime(time.Now().Add(time.Hour))
+
+	apps := []datastore.Application{
+		{
+			UID:     "app-1",
+			GroupID: "group-1",
+			Endpoints: []datastore.Endpoint{
+				{UID: "endpoint-1", Secrets: []datastore.EndpointSecret{{Value: "old", ExpiresAt: expired}, {Value: "previous", ExpiresAt: active}}},
+			},
+		},
+		{
+			UID:     "app-2",
+			GroupID: "group-1",
+			Endpoints: []datastore.Endpoint{
+				{UID: "endpoint-2", Secrets: []datastore.EndpointSecret{{Value: "previous", ExpiresAt: active}}},
+			},
+		},
+	}
+
+	groupRepo.EXPECT().LoadGroups(gomock.Any(), gomock.Any()).Return([]*datastore.Group{{UID: "group-1"}}, nil)
+	appRepo.EXPECT().LoadApplicationsPagedByGroupId(gomock.Any(), "group-1", datastore.Pageable{Page: 1, PerPage: 100}).
+		Return(apps, datastore.PaginationData{Page: 1, Next: 0}, nil)
+
+	// only the application with an expired secret is updated
+	appRepo.EXPECT().UpdateApplication(gomock.Any(), gomock.Any(), "group-1").
+		DoAndReturn(func(_ context.Context, app *datastore.Application, _ string) error {
+			require.Equal(t, "app-1", app.UID)
+			require.Len(t, app.Endpoints[0].Secrets, 1)
+			require.Equal(t, "previous", app.Endpoints[0].Secrets[0].Value)
+			return nil
+		})
+
+	err := PruneEndpointSecrets(groupRepo, appRepo)(context.Background(), nil)
+	require.NoError(t, err)
+}
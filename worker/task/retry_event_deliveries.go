@@ -8,14 +8,19 @@ import (
 
 	"github.com/frain-dev/convoy"
 	"github.com/frain-dev/convoy/datastore"
-	"github.com/frain-dev/convoy/datastore/mongo"
 	"github.com/frain-dev/convoy/queue"
-	redisqueue "github.com/frain-dev/convoy/queue/redis"
 	"github.com/frain-dev/convoy/util"
 	log "github.com/sirupsen/logrus"
 )
 
-func RetryEventDeliveries(statuses []datastore.EventDeliveryStatus, lookBackDuration string, store datastore.Store, eventQueue queue.Queuer) {
+// requeuer is implemented by the queues event deliveries can be requeued on,
+// the deliveries are removed from the queue before they are written again.
+type requeuer interface {
+	queue.Queuer
+	DeleteEventDeliveriesfromQueue(convoy.QueueName, []string) error
+}
+
+func RetryEventDeliveries(statuses []datastore.EventDeliveryStatus, lookBackDuration string, eventDeliveryRepo datastore.EventDeliveryRepository, groupRepo datastore.GroupRepository, eventQueue queue.Queuer) {
 	if statuses == nil {
 		statuses = []datastore.EventDeliveryStatus{"Retry", "Scheduled", "Processing"}
 	}
@@ -48,17 +53,15 @@ func RetryEventDeliveries(statuses []datastore.EventDeliveryStatus, lookBackDura
 		count := 0
 
 		ctx := context.Background()
-		var q *redisqueue.RedisQueue
-		q, ok := eventQueue.(*redisqueue.RedisQueue)
+		q, ok := eventQueue.(requeuer)
 		if !ok {
 			log.Errorf("Invalid queue type for requeing event deliveries: %T", eventQueue)
+			return
 		}
 
 		var wg sync.WaitGroup
 
 		wg.Add(1)
-		eventDeliveryRepo := mongo.NewEventDeliveryRepository(store)
-		groupRepo := mongo.NewGroupRepo(store)
 
 		go processEventDeliveryBatch(ctx, status, eventDeliveryRepo, groupRepo, deliveryChan, q, &wg)
 
@@ -95,7 +98,7 @@ func RetryEventDeliveries(statuses []datastore.EventDeliveryStatus, lookBackDura
 	}
 }
 
-func processEventDeliveryBatch(ctx context.Context, status datastore.EventDeliveryStatus, eventDeliveryRepo datastore.EventDeliveryRepository, groupRepo datastore.GroupRepository, deliveryChan <-chan []datastore.EventDelivery, q *redisqueue.RedisQueue, wg *sync.WaitGroup) {
+func processEventDeliveryBatch(ctx context.Context, status datastore.EventDeliveryStatus, eventDeliveryRepo datastore.EventDeliveryRepository, groupRepo datastore.GroupRepository, deliveryChan <-chan []datastore.EventDelivery, q requeuer, wg *sync.WaitGroup) {
 	defer wg.Done()
 
 	batchCount := 1
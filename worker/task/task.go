@@ -35,6 +35,51 @@ func (e *RateLimitError) Delay() time.Duration {
 func (e *RateLimitError) RateLimit() {
 }
 
+// CircuitBreakerError is returned when an endpoint's circuit breaker is open,
+// the delivery is requeued without counting as an attempt.
+type CircuitBreakerError struct {
+	delay time.Duration
+	Err   error
+}
+
+func (e *CircuitBreakerError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *CircuitBreakerError) Delay() time.Duration {
+	return e.delay
+}
+
+// OrderingError is returned when an ordered delivery has to wait for the ones
+// before it, the delivery is requeued without counting as an attempt.
+type OrderingError struct {
+	delay time.Duration
+	Err   error
+}
+
+func (e *OrderingError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *OrderingError) Delay() time.Duration {
+	return e.delay
+}
+
+// BatchError is returned when a batched delivery waits for its batch to fill
+// up or to be sent, the delivery is requeued without counting as an attempt.
+type BatchError struct {
+	delay time.Duration
+	Err   error
+}
+
+func (e *BatchError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *BatchError) Delay() time.Duration {
+	return e.delay
+}
+
 func GetRetryDelay(n int, err error, t *asynq.Task) time.Duration {
 	if endpointError, ok := err.(*EndpointError); ok {
 		return endpointError.Delay()
@@ -42,5 +87,14 @@ func GetRetryDelay(n int, err error, t *asynq.Task) time.Duration {
 	if rateLimitError, ok := err.(*RateLimitError); ok {
 		return rateLimitError.Delay()
 	}
+	if circuitBreakerError, ok := err.(*CircuitBreakerError); ok {
+		return circuitBreakerError.Delay()
+	}
+	if orderingError, ok := err.(*OrderingError); ok {
+		return orderingError.Delay()
+	}
+	if batchError, ok := err.(*BatchError); ok {
+		return batchError.Delay()
+	}
 	return defaultDelay
 }